@@ -2,11 +2,16 @@ package main
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/user"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -14,9 +19,12 @@ import (
 	"github.com/cdtdelta/4n6time/internal/csvparser"
 	"github.com/cdtdelta/4n6time/internal/database"
 	"github.com/cdtdelta/4n6time/internal/dynamicparser"
+	"github.com/cdtdelta/4n6time/internal/ioc"
 	"github.com/cdtdelta/4n6time/internal/jsonlparser"
 	"github.com/cdtdelta/4n6time/internal/model"
 	"github.com/cdtdelta/4n6time/internal/query"
+	"github.com/cdtdelta/4n6time/internal/querylang"
+	"github.com/cdtdelta/4n6time/internal/sigma"
 	"github.com/cdtdelta/4n6time/internal/tlnparser"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -26,7 +34,10 @@ import (
 type App struct {
 	ctx    context.Context
 	store  database.Store
-	driver string // "sqlite" or "postgres"
+	driver string // "sqlite", "postgres" or "mysql"
+
+	// examiner is recorded in the audit log with every change the user makes
+	examiner string
 
 	// Logging
 	logFile    *os.File
@@ -121,7 +132,7 @@ func (a *App) saveLoggingConfig() {
 	os.WriteFile(path, data, 0644)
 }
 
-// maskConnStr masks the password in a PostgreSQL connection string for safe logging.
+// maskConnStr masks the password in a PostgreSQL or MySQL connection URL for safe logging.
 func maskConnStr(connStr string) string {
 	if idx := strings.Index(connStr, "://"); idx >= 0 {
 		rest := connStr[idx+3:]
@@ -140,6 +151,7 @@ func maskConnStr(connStr string) string {
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 	a.loadLoggingConfig()
+	a.loadExaminerConfig()
 	a.logInfo("Application started, version " + Version)
 }
 
@@ -164,6 +176,8 @@ func (a *App) CloseDatabase() {
 	if a.store != nil {
 		if a.driver == "postgres" {
 			a.logInfo("PostgreSQL disconnected")
+		} else if a.driver == "mysql" {
+			a.logInfo("MySQL disconnected")
 		} else {
 			a.logInfo("Database closed: " + a.store.Path())
 		}
@@ -246,7 +260,7 @@ func (a *App) ImportCSV() (*DBInfo, error) {
 
 	// Determine target store: import into existing database or create new SQLite
 	var store database.Store
-	importIntoExisting := a.store != nil && (a.driver == "postgres" || a.driver == "sqlite")
+	importIntoExisting := a.store != nil && (a.driver == "postgres" || a.driver == "mysql" || a.driver == "sqlite")
 
 	if !importIntoExisting {
 		// No database open: prompt for new SQLite file path
@@ -337,6 +351,16 @@ func (a *App) ImportCSV() (*DBInfo, error) {
 		return nil, fmt.Errorf("unknown format: %s", formatName)
 	}
 
+	// Record the import so its events can be linked to an evidence item
+	batchID, err := store.InsertImportBatch(&database.ImportBatch{SourcePath: csvPath, Format: formatName})
+	if err != nil {
+		closeOnError()
+		return nil, fmt.Errorf("recording import batch: %w", err)
+	}
+	for _, e := range events {
+		e.BatchID = batchID
+	}
+
 	// Insert into database
 	total := len(events)
 	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
@@ -363,9 +387,16 @@ func (a *App) ImportCSV() (*DBInfo, error) {
 	a.logInfo("Metadata update complete")
 
 	if !importIntoExisting {
-		a.store = store
-		a.driver = "sqlite"
+		a.useStore(store, "sqlite")
+	}
+
+	// Color the new events by the database's color rules
+	if n, err := a.applyColorRules(store, query.Simple("batch_id", query.Equal, strconv.FormatInt(batchID, 10))); err != nil {
+		a.logError("Applying color rules after import: " + err.Error())
+	} else if n > 0 {
+		a.logInfo(fmt.Sprintf("Color rules recolored %d imported events", n))
 	}
+
 	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
 		"phase": "done", "message": fmt.Sprintf("Import complete: %d events", total), "count": total, "total": total,
 	})
@@ -385,6 +416,23 @@ type QueryRequest struct {
 	PageSize     int          `json:"pageSize"`
 	SearchText   string       `json:"searchText"`
 	BookmarkOnly bool         `json:"bookmarkOnly"`
+
+	// Groups are parenthesized groups of filters, combined with Filters
+	// using Logic.
+	Groups []FilterGroup `json:"groups,omitempty"`
+
+	// Sort orders the results by several columns, most significant first,
+	// and takes precedence over OrderBy. Ties are broken by event id.
+	Sort []SortField `json:"sort,omitempty"`
+
+	// Direction selects cursor pagination instead of Page: "first", "last",
+	// "next" or "prev" (relative to Cursor), or "seek" to start at the first
+	// event at or after SeekDatetime. Cursors follow any sort, descending
+	// and multi-column ones included, but are only valid for the sort they
+	// came from. Leave empty to paginate by Page.
+	Direction    string `json:"direction,omitempty"`
+	Cursor       string `json:"cursor,omitempty"`
+	SeekDatetime string `json:"seekDatetime,omitempty"`
 }
 
 type FilterItem struct {
@@ -393,11 +441,37 @@ type FilterItem struct {
 	Value    string `json:"value"`
 }
 
+// SortField is one column of a multi-column sort. Direction is "ASC" or
+// "DESC"; empty means ascending.
+type SortField struct {
+	Field     string `json:"field"`
+	Direction string `json:"direction"`
+}
+
+// FilterGroup is a parenthesized group of filters. Its Filters and nested
+// Groups are combined with Logic ("AND" or "OR"), so
+// (source = EVT AND event_identifier = 4624) OR (source = EVT AND
+// event_identifier = 4625) is an OR group holding two AND groups.
+type FilterGroup struct {
+	Logic   string        `json:"logic"`
+	Filters []FilterItem  `json:"filters"`
+	Groups  []FilterGroup `json:"groups,omitempty"`
+}
+
 type QueryResponse struct {
 	Events     []*model.Event `json:"events"`
 	TotalCount int64          `json:"totalCount"`
 	Page       int            `json:"page"`
 	PageSize   int            `json:"pageSize"`
+
+	// Matches holds ranked, highlighted search snippets for the events on
+	// this page when SearchText is set and the backend has a full-text index.
+	Matches []database.SearchMatch `json:"matches,omitempty"`
+
+	// NextCursor and PrevCursor page forward and backward from this page
+	// when the request used Direction. They are empty at either end.
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
 }
 
 func (a *App) QueryEvents(req QueryRequest) (*QueryResponse, error) {
@@ -413,70 +487,16 @@ func (a *App) QueryEvents(req QueryRequest) (*QueryResponse, error) {
 	q := query.New(pageSize)
 	q.SetDialect(a.queryDialect())
 
-	// Set logic
-	if req.Logic == "OR" {
-		q.SetLogic(query.OR)
-	}
-
-	// Add filters
-	for _, f := range req.Filters {
-		// Handle date range comparisons directly via SQL
-		// since the query builder's Simple predicate handles these
-		var op query.Operator
-		switch f.Operator {
-		case "=":
-			op = query.Equal
-		case "!=":
-			op = query.NotEqual
-		case "LIKE":
-			op = query.Like
-		case "NOT LIKE":
-			op = query.NotLike
-		case ">=":
-			op = query.GreaterOrEqual
-		case "<=":
-			op = query.LessOrEqual
-		default:
-			continue
-		}
-		// Normalize partial dates for datetime fields
-		val := f.Value
-		if f.Field == "datetime" {
-			val = normalizeDate(val, op == query.LessOrEqual)
-		}
-		p := query.Simple(f.Field, op, val)
-		q.AddPredicate(p)
-	}
-
-	// Full-text search across key columns
-	if req.SearchText != "" {
-		searchFields := []string{
-			"desc", "filename", "source", "sourcetype", "type",
-			"user", "host", "extra", "tag", "url", "source_name",
-			"computer_name", "format", "notes",
-		}
-		var searchPreds []*query.Predicate
-		for _, field := range searchFields {
-			p := query.Simple(field, query.Like, req.SearchText)
-			if p != nil {
-				searchPreds = append(searchPreds, p)
-			}
-		}
-		if len(searchPreds) > 0 {
-			combined := query.Combine(searchPreds, query.OR)
-			q.AddPredicate(combined)
-		}
-	}
+	// Filters, filter groups, full-text search and bookmark filter
+	q.AddPredicate(requestFilters(req).Predicate(normalizeDate))
 
-	// Bookmark filter
-	if req.BookmarkOnly {
-		p := query.Simple("bookmark", query.Equal, "1")
-		q.AddPredicate(p)
+	// Order by, with the id tiebreaker
+	keys, err := requestSort(req, "")
+	if err != nil {
+		return nil, err
 	}
-
-	// Order by
-	if req.OrderBy != "" {
-		q.OrderBy(req.OrderBy)
+	if err := q.SetSort(keys...); err != nil {
+		return nil, err
 	}
 
 	// Page
@@ -486,6 +506,12 @@ func (a *App) QueryEvents(req QueryRequest) (*QueryResponse, error) {
 	}
 	q.SetPage(page)
 
+	// Cursor pagination
+	mode, err := a.setKeyset(q, req)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build and execute
 	sqlStr, args := q.Build()
 	countSQL, countArgs := q.BuildCount()
@@ -500,16 +526,145 @@ func (a *App) QueryEvents(req QueryRequest) (*QueryResponse, error) {
 		return nil, fmt.Errorf("querying events: %w", err)
 	}
 
+	var nextCursor, prevCursor string
+	if mode != query.PageOffset && len(events) > 0 {
+		// A short page in the direction of travel means that end was reached
+		full := len(events) == pageSize
+		forward := mode == query.PageFirst || mode == query.PageAfter
+		if mode != query.PageLast && (full || !forward) {
+			nextCursor = q.CursorFor(events[len(events)-1]).Encode()
+		}
+		if mode != query.PageFirst && (full || forward) {
+			prevCursor = q.CursorFor(events[0]).Encode()
+		}
+		switch mode {
+		case query.PageFirst:
+			page = 1
+		case query.PageLast:
+			page = int((totalCount + int64(pageSize) - 1) / int64(pageSize))
+			if page < 1 {
+				page = 1
+			}
+		}
+	}
+
+	// Snippets are a display aid; a failure here should not fail the query
+	var matches []database.SearchMatch
+	if req.SearchText != "" {
+		ids := make([]int64, 0, len(events))
+		for _, e := range events {
+			if e.ID > 0 {
+				ids = append(ids, e.ID)
+			}
+		}
+		matches, err = a.store.SearchSnippets(req.SearchText, ids)
+		if err != nil {
+			a.logError("Search snippet error: " + err.Error())
+		}
+	}
+
 	return &QueryResponse{
 		Events:     events,
 		TotalCount: totalCount,
 		Page:       page,
 		PageSize:   pageSize,
+		Matches:    matches,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
 	}, nil
 }
 
-// AdvancedSearch executes a raw WHERE clause query with pagination.
-// Returns the same result format as QueryEvents.
+// requestFilters returns the filters, search and bookmark flag of req as
+// the query.FilterSet that the grid, the export and the histogram all
+// compile, so they agree on which events match.
+func requestFilters(req QueryRequest) query.FilterSet {
+	return query.FilterSet{
+		FilterGroup:  filterGroup(req.Logic, req.Filters, req.Groups),
+		SearchText:   req.SearchText,
+		BookmarkOnly: req.BookmarkOnly,
+	}
+}
+
+// filterGroup converts filters and nested filter groups from the frontend
+// into a query.FilterGroup.
+func filterGroup(logic string, filters []FilterItem, groups []FilterGroup) query.FilterGroup {
+	g := query.FilterGroup{Logic: logic}
+	for _, f := range filters {
+		g.Filters = append(g.Filters, query.Filter(f))
+	}
+	for _, sub := range groups {
+		g.Groups = append(g.Groups, filterGroup(sub.Logic, sub.Filters, sub.Groups))
+	}
+	return g
+}
+
+// requestSort returns the sort order of req: its Sort columns, or else
+// OrderBy (def when that is empty) ascending.
+func requestSort(req QueryRequest, def string) ([]query.SortKey, error) {
+	if len(req.Sort) == 0 {
+		orderBy := req.OrderBy
+		if orderBy == "" {
+			orderBy = def
+		}
+		if orderBy == "" {
+			return nil, nil
+		}
+		return []query.SortKey{{Field: orderBy}}, nil
+	}
+	keys := make([]query.SortKey, len(req.Sort))
+	for i, sf := range req.Sort {
+		switch strings.ToUpper(sf.Direction) {
+		case "", "ASC":
+		case "DESC":
+			keys[i].Desc = true
+		default:
+			return nil, fmt.Errorf("invalid sort direction: %s", sf.Direction)
+		}
+		keys[i].Field = sf.Field
+	}
+	return keys, nil
+}
+
+// setKeyset configures q for the cursor pagination requested by req and
+// returns the page mode, or query.PageOffset if req.Direction is empty.
+// Seeking to a datetime sorts by datetime, since the seek is only meaningful
+// in time order.
+func (a *App) setKeyset(q *query.Query, req QueryRequest) (query.PageMode, error) {
+	var mode query.PageMode
+	var cursor *query.Cursor
+	switch req.Direction {
+	case "":
+		return query.PageOffset, nil
+	case "first":
+		mode = query.PageFirst
+	case "last":
+		mode = query.PageLast
+	case "next", "prev":
+		mode = query.PageAfter
+		if req.Direction == "prev" {
+			mode = query.PageBefore
+		}
+		c, err := query.DecodeCursor(req.Cursor)
+		if err != nil {
+			return 0, err
+		}
+		cursor = c
+	case "seek":
+		mode = query.PageAfter
+		q.OrderBy("datetime")
+		cursor = query.SeekCursor("datetime", normalizeDate(req.SeekDatetime, false))
+	default:
+		return 0, fmt.Errorf("invalid page direction: %s", req.Direction)
+	}
+	if err := q.SetKeyset(mode, cursor); err != nil {
+		return 0, err
+	}
+	return mode, nil
+}
+
+// AdvancedSearch runs a query written in the advanced search syntax (see
+// query.Parse) with pagination. Returns the same result format as
+// QueryEvents; syntax errors report their column.
 func (a *App) AdvancedSearch(whereClause string, page, pageSize int) (*QueryResponse, error) {
 	if a.store == nil {
 		return nil, fmt.Errorf("no database open")
@@ -521,18 +676,62 @@ func (a *App) AdvancedSearch(whereClause string, page, pageSize int) (*QueryResp
 		page = 1
 	}
 
-	// On PostgreSQL, auto-quote reserved word column names so users don't have to
-	if a.driver == "postgres" {
-		whereClause = quotePostgresReservedWords(whereClause)
+	pred, err := query.Parse(whereClause)
+	if err != nil {
+		return nil, fmt.Errorf("syntax error at %w", err)
+	}
+	return a.runPredicate(pred, page, pageSize)
+}
+
+// LuceneSearch runs a query written in the Lucene/KQL-style syntax (see
+// querylang.Parse), such as "host:WS01 AND user:admin*", with pagination.
+// Returns the same result format as QueryEvents; syntax errors report their
+// column.
+func (a *App) LuceneSearch(text string, page, pageSize int) (*QueryResponse, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	if pageSize <= 0 {
+		pageSize = 1000
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	pred, err := querylang.Parse(text, normalizeDate)
+	if err != nil {
+		return nil, fmt.Errorf("syntax error at %w", err)
+	}
+	return a.runPredicate(pred, page, pageSize)
+}
+
+// runPredicate runs a compiled advanced search, ordered by datetime, and
+// returns the requested page. Examiner notes are left out when the search
+// only matches other sources.
+func (a *App) runPredicate(pred *query.Predicate, page, pageSize int) (*QueryResponse, error) {
+	if pred.ExcludesSource("EXAMINER") {
+		where, args, _ := pred.WhereClauseFor(a.queryDialect(), 1)
+		totalCount, err := a.store.CountEvents(where, args)
+		if err != nil {
+			a.logError("Advanced search count error: " + err.Error())
+			return nil, fmt.Errorf("count query error: %w", err)
+		}
+		events, err := a.store.QueryEvents(where, args, "datetime", pageSize, pageSize*(page-1))
+		if err != nil {
+			a.logError("Advanced search error: " + err.Error())
+			return nil, fmt.Errorf("query error: %w", err)
+		}
+		return &QueryResponse{Events: events, TotalCount: totalCount, Page: page, PageSize: pageSize}, nil
 	}
 
-	rq := query.NewRaw(pageSize, whereClause)
-	rq.SetDialect(a.queryDialect())
-	rq.SetPage(page)
-	rq.OrderBy("datetime")
+	q := query.New(pageSize)
+	q.SetDialect(a.queryDialect())
+	q.AddPredicate(pred)
+	q.SetPage(page)
+	q.OrderBy("datetime")
 
-	sqlStr, args := rq.Build()
-	countSQL, countArgs := rq.BuildCount()
+	sqlStr, sqlArgs := q.Build()
+	countSQL, countArgs := q.BuildCount()
 
 	totalCount, err := a.store.ExecuteCountQuery(countSQL, countArgs)
 	if err != nil {
@@ -540,7 +739,7 @@ func (a *App) AdvancedSearch(whereClause string, page, pageSize int) (*QueryResp
 		return nil, fmt.Errorf("count query error: %w", err)
 	}
 
-	events, err := a.store.ExecuteQuery(sqlStr, args)
+	events, err := a.store.ExecuteQuery(sqlStr, sqlArgs)
 	if err != nil {
 		a.logError("Advanced search error: " + err.Error())
 		return nil, fmt.Errorf("query error: %w", err)
@@ -554,21 +753,300 @@ func (a *App) AdvancedSearch(whereClause string, page, pageSize int) (*QueryResp
 	}, nil
 }
 
-// quotePostgresReservedWords replaces standalone occurrences of desc, user, and
-// offset with their double-quoted versions ("desc", "user", "offset") so that
-// PostgreSQL accepts them as column names. Only text outside single-quoted string
-// literals is processed; quoted values are left untouched.
-func quotePostgresReservedWords(where string) string {
-	// Split on single quotes. Even-indexed segments (0, 2, 4, ...) are outside
-	// string literals; odd-indexed segments are inside.
-	parts := strings.Split(where, "'")
-	re := regexp.MustCompile(`(?i)\b(desc|user|offset)\b`)
-	for i := 0; i < len(parts); i += 2 {
-		parts[i] = re.ReplaceAllStringFunc(parts[i], func(match string) string {
-			return `"` + strings.ToLower(match) + `"`
-		})
+// contextLimit caps the events GetEventContext returns on each side of the
+// anchor.
+const contextLimit = 5000
+
+// ContextRequest asks for the events around an anchor event.
+type ContextRequest struct {
+	// ID is the anchor event, or a negative examiner note id.
+	ID int64 `json:"id"`
+
+	// Window is a duration such as "5m" or "1h30m", selecting every event
+	// that long before or after the anchor. Leave it empty to take the
+	// Before events preceding the anchor and the After events following it.
+	Window string `json:"window,omitempty"`
+	Before int    `json:"before,omitempty"`
+	After  int    `json:"after,omitempty"`
+
+	// Filters limits the surrounding events to those the grid shows for
+	// this request. Leave it nil to look across all sources. The anchor is
+	// always included.
+	Filters *QueryRequest `json:"filters,omitempty"`
+}
+
+// ContextResponse holds the events around an anchor, in grid order.
+type ContextResponse struct {
+	Events      []*model.Event `json:"events"`
+	AnchorIndex int            `json:"anchorIndex"`
+
+	// Truncated reports that more than contextLimit events fell on one
+	// side of the anchor, so those furthest from it were left out.
+	Truncated bool `json:"truncated"`
+}
+
+// GetEventContext returns the events surrounding an event or examiner note,
+// ordered by datetime and id like the grid, with the anchor's position. Each
+// side is read as a keyset page from the anchor through the datetime index,
+// so the cost does not depend on where the anchor lies in the timeline.
+func (a *App) GetEventContext(req ContextRequest) (*ContextResponse, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	if req.Before < 0 || req.After < 0 {
+		return nil, fmt.Errorf("context counts cannot be negative")
+	}
+
+	anchor, err := a.findEvent(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter *query.Predicate
+	if req.Filters != nil {
+		filter = requestFilters(*req.Filters).Predicate(normalizeDate)
+	}
+
+	before, after := min(req.Before, contextLimit), min(req.After, contextLimit)
+	truncated := before < req.Before || after < req.After
+	var lowBound, highBound *query.Predicate
+	var inWindow func(e *model.Event) bool
+	if req.Window != "" {
+		window, err := time.ParseDuration(req.Window)
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("invalid context window: %s", req.Window)
+		}
+		at, err := parseEventTime(anchor.Datetime)
+		if err != nil {
+			return nil, fmt.Errorf("event %d has no usable datetime", req.ID)
+		}
+		low, high := at.Add(-window), at.Add(window)
+		lowBound = query.Compare("datetime", query.GreaterOrEqual, low.Format(eventTimeLayout))
+		highBound = query.Compare("datetime", query.LessOrEqual, high.Format(eventTimeLayout))
+		// Examiner notes are unioned in unfiltered, so they are trimmed here
+		inWindow = func(e *model.Event) bool {
+			t, err := parseEventTime(e.Datetime)
+			return err == nil && !t.Before(low) && !t.After(high)
+		}
+		before, after = contextLimit, contextLimit
+	}
+
+	side := func(mode query.PageMode, n int, bound *query.Predicate) ([]*model.Event, error) {
+		if n == 0 {
+			return nil, nil
+		}
+		q := query.New(n)
+		q.SetDialect(a.queryDialect())
+		q.AddPredicate(filter)
+		q.AddPredicate(bound)
+		q.OrderBy("datetime")
+		if err := q.SetKeyset(mode, q.CursorFor(anchor)); err != nil {
+			return nil, err
+		}
+		sqlStr, args := q.Build()
+		events, err := a.store.ExecuteQuery(sqlStr, args)
+		if err != nil || inWindow == nil {
+			return events, err
+		}
+		kept := events[:0]
+		for _, e := range events {
+			if inWindow(e) {
+				kept = append(kept, e)
+			}
+		}
+		if len(kept) == n {
+			truncated = true
+		}
+		return kept, nil
+	}
+
+	prev, err := side(query.PageBefore, before, lowBound)
+	if err != nil {
+		return nil, fmt.Errorf("querying events before: %w", err)
+	}
+	next, err := side(query.PageAfter, after, highBound)
+	if err != nil {
+		return nil, fmt.Errorf("querying events after: %w", err)
+	}
+
+	events := make([]*model.Event, 0, len(prev)+1+len(next))
+	events = append(append(append(events, prev...), anchor), next...)
+	return &ContextResponse{Events: events, AnchorIndex: len(prev), Truncated: truncated}, nil
+}
+
+// PivotRequest asks for the events sharing a value with an event.
+type PivotRequest struct {
+	// ID is the event to pivot from.
+	ID int64 `json:"id"`
+
+	// Field is the column whose value to match, such as "filename" or
+	// "user_sid", or "url_domain" for the host name in the URL.
+	Field    string `json:"field"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"pageSize"`
+}
+
+// PivotResponse is a page of the events sharing Value in Field, with counts
+// per source type across all of them.
+type PivotResponse struct {
+	Field       string            `json:"field"`
+	Value       string            `json:"value"`
+	Events      []*model.Event    `json:"events"`
+	TotalCount  int64             `json:"totalCount"`
+	Page        int               `json:"page"`
+	PageSize    int               `json:"pageSize"`
+	SourceTypes []SourceTypeCount `json:"sourceTypes"`
+}
+
+// SourceTypeCount is the number of pivot matches of one source type.
+type SourceTypeCount struct {
+	SourceType string `json:"sourceType"`
+	Count      int64  `json:"count"`
+}
+
+// RelatedPivot is one pivot from an event and how many events it finds,
+// the event itself included.
+type RelatedPivot struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// Pivot returns the events sharing the value of a field with an event, in
+// time order, and how many of them there are of each source type.
+func (a *App) Pivot(req PivotRequest) (*PivotResponse, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 1000
+	}
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	anchor, err := a.findEvent(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	pred, value, err := query.Pivot(anchor, req.Field)
+	if err != nil {
+		return nil, err
+	}
+	if pred == nil {
+		return nil, fmt.Errorf("event %d has no %s to pivot on", req.ID, req.Field)
+	}
+
+	d := a.queryDialect()
+	where, args, _ := pred.WhereClauseFor(d, 1)
+	total, err := a.store.CountEvents(where, args)
+	if err != nil {
+		return nil, fmt.Errorf("counting pivot: %w", err)
+	}
+	events, err := a.store.QueryEvents(where, args, "datetime, "+d.IDColumn(), pageSize, pageSize*(page-1))
+	if err != nil {
+		return nil, fmt.Errorf("querying pivot: %w", err)
+	}
+	counts, err := a.store.CountByField("sourcetype", where, args)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceTypes := make([]SourceTypeCount, 0, len(counts))
+	for st, n := range counts {
+		sourceTypes = append(sourceTypes, SourceTypeCount{SourceType: st, Count: n})
+	}
+	sort.Slice(sourceTypes, func(i, j int) bool {
+		if sourceTypes[i].Count != sourceTypes[j].Count {
+			return sourceTypes[i].Count > sourceTypes[j].Count
+		}
+		return sourceTypes[i].SourceType < sourceTypes[j].SourceType
+	})
+
+	return &PivotResponse{
+		Field:       req.Field,
+		Value:       value,
+		Events:      events,
+		TotalCount:  total,
+		Page:        page,
+		PageSize:    pageSize,
+		SourceTypes: sourceTypes,
+	}, nil
+}
+
+// GetRelatedEvents summarizes the pivots from an event: for each pivot
+// field it has a value for, how many events share that value, most hits
+// first.
+func (a *App) GetRelatedEvents(id int64) ([]RelatedPivot, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	anchor, err := a.findEvent(id)
+	if err != nil {
+		return nil, err
+	}
+
+	d := a.queryDialect()
+	related := []RelatedPivot{}
+	for _, field := range query.PivotFields {
+		pred, value, err := query.Pivot(anchor, field)
+		if err != nil {
+			return nil, err
+		}
+		if pred == nil {
+			continue
+		}
+		where, args, _ := pred.WhereClauseFor(d, 1)
+		n, err := a.store.CountEvents(where, args)
+		if err != nil {
+			return nil, fmt.Errorf("counting %s pivot: %w", field, err)
+		}
+		related = append(related, RelatedPivot{Field: field, Value: value, Count: n})
+	}
+	sort.SliceStable(related, func(i, j int) bool { return related[i].Count > related[j].Count })
+	return related, nil
+}
+
+// findEvent returns the event with the given id, or the examiner note for a
+// negative id.
+func (a *App) findEvent(id int64) (*model.Event, error) {
+	if id < 0 {
+		notes, err := a.store.GetExaminerNotes()
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range notes {
+			if n.ID == id {
+				return n, nil
+			}
+		}
+		return nil, fmt.Errorf("examiner note %d not found", -id)
+	}
+	d := a.queryDialect()
+	events, err := a.store.QueryEvents(d.IDColumn()+" = "+d.Placeholder(1), []interface{}{id}, "", 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("event %d not found", id)
+	}
+	return events[0], nil
+}
+
+// eventTimeLayout is the datetime format stored by every backend.
+const eventTimeLayout = "2006-01-02 15:04:05"
+
+// parseEventTime parses an event datetime as stored ("2025-01-15 10:30:00")
+// or as PostgreSQL returns it ("2025-01-15T10:30:00Z"), ignoring fractional
+// seconds.
+func parseEventTime(value string) (time.Time, error) {
+	value = strings.Replace(value, "T", " ", 1)
+	if len(value) > len(eventTimeLayout) {
+		value = value[:len(eventTimeLayout)]
 	}
-	return strings.Join(parts, "'")
+	return time.Parse(eventTimeLayout, value)
 }
 
 // splitIDs separates a slice of IDs into positive (regular event) and
@@ -622,6 +1100,56 @@ func (a *App) BulkAddTag(ids []int64, tag string) error {
 	return nil
 }
 
+// BulkRemoveTag removes a tag from multiple events. Examiner notes are skipped.
+func (a *App) BulkRemoveTag(ids []int64, tag string) error {
+	if a.store == nil {
+		return fmt.Errorf("no database open")
+	}
+	regular, _ := splitIDs(ids)
+	if len(regular) > 0 {
+		if err := a.store.BulkRemoveTag(regular, tag); err != nil {
+			return fmt.Errorf("bulk remove tag: %w", err)
+		}
+	}
+	a.logInfo(fmt.Sprintf("Bulk tag remove: %d events, tag=%s", len(regular), tag))
+	return nil
+}
+
+// RenameTag renames a tag across the database when ids is empty, or moves
+// only the selected events from oldName to newName. Renaming onto an
+// existing tag merges the two.
+func (a *App) RenameTag(ids []int64, oldName, newName string) error {
+	if a.store == nil {
+		return fmt.Errorf("no database open")
+	}
+	regular, _ := splitIDs(ids)
+	if len(ids) > 0 && len(regular) == 0 {
+		return nil // only examiner notes selected
+	}
+	if err := a.store.RenameTag(regular, oldName, newName); err != nil {
+		return fmt.Errorf("rename tag: %w", err)
+	}
+	a.logInfo(fmt.Sprintf("Tag rename: %s -> %s (%d selected events)", oldName, newName, len(regular)))
+	return nil
+}
+
+// MergeTags folds the source tags into target across the database when ids
+// is empty, deleting the sources, or retags only the selected events.
+func (a *App) MergeTags(ids []int64, sources []string, target string) error {
+	if a.store == nil {
+		return fmt.Errorf("no database open")
+	}
+	regular, _ := splitIDs(ids)
+	if len(ids) > 0 && len(regular) == 0 {
+		return nil // only examiner notes selected
+	}
+	if err := a.store.MergeTags(regular, sources, target); err != nil {
+		return fmt.Errorf("merge tags: %w", err)
+	}
+	a.logInfo(fmt.Sprintf("Tag merge: %s -> %s (%d selected events)", strings.Join(sources, ","), target, len(regular)))
+	return nil
+}
+
 // BulkSetBookmark sets the bookmark value on multiple events. Positive IDs update
 // log2timeline; negative IDs update examiner_notes.
 func (a *App) BulkSetBookmark(ids []int64, value int64) error {
@@ -668,67 +1196,16 @@ func (a *App) ExportCSV(req QueryRequest) (string, error) {
 	q := query.New(999999999) // effectively unlimited
 	q.SetDialect(a.queryDialect())
 
-	if req.Logic == "OR" {
-		q.SetLogic(query.OR)
-	}
-
-	for _, f := range req.Filters {
-		var op query.Operator
-		switch f.Operator {
-		case "=":
-			op = query.Equal
-		case "!=":
-			op = query.NotEqual
-		case "LIKE":
-			op = query.Like
-		case "NOT LIKE":
-			op = query.NotLike
-		case ">=":
-			op = query.GreaterOrEqual
-		case "<=":
-			op = query.LessOrEqual
-		default:
-			continue
-		}
-		// Normalize partial dates for datetime fields
-		val := f.Value
-		if f.Field == "datetime" {
-			val = normalizeDate(val, op == query.LessOrEqual)
-		}
-		q.AddPredicate(query.Simple(f.Field, op, val))
-	}
+	// The same events as the grid
+	q.AddPredicate(requestFilters(req).Predicate(normalizeDate))
 
-	// Full-text search across key columns
-	if req.SearchText != "" {
-		searchFields := []string{
-			"desc", "filename", "source", "sourcetype", "type",
-			"user", "host", "extra", "tag", "url", "source_name",
-			"computer_name", "format", "notes",
-		}
-		var searchPreds []*query.Predicate
-		for _, field := range searchFields {
-			p := query.Simple(field, query.Like, req.SearchText)
-			if p != nil {
-				searchPreds = append(searchPreds, p)
-			}
-		}
-		if len(searchPreds) > 0 {
-			combined := query.Combine(searchPreds, query.OR)
-			q.AddPredicate(combined)
-		}
-	}
-
-	// Bookmark filter
-	if req.BookmarkOnly {
-		p := query.Simple("bookmark", query.Equal, "1")
-		q.AddPredicate(p)
+	keys, err := requestSort(req, "datetime")
+	if err != nil {
+		return "", err
 	}
-
-	orderBy := req.OrderBy
-	if orderBy == "" {
-		orderBy = "datetime"
+	if err := q.SetSort(keys...); err != nil {
+		return "", err
 	}
-	q.OrderBy(orderBy)
 	q.SetPage(1)
 
 	sqlStr, args := q.Build()
@@ -747,9 +1224,17 @@ func (a *App) ExportCSV(req QueryRequest) (string, error) {
 	}
 
 	runtime.EventsEmit(a.ctx, "export:status", "Done")
-
 	a.logInfo(fmt.Sprintf("Export CSV: %d events to %s", len(events), savePath))
-	return fmt.Sprintf("Exported %d events to %s", len(events), savePath), nil
+	result := fmt.Sprintf("Exported %d events to %s", len(events), savePath)
+
+	// The batch_id column refers to the import batches in the case sidecar.
+	// The CSV is complete without it, so a failure here is only a warning.
+	casePath := strings.TrimSuffix(savePath, filepath.Ext(savePath)) + ".case.json"
+	if err := a.writeCaseRecord(casePath); err != nil {
+		a.logError("Export CSV case record: " + err.Error())
+		result += fmt.Sprintf(" (warning: case record not written: %v)", err)
+	}
+	return result, nil
 }
 
 // -- Metadata Operations --
@@ -787,66 +1272,11 @@ func (a *App) GetTimelineHistogram(req QueryRequest) ([]TimelineBucket, error) {
 		return nil, fmt.Errorf("no database open")
 	}
 
-	// Build WHERE clause from filters using dialect-aware placeholders and quoting
-	d := a.queryDialect()
-	var whereParts []string
-	var whereArgs []interface{}
-	paramIdx := 1
-
-	// Always exclude junk dates (zeroed, pre-epoch, far-future)
-	whereParts = append(whereParts, "datetime > '1970-01-01' AND datetime < '2100-01-01'")
-
-	for _, f := range req.Filters {
-		switch f.Operator {
-		case "=", "!=", "LIKE", "NOT LIKE", ">=", "<=":
-			// Normalize partial dates for datetime fields
-			val := f.Value
-			if f.Field == "datetime" {
-				val = normalizeDate(val, f.Operator == "<=")
-			}
-			whereParts = append(whereParts, fmt.Sprintf("%s %s %s", d.QuoteColumn(f.Field), f.Operator, d.Placeholder(paramIdx)))
-			paramIdx++
-			whereArgs = append(whereArgs, val)
-		}
-	}
-
-	// Full-text search for histogram
-	if req.SearchText != "" {
-		searchFields := []string{
-			"desc", "filename", "source", "sourcetype", "type",
-			"user", "host", "extra", "tag", "url", "source_name",
-			"computer_name", "format", "notes",
-		}
-		var orParts []string
-		for _, field := range searchFields {
-			orParts = append(orParts, fmt.Sprintf("%s LIKE %s", d.QuoteColumn(field), d.Placeholder(paramIdx)))
-			paramIdx++
-			whereArgs = append(whereArgs, "%"+req.SearchText+"%")
-		}
-		whereParts = append(whereParts, "("+strings.Join(orParts, " OR ")+")")
-	}
-
-	// Bookmark filter for histogram
-	if req.BookmarkOnly {
-		whereParts = append(whereParts, "bookmark = 1")
-	}
-
-	logic := "AND"
-	if req.Logic == "OR" {
-		logic = "OR"
-	}
-
-	whereClause := ""
-	// The first part (junk date filter) is always AND
-	// User filters get their own logic
-	if len(whereParts) == 1 {
-		// Only the junk date filter, no user filters
-		whereClause = "WHERE " + whereParts[0]
-	} else {
-		// Junk date filter AND (user filters with their logic)
-		userParts := whereParts[1:]
-		whereClause = "WHERE " + whereParts[0] + " AND (" + strings.Join(userParts, " "+logic+" ") + ")"
-	}
+	// The events the grid shows, less the junk dates (zeroed, pre-epoch,
+	// far-future) that would stretch the chart
+	pred := query.Combine([]*query.Predicate{query.Chartable(), requestFilters(req).Predicate(normalizeDate)}, query.AND)
+	where, whereArgs, _ := pred.WhereClauseFor(a.queryDialect(), 1)
+	whereClause := "WHERE " + where
 
 	// Delegate to store for all database operations (date range, bucketing, histogram query)
 	dbBuckets, err := a.store.GetTimelineHistogram(whereClause, whereArgs)
@@ -870,6 +1300,34 @@ func (a *App) GetTags() ([]string, error) {
 	return a.store.GetDistinctTags()
 }
 
+// GetTagDetails returns every tag with its color, description and event count.
+func (a *App) GetTagDetails() ([]database.Tag, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	return a.store.GetTags()
+}
+
+// SaveTag creates a tag or updates its color and description.
+func (a *App) SaveTag(tag database.Tag) error {
+	if a.store == nil {
+		return fmt.Errorf("no database open")
+	}
+	return a.store.SaveTag(&tag)
+}
+
+// DeleteTag removes a tag from every event and deletes it.
+func (a *App) DeleteTag(name string) error {
+	if a.store == nil {
+		return fmt.Errorf("no database open")
+	}
+	if err := a.store.DeleteTag(name); err != nil {
+		return fmt.Errorf("delete tag: %w", err)
+	}
+	a.logInfo("Tag deleted: " + name)
+	return nil
+}
+
 // -- Event Operations --
 
 // UpdateEventFields updates specific fields on an event.
@@ -950,225 +1408,1693 @@ func (a *App) ToggleExaminerNoteBookmark(negatedID int64) (int64, error) {
 	return a.store.ToggleExaminerNoteBookmark(-negatedID)
 }
 
-// -- Saved Queries --
+// -- Indicator Search --
 
-// GetSavedQueries returns all saved queries.
-func (a *App) GetSavedQueries() ([]database.SavedQuery, error) {
-	if a.store == nil {
-		return nil, fmt.Errorf("no database open")
-	}
-	return a.store.GetSavedQueries()
+// maxIndicators bounds a batch search, since each indicator is a search of
+// the whole table.
+const maxIndicators = 1000
+
+// markBatchSize is how many hits a batch search or Sigma run tags or colors
+// at a time.
+const markBatchSize = 10000
+
+// BatchSearchRequest is a list of indicators to search for, and whether to
+// mark their hits.
+type BatchSearchRequest struct {
+	Indicators []ioc.Indicator `json:"indicators"`
+
+	// Tag adds each indicator's name, or its value when it has none, as a
+	// tag on its hits. Color, when set, colors every hit.
+	Tag   bool   `json:"tag"`
+	Color string `json:"color"`
 }
 
-// SaveQuery stores a named query.
-func (a *App) SaveQuery(name, queryStr string) error {
-	if a.store == nil {
-		return fmt.Errorf("no database open")
-	}
-	return a.store.SaveQuery(name, queryStr)
+// IndicatorHits is the outcome of searching for one indicator. Error is set
+// when it has no text to search for, or when its hits could not be tagged,
+// such as for a name with a comma.
+type IndicatorHits struct {
+	ioc.Indicator
+	Hits  int64  `json:"hits"`
+	Error string `json:"error,omitempty"`
 }
 
-// DeleteSavedQuery removes a saved query.
-func (a *App) DeleteSavedQuery(name string) error {
-	if a.store == nil {
-		return fmt.Errorf("no database open")
-	}
-	return a.store.DeleteQuery(name)
+// BatchSearchResponse summarizes a batch search per indicator, in the order
+// given, with the number of events matching any of them.
+type BatchSearchResponse struct {
+	Results   []IndicatorHits `json:"results"`
+	Matched   int             `json:"matched"`
+	TotalHits int64           `json:"totalHits"`
 }
 
-// -- PostgreSQL Connection --
+// ParseIndicators reads pasted indicators, one per line.
+func (a *App) ParseIndicators(text string) []ioc.Indicator {
+	return ioc.ParseText(text)
+}
 
-// ConnectPostgres connects to an existing 4n6time PostgreSQL database.
-func (a *App) ConnectPostgres(host, port, dbName, user, password, sslMode string) (*DBInfo, error) {
-	if host == "" {
-		host = "localhost"
+// LoadIndicators reads indicators from a text, CSV or STIX file chosen by
+// the user. Returns nil if the dialog is cancelled.
+func (a *App) LoadIndicators() ([]ioc.Indicator, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Load Indicators",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Indicator Lists (*.txt, *.csv, *.json)", Pattern: "*.txt;*.csv;*.json"},
+			{DisplayName: "All Files (*.*)", Pattern: "*.*"},
+		},
+	})
+	if err != nil {
+		return nil, err
 	}
-	if port == "" {
-		port = "5432"
+	if path == "" {
+		return nil, nil
 	}
-	if sslMode == "" {
-		sslMode = "disable"
+	list, err := ioc.ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
+	a.logInfo(fmt.Sprintf("Loaded %d indicators from %s", len(list), path))
+	return list, nil
+}
 
-	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		user, password, host, port, dbName, sslMode)
+// BatchSearch counts the events matching each indicator across the
+// searchable fields, as the search box does, optionally tagging and
+// coloring the hits.
+func (a *App) BatchSearch(req BatchSearchRequest) (*BatchSearchResponse, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	if len(req.Indicators) > maxIndicators {
+		return nil, fmt.Errorf("too many indicators: %d, the limit is %d", len(req.Indicators), maxIndicators)
+	}
 
-	// Close any existing database
-	if a.store != nil {
-		a.store.Close()
-		a.store = nil
+	d := a.queryDialect()
+	resp := &BatchSearchResponse{Results: make([]IndicatorHits, 0, len(req.Indicators))}
+	var preds []*query.Predicate
+	var matched []int
+	for _, ind := range req.Indicators {
+		result := IndicatorHits{Indicator: ind}
+		pred := query.Search(ind.Value)
+		if pred == nil {
+			result.Error = "nothing to search for"
+			resp.Results = append(resp.Results, result)
+			continue
+		}
+		preds = append(preds, pred)
+		where, args, _ := pred.WhereClauseFor(d, 1)
+		n, err := a.store.CountEvents(where, args)
+		if err != nil {
+			return nil, fmt.Errorf("searching for %s: %w", ind.Value, err)
+		}
+		result.Hits = n
+		if n > 0 {
+			resp.Matched++
+			matched = append(matched, len(resp.Results))
+		}
+		resp.Results = append(resp.Results, result)
 	}
+	// Every indicator is counted before any hit is tagged, since the search
+	// covers tags too
+	if len(preds) > 0 {
+		where, args, _ := query.Combine(preds, query.OR).WhereClauseFor(d, 1)
+		total, err := a.store.CountEvents(where, args)
+		if err != nil {
+			return nil, fmt.Errorf("counting indicator hits: %w", err)
+		}
+		resp.TotalHits = total
 
-	store, err := database.OpenStore("postgres", connStr)
-	if err != nil {
-		return nil, fmt.Errorf("connecting to PostgreSQL: %w", err)
+		if req.Color != "" && total > 0 {
+			err := a.store.EachMatchingID(where, args, markBatchSize, func(ids []int64) error {
+				return a.store.BulkUpdateColor(ids, req.Color)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("coloring indicator hits: %w", err)
+			}
+		}
+	}
+	if req.Tag {
+		for _, i := range matched {
+			result := &resp.Results[i]
+			where, args, _ := query.Search(result.Value).WhereClauseFor(d, 1)
+			err := a.store.EachMatchingID(where, args, markBatchSize, func(ids []int64) error {
+				return a.store.BulkAddTag(ids, result.Label())
+			})
+			if err != nil {
+				result.Error = err.Error()
+			}
+		}
 	}
 
-	a.store = store
-	a.driver = "postgres"
-	a.logInfo("Connected to PostgreSQL: " + maskConnStr(connStr))
-	return a.getDBInfo()
+	a.logInfo(fmt.Sprintf("Batch search: %d of %d indicators matched %d events (tag=%t, color=%s)",
+		resp.Matched, len(resp.Results), resp.TotalHits, req.Tag, req.Color))
+	return resp, nil
 }
 
-// CreatePostgresDatabase creates the 4n6time schema on a PostgreSQL database and connects to it.
-func (a *App) CreatePostgresDatabase(host, port, dbName, user, password, sslMode string) (*DBInfo, error) {
-	if host == "" {
-		host = "localhost"
+// -- Sigma Rules --
+
+// SigmaRequest names a directory of Sigma rules to run over the database,
+// and whether to tag their matches.
+type SigmaRequest struct {
+	Dir string `json:"dir"`
+
+	// Tag adds each rule's title, and "sigma:" followed by its level, as
+	// tags on its matches.
+	Tag bool `json:"tag"`
+}
+
+// SigmaRuleHits is the outcome of running one rule. Error is set when the
+// rule could not be compiled or its matches tagged.
+type SigmaRuleHits struct {
+	Title string `json:"title"`
+	ID    string `json:"id"`
+	Level string `json:"level"`
+	Path  string `json:"path"`
+	Hits  int64  `json:"hits"`
+	Error string `json:"error,omitempty"`
+}
+
+// SigmaResponse summarizes a run of Sigma rules, per rule in path order,
+// with the files that could not be read as rules and the number of events
+// matching any rule. TagErrors holds the failures of tagging matches by
+// level, which belong to no single rule.
+type SigmaResponse struct {
+	Rules     []SigmaRuleHits   `json:"rules"`
+	Failed    []sigma.LoadError `json:"failed"`
+	Matched   int               `json:"matched"`
+	TotalHits int64             `json:"totalHits"`
+	TagErrors []string          `json:"tagErrors,omitempty"`
+}
+
+// ChooseSigmaDirectory asks the user for a directory of Sigma rules.
+// Returns "" if the dialog is cancelled.
+func (a *App) ChooseSigmaDirectory() (string, error) {
+	return runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Choose Sigma Rules Directory",
+	})
+}
+
+// RunSigmaRules runs every Sigma rule under a directory over the database
+// and counts each rule's matches, optionally tagging them. Rules that use
+// unsupported Sigma features are reported rather than run.
+func (a *App) RunSigmaRules(req SigmaRequest) (*SigmaResponse, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
 	}
-	if port == "" {
-		port = "5432"
+	if strings.TrimSpace(req.Dir) == "" {
+		return nil, fmt.Errorf("no rules directory given")
 	}
-	if sslMode == "" {
-		sslMode = "disable"
+	rules, failed, err := sigma.LoadDir(req.Dir)
+	if err != nil {
+		return nil, err
 	}
 
-	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		user, password, host, port, dbName, sslMode)
+	d := a.queryDialect()
+	resp := &SigmaResponse{Rules: make([]SigmaRuleHits, 0, len(rules)), Failed: failed}
+	var preds []*query.Predicate
+	matched := make(map[int]*query.Predicate)
+	var levels []string
+	levelPreds := make(map[string][]*query.Predicate)
+	for _, r := range rules {
+		result := SigmaRuleHits{Title: r.Title, ID: r.ID, Level: r.Level, Path: r.Path}
+		pred, err := sigma.Compile(r, sigma.DefaultFields)
+		if err != nil {
+			result.Error = err.Error()
+			resp.Rules = append(resp.Rules, result)
+			continue
+		}
+		preds = append(preds, pred)
 
-	// Close any existing database
-	if a.store != nil {
-		a.store.Close()
-		a.store = nil
+		where, args, _ := pred.WhereClauseFor(d, 1)
+		n, err := a.store.CountEvents(where, args)
+		if err != nil {
+			// A rule whose regular expression the backend rejects fails
+			// alone
+			result.Error = err.Error()
+			resp.Rules = append(resp.Rules, result)
+			continue
+		}
+		result.Hits = n
+		if n > 0 {
+			resp.Matched++
+			matched[len(resp.Rules)] = pred
+			level := strings.ToLower(strings.TrimSpace(r.Level))
+			if level != "" {
+				if levelPreds[level] == nil {
+					levels = append(levels, level)
+				}
+				levelPreds[level] = append(levelPreds[level], pred)
+			}
+		}
+		resp.Rules = append(resp.Rules, result)
 	}
 
-	store, err := database.CreateStore("postgres", connStr, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating PostgreSQL schema: %w", err)
+	if len(preds) > 0 && resp.Matched > 0 {
+		where, args, _ := query.Combine(preds, query.OR).WhereClauseFor(d, 1)
+		total, err := a.store.CountEvents(where, args)
+		if err != nil {
+			return nil, fmt.Errorf("counting Sigma matches: %w", err)
+		}
+		resp.TotalHits = total
 	}
 
-	a.store = store
-	a.driver = "postgres"
-	a.logInfo("Created PostgreSQL schema and connected: " + maskConnStr(connStr))
-	return a.getDBInfo()
+	// Matches are tagged only once every rule has been counted, so a rule
+	// that looks at tags is not thrown off by another rule's
+	if req.Tag {
+		for i, result := range resp.Rules {
+			pred := matched[i]
+			if pred == nil {
+				continue
+			}
+			where, args, _ := pred.WhereClauseFor(d, 1)
+			err := a.store.EachMatchingID(where, args, markBatchSize, func(ids []int64) error {
+				return a.store.BulkAddTag(ids, sigmaTag(result.Title))
+			})
+			if err != nil {
+				resp.Rules[i].Error = err.Error()
+			}
+		}
+		for _, level := range levels {
+			where, args, _ := query.Combine(levelPreds[level], query.OR).WhereClauseFor(d, 1)
+			err := a.store.EachMatchingID(where, args, markBatchSize, func(ids []int64) error {
+				return a.store.BulkAddTag(ids, "sigma:"+level)
+			})
+			if err != nil {
+				resp.TagErrors = append(resp.TagErrors, fmt.Sprintf("tagging sigma:%s matches: %v", level, err))
+			}
+		}
+	}
+
+	a.logInfo(fmt.Sprintf("Sigma rules: %d of %d rules matched %d events, %d files unreadable (tag=%t) from %s",
+		resp.Matched, len(resp.Rules), resp.TotalHits, len(failed), req.Tag, req.Dir))
+	return resp, nil
 }
 
-// PushToPostgres copies all events from the currently open SQLite database to a new PostgreSQL database.
-// The SQLite database remains open after the push completes.
-func (a *App) PushToPostgres(host, port, dbName, user, password, sslMode string) (string, error) {
-	if a.store == nil || a.driver != "sqlite" {
-		return "", fmt.Errorf("no SQLite database is open")
+// GetSigmaMatches returns a page of the events matching the Sigma rule in
+// a file, in time order.
+func (a *App) GetSigmaMatches(path string, page, pageSize int) (*QueryResponse, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
 	}
-
-	if host == "" {
-		host = "localhost"
+	if pageSize <= 0 {
+		pageSize = 1000
 	}
-	if port == "" {
-		port = "5432"
+	if page < 1 {
+		page = 1
 	}
-	if sslMode == "" {
-		sslMode = "disable"
+
+	r, err := sigma.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pred, err := sigma.Compile(r, sigma.DefaultFields)
+	if err != nil {
+		return nil, err
 	}
+	return a.runPredicate(pred, page, pageSize)
+}
 
-	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		user, password, host, port, dbName, sslMode)
+// sigmaTag turns a rule title into a tag name, which cannot hold commas.
+func sigmaTag(title string) string {
+	return strings.ReplaceAll(title, ",", ";")
+}
 
-	pushStart := time.Now()
-	a.logInfo("Push to PostgreSQL started: " + maskConnStr(connStr))
+// -- Query Performance --
 
-	// Verify SQLite has data before creating the PostgreSQL schema
-	sourceCount, err := a.store.CountEvents("", nil)
-	if err != nil {
-		return "", fmt.Errorf("counting SQLite events: %w", err)
+// QueryPlanResponse explains how the backend runs the grid's query: the
+// SQL, its plan and timing, the fields it filters on, and the indexes that
+// would spare it a full scan of the timeline.
+type QueryPlanResponse struct {
+	SQL             string                         `json:"sql"`
+	Plan            *database.QueryPlan            `json:"plan"`
+	Fields          []string                       `json:"fields"`
+	Recommendations []database.IndexRecommendation `json:"recommendations"`
+}
+
+// ExplainQuery runs the query QueryEvents would run for req under the
+// backend's EXPLAIN and recommends indexes when it scans the whole
+// timeline. Examiner notes, which QueryEvents merges in separately, are
+// left out.
+func (a *App) ExplainQuery(req QueryRequest) (*QueryPlanResponse, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
 	}
-	if sourceCount == 0 {
-		return "", fmt.Errorf("SQLite database has no events to push")
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 1000
 	}
 
-	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
-		"phase": "reading", "message": fmt.Sprintf("SQLite database has %d events, connecting to PostgreSQL...", sourceCount), "count": 0, "total": 0,
-	})
-
-	// Create PostgreSQL store with schema
-	pgStore, err := database.CreateStore("postgres", connStr, nil)
+	q := query.New(pageSize)
+	q.SetDialect(a.queryDialect())
+	q.AddPredicate(requestFilters(req).Predicate(normalizeDate))
+	keys, err := requestSort(req, "")
 	if err != nil {
-		return "", fmt.Errorf("creating PostgreSQL schema: %w", err)
+		return nil, err
 	}
-	defer pgStore.Close()
-
-	// Read all events from SQLite using the same pattern as ExportCSV
-	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
-		"phase": "reading", "message": fmt.Sprintf("Reading %d events from SQLite...", sourceCount), "count": 0, "total": sourceCount,
-	})
+	if err := q.SetSort(keys...); err != nil {
+		return nil, err
+	}
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	q.SetPage(page)
 
-	q := query.New(999999999) // effectively unlimited, matches ExportCSV pattern
-	q.SetDialect(query.DefaultDialect)
-	q.OrderBy("datetime")
-	q.SetPage(1)
+	// Explain the page the grid asked for, keyset pages included
+	if _, err := a.setKeyset(q, req); err != nil {
+		return nil, err
+	}
 	sqlStr, args := q.Build()
 
-	events, err := a.store.ExecuteQuery(sqlStr, args)
+	plan, err := a.store.ExplainQuery(sqlStr, args)
 	if err != nil {
-		return "", fmt.Errorf("reading events from SQLite: %w", err)
+		a.logError("Explain error: " + err.Error())
+		return nil, err
 	}
 
-	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
-		"phase": "reading", "message": fmt.Sprintf("Read %d events from SQLite", len(events)), "count": len(events), "total": sourceCount,
-	})
-
-	if len(events) == 0 {
-		return "", fmt.Errorf("query returned 0 events from SQLite (expected %d); the database may be empty or the query failed", sourceCount)
-	}
+	// Full-text search has its own index, so only the filters count
+	// toward recommendations
+	filters := req
+	filters.SearchText = ""
+	fq := query.New(0)
+	fq.AddPredicate(requestFilters(filters).Predicate(normalizeDate))
 
-	// Insert into PostgreSQL
-	total := len(events)
+	resp := &QueryPlanResponse{SQL: sqlStr, Plan: plan, Fields: fq.PredicateFields()}
+	if slices.Contains(plan.FullScans, "log2timeline") {
+		existing, err := a.store.ListIndexes()
+		if err != nil {
+			return nil, err
+		}
+		sortField := ""
+		if len(keys) > 0 {
+			sortField = keys[0].Field
+		}
+		if req.Direction == "seek" {
+			sortField = "datetime"
+		}
+		resp.Recommendations = database.RecommendIndexes(resp.Fields, sortField, existing)
+	}
+	a.logInfo(fmt.Sprintf("Explain: %.1f ms, full scans %v, %d index recommendations",
+		plan.DurationMs, plan.FullScans, len(resp.Recommendations)))
+	return resp, nil
+}
+
+// GetIndexes returns the indexes on the timeline table.
+func (a *App) GetIndexes() ([]database.IndexInfo, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	return a.store.ListIndexes()
+}
+
+// CreateIndex creates an index on the given columns of the timeline, in
+// order, and returns its name. An index that already exists is kept.
+func (a *App) CreateIndex(columns []string) (string, error) {
+	if a.store == nil {
+		return "", fmt.Errorf("no database open")
+	}
+	name, err := a.store.CreateIndex(columns)
+	if err != nil {
+		a.logError("Create index error: " + err.Error())
+		return "", err
+	}
+	a.logInfo(fmt.Sprintf("Created index %s on (%s)", name, strings.Join(columns, ", ")))
+	return name, nil
+}
+
+// -- Saved Queries --
+
+// GetSavedQueries returns all saved queries.
+func (a *App) GetSavedQueries() ([]database.SavedQuery, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	return a.store.GetSavedQueries()
+}
+
+// SaveQuery stores a named query.
+func (a *App) SaveQuery(name, queryStr string) error {
+	if a.store == nil {
+		return fmt.Errorf("no database open")
+	}
+	return a.store.SaveQuery(name, queryStr)
+}
+
+// DeleteSavedQuery removes a saved query.
+func (a *App) DeleteSavedQuery(name string) error {
+	if a.store == nil {
+		return fmt.Errorf("no database open")
+	}
+	return a.store.DeleteQuery(name)
+}
+
+// UpdateSavedQuery replaces the saved query named q.Name, including its
+// description, category, author, OS and event IDs, or adds it.
+func (a *App) UpdateSavedQuery(q database.SavedQuery) error {
+	if a.store == nil {
+		return fmt.Errorf("no database open")
+	}
+	return a.store.UpsertSavedQuery(q)
+}
+
+// GetSavedQueryParameters lists the named parameters (":username") of a
+// saved query in order of first use, with their declared type, default and
+// label. Undeclared parameters are strings with no default.
+func (a *App) GetSavedQueryParameters(name string) ([]database.QueryParameter, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	sq, err := a.findSavedQuery(name)
+	if err != nil {
+		return nil, err
+	}
+	return savedQueryParameters(sq)
+}
+
+// RunSavedQuery runs a saved query in the advanced search syntax with its
+// parameters bound to values, falling back to each parameter's default.
+// Values are passed to the database as query arguments, never spliced into
+// the SQL.
+func (a *App) RunSavedQuery(name string, values map[string]string, page, pageSize int) (*QueryResponse, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	if pageSize <= 0 {
+		pageSize = 1000
+	}
+	if page < 1 {
+		page = 1
+	}
+	sq, err := a.findSavedQuery(name)
+	if err != nil {
+		return nil, err
+	}
+	pred, err := bindSavedQuery(sq, values)
+	if err != nil {
+		return nil, err
+	}
+	return a.runPredicate(pred, page, pageSize)
+}
+
+// findSavedQuery returns the saved query with the given name.
+func (a *App) findSavedQuery(name string) (*database.SavedQuery, error) {
+	saved, err := a.store.GetSavedQueries()
+	if err != nil {
+		return nil, err
+	}
+	for i := range saved {
+		if saved[i].Name == name {
+			return &saved[i], nil
+		}
+	}
+	return nil, fmt.Errorf("saved query %q not found", name)
+}
+
+// savedQueryWhere returns the WHERE clause of a saved query: the query text
+// itself, or the whereClause of an advanced search saved by the frontend.
+// Saved filter sets have no WHERE clause.
+func savedQueryWhere(sq *database.SavedQuery) (string, error) {
+	text := strings.TrimSpace(sq.Query)
+	if !strings.HasPrefix(text, "{") {
+		return text, nil
+	}
+	var state struct {
+		Advanced    bool   `json:"advanced"`
+		WhereClause string `json:"whereClause"`
+	}
+	if err := json.Unmarshal([]byte(text), &state); err != nil {
+		return text, nil
+	}
+	if !state.Advanced {
+		return "", fmt.Errorf("saved query %q is a filter set, not a WHERE clause", sq.Name)
+	}
+	return state.WhereClause, nil
+}
+
+// savedQueryParameters returns the parameters used in a saved query's WHERE
+// clause, applying its declarations.
+func savedQueryParameters(sq *database.SavedQuery) ([]database.QueryParameter, error) {
+	where, err := savedQueryWhere(sq)
+	if err != nil {
+		return nil, err
+	}
+	declared := make(map[string]database.QueryParameter, len(sq.Parameters))
+	for _, p := range sq.Parameters {
+		declared[p.Name] = p
+	}
+	names, err := query.ParamNames(where)
+	if err != nil {
+		return nil, err
+	}
+	params := []database.QueryParameter{}
+	for _, name := range names {
+		p, ok := declared[name]
+		if !ok {
+			p = database.QueryParameter{Name: name}
+		}
+		if p.Type == "" {
+			p.Type = database.ParamString
+		}
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+// bindSavedQuery binds values, or the declared defaults, to the parameters of
+// a saved query and compiles it. Integer values are checked; date-only
+// datetime values ("2025", "2025-01", "2025-01-15") mean the start of that
+// period.
+func bindSavedQuery(sq *database.SavedQuery, values map[string]string) (*query.Predicate, error) {
+	where, err := savedQueryWhere(sq)
+	if err != nil {
+		return nil, err
+	}
+	params, err := savedQueryParameters(sq)
+	if err != nil {
+		return nil, err
+	}
+	bound := make(map[string]string, len(params))
+	for _, p := range params {
+		raw := strings.TrimSpace(values[p.Name])
+		if raw == "" {
+			raw = p.Default
+		}
+		if raw == "" {
+			return nil, fmt.Errorf("parameter :%s needs a value", p.Name)
+		}
+		switch p.Type {
+		case database.ParamInteger:
+			if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+				return nil, fmt.Errorf("parameter :%s must be an integer, got %q", p.Name, raw)
+			}
+		case database.ParamDatetime:
+			raw = normalizeDate(raw, false)
+		}
+		bound[p.Name] = raw
+	}
+	pred, err := query.ParseWithParams(where, bound)
+	if err != nil {
+		return nil, fmt.Errorf("saved query %q: syntax error at %w", sq.Name, err)
+	}
+	return pred, nil
+}
+
+// ExportQueryPack saves the saved queries to a file chosen by the user so
+// they can be shared across cases: JSON holds every field; the legacy .csv
+// query pack format (Name, SQL, Description, EID, OS, IP) has no category or
+// author and leaves IP empty.
+func (a *App) ExportQueryPack() (string, error) {
+	if a.store == nil {
+		return "", fmt.Errorf("no database open")
+	}
+	savePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Query Pack",
+		DefaultFilename: "queries.json",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Query Pack (*.json)", Pattern: "*.json"},
+			{DisplayName: "Legacy Query Pack (*.csv)", Pattern: "*.csv"},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if savePath == "" {
+		return "", nil // user cancelled
+	}
+
+	queries, err := a.store.GetSavedQueries()
+	if err != nil {
+		return "", err
+	}
+	if queries == nil {
+		queries = []database.SavedQuery{}
+	}
+
+	if strings.EqualFold(filepath.Ext(savePath), ".csv") {
+		entries := make([]csvparser.SavedQueryEntry, len(queries))
+		for i, q := range queries {
+			entries[i] = csvparser.SavedQueryEntry{
+				Name:        q.Name,
+				SQL:         q.Query,
+				Description: q.Description,
+				EID:         q.EventIDs,
+				OS:          q.OS,
+			}
+		}
+		if err := csvparser.WriteSavedQueries(savePath, entries); err != nil {
+			return "", fmt.Errorf("writing query pack: %w", err)
+		}
+	} else {
+		data, err := json.MarshalIndent(queries, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(savePath, data, 0644); err != nil {
+			return "", fmt.Errorf("writing query pack: %w", err)
+		}
+	}
+	a.logInfo(fmt.Sprintf("Exported %d saved queries to %s", len(queries), savePath))
+	return fmt.Sprintf("Exported %d saved queries to %s", len(queries), savePath), nil
+}
+
+// ImportQueryPack adds the queries in a JSON or legacy CSV query pack chosen
+// by the user to the current database. A query with the same name as an
+// existing one replaces it, so re-importing an updated pack refreshes it.
+func (a *App) ImportQueryPack() (string, error) {
+	if a.store == nil {
+		return "", fmt.Errorf("no database open")
+	}
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Import Query Pack",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Query Packs (*.json, *.csv)", Pattern: "*.json;*.csv"},
+			{DisplayName: "All Files (*.*)", Pattern: "*.*"},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", nil
+	}
+
+	queries, err := readQueryPack(path)
+	if err != nil {
+		return "", err
+	}
+	existing, err := a.store.GetSavedQueries()
+	if err != nil {
+		return "", err
+	}
+	names := make(map[string]bool, len(existing))
+	for _, q := range existing {
+		names[q.Name] = true
+	}
+
+	added, replaced := 0, 0
+	for _, q := range queries {
+		q.Name = strings.TrimSpace(q.Name)
+		if q.Name == "" || strings.TrimSpace(q.Query) == "" {
+			continue
+		}
+		if err := a.store.UpsertSavedQuery(q); err != nil {
+			return "", err
+		}
+		if names[q.Name] {
+			replaced++
+		} else {
+			added++
+			names[q.Name] = true
+		}
+	}
+	msg := fmt.Sprintf("Imported %d saved queries (%d new, %d replaced)", added+replaced, added, replaced)
+	a.logInfo(msg + " from " + path)
+	return msg, nil
+}
+
+// readQueryPack reads a JSON query pack, or a legacy CSV one when path does
+// not end in .json.
+func readQueryPack(path string) ([]database.SavedQuery, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var queries []database.SavedQuery
+		if err := json.Unmarshal(data, &queries); err != nil {
+			return nil, fmt.Errorf("reading query pack: %w", err)
+		}
+		return queries, nil
+	}
+
+	entries, err := csvparser.ReadSavedQueries(path)
+	if err != nil {
+		return nil, err
+	}
+	queries := make([]database.SavedQuery, len(entries))
+	for i, e := range entries {
+		queries[i] = database.SavedQuery{
+			Name:        e.Name,
+			Query:       e.SQL,
+			Description: e.Description,
+			OS:          e.OS,
+			EventIDs:    e.EID,
+		}
+	}
+	return queries, nil
+}
+
+// -- PostgreSQL Connection --
+
+// ConnectPostgres connects to an existing 4n6time PostgreSQL database.
+func (a *App) ConnectPostgres(host, port, dbName, user, password, sslMode string) (*DBInfo, error) {
+	if host == "" {
+		host = "localhost"
+	}
+	if port == "" {
+		port = "5432"
+	}
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
+		user, password, host, port, dbName, sslMode)
+
+	// Close any existing database
+	if a.store != nil {
+		a.store.Close()
+		a.store = nil
+	}
+
+	store, err := database.OpenStore("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to PostgreSQL: %w", err)
+	}
+
+	a.useStore(store, "postgres")
+	a.logInfo("Connected to PostgreSQL: " + maskConnStr(connStr))
+	return a.getDBInfo()
+}
+
+// CreatePostgresDatabase creates the 4n6time schema on a PostgreSQL database and connects to it.
+func (a *App) CreatePostgresDatabase(host, port, dbName, user, password, sslMode string) (*DBInfo, error) {
+	if host == "" {
+		host = "localhost"
+	}
+	if port == "" {
+		port = "5432"
+	}
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
+		user, password, host, port, dbName, sslMode)
+
+	// Close any existing database
+	if a.store != nil {
+		a.store.Close()
+		a.store = nil
+	}
+
+	store, err := database.CreateStore("postgres", connStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating PostgreSQL schema: %w", err)
+	}
+
+	a.useStore(store, "postgres")
+	a.logInfo("Created PostgreSQL schema and connected: " + maskConnStr(connStr))
+	return a.getDBInfo()
+}
+
+// PushToPostgres copies all events from the currently open SQLite database to a new PostgreSQL database.
+// The SQLite database remains open after the push completes.
+func (a *App) PushToPostgres(host, port, dbName, user, password, sslMode string) (string, error) {
+	if a.store == nil || a.driver != "sqlite" {
+		return "", fmt.Errorf("no SQLite database is open")
+	}
+
+	if host == "" {
+		host = "localhost"
+	}
+	if port == "" {
+		port = "5432"
+	}
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
+		user, password, host, port, dbName, sslMode)
+
+	pushStart := time.Now()
+	a.logInfo("Push to PostgreSQL started: " + maskConnStr(connStr))
+
+	// Verify SQLite has data before creating the PostgreSQL schema
+	sourceCount, err := a.store.CountEvents("", nil)
+	if err != nil {
+		return "", fmt.Errorf("counting SQLite events: %w", err)
+	}
+	if sourceCount == 0 {
+		return "", fmt.Errorf("SQLite database has no events to push")
+	}
+
+	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
+		"phase": "reading", "message": fmt.Sprintf("SQLite database has %d events, connecting to PostgreSQL...", sourceCount), "count": 0, "total": 0,
+	})
+
+	// Create PostgreSQL store with schema
+	pgStore, err := database.CreateStore("postgres", connStr, nil)
+	if err != nil {
+		return "", fmt.Errorf("creating PostgreSQL schema: %w", err)
+	}
+	defer pgStore.Close()
+
+	// Read all events from SQLite using the same pattern as ExportCSV
+	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
+		"phase": "reading", "message": fmt.Sprintf("Reading %d events from SQLite...", sourceCount), "count": 0, "total": sourceCount,
+	})
+
+	q := query.New(999999999) // effectively unlimited, matches ExportCSV pattern
+	q.SetDialect(query.DefaultDialect)
+	q.OrderBy("datetime")
+	q.SetPage(1)
+	sqlStr, args := q.Build()
+
+	events, err := a.store.ExecuteQuery(sqlStr, args)
+	if err != nil {
+		return "", fmt.Errorf("reading events from SQLite: %w", err)
+	}
+
+	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
+		"phase": "reading", "message": fmt.Sprintf("Read %d events from SQLite", len(events)), "count": len(events), "total": sourceCount,
+	})
+
+	if len(events) == 0 {
+		return "", fmt.Errorf("query returned 0 events from SQLite (expected %d); the database may be empty or the query failed", sourceCount)
+	}
+
+	// Copy case metadata, evidence items and import batches, relinking events
+	batchIDs, _, err := database.CopyCaseRecords(pgStore, a.store)
+	if err != nil {
+		return "", fmt.Errorf("copying case records to PostgreSQL: %w", err)
+	}
+	for _, e := range events {
+		e.BatchID = batchIDs[e.BatchID]
+	}
+
+	// Insert into PostgreSQL
+	total := len(events)
 	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
 		"phase": "inserting", "message": fmt.Sprintf("Inserting %d events into PostgreSQL...", total), "count": 0, "total": total,
 	})
 
-	inserted, err := pgStore.InsertEvents(events, func(count int) {
-		runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
-			"phase": "inserting", "message": fmt.Sprintf("Inserted %d of %d events into PostgreSQL...", count, total), "count": count, "total": total,
-		})
-	})
-	if err != nil {
-		return "", fmt.Errorf("inserting events into PostgreSQL (inserted %d of %d before failure): %w", inserted, total, err)
+	inserted, err := pgStore.InsertEvents(events, func(count int) {
+		runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
+			"phase": "inserting", "message": fmt.Sprintf("Inserted %d of %d events into PostgreSQL...", count, total), "count": count, "total": total,
+		})
+	})
+	if err != nil {
+		return "", fmt.Errorf("inserting events into PostgreSQL (inserted %d of %d before failure): %w", inserted, total, err)
+	}
+
+	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
+		"phase": "inserting", "message": fmt.Sprintf("Successfully inserted %d events into PostgreSQL", inserted), "count": inserted, "total": total,
+	})
+
+	// Copy examiner notes
+	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
+		"phase": "inserting", "message": "Copying examiner notes...", "count": inserted, "total": total,
+	})
+	examNotes, err := a.store.GetExaminerNotes()
+	if err != nil {
+		// Not fatal; log and continue
+		a.logError("Failed to read examiner notes for push: " + err.Error())
+	}
+	notesInserted := 0
+	for _, note := range examNotes {
+		_, err := pgStore.InsertExaminerNote(note.Datetime, note.Desc, note.Tag, note.Color)
+		if err != nil {
+			a.logError("Failed to push examiner note: " + err.Error())
+			continue
+		}
+		notesInserted++
+	}
+	if notesInserted > 0 {
+		a.logInfo(fmt.Sprintf("Pushed %d examiner notes to PostgreSQL", notesInserted))
+	}
+
+	// Update PostgreSQL metadata
+	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
+		"phase": "metadata", "message": "Building PostgreSQL metadata and indexes...", "count": 0, "total": 0,
+	})
+	if err := pgStore.UpdateMetadata(); err != nil {
+		return "", fmt.Errorf("updating PostgreSQL metadata: %w", err)
+	}
+	a.logInfo("Metadata update complete (PostgreSQL)")
+
+	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
+		"phase": "done", "message": fmt.Sprintf("Push complete: %d events transferred to PostgreSQL", inserted), "count": inserted, "total": total,
+	})
+	a.logInfo(fmt.Sprintf("Push complete: %d events + %d notes in %s", inserted, notesInserted, time.Since(pushStart).Round(time.Millisecond)))
+
+	msg := fmt.Sprintf("Pushed %d events to PostgreSQL", inserted)
+	if notesInserted > 0 {
+		msg += fmt.Sprintf(" (%d examiner notes)", notesInserted)
+	}
+	return msg, nil
+}
+
+// -- MySQL Connection --
+
+// mysqlConnStr builds a mysql:// connection URL, applying the same defaults
+// as the PostgreSQL dialog (localhost, the standard port, TLS off).
+func mysqlConnStr(host, port, dbName, user, password, tlsMode string) string {
+	if host == "" {
+		host = "localhost"
+	}
+	if port == "" {
+		port = "3306"
+	}
+	if tlsMode == "" {
+		tlsMode = "false"
+	}
+	return fmt.Sprintf("mysql://%s:%s@%s:%s/%s?tls=%s",
+		user, password, host, port, dbName, tlsMode)
+}
+
+// ConnectMySQL connects to an existing 4n6time MySQL or MariaDB database.
+// tlsMode is passed to the driver's tls parameter ("false", "true", "skip-verify", "preferred").
+func (a *App) ConnectMySQL(host, port, dbName, user, password, tlsMode string) (*DBInfo, error) {
+	connStr := mysqlConnStr(host, port, dbName, user, password, tlsMode)
+
+	// Close any existing database
+	if a.store != nil {
+		a.store.Close()
+		a.store = nil
+	}
+
+	store, err := database.OpenStore("mysql", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to MySQL: %w", err)
+	}
+
+	a.useStore(store, "mysql")
+	a.logInfo("Connected to MySQL: " + maskConnStr(connStr))
+	return a.getDBInfo()
+}
+
+// CreateMySQLDatabase creates the 4n6time schema on a MySQL or MariaDB database and connects to it.
+func (a *App) CreateMySQLDatabase(host, port, dbName, user, password, tlsMode string) (*DBInfo, error) {
+	connStr := mysqlConnStr(host, port, dbName, user, password, tlsMode)
+
+	// Close any existing database
+	if a.store != nil {
+		a.store.Close()
+		a.store = nil
+	}
+
+	store, err := database.CreateStore("mysql", connStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating MySQL schema: %w", err)
+	}
+
+	a.useStore(store, "mysql")
+	a.logInfo("Created MySQL schema and connected: " + maskConnStr(connStr))
+	return a.getDBInfo()
+}
+
+// -- Merge --
+
+// MergeDatabase opens a file dialog for another 4n6time SQLite database and
+// merges its events, examiner notes, saved queries and tags into the current
+// database. When dedup is true, events already present (by fingerprint) are skipped.
+func (a *App) MergeDatabase(dedup bool) (string, error) {
+	if a.store == nil {
+		return "", fmt.Errorf("no database open")
+	}
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Merge 4n6time Database",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "SQLite Database (*.db)", Pattern: "*.db"},
+			{DisplayName: "All Files (*.*)", Pattern: "*.*"},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", nil // user cancelled
+	}
+	return a.mergeFrom("sqlite", path, path, dedup)
+}
+
+// MergePostgresDatabase merges an existing 4n6time PostgreSQL database into the
+// current database. When dedup is true, events already present are skipped.
+func (a *App) MergePostgresDatabase(host, port, dbName, user, password, sslMode string, dedup bool) (string, error) {
+	if a.store == nil {
+		return "", fmt.Errorf("no database open")
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	if port == "" {
+		port = "5432"
+	}
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
+		user, password, host, port, dbName, sslMode)
+
+	return a.mergeFrom("postgres", connStr, maskConnStr(connStr), dedup)
+}
+
+// mergeFrom opens the source database with the given driver, merges it into
+// the current store with progress reporting, and closes the source.
+func (a *App) mergeFrom(driver, pathOrConnStr, label string, dedup bool) (string, error) {
+	mergeStart := time.Now()
+	a.logInfo("Merge started: " + label)
+
+	src, err := database.OpenStore(driver, pathOrConnStr)
+	if err != nil {
+		return "", fmt.Errorf("opening source database: %w", err)
+	}
+	defer src.Close()
+
+	result, err := database.MergeStore(a.store, src, database.MergeOptions{
+		Dedup:       dedup,
+		SourceLabel: label,
+	}, func(phase string, count, total int) {
+		var msg string
+		switch phase {
+		case "reading":
+			msg = "Reading source database..."
+		case "indexing":
+			msg = fmt.Sprintf("Indexed %d of %d existing events for duplicates...", count, total)
+			phase = "reading"
+		case "inserting":
+			msg = fmt.Sprintf("Merged %d of %d events...", count, total)
+		case "notes":
+			msg = fmt.Sprintf("Copied %d of %d examiner notes...", count, total)
+			phase = "inserting"
+		case "metadata":
+			msg = "Building metadata and indexes..."
+		}
+		runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
+			"phase": phase, "message": msg, "count": count, "total": total,
+		})
+	})
+	if err != nil {
+		a.logError("Merge failed: " + err.Error())
+		return "", fmt.Errorf("merging database: %w", err)
+	}
+
+	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
+		"phase": "done", "message": fmt.Sprintf("Merge complete: %d events", result.EventsCopied), "count": result.EventsCopied, "total": result.EventsCopied,
+	})
+	a.logInfo(fmt.Sprintf("Merge complete: %d events, %d duplicates skipped, %d notes, %d saved queries in %s",
+		result.EventsCopied, result.EventsSkipped, result.NotesCopied, result.QueriesCopied, time.Since(mergeStart).Round(time.Millisecond)))
+
+	msg := fmt.Sprintf("Merged %d events", result.EventsCopied)
+	if result.EventsSkipped > 0 {
+		msg += fmt.Sprintf(" (%d duplicates skipped)", result.EventsSkipped)
+	}
+	if result.NotesCopied > 0 {
+		msg += fmt.Sprintf(", %d examiner notes", result.NotesCopied)
+	}
+	if result.QueriesCopied > 0 {
+		msg += fmt.Sprintf(", %d saved queries", result.QueriesCopied)
+	}
+	if result.EvidenceCopied > 0 {
+		msg += fmt.Sprintf(", %d evidence items", result.EvidenceCopied)
+	}
+	return msg, nil
+}
+
+// GetProvenance returns the provenance history of the current database.
+func (a *App) GetProvenance() ([]database.ProvenanceEntry, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	return a.store.GetProvenance()
+}
+
+// -- Color Rules --
+
+// GetColorRules returns the color rules of the current database, highest priority first.
+func (a *App) GetColorRules() ([]database.ColorRule, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	return a.store.GetColorRules()
+}
+
+// SaveColorRule creates a color rule, or updates it when rule.ID is set, and
+// returns it as stored. Rules whose filters cannot be compiled are rejected.
+func (a *App) SaveColorRule(rule database.ColorRule) (*database.ColorRule, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	if _, err := a.compileColorRule(rule, nil); err != nil {
+		return nil, err
+	}
+	if err := a.store.SaveColorRule(&rule); err != nil {
+		return nil, err
+	}
+	a.logInfo(fmt.Sprintf("Color rule saved: %s -> %s", rule.Name, rule.Color))
+	return &rule, nil
+}
+
+// DeleteColorRule deletes a color rule.
+func (a *App) DeleteColorRule(id int64) error {
+	if a.store == nil {
+		return fmt.Errorf("no database open")
+	}
+	return a.store.DeleteColorRule(id)
+}
+
+// PreviewColorRule returns how many events rule matches, whether or not it
+// is saved or enabled.
+func (a *App) PreviewColorRule(rule database.ColorRule) (int64, error) {
+	if a.store == nil {
+		return 0, fmt.Errorf("no database open")
+	}
+	m, err := a.compileColorRule(rule, nil)
+	if err != nil {
+		return 0, err
+	}
+	countSQL := "SELECT COUNT(*) FROM log2timeline"
+	if m.Where != "" {
+		countSQL += " WHERE " + m.Where
+	}
+	return a.store.ExecuteCountQuery(countSQL, m.Args)
+}
+
+// ApplyColorRules applies every enabled color rule to the whole database as
+// one undoable change and returns a status message.
+func (a *App) ApplyColorRules() (string, error) {
+	if a.store == nil {
+		return "", fmt.Errorf("no database open")
+	}
+	n, err := a.applyColorRules(a.store, nil)
+	if err != nil {
+		return "", fmt.Errorf("applying color rules: %w", err)
+	}
+	a.logInfo(fmt.Sprintf("Color rules applied: %d events recolored", n))
+	return fmt.Sprintf("Color rules recolored %d events", n), nil
+}
+
+// applyColorRules applies the enabled rules of store, lowest priority first
+// so higher priorities win, to the events matching scope (all when nil).
+func (a *App) applyColorRules(store database.Store, scope *query.Predicate) (int64, error) {
+	rules, err := store.GetColorRules()
+	if err != nil {
+		return 0, err
+	}
+	var matches []database.ColorRuleMatch
+	for i := len(rules) - 1; i >= 0; i-- {
+		if !rules[i].Enabled {
+			continue
+		}
+		m, err := a.compileColorRule(rules[i], scope)
+		if err != nil {
+			return 0, fmt.Errorf("color rule %q: %w", rules[i].Name, err)
+		}
+		matches = append(matches, *m)
+	}
+	if len(matches) == 0 {
+		return 0, nil
+	}
+	return store.ApplyColorRules(matches)
+}
+
+// compileColorRule turns a rule into a WHERE fragment for the current
+// dialect, ANDed with scope when given. Saved query rules compile the saved
+// query like AdvancedSearch, with parameter defaults.
+func (a *App) compileColorRule(rule database.ColorRule, scope *query.Predicate) (*database.ColorRuleMatch, error) {
+	d := a.queryDialect()
+	var where string
+	var args []interface{}
+	next := 1
+
+	if name := strings.TrimSpace(rule.SavedQuery); name != "" {
+		sq, err := a.findSavedQuery(name)
+		if err != nil {
+			return nil, err
+		}
+		// Parameterized saved queries run with their defaults
+		pred, err := bindSavedQuery(sq, nil)
+		if err != nil {
+			return nil, err
+		}
+		where, args, next = pred.WhereClauseFor(d, 1)
+		if where == "" {
+			return nil, fmt.Errorf("saved query %q matches every event", name)
+		}
+	} else {
+		var preds []*query.Predicate
+		for _, f := range rule.Filters {
+			if _, ok := query.ParseOperator(f.Operator); !ok {
+				return nil, fmt.Errorf("unknown operator %q", f.Operator)
+			}
+			p := query.Filter(f).Predicate(normalizeDate)
+			if p == nil {
+				return nil, fmt.Errorf("invalid filter: %s %s", f.Field, f.Operator)
+			}
+			preds = append(preds, p)
+		}
+		logic := query.AND
+		if strings.EqualFold(rule.Logic, "OR") {
+			logic = query.OR
+		}
+		where, args, next = query.Combine(preds, logic).WhereClauseFor(d, 1)
+		if where == "" {
+			return nil, fmt.Errorf("color rule needs filters or a saved query")
+		}
+	}
+
+	if scope != nil {
+		scopeSQL, scopeArgs, _ := scope.WhereClauseFor(d, next)
+		where = where + " AND " + scopeSQL
+		args = append(args, scopeArgs...)
 	}
+	return &database.ColorRuleMatch{Where: where, Args: args, Color: rule.Color}, nil
+}
 
-	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
-		"phase": "inserting", "message": fmt.Sprintf("Successfully inserted %d events into PostgreSQL", inserted), "count": inserted, "total": total,
+// ExportColorRules saves the color rules to a file chosen by the user: JSON
+// holds every rule; the legacy .csv color coding format (type|host,colorcode)
+// holds only single "=" rules on one of those fields, and other rules are skipped.
+func (a *App) ExportColorRules() (string, error) {
+	if a.store == nil {
+		return "", fmt.Errorf("no database open")
+	}
+	savePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Color Rules",
+		DefaultFilename: "color_rules.json",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Color Rules (*.json)", Pattern: "*.json"},
+			{DisplayName: "Color Coding Template (*.csv)", Pattern: "*.csv"},
+		},
 	})
+	if err != nil {
+		return "", err
+	}
+	if savePath == "" {
+		return "", nil // user cancelled
+	}
 
-	// Copy examiner notes
-	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
-		"phase": "inserting", "message": "Copying examiner notes...", "count": inserted, "total": total,
-	})
-	examNotes, err := a.store.GetExaminerNotes()
+	rules, err := a.store.GetColorRules()
 	if err != nil {
-		// Not fatal; log and continue
-		a.logError("Failed to read examiner notes for push: " + err.Error())
+		return "", err
 	}
-	notesInserted := 0
-	for _, note := range examNotes {
-		_, err := pgStore.InsertExaminerNote(note.Datetime, note.Desc, note.Tag, note.Color)
+
+	if strings.EqualFold(filepath.Ext(savePath), ".csv") {
+		field, mapping, skipped, err := legacyColorCoding(rules)
 		if err != nil {
-			a.logError("Failed to push examiner note: " + err.Error())
+			return "", err
+		}
+		if err := csvparser.WriteColorCoding(savePath, field, mapping); err != nil {
+			return "", fmt.Errorf("writing color coding: %w", err)
+		}
+		msg := fmt.Sprintf("Exported %d color rules to %s", len(mapping), savePath)
+		if skipped > 0 {
+			msg += fmt.Sprintf(" (%d rules not expressible as a color coding template skipped)", skipped)
+		}
+		a.logInfo(msg)
+		return msg, nil
+	}
+
+	for i := range rules {
+		rules[i].ID = 0
+	}
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(savePath, data, 0644); err != nil {
+		return "", fmt.Errorf("writing color rules: %w", err)
+	}
+	a.logInfo(fmt.Sprintf("Exported %d color rules to %s", len(rules), savePath))
+	return fmt.Sprintf("Exported %d color rules to %s", len(rules), savePath), nil
+}
+
+// legacyColorCoding converts rules to a color coding template mapping. The
+// template holds one field, taken from the highest priority convertible
+// rule; rules on other fields or with other conditions are counted as
+// skipped. Where two rules map the same value, the higher priority wins.
+func legacyColorCoding(rules []database.ColorRule) (field string, mapping map[string]string, skipped int, err error) {
+	mapping = make(map[string]string)
+	for _, r := range rules {
+		if r.SavedQuery != "" || len(r.Filters) != 1 {
+			skipped++
 			continue
 		}
-		notesInserted++
+		f := r.Filters[0]
+		if (f.Field != "type" && f.Field != "host") || f.Operator != string(query.Equal) || (field != "" && f.Field != field) {
+			skipped++
+			continue
+		}
+		field = f.Field
+		if _, ok := mapping[f.Value]; !ok {
+			mapping[f.Value] = r.Color
+		}
 	}
-	if notesInserted > 0 {
-		a.logInfo(fmt.Sprintf("Pushed %d examiner notes to PostgreSQL", notesInserted))
+	if field == "" {
+		return "", nil, skipped, fmt.Errorf("no color rules can be expressed as a color coding template (type|host = value); export as JSON instead")
 	}
+	return field, mapping, skipped, nil
+}
 
-	// Update PostgreSQL metadata
-	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
-		"phase": "metadata", "message": "Building PostgreSQL metadata and indexes...", "count": 0, "total": 0,
+// ImportColorRules adds the rules in a JSON export or a legacy color coding
+// template (type|host,colorcode) chosen by the user to the current database.
+func (a *App) ImportColorRules() (string, error) {
+	if a.store == nil {
+		return "", fmt.Errorf("no database open")
+	}
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Import Color Rules",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Color Rules (*.json, *.csv)", Pattern: "*.json;*.csv"},
+			{DisplayName: "All Files (*.*)", Pattern: "*.*"},
+		},
 	})
-	if err := pgStore.UpdateMetadata(); err != nil {
-		return "", fmt.Errorf("updating PostgreSQL metadata: %w", err)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", nil
 	}
-	a.logInfo("Metadata update complete (PostgreSQL)")
 
-	runtime.EventsEmit(a.ctx, "import:progress", map[string]interface{}{
-		"phase": "done", "message": fmt.Sprintf("Push complete: %d events transferred to PostgreSQL", inserted), "count": inserted, "total": total,
+	var rules []database.ColorRule
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		if err := json.Unmarshal(data, &rules); err != nil {
+			return "", fmt.Errorf("reading color rules: %w", err)
+		}
+	} else {
+		cc, err := csvparser.ReadColorCoding(path)
+		if err != nil {
+			return "", err
+		}
+		rules = colorCodingRules(cc)
+	}
+
+	for i := range rules {
+		rules[i].ID = 0
+		if err := a.store.SaveColorRule(&rules[i]); err != nil {
+			return "", fmt.Errorf("importing color rule %q: %w", rules[i].Name, err)
+		}
+	}
+	a.logInfo(fmt.Sprintf("Imported %d color rules from %s", len(rules), path))
+	return fmt.Sprintf("Imported %d color rules", len(rules)), nil
+}
+
+// colorCodingRules converts a legacy color coding template to enabled rules,
+// one per value, in value order.
+func colorCodingRules(cc *csvparser.ColorCoding) []database.ColorRule {
+	values := make([]string, 0, len(cc.Mapping))
+	for v := range cc.Mapping {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	rules := make([]database.ColorRule, 0, len(values))
+	for _, v := range values {
+		rules = append(rules, database.ColorRule{
+			Name:    cc.Field + " = " + v,
+			Filters: []database.RuleFilter{{Field: cc.Field, Operator: string(query.Equal), Value: v}},
+			Logic:   "AND",
+			Color:   cc.Mapping[v],
+			Enabled: true,
+		})
+	}
+	return rules
+}
+
+// -- Audit Log --
+
+// examinerConfig is the persistent examiner identity stored in the user's config directory.
+type examinerConfig struct {
+	Name string `json:"name"`
+}
+
+func examinerConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "4n6time", "examiner.json")
+}
+
+// loadExaminerConfig restores the saved examiner name, falling back to the
+// operating system user.
+func (a *App) loadExaminerConfig() {
+	if u, err := user.Current(); err == nil {
+		a.examiner = u.Username
+	}
+	path := examinerConfigPath()
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var cfg examinerConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return
+	}
+	if cfg.Name != "" {
+		a.examiner = cfg.Name
+	}
+}
+
+// GetExaminer returns the examiner name recorded with each change.
+func (a *App) GetExaminer() string {
+	return a.examiner
+}
+
+// SetExaminer sets and persists the examiner name recorded with each change.
+func (a *App) SetExaminer(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("examiner name is required")
+	}
+	a.examiner = name
+	if a.store != nil {
+		a.store.SetExaminer(name)
+	}
+	path := examinerConfigPath()
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(examinerConfig{Name: name}, "", "  ")
+	if err != nil {
+		return err
+	}
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("saving examiner: %w", err)
+	}
+	a.logInfo("Examiner set: " + name)
+	return nil
+}
+
+// GetAuditLog returns the audit entries matching filter, oldest first.
+// Examiner notes appear under target "examiner_note" with positive IDs.
+func (a *App) GetAuditLog(filter database.AuditFilter) ([]database.AuditEntry, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	return a.store.GetAuditLog(filter)
+}
+
+// ExportAuditLog writes the full audit log to a CSV file chosen by the user.
+func (a *App) ExportAuditLog() (string, error) {
+	if a.store == nil {
+		return "", fmt.Errorf("no database open")
+	}
+
+	savePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Audit Log",
+		DefaultFilename: "audit_log.csv",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "CSV Files (*.csv)", Pattern: "*.csv"},
+		},
 	})
-	a.logInfo(fmt.Sprintf("Push complete: %d events + %d notes in %s", inserted, notesInserted, time.Since(pushStart).Round(time.Millisecond)))
+	if err != nil {
+		return "", err
+	}
+	if savePath == "" {
+		return "", nil // user cancelled
+	}
 
-	msg := fmt.Sprintf("Pushed %d events to PostgreSQL", inserted)
-	if notesInserted > 0 {
-		msg += fmt.Sprintf(" (%d examiner notes)", notesInserted)
+	entries, err := a.store.GetAuditLog(database.AuditFilter{})
+	if err != nil {
+		return "", fmt.Errorf("reading audit log: %w", err)
 	}
-	return msg, nil
+	if err := writeAuditCSV(savePath, entries); err != nil {
+		return "", fmt.Errorf("writing audit log: %w", err)
+	}
+
+	a.logInfo(fmt.Sprintf("Export audit log: %d entries to %s", len(entries), savePath))
+	return fmt.Sprintf("Exported %d audit entries to %s", len(entries), savePath), nil
+}
+
+// Undo reverts the most recent annotation change in the current database
+// and returns a status message. Changes made before the last tag rename,
+// merge or delete cannot be undone.
+func (a *App) Undo() (string, error) {
+	if a.store == nil {
+		return "", fmt.Errorf("no database open")
+	}
+	entry, err := a.store.Undo()
+	if err != nil {
+		return "", fmt.Errorf("undo: %w", err)
+	}
+	if entry == nil {
+		return "Nothing to undo", nil
+	}
+	a.logInfo(fmt.Sprintf("Undo: %s (%d rows)", entry.Operation, len(entry.IDs)))
+	return fmt.Sprintf("Undid %s on %d rows", strings.ReplaceAll(entry.Operation, "_", " "), len(entry.IDs)), nil
+}
+
+// Redo reapplies the most recently undone change and returns a status message.
+func (a *App) Redo() (string, error) {
+	if a.store == nil {
+		return "", fmt.Errorf("no database open")
+	}
+	entry, err := a.store.Redo()
+	if err != nil {
+		return "", fmt.Errorf("redo: %w", err)
+	}
+	if entry == nil {
+		return "Nothing to redo", nil
+	}
+	a.logInfo(fmt.Sprintf("Redo: %s (%d rows)", entry.Operation, len(entry.IDs)))
+	return fmt.Sprintf("Redid %s on %d rows", strings.ReplaceAll(entry.Operation, "_", " "), len(entry.IDs)), nil
+}
+
+// GetUndoState reports what Undo and Redo would act on next.
+func (a *App) GetUndoState() (*database.UndoState, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	return a.store.GetUndoState()
+}
+
+// writeAuditCSV writes audit entries as CSV, with IDs space-separated and
+// the old and new values as JSON.
+func writeAuditCSV(path string, entries []database.AuditEntry) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"id", "created_at", "examiner", "operation", "target", "ids", "old_values", "new_values"})
+	for _, e := range entries {
+		ids := make([]string, len(e.IDs))
+		for i, id := range e.IDs {
+			ids[i] = strconv.FormatInt(id, 10)
+		}
+		oldJSON, err := json.Marshal(e.OldValues)
+		if err != nil {
+			return err
+		}
+		newJSON, err := json.Marshal(e.NewValues)
+		if err != nil {
+			return err
+		}
+		w.Write([]string{
+			strconv.FormatInt(e.ID, 10), e.CreatedAt, e.Examiner, e.Operation, e.Target,
+			strings.Join(ids, " "), string(oldJSON), string(newJSON),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// -- Case and Evidence --
+
+// GetCaseInfo returns the case metadata of the current database.
+func (a *App) GetCaseInfo() (*database.CaseInfo, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	return a.store.GetCaseInfo()
+}
+
+// SaveCaseInfo replaces the case metadata of the current database.
+// Dates may be given as YYYY-MM-DD or YYYY-MM-DD HH:MM:SS.
+func (a *App) SaveCaseInfo(c database.CaseInfo) error {
+	if a.store == nil {
+		return fmt.Errorf("no database open")
+	}
+	if err := a.store.SaveCaseInfo(&c); err != nil {
+		return err
+	}
+	a.logInfo("Case information saved: " + c.CaseNumber)
+	return nil
+}
+
+// GetEvidenceItems returns the evidence items of the current database.
+func (a *App) GetEvidenceItems() ([]database.EvidenceItem, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	return a.store.GetEvidenceItems()
+}
+
+// AddEvidenceItem adds an evidence item and returns its ID.
+func (a *App) AddEvidenceItem(item database.EvidenceItem) (int64, error) {
+	if a.store == nil {
+		return 0, fmt.Errorf("no database open")
+	}
+	id, err := a.store.InsertEvidenceItem(&item)
+	if err != nil {
+		return 0, err
+	}
+	a.logInfo(fmt.Sprintf("Evidence item added: %s (ID %d)", item.Name, id))
+	return id, nil
+}
+
+// UpdateEvidenceItem overwrites an existing evidence item, matched by ID.
+func (a *App) UpdateEvidenceItem(item database.EvidenceItem) error {
+	if a.store == nil {
+		return fmt.Errorf("no database open")
+	}
+	return a.store.UpdateEvidenceItem(&item)
+}
+
+// DeleteEvidenceItem deletes an evidence item. Import batches linked to it
+// are unlinked; their events are kept.
+func (a *App) DeleteEvidenceItem(id int64) error {
+	if a.store == nil {
+		return fmt.Errorf("no database open")
+	}
+	if err := a.store.DeleteEvidenceItem(id); err != nil {
+		return err
+	}
+	a.logInfo(fmt.Sprintf("Evidence item deleted (ID %d)", id))
+	return nil
+}
+
+// GetImportBatches returns the import batches of the current database.
+func (a *App) GetImportBatches() ([]database.ImportBatch, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("no database open")
+	}
+	return a.store.GetImportBatches()
+}
+
+// LinkImportBatch links the events of an import batch to an evidence item.
+// An evidenceID of 0 removes the link.
+func (a *App) LinkImportBatch(batchID, evidenceID int64) error {
+	if a.store == nil {
+		return fmt.Errorf("no database open")
+	}
+	return a.store.SetImportBatchEvidence(batchID, evidenceID)
+}
+
+// writeCaseRecord writes the case metadata, evidence items and import
+// batches of the current database to path as JSON.
+func (a *App) writeCaseRecord(path string) error {
+	record, err := database.GetCaseRecord(a.store)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
 }
 
 // -- Internal Helpers --
@@ -1180,8 +3106,17 @@ func (a *App) GetVersion() string {
 
 // queryDialect returns the appropriate QueryDialect for the current database driver.
 func (a *App) queryDialect() query.QueryDialect {
-	if a.driver == "postgres" {
-		return &database.PostgresDialect{}
+	switch a.driver {
+	case "postgres":
+		d := &database.PostgresDialect{}
+		if pg, ok := a.store.(*database.PostgresStore); ok {
+			d.Trigram = pg.HasTrigramIndex()
+		}
+		return d
+	case "mysql":
+		return &database.MySQLDialect{}
+	case "sqlite":
+		return &database.SQLiteDialect{}
 	}
 	return query.DefaultDialect
 }
@@ -1257,12 +3192,19 @@ func (a *App) loadDatabase(path string) (*DBInfo, error) {
 		return nil, fmt.Errorf("opening database: %w", err)
 	}
 
-	a.store = store
-	a.driver = "sqlite"
+	a.useStore(store, "sqlite")
 	a.logInfo("Database opened: " + path)
 	return a.getDBInfo()
 }
 
+// useStore makes store the current database and tags its changes with the
+// current examiner.
+func (a *App) useStore(store database.Store, driver string) {
+	store.SetExaminer(a.examiner)
+	a.store = store
+	a.driver = driver
+}
+
 func (a *App) getDBInfo() (*DBInfo, error) {
 	count, err := a.store.CountEvents("", nil)
 	if err != nil {
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cdtdelta/4n6time/internal/database"
+	"github.com/cdtdelta/4n6time/internal/ioc"
+	"github.com/cdtdelta/4n6time/internal/model"
+)
+
+// newTestApp returns an App on a fresh SQLite database holding events.
+func newTestApp(t *testing.T, events []*model.Event) *App {
+	t.Helper()
+	store, err := database.CreateSQLite(filepath.Join(t.TempDir(), "test.db"), nil)
+	if err != nil {
+		t.Fatalf("CreateSQLite failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if _, err := store.InsertEvents(events, nil); err != nil {
+		t.Fatalf("InsertEvents failed: %v", err)
+	}
+	a := NewApp()
+	a.useStore(store, "sqlite")
+	return a
+}
+
+// The filter logic applies only to the filters and filter groups. Search
+// text and the bookmark flag always narrow the result, in the grid and the
+// histogram alike.
+func TestFilterGroupsWithSearchAndBookmark(t *testing.T) {
+	event := func(source, eventID, desc string, bookmark int64) *model.Event {
+		return &model.Event{Datetime: "2025-01-15 10:00:00", Source: source, EventID: eventID, Desc: desc, Bookmark: bookmark}
+	}
+	a := newTestApp(t, []*model.Event{
+		event("REG", "", "mimikatz run key", 1),
+		event("EVT", "4624", "mimikatz logon", 0),
+		event("EVT", "4624", "logon", 1),
+		event("EVT", "4625", "mimikatz failed logon", 1),
+		event("FILE", "", "mimikatz.exe", 1),
+	})
+
+	// source = REG OR (source = EVT AND event_identifier = 4624)
+	base := QueryRequest{
+		Logic:   "OR",
+		OrderBy: "datetime",
+		Filters: []FilterItem{{Field: "source", Operator: "=", Value: "REG"}},
+		Groups: []FilterGroup{{Logic: "AND", Filters: []FilterItem{
+			{Field: "source", Operator: "=", Value: "EVT"},
+			{Field: "event_identifier", Operator: "=", Value: "4624"},
+		}}},
+	}
+	withSearch := base
+	withSearch.SearchText = "mimikatz"
+	withBookmark := base
+	withBookmark.BookmarkOnly = true
+	withBoth := withSearch
+	withBoth.BookmarkOnly = true
+
+	tests := []struct {
+		name string
+		req  QueryRequest
+		want int64
+	}{
+		{"groups", base, 3},
+		{"groups and search", withSearch, 2},
+		{"groups and bookmark", withBookmark, 2},
+		{"groups, search and bookmark", withBoth, 1},
+	}
+	for _, tt := range tests {
+		resp, err := a.QueryEvents(tt.req)
+		if err != nil {
+			t.Fatalf("%s: QueryEvents failed: %v", tt.name, err)
+		}
+		if resp.TotalCount != tt.want || int64(len(resp.Events)) != tt.want {
+			t.Errorf("%s: QueryEvents = %d events, total %d; want %d", tt.name, len(resp.Events), resp.TotalCount, tt.want)
+		}
+
+		buckets, err := a.GetTimelineHistogram(tt.req)
+		if err != nil {
+			t.Fatalf("%s: GetTimelineHistogram failed: %v", tt.name, err)
+		}
+		var charted int64
+		for _, b := range buckets {
+			charted += b.Count
+		}
+		if charted != tt.want {
+			t.Errorf("%s: histogram holds %d events; want %d", tt.name, charted, tt.want)
+		}
+	}
+}
+
+// Every indicator is searched for and counted before any hits are tagged,
+// and one without text is reported rather than dropped.
+func TestBatchSearchTagsAndColorsHits(t *testing.T) {
+	a := newTestApp(t, []*model.Event{
+		{Datetime: "2025-01-15 10:00:00", Desc: "connect to evil.example"},
+		{Datetime: "2025-01-15 10:01:00", Desc: "ran dropper.exe from evil.example"},
+		{Datetime: "2025-01-15 10:02:00", Desc: "benign"},
+	})
+
+	resp, err := a.BatchSearch(BatchSearchRequest{
+		Indicators: []ioc.Indicator{
+			{Value: "evil.example"},
+			{Value: "  "},
+			{Value: "dropper.exe", Name: "dropper"},
+			{Value: "nowhere.example"},
+		},
+		Tag:   true,
+		Color: "RED",
+	})
+	if err != nil {
+		t.Fatalf("BatchSearch failed: %v", err)
+	}
+	if len(resp.Results) != 4 {
+		t.Fatalf("got %d results; want 4", len(resp.Results))
+	}
+	for i, want := range []int64{2, 0, 1, 0} {
+		if resp.Results[i].Hits != want {
+			t.Errorf("result %d: %d hits; want %d", i, resp.Results[i].Hits, want)
+		}
+	}
+	if resp.Results[1].Error == "" {
+		t.Error("expected an error for the blank indicator")
+	}
+	if resp.Matched != 2 || resp.TotalHits != 2 {
+		t.Errorf("Matched = %d, TotalHits = %d; want 2 and 2", resp.Matched, resp.TotalHits)
+	}
+
+	events, err := a.store.QueryEvents("", nil, "datetime", 0, 0)
+	if err != nil {
+		t.Fatalf("QueryEvents failed: %v", err)
+	}
+	for i, want := range []struct{ tag, color string }{
+		{"evil.example", "RED"},
+		{"dropper,evil.example", "RED"},
+		{"", ""},
+	} {
+		if events[i].Tag != want.tag || events[i].Color != want.color {
+			t.Errorf("event %d: tag %q, color %q; want %q, %q", i, events[i].Tag, events[i].Color, want.tag, want.color)
+		}
+	}
+}
+
+// Each matching rule tags its matches with its title, and every match is
+// tagged once with the level of the rules it matched.
+func TestRunSigmaRulesTagsMatches(t *testing.T) {
+	a := newTestApp(t, []*model.Event{
+		{Datetime: "2025-01-15 10:00:00", EventID: "4624", Desc: "logon"},
+		{Datetime: "2025-01-15 10:01:00", EventID: "4625", Desc: "failed logon"},
+		{Datetime: "2025-01-15 10:02:00", EventID: "1", Desc: "process"},
+	})
+	dir := t.TempDir()
+	for name, rule := range map[string]string{
+		"logon.yml":  "title: Logon\nlevel: high\ndetection:\n  sel: {EventID: [4624, 4625]}\n  condition: sel\n",
+		"failed.yml": "title: Failed Logon\nlevel: high\ndetection:\n  sel: {EventID: 4625}\n  condition: sel\n",
+		"none.yml":   "title: Nothing\nlevel: low\ndetection:\n  sel: {EventID: 9999}\n  condition: sel\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(rule), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp, err := a.RunSigmaRules(SigmaRequest{Dir: dir, Tag: true})
+	if err != nil {
+		t.Fatalf("RunSigmaRules failed: %v", err)
+	}
+	if resp.Matched != 2 || resp.TotalHits != 2 {
+		t.Errorf("Matched = %d, TotalHits = %d; want 2 and 2", resp.Matched, resp.TotalHits)
+	}
+
+	events, err := a.store.QueryEvents("", nil, "datetime", 0, 0)
+	if err != nil {
+		t.Fatalf("QueryEvents failed: %v", err)
+	}
+	for i, want := range []string{"Logon,sigma:high", "Failed Logon,Logon,sigma:high", ""} {
+		if events[i].Tag != want {
+			t.Errorf("event %d: tag %q; want %q", i, events[i].Tag, want)
+		}
+	}
+}
+
+// levelTagFailingStore fails to add Sigma level tags.
+type levelTagFailingStore struct {
+	database.Store
+}
+
+func (s levelTagFailingStore) BulkAddTag(ids []int64, tag string) error {
+	if strings.HasPrefix(tag, "sigma:") {
+		return errors.New("disk full")
+	}
+	return s.Store.BulkAddTag(ids, tag)
+}
+
+// A failed level tag is reported alongside the per-rule results rather
+// than discarding them.
+func TestRunSigmaRulesReportsLevelTagFailure(t *testing.T) {
+	a := newTestApp(t, []*model.Event{
+		{Datetime: "2025-01-15 10:00:00", EventID: "4624", Desc: "logon"},
+	})
+	a.store = levelTagFailingStore{a.store}
+	dir := t.TempDir()
+	rule := "title: Logon\nlevel: high\ndetection:\n  sel: {EventID: 4624}\n  condition: sel\n"
+	if err := os.WriteFile(filepath.Join(dir, "logon.yml"), []byte(rule), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := a.RunSigmaRules(SigmaRequest{Dir: dir, Tag: true})
+	if err != nil {
+		t.Fatalf("RunSigmaRules failed: %v", err)
+	}
+	if len(resp.Rules) != 1 || resp.Rules[0].Hits != 1 || resp.Rules[0].Error != "" {
+		t.Errorf("expected the rule's result, got %+v", resp.Rules)
+	}
+	if len(resp.TagErrors) != 1 || !strings.Contains(resp.TagErrors[0], "sigma:high") {
+		t.Errorf("TagErrors = %v, want the sigma:high failure", resp.TagErrors)
+	}
+}
+
+// Explain runs the query of the page the grid shows, so a page reached by
+// cursor is explained as a keyset query rather than an offset one.
+func TestExplainQueryFollowsCursor(t *testing.T) {
+	var events []*model.Event
+	for i := range 5 {
+		events = append(events, &model.Event{Datetime: fmt.Sprintf("2025-01-15 10:0%d:00", i), Source: "EVT"})
+	}
+	a := newTestApp(t, events)
+
+	req := QueryRequest{OrderBy: "datetime", Page: 1, PageSize: 2, Direction: "first"}
+	first, err := a.QueryEvents(req)
+	if err != nil {
+		t.Fatalf("QueryEvents failed: %v", err)
+	}
+	req.Page, req.Direction, req.Cursor = 2, "next", first.NextCursor
+
+	offset, err := a.ExplainQuery(QueryRequest{OrderBy: "datetime", Page: 2, PageSize: 2})
+	if err != nil {
+		t.Fatalf("ExplainQuery failed: %v", err)
+	}
+	keyset, err := a.ExplainQuery(req)
+	if err != nil {
+		t.Fatalf("ExplainQuery failed: %v", err)
+	}
+	if !strings.Contains(offset.SQL, "OFFSET") || strings.Contains(keyset.SQL, "OFFSET") {
+		t.Errorf("offset page SQL = %s\nkeyset page SQL = %s", offset.SQL, keyset.SQL)
+	}
+
+	req.Cursor = "not a cursor"
+	if _, err := a.ExplainQuery(req); err == nil {
+		t.Error("expected an error for an invalid cursor")
+	}
+}
+
+// Descending sorts page by cursor like ascending ones, rather than quietly
+// falling back to OFFSET pages without cursors.
+func TestQueryEventsDescendingCursor(t *testing.T) {
+	var events []*model.Event
+	for i := range 5 {
+		events = append(events, &model.Event{Datetime: fmt.Sprintf("2025-01-15 10:0%d:00", i), Desc: fmt.Sprint(i)})
+	}
+	a := newTestApp(t, events)
+
+	req := QueryRequest{Sort: []SortField{{Field: "datetime", Direction: "DESC"}}, PageSize: 2, Direction: "first"}
+	var got []string
+	for range 5 {
+		resp, err := a.QueryEvents(req)
+		if err != nil {
+			t.Fatalf("QueryEvents failed: %v", err)
+		}
+		for _, e := range resp.Events {
+			got = append(got, e.Desc)
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		req.Direction, req.Cursor = "next", resp.NextCursor
+	}
+	if strings.Join(got, ",") != "4,3,2,1,0" {
+		t.Errorf("descending pages = %v, want 4,3,2,1,0", got)
+	}
+
+	// A cursor from the descending sort is refused under the ascending one
+	req.Sort[0].Direction = "ASC"
+	if _, err := a.QueryEvents(req); err == nil {
+		t.Error("expected an error for a cursor from another sort")
+	}
+}
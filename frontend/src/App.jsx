@@ -3,7 +3,7 @@ import { AgGridReact } from 'ag-grid-react'
 import 'ag-grid-community/styles/ag-grid.css'
 import 'ag-grid-community/styles/ag-theme-alpine.css'
 
-import { OpenDatabase, ImportCSV, CloseDatabase, QueryEvents, ExportCSV, GetVersion, ToggleBookmark, ConnectPostgres, CreatePostgresDatabase, PushToPostgres, AddExaminerNote, DeleteExaminerNote, UpdateExaminerNoteColor, AdvancedSearch, SaveQuery, BulkUpdateColor, BulkAddTag, BulkSetBookmark } from '../wailsjs/go/main/App'
+import { OpenDatabase, ImportCSV, CloseDatabase, QueryEvents, ExportCSV, GetVersion, ToggleBookmark, ConnectPostgres, CreatePostgresDatabase, PushToPostgres, AddExaminerNote, DeleteExaminerNote, UpdateExaminerNoteColor, AdvancedSearch, LuceneSearch, GetEventContext, Pivot, GetSigmaMatches, SaveQuery, BulkUpdateColor, BulkAddTag, BulkSetBookmark, Undo, Redo } from '../wailsjs/go/main/App'
 import ImportProgress from './components/ImportProgress'
 import PostgresDialog from './components/PostgresDialog'
 import FilterPanel from './components/FilterPanel'
@@ -15,6 +15,9 @@ import ThemePicker from './components/ThemePicker'
 import AboutDialog from './components/AboutDialog'
 import HelpDialog from './components/HelpDialog'
 import LoggingDialog from './components/LoggingDialog'
+import IndicatorSearch from './components/IndicatorSearch'
+import SigmaRules from './components/SigmaRules'
+import QueryPlan from './components/QueryPlan'
 import AddNoteDialog from './components/AddNoteDialog'
 import HighlightText from './components/HighlightText'
 import themes, { lightThemes } from './themes'
@@ -67,13 +70,18 @@ const defaultColDefs = [
   { field: 'source_name', headerName: 'Source Name', width: 120, hide: true },
   { field: 'user_sid', headerName: 'User SID', width: 120, hide: true },
   { field: 'computer_name', headerName: 'Computer', width: 120, hide: true },
+  { field: 'batch_id', headerName: 'Import Batch', width: 100, hide: true },
 ]
 
+// Grid columns whose field differs from the database column name
+const sortColumnMap = { macb: 'MACB', url: 'URL' }
+
 function App() {
   const [dbInfo, setDbInfo] = useState(null)
   const [events, setEvents] = useState([])
   const [totalCount, setTotalCount] = useState(0)
   const [currentPage, setCurrentPage] = useState(1)
+  const [cursors, setCursors] = useState({ next: '', prev: '' })
   const [loading, setLoading] = useState(false)
   const [status, setStatus] = useState('')
   const [importing, setImporting] = useState(false)
@@ -92,12 +100,17 @@ function App() {
   const [showAbout, setShowAbout] = useState(false)
   const [showHelp, setShowHelp] = useState(false)
   const [showLogging, setShowLogging] = useState(false)
+  const [showIndicatorSearch, setShowIndicatorSearch] = useState(false)
+  const [showSigma, setShowSigma] = useState(false)
+  const [planRequest, setPlanRequest] = useState(null)
   const [showPostgres, setShowPostgres] = useState(false)
   const [showPushPostgres, setShowPushPostgres] = useState(false)
   const [showAddNote, setShowAddNote] = useState(false)
   const [filterVersion, setFilterVersion] = useState(0)
   const [pageInputValue, setPageInputValue] = useState('1')
   const [bookmarkOnly, setBookmarkOnly] = useState(false)
+  const [sortModel, setSortModel] = useState([])
+  const [contextInfo, setContextInfo] = useState(null)
   const [currentTheme, setCurrentTheme] = useState(() => {
     try { return window.localStorage?.getItem('4n6time-theme') || 'forensic-dark' }
     catch { return 'forensic-dark' }
@@ -134,6 +147,8 @@ function App() {
   const [version, setVersion] = useState('')
   const gridRef = useRef(null)
   const resizingRef = useRef(false)
+  // The cursor paging that loaded the current page, so Explain shows its query
+  const pageNavRef = useRef(undefined)
 
   // Load version on mount
   useEffect(() => {
@@ -191,26 +206,36 @@ function App() {
       pageSize: PAGE_SIZE,
       searchText: activeSearch,
       bookmarkOnly: bookmarkOnly,
+      sort: sortModel,
     }
 
     const fs = filterState || activeFilters
     if (fs) {
       req.filters = fs.filters || []
+      req.groups = fs.groups || []
       req.logic = fs.logic || 'AND'
 
       if (fs.dateFrom && fs.dateTo) {
-        req.filters = [
-          ...req.filters,
+        const dateRange = [
           { field: 'datetime', operator: '>=', value: fs.dateFrom },
           { field: 'datetime', operator: '<=', value: fs.dateTo },
         ]
+        if (req.logic === 'OR' && (req.filters.length > 0 || req.groups.length > 0)) {
+          // The date range must hold whichever of the filters match
+          req.groups = [{ logic: 'OR', filters: req.filters, groups: req.groups }]
+          req.filters = dateRange
+          req.logic = 'AND'
+        } else {
+          req.filters = [...req.filters, ...dateRange]
+        }
       }
     }
 
     return req
-  }, [activeFilters, activeSearch, bookmarkOnly])
+  }, [activeFilters, activeSearch, bookmarkOnly, sortModel])
 
-  const loadPage = useCallback(async (page, info, filterState) => {
+  // nav optionally requests cursor paging: { direction, cursor }
+  const loadPage = useCallback(async (page, info, filterState, nav) => {
     const db = info || dbInfo
     if (!db) return
 
@@ -222,24 +247,30 @@ function App() {
 
       if (searchMode === 'advanced' && activeSearch) {
         result = await AdvancedSearch(activeSearch, page, PAGE_SIZE)
+      } else if (searchMode === 'lucene' && activeSearch) {
+        result = await LuceneSearch(activeSearch, page, PAGE_SIZE)
       } else {
-        const req = buildQueryRequest(page, filterState)
+        const req = { ...buildQueryRequest(page, filterState), ...nav }
         result = await QueryEvents(req)
+        pageNavRef.current = nav
       }
 
       if (result) {
+        setContextInfo(null)
         setEvents(result.events || [])
         setTotalCount(result.totalCount)
         setCurrentPage(result.page)
+        setCursors({ next: result.nextCursor || '', prev: result.prevCursor || '' })
 
-        const filterCount = (filterState || activeFilters)?.filters?.length || 0
+        const fs = filterState || activeFilters
+        const filterCount = (fs?.filters?.length || 0) + (fs?.groups || []).reduce((n, g) => n + g.filters.length, 0)
         const filterLabel = filterCount > 0 ? ` (${filterCount} filter${filterCount > 1 ? 's' : ''} active)` : ''
-        const searchLabel = activeSearch ? (searchMode === 'advanced' ? ' | Advanced: ' + activeSearch : ` | Search: "${activeSearch}"`) : ''
+        const searchLabel = activeSearch ? (searchMode === 'advanced' ? ' | Advanced: ' + activeSearch : searchMode === 'lucene' ? ' | Query: ' + activeSearch : ` | Search: "${activeSearch}"`) : ''
         const bookmarkLabel = bookmarkOnly ? ' | \u2605 Bookmarked only' : ''
         setStatus(`Showing ${result.events?.length || 0} of ${result.totalCount.toLocaleString()} events${filterLabel}${searchLabel}${bookmarkLabel}`)
       }
     } catch (err) {
-      if (searchMode === 'advanced') {
+      if (searchMode !== 'simple') {
         setSearchError(String(err))
       }
       setStatus('Error: ' + err)
@@ -331,20 +362,22 @@ function App() {
     }
   }, [])
 
+  // Sequential and first/last navigation use cursors so large timelines
+  // never scan past skipped rows; typed page numbers still use offsets.
   const handlePrevPage = useCallback(() => {
-    if (currentPage > 1) loadPage(currentPage - 1)
-  }, [currentPage, loadPage])
+    if (currentPage > 1) loadPage(currentPage - 1, null, null, cursors.prev ? { direction: 'prev', cursor: cursors.prev } : undefined)
+  }, [currentPage, cursors, loadPage])
 
   const handleNextPage = useCallback(() => {
-    if (currentPage < totalPages) loadPage(currentPage + 1)
-  }, [currentPage, totalPages, loadPage])
+    if (currentPage < totalPages) loadPage(currentPage + 1, null, null, cursors.next ? { direction: 'next', cursor: cursors.next } : undefined)
+  }, [currentPage, totalPages, cursors, loadPage])
 
   const handleFirstPage = useCallback(() => {
-    if (currentPage > 1) loadPage(1)
+    if (currentPage > 1) loadPage(1, null, null, { direction: 'first' })
   }, [currentPage, loadPage])
 
   const handleLastPage = useCallback(() => {
-    if (currentPage < totalPages) loadPage(totalPages)
+    if (currentPage < totalPages) loadPage(totalPages, null, null, { direction: 'last' })
   }, [currentPage, totalPages, loadPage])
 
   const handlePageInputSubmit = useCallback(() => {
@@ -385,9 +418,23 @@ function App() {
     setSelectedEvent(null)
   }, [])
 
+  // Show an indicator's hits from the batch search as a keyword search
+  const handleShowIndicatorHits = useCallback((value) => {
+    setSearchMode('simple')
+    setShowSearchHelp(false)
+    setSearchText(value)
+    setSearchError('')
+    setActiveSearch(value)
+    setCurrentPage(1)
+    setSelectedEvent(null)
+    setShowIndicatorSearch(false)
+  }, [])
+
   const handleToggleSearchMode = useCallback(() => {
-    const newMode = searchMode === 'simple' ? 'advanced' : 'simple'
+    // Cycle keyword -> field query -> SQL -> keyword
+    const newMode = { simple: 'lucene', lucene: 'advanced', advanced: 'simple' }[searchMode]
     setSearchMode(newMode)
+    setShowSearchHelp(false)
     setSearchText('')
     setActiveSearch('')
     setSearchError('')
@@ -427,6 +474,23 @@ function App() {
     }
   }, [bookmarkOnly]) // eslint-disable-line react-hooks/exhaustive-deps
 
+  // Reload when the grid's sort changes
+  useEffect(() => {
+    if (dbInfo) {
+      loadPage(1)
+    }
+  }, [sortModel]) // eslint-disable-line react-hooks/exhaustive-deps
+
+  // Sort on the server so the order holds across pages. Shift-click a
+  // header to add it as a secondary sort column.
+  const handleSortChanged = useCallback((params) => {
+    const sort = params.api.getColumnState()
+      .filter(c => c.sort && c.colId !== 'id')
+      .sort((a, b) => (a.sortIndex ?? 0) - (b.sortIndex ?? 0))
+      .map(c => ({ field: sortColumnMap[c.colId] || c.colId, direction: c.sort.toUpperCase() }))
+    setSortModel(sort)
+  }, [])
+
   const toggleFilters = useCallback(() => {
     setShowFilters(prev => !prev)
   }, [])
@@ -481,6 +545,25 @@ function App() {
     }
   }, [buildQueryRequest, activeFilters])
 
+  // Undo/redo annotation changes; inside a text field the shortcut edits the text instead
+  const handleUndoRedo = useCallback(async (redo) => {
+    const el = document.activeElement
+    if (el && (el.tagName === 'INPUT' || el.tagName === 'TEXTAREA')) {
+      document.execCommand(redo ? 'redo' : 'undo')
+      return
+    }
+    if (!dbInfo) return
+    try {
+      const msg = redo ? await Redo() : await Undo()
+      await loadPage(currentPage)
+      setSelectedEvent(null)
+      setSelectedEvents([])
+      setStatus(msg)
+    } catch (err) {
+      setStatus((redo ? 'Redo' : 'Undo') + ' error: ' + err)
+    }
+  }, [dbInfo, loadPage, currentPage])
+
   const toggleTimeline = useCallback(() => {
     setShowTimeline(prev => !prev)
   }, [])
@@ -551,6 +634,92 @@ function App() {
     }
   }, [])
 
+  // Show the events around one event in the grid, in place of the current
+  // page until the next page load. window is a duration such as '5m', or
+  // 'n:25' for 25 events either side.
+  const handleShowContext = useCallback(async (eventId, window, filtered) => {
+    setLoading(true)
+    try {
+      const req = { id: eventId, filters: filtered ? buildQueryRequest(1, activeFilters) : null }
+      const count = window.startsWith('n:') ? parseInt(window.slice(2), 10) : 0
+      if (count > 0) {
+        req.before = count
+        req.after = count
+      } else {
+        req.window = window
+      }
+      const result = await GetEventContext(req)
+      const events = result.events || []
+      const label = count > 0 ? `\u00b1${count} events` : `\u00b1${window}`
+      setEvents(events)
+      setContextInfo({
+        anchorId: eventId,
+        description: `Context ${label} around event ${eventId}${filtered ? ', matching the current filters' : ', all sources'}${result.truncated ? ' (truncated)' : ''}`,
+      })
+      setStatus(`Context: ${events.length} events ${label} around event ${eventId}`)
+      setTimeout(() => {
+        gridRef.current?.api?.ensureIndexVisible(result.anchorIndex, 'middle')
+      }, 0)
+    } catch (err) {
+      setStatus('Error: ' + err)
+    } finally {
+      setLoading(false)
+    }
+  }, [buildQueryRequest, activeFilters])
+
+  // Show the events sharing a field's value with an event, such as every
+  // event for the same filename, in place of the current page
+  const handlePivot = useCallback(async (eventId, field) => {
+    setLoading(true)
+    try {
+      const result = await Pivot({ id: eventId, field, page: 1, pageSize: PAGE_SIZE })
+      const events = result.events || []
+      const breakdown = (result.sourceTypes || []).slice(0, 5)
+        .map(st => `${st.count.toLocaleString()} ${st.sourceType || '(none)'}`).join(', ')
+      const shown = result.totalCount > events.length ? `first ${events.length} of ` : ''
+      setEvents(events)
+      setContextInfo({
+        anchorId: eventId,
+        description: `Pivot on ${field} = ${result.value}: ${shown}${result.totalCount.toLocaleString()} events (${breakdown})`,
+      })
+      setStatus(`Pivot: ${result.totalCount.toLocaleString()} events with ${field} = ${result.value}`)
+    } catch (err) {
+      setStatus('Error: ' + err)
+    } finally {
+      setLoading(false)
+    }
+  }, [])
+
+  // The advanced and field query modes run their own queries, so only the
+  // filters and sort are explained there
+  const handleExplain = useCallback(() => {
+    const req = { ...buildQueryRequest(currentPage, activeFilters), ...pageNavRef.current }
+    if (searchMode !== 'simple') req.searchText = ''
+    setPlanRequest(req)
+  }, [buildQueryRequest, currentPage, activeFilters, searchMode])
+
+  // Show the events matching a Sigma rule in place of the current page
+  const handleShowSigmaMatches = useCallback(async (rule) => {
+    setLoading(true)
+    try {
+      const result = await GetSigmaMatches(rule.path, 1, PAGE_SIZE)
+      const events = result.events || []
+      const shown = result.totalCount > events.length ? `first ${events.length} of ` : ''
+      setEvents(events)
+      setSelectedEvent(null)
+      setContextInfo({
+        anchorId: null,
+        description: `Sigma: ${rule.title}${rule.level ? ` [${rule.level}]` : ''}: ${shown}${result.totalCount.toLocaleString()} events`,
+      })
+      setStatus(`Sigma: ${result.totalCount.toLocaleString()} events match ${rule.title}`)
+      setShowSigma(false)
+    } catch (err) {
+      setStatus('Error: ' + err)
+    } finally {
+      setLoading(false)
+    }
+  }, [])
+
   const handleCloseDetail = useCallback(() => {
     setSelectedEvent(null)
     setSelectedEvents([])
@@ -660,6 +829,8 @@ function App() {
     const cancelAbout = EventsOn('menu:about', () => { setShowAbout(true) })
     const cancelHelp = EventsOn('menu:help', () => { setShowHelp(true) })
     const cancelLogging = EventsOn('menu:logging', () => { setShowLogging(true) })
+    const cancelUndo = EventsOn('menu:undo', () => { handleUndoRedo(false) })
+    const cancelRedo = EventsOn('menu:redo', () => { handleUndoRedo(true) })
     return () => {
       if (typeof cancelOpen === 'function') cancelOpen()
       if (typeof cancelImport === 'function') cancelImport()
@@ -673,10 +844,17 @@ function App() {
       if (typeof cancelAbout === 'function') cancelAbout()
       if (typeof cancelHelp === 'function') cancelHelp()
       if (typeof cancelLogging === 'function') cancelLogging()
+      if (typeof cancelUndo === 'function') cancelUndo()
+      if (typeof cancelRedo === 'function') cancelRedo()
     }
-  }, [handleOpenDB, handleImportCSV, handleCloseDB, handleExportCSV])
+  }, [handleOpenDB, handleImportCSV, handleCloseDB, handleExportCSV, handleUndoRedo])
 
   // Color-coded row styling based on the event's color field
+  // Mark the anchor of a context window or pivot
+  const rowClassRules = useMemo(() => ({
+    'context-anchor': (params) => contextInfo !== null && params.data?.id === contextInfo.anchorId,
+  }), [contextInfo])
+
   const getRowStyle = useCallback((params) => {
     const color = params.data?.color
     if (!color) return null
@@ -772,19 +950,22 @@ function App() {
         >
           Timeline
         </button>
+        <button onClick={() => setShowIndicatorSearch(true)} title="Search for a list of indicators">IOC Search</button>
+        <button onClick={() => setShowSigma(true)} title="Run Sigma detection rules">Sigma</button>
+        <button onClick={handleExplain} title="Explain the current query and recommend indexes">Explain</button>
         <div className="toolbar-separator" />
         <div className="search-bar">
           <button
-            className={`search-mode-btn ${searchMode === 'advanced' ? 'active' : ''}`}
+            className={`search-mode-btn ${searchMode !== 'simple' ? 'active' : ''}`}
             onClick={handleToggleSearchMode}
-            title={searchMode === 'simple' ? 'Switch to advanced SQL mode' : 'Switch to simple keyword mode'}
+            title={{ simple: 'Switch to field query mode', lucene: 'Switch to advanced SQL mode', advanced: 'Switch to simple keyword mode' }[searchMode]}
           >
-            {searchMode === 'simple' ? 'Aa' : 'SQL'}
+            {{ simple: 'Aa', lucene: 'KQL', advanced: 'SQL' }[searchMode]}
           </button>
           <input
             type="text"
-            className={searchMode === 'advanced' ? 'search-input-advanced' : ''}
-            placeholder={searchMode === 'advanced' ? "source = 'FILE' AND datetime > '2025-01-01'" : 'Search events...'}
+            className={searchMode !== 'simple' ? 'search-input-advanced' : ''}
+            placeholder={{ simple: 'Search events...', lucene: 'host:WS01 AND user:admin* AND NOT source:REG', advanced: "source = 'FILE' AND datetime > '2025-01-01'" }[searchMode]}
             value={searchText}
             onChange={(e) => { setSearchText(e.target.value); setSearchError('') }}
             onKeyDown={(e) => { if (e.key === 'Enter') handleSearch() }}
@@ -793,6 +974,15 @@ function App() {
             <button className="search-clear" onClick={handleClearSearch} title="Clear search">x</button>
           )}
           <button className="search-btn" onClick={handleSearch}>Search</button>
+          {searchMode === 'lucene' && (
+            <button
+              className="search-help-btn"
+              onClick={() => setShowSearchHelp(prev => !prev)}
+              title="Show field query syntax"
+            >
+              ?
+            </button>
+          )}
           {searchMode === 'advanced' && (
             <>
               <button
@@ -813,20 +1003,38 @@ function App() {
               )}
             </>
           )}
-          {showSearchHelp && (
+          {showSearchHelp && searchMode === 'lucene' && (
+            <div className="search-help-popup">
+              <div className="search-help-header">
+                <span>Field Query Help</span>
+                <button onClick={() => setShowSearchHelp(false)}>x</button>
+              </div>
+              <div className="search-help-body">
+                <p><strong>Terms:</strong> field:value matches exactly; * and ? are wildcards; field:"two words" quotes a value; field:* means not empty. A word without a field searches all text fields.</p>
+                <p><strong>Ranges:</strong> field:[a TO b] inclusive, field:{'{'}a TO b{'}'} exclusive, * for an open end; field:&gt;value, &gt;=, &lt;, &lt;=. Partial dates such as 2025-01 cover the whole period.</p>
+                <p><strong>Logic:</strong> AND (the default), OR, NOT or -term, ( ), field:(a OR b)</p>
+                <p><strong>Examples:</strong></p>
+                <code>host:WS01 AND user:admin* AND NOT source:REG</code>
+                <code>datetime:[2024-01-01 TO 2024-01-02] event_identifier:(4624 OR 4625)</code>
+                <code>message:"Run key" -tag:benign</code>
+              </div>
+            </div>
+          )}
+          {showSearchHelp && searchMode === 'advanced' && (
             <div className="search-help-popup">
               <div className="search-help-header">
                 <span>Advanced Search Help</span>
                 <button onClick={() => setShowSearchHelp(false)}>x</button>
               </div>
               <div className="search-help-body">
-                <p><strong>Fields:</strong> datetime, timezone, MACB, source, sourcetype, type, user, host, desc, filename, inode, notes, format, extra, reportnotes, inreport, tag, color, offset, store_number, store_index, vss_store_number, URL, record_number, event_identifier, event_type, source_name, user_sid, computer_name, bookmark</p>
-                <p><strong>Operators:</strong> =, !=, LIKE, NOT LIKE, &gt;, &lt;, &gt;=, &lt;=, AND, OR, BETWEEN</p>
-                <p><strong>PostgreSQL note:</strong> The columns <em>desc</em>, <em>user</em>, and <em>offset</em> are reserved words and will be auto-quoted when using a PostgreSQL database.</p>
+                <p><strong>Fields:</strong> datetime, timezone, MACB, source, sourcetype, type, user, host, desc, filename, inode, notes, format, extra, reportnotes, inreport, tag, color, offset, store_number, store_index, vss_store_number, URL, record_number, event_identifier, event_type, source_name, user_sid, computer_name, bookmark, batch_id</p>
+                <p><strong>Operators:</strong> =, !=, LIKE, NOT LIKE, &gt;, &lt;, &gt;=, &lt;=, IN, NOT IN, BETWEEN, IS EMPTY, IS NOT EMPTY, AND, OR, NOT, ( )</p>
+                <p><strong>Values:</strong> quote text as 'value'; numbers may be bare. Subqueries, functions and semicolons are rejected.</p>
                 <p><strong>Examples:</strong></p>
                 <code>source = 'EXAMINER'</code>
                 <code>desc LIKE '%malware%' AND host = 'WORKSTATION1'</code>
                 <code>datetime BETWEEN '2025-01-01' AND '2025-06-01'</code>
+                <code>event_identifier IN (4624, 4625) AND NOT user IS EMPTY</code>
               </div>
             </div>
           )}
@@ -901,6 +1109,26 @@ function App() {
         onClose={() => setShowLogging(false)}
       />
 
+      <IndicatorSearch
+        visible={showIndicatorSearch}
+        onClose={() => setShowIndicatorSearch(false)}
+        onShowHits={handleShowIndicatorHits}
+        onChanged={() => loadPage(currentPage)}
+      />
+
+      <SigmaRules
+        visible={showSigma}
+        onClose={() => setShowSigma(false)}
+        onShowMatches={handleShowSigmaMatches}
+        onChanged={() => loadPage(currentPage)}
+      />
+
+      <QueryPlan
+        visible={planRequest !== null}
+        request={planRequest}
+        onClose={() => setPlanRequest(null)}
+      />
+
       <PostgresDialog
         visible={showPushPostgres}
         mode="push"
@@ -940,6 +1168,13 @@ function App() {
             theme={currentTheme}
           />
 
+          {contextInfo && (
+            <div className="context-bar">
+              <span>{contextInfo.description}</span>
+              <button onClick={() => loadPage(currentPage)}>Back to results</button>
+            </div>
+          )}
+
           <div className={`grid-container ${lightThemes.has(currentTheme) ? 'ag-theme-alpine' : 'ag-theme-alpine-dark'}`}>
             <AgGridReact
               ref={gridRef}
@@ -953,7 +1188,9 @@ function App() {
               suppressCellFocus={false}
               getRowId={(params) => String(params.data.id)}
               getRowStyle={getRowStyle}
+              rowClassRules={rowClassRules}
               onSelectionChanged={handleRowSelected}
+              onSortChanged={handleSortChanged}
               onCellClicked={(params) => {
                 if (params.colDef.field === 'bookmark') {
                   handleBookmarkToggle(params.data.id)
@@ -1016,6 +1253,8 @@ function App() {
                 searchText={activeSearch}
                 onToggleBookmark={handleBookmarkToggle}
                 onDeleteNote={handleDeleteExaminerNote}
+                onShowContext={handleShowContext}
+                onPivot={handlePivot}
               />
             </>
           )}
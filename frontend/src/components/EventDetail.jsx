@@ -1,5 +1,5 @@
 import { useState, useEffect, useCallback } from 'react'
-import { UpdateEventFields, UpdateExaminerNoteColor } from '../../wailsjs/go/main/App'
+import { UpdateEventFields, UpdateExaminerNoteColor, GetRelatedEvents } from '../../wailsjs/go/main/App'
 import HighlightText from './HighlightText'
 
 // Field groups for organized display
@@ -50,6 +50,17 @@ const fieldGroups = [
   },
 ]
 
+// Windows offered for showing the events around this one. 'n:25' means
+// 25 events either side.
+const contextWindows = [
+  { value: '1m', label: '\u00b11 min' },
+  { value: '5m', label: '\u00b15 min' },
+  { value: '15m', label: '\u00b115 min' },
+  { value: '1h', label: '\u00b11 hour' },
+  { value: 'n:25', label: '\u00b125 events' },
+  { value: 'n:100', label: '\u00b1100 events' },
+]
+
 // Colors available for marking events
 const colorOptions = [
   '', 'RED', 'ORANGE', 'YELLOW', 'GREEN', 'BLUE', 'PURPLE', 'WHITE', 'BLACK',
@@ -67,7 +78,9 @@ const colorDisplayMap = {
   'BLACK': '#2c3e50',
 }
 
-function EventDetail({ event, onUpdate, onClose, height, searchText, onToggleBookmark, onDeleteNote }) {
+function EventDetail({ event, onUpdate, onClose, height, searchText, onToggleBookmark, onDeleteNote, onShowContext, onPivot }) {
+  const [contextWindow, setContextWindow] = useState('5m')
+  const [contextFiltered, setContextFiltered] = useState(false)
   const isExaminerNote = event && event.id < 0
   const [tag, setTag] = useState('')
   const [color, setColor] = useState('')
@@ -75,6 +88,8 @@ function EventDetail({ event, onUpdate, onClose, height, searchText, onToggleBoo
   const [reportNotes, setReportNotes] = useState('')
   const [saving, setSaving] = useState(false)
   const [dirty, setDirty] = useState(false)
+  const [related, setRelated] = useState(null)
+  const [relatedLoading, setRelatedLoading] = useState(false)
 
   // Sync state when a new event is selected
   useEffect(() => {
@@ -84,9 +99,25 @@ function EventDetail({ event, onUpdate, onClose, height, searchText, onToggleBoo
       setNotes(event.notes || '')
       setReportNotes(event.reportnotes || '')
       setDirty(false)
+      setRelated(null)
     }
   }, [event?.id])
 
+  // Count the events sharing each pivotable value with this one. Loaded on
+  // request since every pivot is a count over the whole table.
+  const handleLoadRelated = useCallback(async () => {
+    if (!event) return
+    setRelatedLoading(true)
+    try {
+      setRelated(await GetRelatedEvents(event.id) || [])
+    } catch (err) {
+      console.error('Error loading related events:', err)
+      setRelated([])
+    } finally {
+      setRelatedLoading(false)
+    }
+  }, [event])
+
   const handleSave = useCallback(async () => {
     if (!event || !dirty) return
 
@@ -144,6 +175,22 @@ function EventDetail({ event, onUpdate, onClose, height, searchText, onToggleBoo
             Delete
           </button>
         )}
+        {onShowContext && (
+          <span className="detail-context">
+            <select value={contextWindow} onChange={(e) => setContextWindow(e.target.value)} title="How much to show around this event">
+              {contextWindows.map(w => (
+                <option key={w.value} value={w.value}>{w.label}</option>
+              ))}
+            </select>
+            <label title="Only show surrounding events that match the current filters">
+              <input type="checkbox" checked={contextFiltered} onChange={(e) => setContextFiltered(e.target.checked)} />
+              Filtered
+            </label>
+            <button onClick={() => onShowContext(event.id, contextWindow, contextFiltered)} title="Show the events around this one">
+              Context
+            </button>
+          </span>
+        )}
         <button className="detail-close" onClick={onClose}>x</button>
       </div>
 
@@ -166,6 +213,35 @@ function EventDetail({ event, onUpdate, onClose, height, searchText, onToggleBoo
           </div>
         )}
 
+        {/* Related events by pivot */}
+        {onPivot && (
+          <div className="detail-desc-section">
+            <label>Related</label>
+            {related === null ? (
+              <button className="detail-related-load" onClick={handleLoadRelated} disabled={relatedLoading}>
+                {relatedLoading ? 'Counting...' : 'Find related events'}
+              </button>
+            ) : related.length === 0 ? (
+              <div className="detail-desc-text">(no pivotable values)</div>
+            ) : (
+              <div className="detail-related">
+                {related.map(r => (
+                  <button
+                    key={r.field}
+                    className="detail-related-item"
+                    onClick={() => onPivot(event.id, r.field)}
+                    title={`Show every event with ${r.field} = ${r.value}`}
+                  >
+                    <span className="detail-field-label">{r.field}</span>
+                    <span className="detail-related-value">{r.value}</span>
+                    <span className="detail-related-count">{r.count.toLocaleString()}</span>
+                  </button>
+                ))}
+              </div>
+            )}
+          </div>
+        )}
+
         {/* Field groups in columns */}
         <div className="detail-fields">
           {fieldGroups.map(group => (
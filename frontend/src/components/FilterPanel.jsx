@@ -1,8 +1,24 @@
 import { useState, useEffect, useCallback } from 'react'
 import { GetDistinctValues, GetMinMaxDate } from '../../wailsjs/go/main/App'
 
+// Operators that take no value
+const valuelessOperators = new Set(['IS EMPTY', 'IS NOT EMPTY'])
+
+// Value hints for operators whose value has a particular form
+const valuePlaceholders = {
+  'LIKE': '%pattern%',
+  'NOT LIKE': '%pattern%',
+  'LIKE CASE': 'exact-case text',
+  'REGEXP': 'regular expression',
+  'NOT REGEXP': 'regular expression',
+  'IN': 'a, b, c',
+  'NOT IN': 'a, b, c',
+  'BETWEEN': 'low, high',
+}
+
 function FilterPanel({ visible, onApply, onClear, dbInfo, activeFilters, filterVersion }) {
   const [filters, setFilters] = useState([])
+  const [groups, setGroups] = useState([])
   const [logic, setLogic] = useState('AND')
   const [dateFrom, setDateFrom] = useState('')
   const [dateTo, setDateTo] = useState('')
@@ -15,6 +31,7 @@ function FilterPanel({ visible, onApply, onClear, dbInfo, activeFilters, filterV
     if (activeFilters.dateFrom) setDateFrom(activeFilters.dateFrom)
     if (activeFilters.dateTo) setDateTo(activeFilters.dateTo)
     if (activeFilters.filters) setFilters(activeFilters.filters)
+    setGroups(activeFilters.groups || [])
     if (activeFilters.logic) setLogic(activeFilters.logic)
   }, [activeFilters])
 
@@ -91,19 +108,42 @@ function FilterPanel({ visible, onApply, onClear, dbInfo, activeFilters, filterV
     setFilters(prev => prev.filter((_, i) => i !== index))
   }, [])
 
+  // Groups are parenthesized sets of filters with their own AND/OR logic,
+  // combined with the top-level filters using the panel's logic
+  const addGroup = useCallback(() => {
+    setGroups(prev => [...prev, { logic: 'AND', filters: [{ field: 'source', operator: '=', value: '' }] }])
+  }, [])
+
+  const updateGroup = useCallback((index, update) => {
+    setGroups(prev => {
+      const updated = [...prev]
+      updated[index] = update(updated[index])
+      return updated
+    })
+  }, [])
+
+  const removeGroup = useCallback((index) => {
+    setGroups(prev => prev.filter((_, i) => i !== index))
+  }, [])
+
   const handleApply = useCallback(() => {
-    // Build filter list, excluding empty values
-    const activeFilters = filters.filter(f => f.value.trim() !== '')
+    // Build filter list, excluding empty values and groups left empty
+    const nonEmpty = list => list.filter(f => f.value.trim() !== '' || valuelessOperators.has(f.operator))
+    const activeGroups = groups
+      .map(g => ({ ...g, filters: nonEmpty(g.filters) }))
+      .filter(g => g.filters.length > 0)
     onApply({
-      filters: activeFilters,
+      filters: nonEmpty(filters),
+      groups: activeGroups,
       logic,
       dateFrom,
       dateTo,
     })
-  }, [filters, logic, dateFrom, dateTo, onApply])
+  }, [filters, groups, logic, dateFrom, dateTo, onApply])
 
   const handleClear = useCallback(() => {
     setFilters([])
+    setGroups([])
     setLogic('AND')
     // Reset dates to full range
     if (dbInfo) {
@@ -119,6 +159,70 @@ function FilterPanel({ visible, onApply, onClear, dbInfo, activeFilters, filterV
     }
   }, [handleApply])
 
+  // renderFilterRow renders one filter's field, operator and value inputs
+  const renderFilterRow = (filter, key, onChange, onRemove) => (
+    <div key={key} className="filter-row">
+      <select
+        value={filter.field}
+        onChange={(e) => onChange('field', e.target.value)}
+      >
+        {filterFields.map(f => (
+          <option key={f.field} value={f.field}>{f.label}</option>
+        ))}
+        <option value="desc">Description</option>
+        <option value="filename">Filename</option>
+        <option value="tag">Tag</option>
+        <option value="notes">Notes</option>
+        <option value="extra">Extra</option>
+        <option value="event_identifier">Event ID</option>
+      </select>
+
+      <select
+        value={filter.operator}
+        onChange={(e) => onChange('operator', e.target.value)}
+      >
+        <option value="=">=</option>
+        <option value="!=">!=</option>
+        <option value="LIKE">LIKE</option>
+        <option value="NOT LIKE">NOT LIKE</option>
+        <option value="LIKE CASE">LIKE (case)</option>
+        <option value="REGEXP">REGEXP</option>
+        <option value="NOT REGEXP">NOT REGEXP</option>
+        <option value="IN">IN</option>
+        <option value="NOT IN">NOT IN</option>
+        <option value="BETWEEN">BETWEEN</option>
+        <option value="IS EMPTY">IS EMPTY</option>
+        <option value="IS NOT EMPTY">IS NOT EMPTY</option>
+        {filter.field === 'tag' && <option value="HAS TAG">HAS TAG</option>}
+        {filter.field === 'tag' && <option value="NOT HAS TAG">NOT HAS TAG</option>}
+      </select>
+
+      {/* Show dropdown if we have distinct values for this field, text input otherwise */}
+      {valuelessOperators.has(filter.operator) ? (
+        <span className="filter-no-value" />
+      ) : distinctValues[filter.field] && filter.operator === '=' ? (
+        <select
+          value={filter.value}
+          onChange={(e) => onChange('value', e.target.value)}
+        >
+          <option value="">-- select --</option>
+          {distinctValues[filter.field].map(v => (
+            <option key={v} value={v}>{v}</option>
+          ))}
+        </select>
+      ) : (
+        <input
+          type="text"
+          value={filter.value}
+          placeholder={valuePlaceholders[filter.operator] || 'value'}
+          onChange={(e) => onChange('value', e.target.value)}
+        />
+      )}
+
+      <button className="filter-remove" onClick={onRemove}>x</button>
+    </div>
+  )
+
   if (!visible) return null
 
   return (
@@ -157,59 +261,45 @@ function FilterPanel({ visible, onApply, onClear, dbInfo, activeFilters, filterV
       </div>
 
       <div className="filter-list">
-        {filters.map((filter, i) => (
-          <div key={i} className="filter-row">
-            <select
-              value={filter.field}
-              onChange={(e) => updateFilter(i, 'field', e.target.value)}
-            >
-              {filterFields.map(f => (
-                <option key={f.field} value={f.field}>{f.label}</option>
-              ))}
-              <option value="desc">Description</option>
-              <option value="filename">Filename</option>
-              <option value="tag">Tag</option>
-              <option value="notes">Notes</option>
-              <option value="extra">Extra</option>
-            </select>
-
-            <select
-              value={filter.operator}
-              onChange={(e) => updateFilter(i, 'operator', e.target.value)}
-            >
-              <option value="=">=</option>
-              <option value="!=">!=</option>
-              <option value="LIKE">LIKE</option>
-              <option value="NOT LIKE">NOT LIKE</option>
-            </select>
-
-            {/* Show dropdown if we have distinct values for this field, text input otherwise */}
-            {distinctValues[filter.field] && filter.operator === '=' ? (
-              <select
-                value={filter.value}
-                onChange={(e) => updateFilter(i, 'value', e.target.value)}
-              >
-                <option value="">-- select --</option>
-                {distinctValues[filter.field].map(v => (
-                  <option key={v} value={v}>{v}</option>
-                ))}
-              </select>
-            ) : (
-              <input
-                type="text"
-                value={filter.value}
-                placeholder={filter.operator.includes('LIKE') ? '%pattern%' : 'value'}
-                onChange={(e) => updateFilter(i, 'value', e.target.value)}
-              />
-            )}
-
-            <button className="filter-remove" onClick={() => removeFilter(i)}>x</button>
+        {filters.map((filter, i) => renderFilterRow(
+          filter, i,
+          (key, val) => updateFilter(i, key, val),
+          () => removeFilter(i),
+        ))}
+        {groups.map((group, g) => (
+          <div key={`group-${g}`} className="filter-group">
+            <div className="filter-group-header">
+              <div className="filter-logic">
+                <button
+                  className={group.logic === 'AND' ? 'active' : ''}
+                  onClick={() => updateGroup(g, grp => ({ ...grp, logic: 'AND' }))}
+                >AND</button>
+                <button
+                  className={group.logic === 'OR' ? 'active' : ''}
+                  onClick={() => updateGroup(g, grp => ({ ...grp, logic: 'OR' }))}
+                >OR</button>
+              </div>
+              <button className="filter-remove" onClick={() => removeGroup(g)} title="Remove group">x</button>
+            </div>
+            {group.filters.map((filter, i) => renderFilterRow(
+              filter, i,
+              (key, val) => updateGroup(g, grp => ({
+                ...grp,
+                filters: grp.filters.map((f, j) => j === i ? { ...f, [key]: val } : f),
+              })),
+              () => updateGroup(g, grp => ({ ...grp, filters: grp.filters.filter((_, j) => j !== i) })),
+            ))}
+            <button
+              className="filter-group-add"
+              onClick={() => updateGroup(g, grp => ({ ...grp, filters: [...grp.filters, { field: 'source', operator: '=', value: '' }] }))}
+            >+ Add Filter</button>
           </div>
         ))}
       </div>
 
       <div className="filter-actions">
         <button onClick={addFilter}>+ Add Filter</button>
+        <button onClick={addGroup}>+ Add Group</button>
         <button className="filter-apply" onClick={handleApply}>Apply</button>
         <button onClick={handleClear}>Clear</button>
       </div>
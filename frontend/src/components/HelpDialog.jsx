@@ -31,8 +31,14 @@ After import, events are stored in the database (SQLite or PostgreSQL) for fast
 Adding Filters:
 Click "Add Filter" to create a new filter row. Each filter has three parts: a field name (e.g., source, sourcetype, desc), an operator (equals, not equals, contains, not contains), and a value. For equals/not equals, a dropdown shows all distinct values in that field. For contains/not contains, type any text.
 
+More operators:
+LIKE (case) finds text containing the value with exactly the same upper and lower case. REGEXP and NOT REGEXP match a case-sensitive regular expression, such as mimikatz|procdump or \\.ps1$. IN and NOT IN take a comma-separated list (4624, 4625, 4634); BETWEEN takes "low, high" and includes both ends. IS EMPTY and IS NOT EMPTY need no value and check for blank fields.
+
 Filter Logic:
-Use the AND/OR toggle to control how multiple filters combine. AND means all filters must match. OR means any filter can match.
+Use the AND/OR toggle to control how multiple filters combine. AND means all filters must match. OR means any filter can match. The toggle applies only to filters and groups: search text and Bookmarked only always narrow the results further, whichever way it is set. The timeline histogram follows the same logic as the grid.
+
+Filter Groups:
+Click "Add Group" to add a parenthesized group of filters with its own AND/OR toggle. Groups combine with the other filters using the panel's toggle, so (source = EVT AND event_identifier = 4624) OR (source = EVT AND event_identifier = 4625) is two AND groups with the panel set to OR.
 
 Date Range:
 Set a start and end date to limit results to a specific time window. The date range works alongside other filters using AND logic.
@@ -49,28 +55,73 @@ Type your search term and press Enter or click the Search button. The search loo
 
 Matching text is highlighted in the grid and detail panel. Highlight colors adapt to the active theme for optimal readability.
 
-Search works alongside filters. If you have active filters and perform a search, both conditions must be satisfied (AND logic). The status bar shows the active search term.
+Search works alongside filters. If you have active filters and perform a search, both conditions must be satisfied (AND logic), even when the filter panel is set to OR. The status bar shows the active search term.
 
 To clear the search, click the "x" button next to the search input.`
+  },
+  {
+    id: 'indicator-search',
+    title: 'Indicator Search',
+    content: `Click IOC Search in the toolbar to search for a whole list of indicators of compromise at once, such as IP addresses, domains, hashes, file names and user names.
+
+Paste the list one indicator per line (lines starting with # are ignored), or click Load File to read a text file, a CSV file or a STIX 2 JSON bundle. A CSV file with an indicator, ioc or value column reads that column, and a name column labels each indicator; otherwise the first column is read. Defanged indicators such as hxxp://evil[.]example are refanged before searching.
+
+Each indicator is searched for like the search bar does, across the same fields. The results table lists the hits for each indicator and the number of events matching any of them. Click an indicator to search the grid for it.
+
+Tick Tag hits to tag every hit with its indicator's name, or its value when it has none, and pick a color to color every hit. Both can be undone.`
+  },
+  {
+    id: 'sigma-rules',
+    title: 'Sigma Rules',
+    content: `Click Sigma in the toolbar to run Sigma detection rules over the timeline. Choose a folder of rule files (.yml or .yaml, searched recursively) and click Run Rules. The results list each rule with its level and the number of matching events; click a rule to show its matches in the grid, and Back to results to return.
+
+Sigma field names are mapped onto timeline columns: EventID to event identifier, Provider_Name to source name, Computer to computer name, User to user, and so on. Fields plaso keeps in the event's extra attributes, such as Image and CommandLine, match when their value appears anywhere in the extra attributes or the description, so they can match more broadly than in a SIEM. Rules for the sysmon, security, powershell, taskscheduler and windefend services only match events from that provider. Values match regardless of case, on every database backend, unless the rule uses the cased modifier.
+
+Rules using aggregations, timeframes or the base64 and cidr modifiers are listed as not run, with the reason in the tooltip. Tick Tag matches to tag every match with the rule's title and its level, such as sigma:high.`
+  },
+  {
+    id: 'query-plan',
+    title: 'Query Plan',
+    content: `Click Explain in the toolbar to see how the database runs the grid's current filters, sort and search. SQLite and MySQL show their query plan and the query is timed; PostgreSQL runs it under EXPLAIN ANALYZE, which reports the time of each step. Examiner notes are left out, and in the advanced and field query modes only the filters and sort are explained.
+
+A full scan means the database reads every event to answer the query, which gets slow on large timelines. When a query scans the timeline, Explain recommends indexes on the fields it filters on, paired with datetime when the results are sorted or filtered by date, such as (source, datetime). Click Create to build one; the plan is explained again so you can see whether it helped. Description, extra, notes and tag are never recommended, since searches on them look for text anywhere in the value.`
+  },
+  {
+    id: 'field-queries',
+    title: 'Field Queries',
+    content: `Field query mode (KQL) accepts the Lucene-style syntax used by Kibana and Timesketch, so queries written there work here with little change.
+
+Terms: field:value matches a field exactly. * matches any run of characters and ? a single character, so user:admin* finds admin, administrator and so on. Quote values containing spaces or special characters: desc:"Run key added". field:* finds events where the field is not empty. A word without a field searches the same fields as simple mode, and a quoted phrase without a field matches that exact text in any of them.
+
+Ranges: datetime:[2024-01-01 TO 2024-01-02] includes both ends, {a TO b} excludes them, and * leaves an end open. field:>value, >=, < and <= compare directly. Event identifiers, record numbers and inodes are compared as numbers when the values are whole numbers, so event_identifier:[4624 TO 4625] does not match 46245. Partial dates cover the whole period, so datetime:2025-01 matches all of January.
+
+Logic: clauses next to each other must both match (AND). Use OR for either, NOT or a leading - to exclude, parentheses to group, and field:(a OR b) to try several values for one field. Examples:
+
+host:WS01 AND user:admin* AND NOT source:REG
+datetime:[2024-01-01 TO 2024-01-02] event_identifier:(4624 OR 4625)
+message:"Run key" -tag:benign
+
+Field names are the same as in SQL mode and are not case-sensitive. The Timesketch names message, timestamp, hostname, username, data_type, display_name and tags are accepted too. tag:name matches events carrying that tag. Use a backslash to search for a literal character such as \\: or \\*.`
   },
   {
     id: 'advanced-search',
     title: 'Advanced Search',
-    content: `Advanced search mode lets you write SQL WHERE clauses directly for precise queries. Toggle between simple keyword search and SQL mode using the Aa/SQL button next to the search bar.
+    content: `Advanced search mode lets you write SQL WHERE clauses directly for precise queries. Switch between keyword search, field queries and SQL mode using the Aa/KQL/SQL button next to the search bar.
 
-Toggling modes: Click the Aa/SQL button to switch. In simple mode (Aa), the search bar performs keyword matching across multiple fields. In SQL mode (SQL), the search bar accepts a raw WHERE clause.
+Toggling modes: Click the Aa/KQL/SQL button to cycle through the modes. In simple mode (Aa), the search bar performs keyword matching across multiple fields. In field query mode (KQL), it accepts Lucene-style queries (see Field Queries). In SQL mode (SQL), the search bar accepts a raw WHERE clause.
 
-SQL syntax: Write any valid SQL WHERE clause using the field names from the database. Examples:
+SQL syntax: Write a WHERE clause using the field names from the database. Clauses are checked before they run: comparisons, LIKE, IN, BETWEEN and IS EMPTY combined with AND, OR, NOT and parentheses are supported, and anything else (subqueries, functions, semicolons) is rejected with the column of the problem. Text values must be in single quotes. Examples:
 
 source = 'FILE'
 desc LIKE '%malware%' AND host = 'WORKSTATION1'
 datetime BETWEEN '2025-01-01' AND '2025-06-01'
 source = 'WEBHIST' OR source = 'OLECF'
 tag LIKE '%important%'
+event_identifier IN (4624, 4625) AND NOT user IS EMPTY
 
-Field reference: Click the ? button next to the search bar to see all 30 available field names and supported operators (=, !=, LIKE, NOT LIKE, >, <, >=, <=, AND, OR, BETWEEN, IN).
+Field reference: Click the ? button next to the search bar to see all 30 available field names and supported operators (=, !=, LIKE, NOT LIKE, >, <, >=, <=, AND, OR, NOT, BETWEEN, IN, IS EMPTY).
 
-PostgreSQL note: When connected to a PostgreSQL database, the columns desc, user, and offset are SQL reserved words. These are automatically double-quoted before execution, so you can use them as-is in your queries.
+Reserved words: the columns desc, user, and offset are SQL reserved words on PostgreSQL and MySQL. They are quoted automatically, so you can use them as-is in your queries.
 
 Saving advanced queries: Click the Save button (floppy disk icon) to save the current SQL query with a name. Saved advanced queries appear in the Saved Queries panel with a "SQL:" prefix. Loading a saved advanced query automatically switches to SQL mode.
 
@@ -118,7 +169,9 @@ Saved queries are stored per-database, so each database has its own set of saved
 
 Toggle columns on or off by clicking them. Some columns are hidden by default to reduce clutter (ID, Filename, Inode, Notes, Format, Extra, Color, URL, Record Number, Event ID, Event Type, Source Name, User SID, Computer). You can show any of these by toggling them in the column chooser.
 
-Columns can also be resized by dragging the column header borders, and reordered by dragging column headers.`
+Columns can also be resized by dragging the column header borders, and reordered by dragging column headers.
+
+Click a column header to sort the whole result set by that column; click again to reverse it. Shift-click further headers to add secondary sort columns. Events that tie on every sort column stay in import order, so paging never shuffles them.`
   },
   {
     id: 'event-detail',
@@ -129,7 +182,11 @@ The detail panel shows all fields for the selected event, including fields not v
 
 Notes and Report: Each event has Notes and Report Notes fields you can edit. You can also mark events as "In Report" and assign a color tag for visual highlighting. Click Save after making changes.
 
-Color-tagged events appear with a colored left border and subtle background tint in the grid for easy identification.`
+Color-tagged events appear with a colored left border and subtle background tint in the grid for easy identification.
+
+Context: To see what happened around an event, pick a window in the detail panel header (a few minutes either side, or a number of events either side) and click Context. The grid shows the surrounding events from every source, including examiner notes, with the chosen event highlighted. Tick Filtered to keep only surrounding events that match the current filters. Click Back to results, or change page, to return to the filtered view.
+
+Related: Click Find related events in the detail panel to count the events sharing this event's filename, inode, user SID, URL domain, computer name, host or user. Click any of them to pivot: the grid shows every event with that value, and the banner breaks the total down by source type. The URL domain pivot matches on the host name whatever the scheme, port or path.`
   },
   {
     id: 'examiner-notes',
@@ -0,0 +1,124 @@
+import { useState, useCallback } from 'react'
+import { ParseIndicators, LoadIndicators, BatchSearch } from '../../wailsjs/go/main/App'
+
+const colorOptions = ['', 'RED', 'ORANGE', 'YELLOW', 'GREEN', 'BLUE', 'PURPLE']
+
+// Batch search for a list of indicators of compromise, pasted or loaded from
+// a text, CSV or STIX file, with a hit count per indicator.
+function IndicatorSearch({ visible, onClose, onShowHits, onChanged }) {
+  const [text, setText] = useState('')
+  const [indicators, setIndicators] = useState(null)
+  const [tag, setTag] = useState(false)
+  const [color, setColor] = useState('')
+  const [results, setResults] = useState(null)
+  const [error, setError] = useState('')
+  const [loading, setLoading] = useState(false)
+
+  // A loaded file replaces the pasted text until the text is edited again
+  const handleLoad = useCallback(async () => {
+    setError('')
+    try {
+      const list = await LoadIndicators()
+      if (list) {
+        setIndicators(list)
+        setText(list.map(ind => ind.value).join('\n'))
+        setResults(null)
+      }
+    } catch (err) {
+      setError(String(err))
+    }
+  }, [])
+
+  const handleSearch = useCallback(async () => {
+    setError('')
+    setLoading(true)
+    try {
+      const list = indicators || await ParseIndicators(text) || []
+      if (list.length === 0) {
+        setError('Enter at least one indicator')
+        return
+      }
+      const result = await BatchSearch({ indicators: list, tag, color })
+      setResults(result)
+      if (onChanged && (tag || color)) onChanged()
+    } catch (err) {
+      setError(String(err))
+    } finally {
+      setLoading(false)
+    }
+  }, [indicators, text, tag, color, onChanged])
+
+  if (!visible) return null
+
+  return (
+    <div className="modal-overlay" onClick={onClose}>
+      <div className="ioc-dialog" onClick={(e) => e.stopPropagation()}>
+        <div className="logging-header">
+          <h2>Indicator Search</h2>
+          <button className="modal-close" onClick={onClose}>x</button>
+        </div>
+        <div className="ioc-content">
+          <textarea
+            className="ioc-input"
+            rows={8}
+            value={text}
+            placeholder={'One indicator per line, e.g.\n203.0.113.7\nevil[.]example\nmimikatz.exe'}
+            onChange={(e) => { setText(e.target.value); setIndicators(null) }}
+          />
+          <div className="ioc-options">
+            <button onClick={handleLoad} title="Load a text, CSV or STIX file">Load File...</button>
+            <label title="Tag each hit with the indicator's name, or its value">
+              <input type="checkbox" checked={tag} onChange={(e) => setTag(e.target.checked)} />
+              Tag hits
+            </label>
+            <label>
+              Color hits
+              <select value={color} onChange={(e) => setColor(e.target.value)}>
+                {colorOptions.map(c => <option key={c || 'none'} value={c}>{c || 'None'}</option>)}
+              </select>
+            </label>
+            <button className="ioc-search-btn" onClick={handleSearch} disabled={loading}>
+              {loading ? 'Searching...' : 'Search'}
+            </button>
+          </div>
+
+          {error && <div className="logging-error">{error}</div>}
+
+          {results && (
+            <>
+              <div className="ioc-summary">
+                {results.matched} of {results.results.length} indicators matched {results.totalHits.toLocaleString()} events
+              </div>
+              <div className="ioc-results">
+                <table>
+                  <thead>
+                    <tr><th>Indicator</th><th>Name</th><th>Type</th><th>Hits</th></tr>
+                  </thead>
+                  <tbody>
+                    {results.results.map(r => (
+                      <tr key={r.value} className={r.hits > 0 ? 'ioc-hit' : ''}>
+                        <td className="ioc-value" title={r.value}>
+                          {r.hits > 0 ? (
+                            <button onClick={() => onShowHits(r.value)} title="Search for this indicator">{r.value}</button>
+                          ) : r.value}
+                        </td>
+                        <td>{r.name}</td>
+                        <td>{r.type}</td>
+                        <td className="ioc-count">
+                          {r.hits.toLocaleString()}
+                          {r.error && <span className="ioc-error" title={r.error}> !</span>}
+                        </td>
+                      </tr>
+                    ))}
+                  </tbody>
+                </table>
+              </div>
+            </>
+          )}
+        </div>
+      </div>
+    </div>
+  )
+}
+
+export default IndicatorSearch
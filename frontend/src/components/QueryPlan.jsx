@@ -0,0 +1,119 @@
+import { useState, useEffect, useCallback } from 'react'
+import { ExplainQuery, CreateIndex } from '../../wailsjs/go/main/App'
+
+// Shows how the backend runs the grid's current query, how long it took,
+// and the indexes that would spare it a full scan, with a button to create
+// each one.
+function QueryPlan({ visible, onClose, request }) {
+  const [result, setResult] = useState(null)
+  const [error, setError] = useState('')
+  const [loading, setLoading] = useState(false)
+  const [creating, setCreating] = useState('')
+  const [created, setCreated] = useState([])
+
+  const explain = useCallback(async () => {
+    setError('')
+    setLoading(true)
+    try {
+      setResult(await ExplainQuery(request))
+    } catch (err) {
+      setError(String(err))
+      setResult(null)
+    } finally {
+      setLoading(false)
+    }
+  }, [request])
+
+  useEffect(() => {
+    if (visible) {
+      setCreated([])
+      explain()
+    }
+  }, [visible, explain])
+
+  // Re-explain after each index so the plan shows whether it helped
+  const handleCreate = useCallback(async (columns) => {
+    setError('')
+    setCreating(columns.join(','))
+    try {
+      const name = await CreateIndex(columns)
+      setCreated(prev => [...prev, name])
+      await explain()
+    } catch (err) {
+      setError(String(err))
+    } finally {
+      setCreating('')
+    }
+  }, [explain])
+
+  if (!visible) return null
+
+  const plan = result?.plan
+  const recommendations = result?.recommendations || []
+
+  return (
+    <div className="modal-overlay" onClick={onClose}>
+      <div className="ioc-dialog" onClick={(e) => e.stopPropagation()}>
+        <div className="logging-header">
+          <h2>Query Plan</h2>
+          <button className="modal-close" onClick={onClose}>x</button>
+        </div>
+        <div className="ioc-content">
+          {error && <div className="logging-error">{error}</div>}
+          {loading && !result && <div className="ioc-summary">Explaining query...</div>}
+
+          {plan && (
+            <>
+              <div className="ioc-summary">
+                Ran in {plan.durationMs.toLocaleString(undefined, { maximumFractionDigits: 1 })} ms
+                {plan.fullScans?.length > 0
+                  ? `, full scan of ${plan.fullScans.join(', ')}`
+                  : ', no full table scans'}
+                {plan.indexes?.length > 0 && `, using ${plan.indexes.join(', ')}`}
+              </div>
+              <pre className="plan-lines">{(plan.lines || []).join('\n')}</pre>
+              <details className="plan-sql">
+                <summary>SQL</summary>
+                <pre>{result.sql}</pre>
+              </details>
+            </>
+          )}
+
+          {created.length > 0 && (
+            <div className="ioc-summary">Created {created.join(', ')}</div>
+          )}
+
+          {recommendations.length > 0 && (
+            <div className="ioc-results">
+              <table>
+                <thead>
+                  <tr><th>Recommended index</th><th>Why</th><th></th></tr>
+                </thead>
+                <tbody>
+                  {recommendations.map(r => (
+                    <tr key={r.name} className="ioc-hit">
+                      <td className="ioc-value" title={r.name}>({r.columns.join(', ')})</td>
+                      <td>{r.reason}</td>
+                      <td className="ioc-count">
+                        <button onClick={() => handleCreate(r.columns)} disabled={!!creating || loading}>
+                          {creating === r.columns.join(',') ? 'Creating...' : 'Create'}
+                        </button>
+                      </td>
+                    </tr>
+                  ))}
+                </tbody>
+              </table>
+            </div>
+          )}
+          {plan && plan.fullScans?.length > 0 && recommendations.length === 0 && (
+            <div className="ioc-summary">
+              No index would help: the query filters only on free text, or existing indexes already cover its fields.
+            </div>
+          )}
+        </div>
+      </div>
+    </div>
+  )
+}
+
+export default QueryPlan
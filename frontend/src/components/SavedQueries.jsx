@@ -1,5 +1,5 @@
 import { useState, useEffect, useCallback } from 'react'
-import { GetSavedQueries, SaveQuery, DeleteSavedQuery } from '../../wailsjs/go/main/App'
+import { GetSavedQueries, SaveQuery, DeleteSavedQuery, ImportQueryPack, ExportQueryPack } from '../../wailsjs/go/main/App'
 
 function SavedQueries({ visible, onLoad, currentFilters, dbInfo }) {
   const [queries, setQueries] = useState([])
@@ -33,7 +33,7 @@ function SavedQueries({ visible, onLoad, currentFilters, dbInfo }) {
     }
 
     // Check for duplicate
-    if (queries.some(q => q.Name === name)) {
+    if (queries.some(q => q.name === name)) {
       setError('A query with this name already exists')
       return
     }
@@ -57,15 +57,34 @@ function SavedQueries({ visible, onLoad, currentFilters, dbInfo }) {
   }, [saveName, queries, currentFilters, loadQueries])
 
   const handleLoad = useCallback((queryStr) => {
+    let filterState
     try {
-      const filterState = JSON.parse(queryStr)
-      onLoad(filterState)
-    } catch (err) {
-      console.error('Error parsing saved query:', err)
-      setError('Could not parse saved query')
+      filterState = JSON.parse(queryStr)
+    } catch {
+      // Query packs store plain WHERE clauses
+      filterState = { advanced: true, whereClause: queryStr }
     }
+    onLoad(filterState)
   }, [onLoad])
 
+  const handleImport = useCallback(async () => {
+    try {
+      await ImportQueryPack()
+      setError('')
+      await loadQueries()
+    } catch (err) {
+      setError('Error importing: ' + err)
+    }
+  }, [loadQueries])
+
+  const handleExport = useCallback(async () => {
+    try {
+      await ExportQueryPack()
+    } catch (err) {
+      setError('Error exporting: ' + err)
+    }
+  }, [])
+
   const handleDelete = useCallback(async (name) => {
     try {
       await DeleteSavedQuery(name)
@@ -87,6 +106,8 @@ function SavedQueries({ visible, onLoad, currentFilters, dbInfo }) {
     <div className="saved-queries-panel">
       <div className="sq-header">
         <span className="sq-title">Saved Queries</span>
+        <button onClick={handleImport} title="Import a query pack (JSON or CSV)">Import</button>
+        <button onClick={handleExport} title="Export saved queries as a query pack">Export</button>
       </div>
 
       {/* Save current filters */}
@@ -114,7 +135,7 @@ function SavedQueries({ visible, onLoad, currentFilters, dbInfo }) {
         {queries.map(q => {
           let summary = ''
           try {
-            const parsed = JSON.parse(q.Query)
+            const parsed = JSON.parse(q.query)
             if (parsed.advanced && parsed.whereClause) {
               const clause = parsed.whereClause
               summary = 'SQL: ' + (clause.length > 40 ? clause.substring(0, 40) + '...' : clause)
@@ -132,16 +153,19 @@ function SavedQueries({ visible, onLoad, currentFilters, dbInfo }) {
               summary = parts.join(', ') || 'no filters'
             }
           } catch {
-            summary = 'raw query'
+            summary = q.description || 'SQL: ' + (q.query.length > 40 ? q.query.substring(0, 40) + '...' : q.query)
+          }
+          if (q.category) {
+            summary = `[${q.category}] ${summary}`
           }
 
           return (
-            <div key={q.Name} className="sq-item">
-              <div className="sq-item-info" onClick={() => handleLoad(q.Query)}>
-                <span className="sq-item-name">{q.Name}</span>
+            <div key={q.name} className="sq-item">
+              <div className="sq-item-info" onClick={() => handleLoad(q.query)}>
+                <span className="sq-item-name" title={q.description || undefined}>{q.name}</span>
                 <span className="sq-item-summary">{summary}</span>
               </div>
-              <button className="sq-item-delete" onClick={() => handleDelete(q.Name)}>x</button>
+              <button className="sq-item-delete" onClick={() => handleDelete(q.name)}>x</button>
             </div>
           )
         })}
@@ -0,0 +1,118 @@
+import { useState, useCallback } from 'react'
+import { ChooseSigmaDirectory, RunSigmaRules } from '../../wailsjs/go/main/App'
+
+// Sigma levels, most severe first, for sorting the results
+const levelOrder = { critical: 0, high: 1, medium: 2, low: 3, informational: 4 }
+
+// Runs a directory of Sigma rules over the database and lists the matches
+// per rule.
+function SigmaRules({ visible, onClose, onShowMatches, onChanged }) {
+  const [dir, setDir] = useState('')
+  const [tag, setTag] = useState(false)
+  const [results, setResults] = useState(null)
+  const [error, setError] = useState('')
+  const [loading, setLoading] = useState(false)
+
+  const handleChoose = useCallback(async () => {
+    setError('')
+    try {
+      const chosen = await ChooseSigmaDirectory()
+      if (chosen) {
+        setDir(chosen)
+        setResults(null)
+      }
+    } catch (err) {
+      setError(String(err))
+    }
+  }, [])
+
+  const handleRun = useCallback(async () => {
+    setError('')
+    setLoading(true)
+    try {
+      const result = await RunSigmaRules({ dir, tag })
+      result.rules.sort((a, b) =>
+        (b.hits - a.hits) || ((levelOrder[a.level] ?? 5) - (levelOrder[b.level] ?? 5)))
+      setResults(result)
+      if (onChanged && tag) onChanged()
+    } catch (err) {
+      setError(String(err))
+    } finally {
+      setLoading(false)
+    }
+  }, [dir, tag, onChanged])
+
+  if (!visible) return null
+
+  const unsupported = results ? results.rules.filter(r => r.error).length : 0
+
+  return (
+    <div className="modal-overlay" onClick={onClose}>
+      <div className="ioc-dialog" onClick={(e) => e.stopPropagation()}>
+        <div className="logging-header">
+          <h2>Sigma Rules</h2>
+          <button className="modal-close" onClick={onClose}>x</button>
+        </div>
+        <div className="ioc-content">
+          <div className="ioc-options">
+            <button onClick={handleChoose}>Choose Folder...</button>
+            <span className="sigma-dir" title={dir}>{dir || 'No folder chosen'}</span>
+          </div>
+          <div className="ioc-options">
+            <label title="Tag each match with the rule's title and sigma:level">
+              <input type="checkbox" checked={tag} onChange={(e) => setTag(e.target.checked)} />
+              Tag matches
+            </label>
+            <button className="ioc-search-btn" onClick={handleRun} disabled={loading || !dir}>
+              {loading ? 'Running...' : 'Run Rules'}
+            </button>
+          </div>
+
+          {error && <div className="logging-error">{error}</div>}
+
+          {results && (
+            <>
+              <div className="ioc-summary">
+                {results.matched} of {results.rules.length} rules matched {results.totalHits.toLocaleString()} events
+                {unsupported > 0 && `, ${unsupported} not run`}
+                {results.failed?.length > 0 && `, ${results.failed.length} files unreadable`}
+              </div>
+              {(results.tagErrors || []).map(e => <div key={e} className="logging-error">{e}</div>)}
+              <div className="ioc-results">
+                <table>
+                  <thead>
+                    <tr><th>Rule</th><th>Level</th><th>Hits</th></tr>
+                  </thead>
+                  <tbody>
+                    {results.rules.map(r => (
+                      <tr key={r.path} className={r.hits > 0 ? 'ioc-hit' : ''}>
+                        <td className="ioc-value" title={r.error ? `${r.path}\n${r.error}` : r.path}>
+                          {r.hits > 0 ? (
+                            <button onClick={() => onShowMatches(r)} title="Show the matching events">{r.title}</button>
+                          ) : r.title}
+                        </td>
+                        <td className={`sigma-level sigma-level-${r.level}`}>{r.level}</td>
+                        <td className="ioc-count">
+                          {r.error ? <span className="ioc-error" title={r.error}>not run</span> : r.hits.toLocaleString()}
+                        </td>
+                      </tr>
+                    ))}
+                    {(results.failed || []).map(f => (
+                      <tr key={f.path}>
+                        <td className="ioc-value" title={`${f.path}\n${f.error}`}>{f.path}</td>
+                        <td />
+                        <td className="ioc-count"><span className="ioc-error" title={f.error}>unreadable</span></td>
+                      </tr>
+                    ))}
+                  </tbody>
+                </table>
+              </div>
+            </>
+          )}
+        </div>
+      </div>
+    </div>
+  )
+}
+
+export default SigmaRules
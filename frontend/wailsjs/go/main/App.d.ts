@@ -2,63 +2,166 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 import {database} from '../models';
+import {ioc} from '../models';
+
+export function AddEvidenceItem(arg1:database.EvidenceItem):Promise<number>;
 
 export function AddExaminerNote(arg1:string,arg2:string):Promise<number>;
 
 export function AdvancedSearch(arg1:string,arg2:number,arg3:number):Promise<main.QueryResponse>;
 
+export function ApplyColorRules():Promise<string>;
+
+export function BatchSearch(arg1:main.BatchSearchRequest):Promise<main.BatchSearchResponse>;
+
 export function BulkAddTag(arg1:Array<number>,arg2:string):Promise<void>;
 
+export function BulkRemoveTag(arg1:Array<number>,arg2:string):Promise<void>;
+
 export function BulkSetBookmark(arg1:Array<number>,arg2:number):Promise<void>;
 
 export function BulkUpdateColor(arg1:Array<number>,arg2:string):Promise<void>;
 
+export function ChooseSigmaDirectory():Promise<string>;
+
 export function CloseDatabase():Promise<void>;
 
+export function ConnectMySQL(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<main.DBInfo>;
+
 export function ConnectPostgres(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<main.DBInfo>;
 
+export function CreateIndex(arg1:Array<string>):Promise<string>;
+
+export function CreateMySQLDatabase(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<main.DBInfo>;
+
 export function CreatePostgresDatabase(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<main.DBInfo>;
 
+export function DeleteColorRule(arg1:number):Promise<void>;
+
+export function DeleteEvidenceItem(arg1:number):Promise<void>;
+
 export function DeleteExaminerNote(arg1:number):Promise<void>;
 
 export function DeleteSavedQuery(arg1:string):Promise<void>;
 
+export function DeleteTag(arg1:string):Promise<void>;
+
 export function DisableLogging():Promise<void>;
 
 export function EnableLogging():Promise<string>;
 
+export function ExplainQuery(arg1:main.QueryRequest):Promise<main.QueryPlanResponse>;
+
+export function ExportAuditLog():Promise<string>;
+
 export function ExportCSV(arg1:main.QueryRequest):Promise<string>;
 
+export function ExportColorRules():Promise<string>;
+
+export function ExportQueryPack():Promise<string>;
+
+export function GetAuditLog(arg1:database.AuditFilter):Promise<Array<database.AuditEntry>>;
+
+export function GetCaseInfo():Promise<database.CaseInfo>;
+
+export function GetColorRules():Promise<Array<database.ColorRule>>;
+
 export function GetDistinctValues(arg1:string):Promise<Record<string, number>>;
 
+export function GetEventContext(arg1:main.ContextRequest):Promise<main.ContextResponse>;
+
+export function GetEvidenceItems():Promise<Array<database.EvidenceItem>>;
+
+export function GetExaminer():Promise<string>;
+
+export function GetImportBatches():Promise<Array<database.ImportBatch>>;
+
+export function GetIndexes():Promise<Array<database.IndexInfo>>;
+
 export function GetLoggingStatus():Promise<main.LoggingStatus>;
 
 export function GetMinMaxDate():Promise<Array<string>>;
 
+export function GetProvenance():Promise<Array<database.ProvenanceEntry>>;
+
+export function GetRelatedEvents(arg1:number):Promise<Array<main.RelatedPivot>>;
+
 export function GetSavedQueries():Promise<Array<database.SavedQuery>>;
 
+export function GetSavedQueryParameters(arg1:string):Promise<Array<database.QueryParameter>>;
+
+export function GetSigmaMatches(arg1:string,arg2:number,arg3:number):Promise<main.QueryResponse>;
+
+export function GetTagDetails():Promise<Array<database.Tag>>;
+
 export function GetTags():Promise<Array<string>>;
 
 export function GetTimelineHistogram(arg1:main.QueryRequest):Promise<Array<main.TimelineBucket>>;
 
+export function GetUndoState():Promise<database.UndoState>;
+
 export function GetVersion():Promise<string>;
 
 export function ImportCSV():Promise<main.DBInfo>;
 
+export function ImportColorRules():Promise<string>;
+
+export function ImportQueryPack():Promise<string>;
+
+export function LinkImportBatch(arg1:number,arg2:number):Promise<void>;
+
+export function LoadIndicators():Promise<Array<ioc.Indicator>>;
+
+export function LuceneSearch(arg1:string,arg2:number,arg3:number):Promise<main.QueryResponse>;
+
+export function MergeDatabase(arg1:boolean):Promise<string>;
+
+export function MergePostgresDatabase(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:boolean):Promise<string>;
+
+export function MergeTags(arg1:Array<number>,arg2:Array<string>,arg3:string):Promise<void>;
+
 export function OpenDatabase():Promise<main.DBInfo>;
 
+export function ParseIndicators(arg1:string):Promise<Array<ioc.Indicator>>;
+
+export function Pivot(arg1:main.PivotRequest):Promise<main.PivotResponse>;
+
+export function PreviewColorRule(arg1:database.ColorRule):Promise<number>;
+
 export function PushToPostgres(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<string>;
 
 export function QueryEvents(arg1:main.QueryRequest):Promise<main.QueryResponse>;
 
+export function Redo():Promise<string>;
+
+export function RenameTag(arg1:Array<number>,arg2:string,arg3:string):Promise<void>;
+
+export function RunSavedQuery(arg1:string,arg2:Record<string, string>,arg3:number,arg4:number):Promise<main.QueryResponse>;
+
+export function RunSigmaRules(arg1:main.SigmaRequest):Promise<main.SigmaResponse>;
+
+export function SaveCaseInfo(arg1:database.CaseInfo):Promise<void>;
+
+export function SaveColorRule(arg1:database.ColorRule):Promise<database.ColorRule>;
+
 export function SaveQuery(arg1:string,arg2:string):Promise<void>;
 
+export function SaveTag(arg1:database.Tag):Promise<void>;
+
+export function SetExaminer(arg1:string):Promise<void>;
+
 export function SetLoggingPersist(arg1:boolean):Promise<void>;
 
 export function ToggleBookmark(arg1:number):Promise<number>;
 
 export function ToggleExaminerNoteBookmark(arg1:number):Promise<number>;
 
+export function Undo():Promise<string>;
+
 export function UpdateEventFields(arg1:number,arg2:Record<string, any>):Promise<void>;
 
+export function UpdateEvidenceItem(arg1:database.EvidenceItem):Promise<void>;
+
 export function UpdateExaminerNoteColor(arg1:number,arg2:string):Promise<void>;
+
+export function UpdateSavedQuery(arg1:database.SavedQuery):Promise<void>;
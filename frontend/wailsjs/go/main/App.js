@@ -2,6 +2,10 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddEvidenceItem(arg1) {
+  return window['go']['main']['App']['AddEvidenceItem'](arg1);
+}
+
 export function AddExaminerNote(arg1, arg2) {
   return window['go']['main']['App']['AddExaminerNote'](arg1, arg2);
 }
@@ -10,10 +14,22 @@ export function AdvancedSearch(arg1, arg2, arg3) {
   return window['go']['main']['App']['AdvancedSearch'](arg1, arg2, arg3);
 }
 
+export function ApplyColorRules() {
+  return window['go']['main']['App']['ApplyColorRules']();
+}
+
+export function BatchSearch(arg1) {
+  return window['go']['main']['App']['BatchSearch'](arg1);
+}
+
 export function BulkAddTag(arg1, arg2) {
   return window['go']['main']['App']['BulkAddTag'](arg1, arg2);
 }
 
+export function BulkRemoveTag(arg1, arg2) {
+  return window['go']['main']['App']['BulkRemoveTag'](arg1, arg2);
+}
+
 export function BulkSetBookmark(arg1, arg2) {
   return window['go']['main']['App']['BulkSetBookmark'](arg1, arg2);
 }
@@ -22,18 +38,42 @@ export function BulkUpdateColor(arg1, arg2) {
   return window['go']['main']['App']['BulkUpdateColor'](arg1, arg2);
 }
 
+export function ChooseSigmaDirectory() {
+  return window['go']['main']['App']['ChooseSigmaDirectory']();
+}
+
 export function CloseDatabase() {
   return window['go']['main']['App']['CloseDatabase']();
 }
 
+export function ConnectMySQL(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['ConnectMySQL'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
 export function ConnectPostgres(arg1, arg2, arg3, arg4, arg5, arg6) {
   return window['go']['main']['App']['ConnectPostgres'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
 
+export function CreateIndex(arg1) {
+  return window['go']['main']['App']['CreateIndex'](arg1);
+}
+
+export function CreateMySQLDatabase(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['CreateMySQLDatabase'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
 export function CreatePostgresDatabase(arg1, arg2, arg3, arg4, arg5, arg6) {
   return window['go']['main']['App']['CreatePostgresDatabase'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
 
+export function DeleteColorRule(arg1) {
+  return window['go']['main']['App']['DeleteColorRule'](arg1);
+}
+
+export function DeleteEvidenceItem(arg1) {
+  return window['go']['main']['App']['DeleteEvidenceItem'](arg1);
+}
+
 export function DeleteExaminerNote(arg1) {
   return window['go']['main']['App']['DeleteExaminerNote'](arg1);
 }
@@ -42,6 +82,10 @@ export function DeleteSavedQuery(arg1) {
   return window['go']['main']['App']['DeleteSavedQuery'](arg1);
 }
 
+export function DeleteTag(arg1) {
+  return window['go']['main']['App']['DeleteTag'](arg1);
+}
+
 export function DisableLogging() {
   return window['go']['main']['App']['DisableLogging']();
 }
@@ -50,14 +94,62 @@ export function EnableLogging() {
   return window['go']['main']['App']['EnableLogging']();
 }
 
+export function ExplainQuery(arg1) {
+  return window['go']['main']['App']['ExplainQuery'](arg1);
+}
+
+export function ExportAuditLog() {
+  return window['go']['main']['App']['ExportAuditLog']();
+}
+
 export function ExportCSV(arg1) {
   return window['go']['main']['App']['ExportCSV'](arg1);
 }
 
+export function ExportColorRules() {
+  return window['go']['main']['App']['ExportColorRules']();
+}
+
+export function ExportQueryPack() {
+  return window['go']['main']['App']['ExportQueryPack']();
+}
+
+export function GetAuditLog(arg1) {
+  return window['go']['main']['App']['GetAuditLog'](arg1);
+}
+
+export function GetCaseInfo() {
+  return window['go']['main']['App']['GetCaseInfo']();
+}
+
+export function GetColorRules() {
+  return window['go']['main']['App']['GetColorRules']();
+}
+
 export function GetDistinctValues(arg1) {
   return window['go']['main']['App']['GetDistinctValues'](arg1);
 }
 
+export function GetEventContext(arg1) {
+  return window['go']['main']['App']['GetEventContext'](arg1);
+}
+
+export function GetEvidenceItems() {
+  return window['go']['main']['App']['GetEvidenceItems']();
+}
+
+export function GetExaminer() {
+  return window['go']['main']['App']['GetExaminer']();
+}
+
+export function GetImportBatches() {
+  return window['go']['main']['App']['GetImportBatches']();
+}
+
+export function GetIndexes() {
+  return window['go']['main']['App']['GetIndexes']();
+}
+
 export function GetLoggingStatus() {
   return window['go']['main']['App']['GetLoggingStatus']();
 }
@@ -66,10 +158,30 @@ export function GetMinMaxDate() {
   return window['go']['main']['App']['GetMinMaxDate']();
 }
 
+export function GetProvenance() {
+  return window['go']['main']['App']['GetProvenance']();
+}
+
+export function GetRelatedEvents(arg1) {
+  return window['go']['main']['App']['GetRelatedEvents'](arg1);
+}
+
 export function GetSavedQueries() {
   return window['go']['main']['App']['GetSavedQueries']();
 }
 
+export function GetSavedQueryParameters(arg1) {
+  return window['go']['main']['App']['GetSavedQueryParameters'](arg1);
+}
+
+export function GetSigmaMatches(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetSigmaMatches'](arg1, arg2, arg3);
+}
+
+export function GetTagDetails() {
+  return window['go']['main']['App']['GetTagDetails']();
+}
+
 export function GetTags() {
   return window['go']['main']['App']['GetTags']();
 }
@@ -78,6 +190,10 @@ export function GetTimelineHistogram(arg1) {
   return window['go']['main']['App']['GetTimelineHistogram'](arg1);
 }
 
+export function GetUndoState() {
+  return window['go']['main']['App']['GetUndoState']();
+}
+
 export function GetVersion() {
   return window['go']['main']['App']['GetVersion']();
 }
@@ -86,10 +202,54 @@ export function ImportCSV() {
   return window['go']['main']['App']['ImportCSV']();
 }
 
+export function ImportColorRules() {
+  return window['go']['main']['App']['ImportColorRules']();
+}
+
+export function ImportQueryPack() {
+  return window['go']['main']['App']['ImportQueryPack']();
+}
+
+export function LinkImportBatch(arg1, arg2) {
+  return window['go']['main']['App']['LinkImportBatch'](arg1, arg2);
+}
+
+export function LoadIndicators() {
+  return window['go']['main']['App']['LoadIndicators']();
+}
+
+export function LuceneSearch(arg1, arg2, arg3) {
+  return window['go']['main']['App']['LuceneSearch'](arg1, arg2, arg3);
+}
+
+export function MergeDatabase(arg1) {
+  return window['go']['main']['App']['MergeDatabase'](arg1);
+}
+
+export function MergePostgresDatabase(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
+  return window['go']['main']['App']['MergePostgresDatabase'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
+}
+
+export function MergeTags(arg1, arg2, arg3) {
+  return window['go']['main']['App']['MergeTags'](arg1, arg2, arg3);
+}
+
 export function OpenDatabase() {
   return window['go']['main']['App']['OpenDatabase']();
 }
 
+export function ParseIndicators(arg1) {
+  return window['go']['main']['App']['ParseIndicators'](arg1);
+}
+
+export function Pivot(arg1) {
+  return window['go']['main']['App']['Pivot'](arg1);
+}
+
+export function PreviewColorRule(arg1) {
+  return window['go']['main']['App']['PreviewColorRule'](arg1);
+}
+
 export function PushToPostgres(arg1, arg2, arg3, arg4, arg5, arg6) {
   return window['go']['main']['App']['PushToPostgres'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
@@ -98,10 +258,42 @@ export function QueryEvents(arg1) {
   return window['go']['main']['App']['QueryEvents'](arg1);
 }
 
+export function Redo() {
+  return window['go']['main']['App']['Redo']();
+}
+
+export function RenameTag(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RenameTag'](arg1, arg2, arg3);
+}
+
+export function RunSavedQuery(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['RunSavedQuery'](arg1, arg2, arg3, arg4);
+}
+
+export function RunSigmaRules(arg1) {
+  return window['go']['main']['App']['RunSigmaRules'](arg1);
+}
+
+export function SaveCaseInfo(arg1) {
+  return window['go']['main']['App']['SaveCaseInfo'](arg1);
+}
+
+export function SaveColorRule(arg1) {
+  return window['go']['main']['App']['SaveColorRule'](arg1);
+}
+
 export function SaveQuery(arg1, arg2) {
   return window['go']['main']['App']['SaveQuery'](arg1, arg2);
 }
 
+export function SaveTag(arg1) {
+  return window['go']['main']['App']['SaveTag'](arg1);
+}
+
+export function SetExaminer(arg1) {
+  return window['go']['main']['App']['SetExaminer'](arg1);
+}
+
 export function SetLoggingPersist(arg1) {
   return window['go']['main']['App']['SetLoggingPersist'](arg1);
 }
@@ -114,10 +306,22 @@ export function ToggleExaminerNoteBookmark(arg1) {
   return window['go']['main']['App']['ToggleExaminerNoteBookmark'](arg1);
 }
 
+export function Undo() {
+  return window['go']['main']['App']['Undo']();
+}
+
 export function UpdateEventFields(arg1, arg2) {
   return window['go']['main']['App']['UpdateEventFields'](arg1, arg2);
 }
 
+export function UpdateEvidenceItem(arg1) {
+  return window['go']['main']['App']['UpdateEvidenceItem'](arg1);
+}
+
 export function UpdateExaminerNoteColor(arg1, arg2) {
   return window['go']['main']['App']['UpdateExaminerNoteColor'](arg1, arg2);
 }
+
+export function UpdateSavedQuery(arg1) {
+  return window['go']['main']['App']['UpdateSavedQuery'](arg1);
+}
@@ -1,24 +1,672 @@
 export namespace database {
 	
+	export class AuditEntry {
+	    id: number;
+	    createdAt: string;
+	    examiner: string;
+	    operation: string;
+	    target: string;
+	    ids: number[];
+	    oldValues: Record<number, any>;
+	    newValues: Record<string, any>;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.createdAt = source["createdAt"];
+	        this.examiner = source["examiner"];
+	        this.operation = source["operation"];
+	        this.target = source["target"];
+	        this.ids = source["ids"];
+	        this.oldValues = source["oldValues"];
+	        this.newValues = source["newValues"];
+	    }
+	}
+	export class AuditFilter {
+	    examiner: string;
+	    operation: string;
+	    target: string;
+	    eventId: number;
+	    since: string;
+	    until: string;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.examiner = source["examiner"];
+	        this.operation = source["operation"];
+	        this.target = source["target"];
+	        this.eventId = source["eventId"];
+	        this.since = source["since"];
+	        this.until = source["until"];
+	        this.limit = source["limit"];
+	    }
+	}
+	export class CaseInfo {
+	    caseNumber: string;
+	    title: string;
+	    examiners: string[];
+	    description: string;
+	    createdAt: string;
+	    closedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CaseInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.caseNumber = source["caseNumber"];
+	        this.title = source["title"];
+	        this.examiners = source["examiners"];
+	        this.description = source["description"];
+	        this.createdAt = source["createdAt"];
+	        this.closedAt = source["closedAt"];
+	    }
+	}
+	export class RuleFilter {
+	    field: string;
+	    operator: string;
+	    value: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.operator = source["operator"];
+	        this.value = source["value"];
+	    }
+	}
+	export class ColorRule {
+	    id: number;
+	    name: string;
+	    filters: RuleFilter[];
+	    logic: string;
+	    savedQuery: string;
+	    color: string;
+	    priority: number;
+	    enabled: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ColorRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.filters = this.convertValues(source["filters"], RuleFilter);
+	        this.logic = source["logic"];
+	        this.savedQuery = source["savedQuery"];
+	        this.color = source["color"];
+	        this.priority = source["priority"];
+	        this.enabled = source["enabled"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EvidenceItem {
+	    id: number;
+	    name: string;
+	    kind: string;
+	    description: string;
+	    path: string;
+	    md5: string;
+	    sha1: string;
+	    sha256: string;
+	    addedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EvidenceItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.kind = source["kind"];
+	        this.description = source["description"];
+	        this.path = source["path"];
+	        this.md5 = source["md5"];
+	        this.sha1 = source["sha1"];
+	        this.sha256 = source["sha256"];
+	        this.addedAt = source["addedAt"];
+	    }
+	}
+	export class ImportBatch {
+	    id: number;
+	    evidenceId: number;
+	    sourcePath: string;
+	    format: string;
+	    importedAt: string;
+	    eventCount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportBatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.evidenceId = source["evidenceId"];
+	        this.sourcePath = source["sourcePath"];
+	        this.format = source["format"];
+	        this.importedAt = source["importedAt"];
+	        this.eventCount = source["eventCount"];
+	    }
+	}
+	export class IndexInfo {
+	    name: string;
+	    columns: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new IndexInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.columns = source["columns"];
+	    }
+	}
+	export class IndexRecommendation {
+	    name: string;
+	    columns: string[];
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new IndexRecommendation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.columns = source["columns"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class ProvenanceEntry {
+	    id: number;
+	    action: string;
+	    detail: string;
+	    createdAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProvenanceEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.action = source["action"];
+	        this.detail = source["detail"];
+	        this.createdAt = source["createdAt"];
+	    }
+	}
+	export class QueryParameter {
+	    name: string;
+	    type: string;
+	    default: string;
+	    label: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new QueryParameter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.default = source["default"];
+	        this.label = source["label"];
+	    }
+	}
+	export class QueryPlan {
+	    lines: string[];
+	    fullScans: string[];
+	    indexes: string[];
+	    durationMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new QueryPlan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.lines = source["lines"];
+	        this.fullScans = source["fullScans"];
+	        this.indexes = source["indexes"];
+	        this.durationMs = source["durationMs"];
+	    }
+	}
+	
 	export class SavedQuery {
-	    Name: string;
-	    Query: string;
+	    name: string;
+	    query: string;
+	    description: string;
+	    category: string;
+	    author: string;
+	    os: string;
+	    eventIds: string;
+	    parameters: QueryParameter[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SavedQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.query = source["query"];
+	        this.description = source["description"];
+	        this.category = source["category"];
+	        this.author = source["author"];
+	        this.os = source["os"];
+	        this.eventIds = source["eventIds"];
+	        this.parameters = this.convertValues(source["parameters"], QueryParameter);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SearchMatch {
+	    id: number;
+	    snippet: string;
+	    rank: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchMatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.snippet = source["snippet"];
+	        this.rank = source["rank"];
+	    }
+	}
+	export class Tag {
+	    id: number;
+	    name: string;
+	    color: string;
+	    description: string;
+	    eventCount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Tag(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.description = source["description"];
+	        this.eventCount = source["eventCount"];
+	    }
+	}
+	export class UndoState {
+	    undoCount: number;
+	    undoOperation: string;
+	    redoCount: number;
+	    redoOperation: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UndoState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.undoCount = source["undoCount"];
+	        this.undoOperation = source["undoOperation"];
+	        this.redoCount = source["redoCount"];
+	        this.redoOperation = source["redoOperation"];
+	    }
+	}
+
+}
+
+export namespace ioc {
+	
+	export class Indicator {
+	    value: string;
+	    name: string;
+	    type: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Indicator(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.value = source["value"];
+	        this.name = source["name"];
+	        this.type = source["type"];
+	    }
+	}
+
+}
+
+export namespace main {
+	
+	export class BatchSearchRequest {
+	    indicators: ioc.Indicator[];
+	    tag: boolean;
+	    color: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchSearchRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.indicators = this.convertValues(source["indicators"], ioc.Indicator);
+	        this.tag = source["tag"];
+	        this.color = source["color"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class IndicatorHits {
+	    value: string;
+	    name: string;
+	    type: string;
+	    hits: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new IndicatorHits(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.value = source["value"];
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.hits = source["hits"];
+	        this.error = source["error"];
+	    }
+	}
+	export class BatchSearchResponse {
+	    results: IndicatorHits[];
+	    matched: number;
+	    totalHits: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchSearchResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.results = this.convertValues(source["results"], IndicatorHits);
+	        this.matched = source["matched"];
+	        this.totalHits = source["totalHits"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SortField {
+	    field: string;
+	    direction: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SortField(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.direction = source["direction"];
+	    }
+	}
+	export class FilterGroup {
+	    logic: string;
+	    filters: FilterItem[];
+	    groups?: FilterGroup[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FilterGroup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.logic = source["logic"];
+	        this.filters = this.convertValues(source["filters"], FilterItem);
+	        this.groups = this.convertValues(source["groups"], FilterGroup);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FilterItem {
+	    field: string;
+	    operator: string;
+	    value: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FilterItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.operator = source["operator"];
+	        this.value = source["value"];
+	    }
+	}
+	export class QueryRequest {
+	    filters: FilterItem[];
+	    logic: string;
+	    orderBy: string;
+	    page: number;
+	    pageSize: number;
+	    searchText: string;
+	    bookmarkOnly: boolean;
+	    groups?: FilterGroup[];
+	    sort?: SortField[];
+	    direction?: string;
+	    cursor?: string;
+	    seekDatetime?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new SavedQuery(source);
+	        return new QueryRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filters = this.convertValues(source["filters"], FilterItem);
+	        this.logic = source["logic"];
+	        this.orderBy = source["orderBy"];
+	        this.page = source["page"];
+	        this.pageSize = source["pageSize"];
+	        this.searchText = source["searchText"];
+	        this.bookmarkOnly = source["bookmarkOnly"];
+	        this.groups = this.convertValues(source["groups"], FilterGroup);
+	        this.sort = this.convertValues(source["sort"], SortField);
+	        this.direction = source["direction"];
+	        this.cursor = source["cursor"];
+	        this.seekDatetime = source["seekDatetime"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ContextRequest {
+	    id: number;
+	    window?: string;
+	    before?: number;
+	    after?: number;
+	    filters?: QueryRequest;
+	
+	    static createFrom(source: any = {}) {
+	        return new ContextRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.Name = source["Name"];
-	        this.Query = source["Query"];
+	        this.id = source["id"];
+	        this.window = source["window"];
+	        this.before = source["before"];
+	        this.after = source["after"];
+	        this.filters = this.convertValues(source["filters"], QueryRequest);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-
-}
-
-export namespace main {
+	export class ContextResponse {
+	    events: model.Event[];
+	    anchorIndex: number;
+	    truncated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ContextResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.events = this.convertValues(source["events"], model.Event);
+	        this.anchorIndex = source["anchorIndex"];
+	        this.truncated = source["truncated"];
+	    }
 	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class DBInfo {
 	    path: string;
 	    driver: string;
@@ -39,22 +687,9 @@ export namespace main {
 	        this.maxDate = source["maxDate"];
 	    }
 	}
-	export class FilterItem {
-	    field: string;
-	    operator: string;
-	    value: string;
 	
-	    static createFrom(source: any = {}) {
-	        return new FilterItem(source);
-	    }
 	
-	    constructor(source: any = {}) {
-	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.field = source["field"];
-	        this.operator = source["operator"];
-	        this.value = source["value"];
-	    }
-	}
+	
 	export class LoggingStatus {
 	    enabled: boolean;
 	    filePath: string;
@@ -71,28 +706,96 @@ export namespace main {
 	        this.persist = source["persist"];
 	    }
 	}
-	export class QueryRequest {
-	    filters: FilterItem[];
-	    logic: string;
-	    orderBy: string;
+	export class PivotRequest {
+	    id: number;
+	    field: string;
 	    page: number;
 	    pageSize: number;
-	    searchText: string;
-	    bookmarkOnly: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new QueryRequest(source);
+	        return new PivotRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.filters = this.convertValues(source["filters"], FilterItem);
-	        this.logic = source["logic"];
-	        this.orderBy = source["orderBy"];
+	        this.id = source["id"];
+	        this.field = source["field"];
 	        this.page = source["page"];
 	        this.pageSize = source["pageSize"];
-	        this.searchText = source["searchText"];
-	        this.bookmarkOnly = source["bookmarkOnly"];
+	    }
+	}
+	export class SourceTypeCount {
+	    sourceType: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SourceTypeCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sourceType = source["sourceType"];
+	        this.count = source["count"];
+	    }
+	}
+	export class PivotResponse {
+	    field: string;
+	    value: string;
+	    events: model.Event[];
+	    totalCount: number;
+	    page: number;
+	    pageSize: number;
+	    sourceTypes: SourceTypeCount[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PivotResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.value = source["value"];
+	        this.events = this.convertValues(source["events"], model.Event);
+	        this.totalCount = source["totalCount"];
+	        this.page = source["page"];
+	        this.pageSize = source["pageSize"];
+	        this.sourceTypes = this.convertValues(source["sourceTypes"], SourceTypeCount);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class QueryPlanResponse {
+	    sql: string;
+	    plan?: database.QueryPlan;
+	    fields: string[];
+	    recommendations: database.IndexRecommendation[];
+	
+	    static createFrom(source: any = {}) {
+	        return new QueryPlanResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sql = source["sql"];
+	        this.plan = this.convertValues(source["plan"], database.QueryPlan);
+	        this.fields = source["fields"];
+	        this.recommendations = this.convertValues(source["recommendations"], database.IndexRecommendation);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -113,11 +816,15 @@ export namespace main {
 		    return a;
 		}
 	}
+	
 	export class QueryResponse {
 	    events: model.Event[];
 	    totalCount: number;
 	    page: number;
 	    pageSize: number;
+	    matches?: database.SearchMatch[];
+	    nextCursor?: string;
+	    prevCursor?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new QueryResponse(source);
@@ -129,6 +836,99 @@ export namespace main {
 	        this.totalCount = source["totalCount"];
 	        this.page = source["page"];
 	        this.pageSize = source["pageSize"];
+	        this.matches = this.convertValues(source["matches"], database.SearchMatch);
+	        this.nextCursor = source["nextCursor"];
+	        this.prevCursor = source["prevCursor"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RelatedPivot {
+	    field: string;
+	    value: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RelatedPivot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.value = source["value"];
+	        this.count = source["count"];
+	    }
+	}
+	export class SigmaRequest {
+	    dir: string;
+	    tag: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SigmaRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dir = source["dir"];
+	        this.tag = source["tag"];
+	    }
+	}
+	export class SigmaRuleHits {
+	    title: string;
+	    id: string;
+	    level: string;
+	    path: string;
+	    hits: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SigmaRuleHits(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.id = source["id"];
+	        this.level = source["level"];
+	        this.path = source["path"];
+	        this.hits = source["hits"];
+	        this.error = source["error"];
+	    }
+	}
+	export class SigmaResponse {
+	    rules: SigmaRuleHits[];
+	    failed: sigma.LoadError[];
+	    matched: number;
+	    totalHits: number;
+	    tagErrors?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SigmaResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rules = this.convertValues(source["rules"], SigmaRuleHits);
+	        this.failed = this.convertValues(source["failed"], sigma.LoadError);
+	        this.matched = source["matched"];
+	        this.totalHits = source["totalHits"];
+	        this.tagErrors = source["tagErrors"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -149,6 +949,9 @@ export namespace main {
 		    return a;
 		}
 	}
+	
+	
+	
 	export class TimelineBucket {
 	    timestamp: string;
 	    count: number;
@@ -200,6 +1003,7 @@ export namespace model {
 	    user_sid: string;
 	    computer_name: string;
 	    bookmark: number;
+	    batch_id: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new Event(source);
@@ -238,6 +1042,26 @@ export namespace model {
 	        this.user_sid = source["user_sid"];
 	        this.computer_name = source["computer_name"];
 	        this.bookmark = source["bookmark"];
+	        this.batch_id = source["batch_id"];
+	    }
+	}
+
+}
+
+export namespace sigma {
+	
+	export class LoadError {
+	    path: string;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LoadError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.error = source["error"];
 	    }
 	}
 
@@ -25,6 +25,7 @@ var exportHeader = []string{
 	"user", "host", "desc", "filename", "inode", "notes", "format",
 	"extra", "reportnotes", "inreport", "tag", "color",
 	"offset", "store_number", "store_index", "vss_store_number", "bookmark",
+	"batch_id",
 }
 
 // ReadResult contains the outcome of a CSV import operation.
@@ -164,6 +165,7 @@ func WriteEvents(path string, events []*model.Event) error {
 			fmt.Sprintf("%d", e.StoreIndex),
 			fmt.Sprintf("%d", e.VSSStoreNumber),
 			fmt.Sprintf("%d", e.Bookmark),
+			fmt.Sprintf("%d", e.BatchID),
 		}
 		if err := writer.Write(row); err != nil {
 			return fmt.Errorf("writing row: %w", err)
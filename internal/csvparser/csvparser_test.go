@@ -245,6 +245,7 @@ func TestWriteEvents(t *testing.T) {
 			Format: "mft", Extra: "", ReportNotes: "", InReport: "",
 			Tag: "malware", Color: "RED", Offset: 0,
 			StoreNumber: -1, StoreIndex: -1, VSSStoreNumber: -1,
+			BatchID: 7,
 		},
 	}
 
@@ -272,6 +273,9 @@ func TestWriteEvents(t *testing.T) {
 	if !contains(content, "RED") {
 		t.Error("expected color in export")
 	}
+	if !contains(content, "bookmark,batch_id\n") || !contains(content, ",0,7\n") {
+		t.Error("expected import batch in export")
+	}
 }
 
 func TestRoundTrip(t *testing.T) {
@@ -0,0 +1,377 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Audit operations recorded in audit_log.operation.
+const (
+	AuditUpdateEvent     = "update_event"
+	AuditToggleBookmark  = "toggle_bookmark"
+	AuditSetColor        = "set_color"
+	AuditSetBookmark     = "set_bookmark"
+	AuditAddTag          = "add_tag"
+	AuditRemoveTag       = "remove_tag"
+	AuditRenameTag       = "rename_tag"
+	AuditMergeTags       = "merge_tags"
+	AuditDeleteTag       = "delete_tag"
+	AuditSaveTag         = "save_tag"
+	AuditAddNote         = "add_note"
+	AuditDeleteNote      = "delete_note"
+	AuditApplyColorRules = "apply_color_rules"
+	AuditUndo            = "undo"
+	AuditRedo            = "redo"
+)
+
+// Audit targets recorded in audit_log.target: the kind of row IDs refers to.
+const (
+	AuditTargetEvent        = "event"
+	AuditTargetExaminerNote = "examiner_note"
+	AuditTargetTag          = "tag"
+)
+
+// auditTargets maps the tables analysts modify to their audit target.
+var auditTargets = map[string]string{
+	"log2timeline":   AuditTargetEvent,
+	"examiner_notes": AuditTargetExaminerNote,
+	"tags":           AuditTargetTag,
+}
+
+// examinerNoteFields are the examiner_notes columns recorded when a note is
+// deleted, enough to recreate it.
+var examinerNoteFields = []string{"datetime", "description", "tag", "color", "bookmark"}
+
+// AuditEntry is one row of the append-only audit_log table. OldValues holds
+// the changed columns of each affected row as they were before the change,
+// keyed by row ID; NewValues holds what the operation applied. Examiner
+// notes are identified by their positive internal IDs.
+type AuditEntry struct {
+	ID        int64                            `json:"id"`
+	CreatedAt string                           `json:"createdAt"`
+	Examiner  string                           `json:"examiner"`
+	Operation string                           `json:"operation"`
+	Target    string                           `json:"target"`
+	IDs       []int64                          `json:"ids"`
+	OldValues map[int64]map[string]interface{} `json:"oldValues"`
+	NewValues map[string]interface{}           `json:"newValues"`
+}
+
+// AuditFilter selects audit entries. Zero fields match everything. Since and
+// Until bound created_at inclusively; EventID matches entries whose IDs
+// include it. Limit keeps only the most recent entries.
+type AuditFilter struct {
+	Examiner  string `json:"examiner"`
+	Operation string `json:"operation"`
+	Target    string `json:"target"`
+	EventID   int64  `json:"eventId"`
+	Since     string `json:"since"`
+	Until     string `json:"until"`
+	Limit     int    `json:"limit"`
+}
+
+// auditChange accumulates the audit record for one operation while it runs.
+type auditChange struct {
+	d         Dialect
+	operation string
+	target    string
+	ids       []int64
+	old       map[int64]map[string]interface{}
+	new       map[string]interface{}
+}
+
+// touch records which rows of table the operation affects without reading
+// their current values, for inserts.
+func (a *auditChange) touch(table string, ids []int64) {
+	a.target = auditTargets[table]
+	a.ids = append(a.ids, ids...)
+}
+
+// snapshot records fields of the given rows of table as their old values.
+// It must run before the change is made.
+func (a *auditChange) snapshot(tx schemaExecer, table string, fields []string, ids []int64) error {
+	a.touch(table, ids)
+	if a.old == nil {
+		a.old = make(map[int64]map[string]interface{})
+	}
+	idCol := "id"
+	if table == "log2timeline" {
+		idCol = a.d.IDColumn()
+	}
+	cols := make([]string, len(fields))
+	for i, f := range fields {
+		cols[i] = a.d.QuoteColumn(f)
+	}
+	for _, chunk := range chunkIDs(ids) {
+		rows, err := tx.Query(fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s IN (%s)",
+			idCol, strings.Join(cols, ", "), table, idCol, placeholders(a.d, len(chunk))), idArgs(chunk)...)
+		if err != nil {
+			return fmt.Errorf("reading values for audit: %w", err)
+		}
+		for rows.Next() {
+			var id int64
+			values := make([]interface{}, len(fields))
+			dest := []interface{}{&id}
+			for i := range values {
+				dest = append(dest, &values[i])
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return fmt.Errorf("reading values for audit: %w", err)
+			}
+			row := a.old[id]
+			if row == nil {
+				row = make(map[string]interface{}, len(fields))
+				a.old[id] = row
+			}
+			for i, f := range fields {
+				row[f] = auditValue(values[i])
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldNames returns the keys of fields in sorted order.
+func fieldNames(fields map[string]interface{}) []string {
+	names := make([]string, 0, len(fields))
+	for f := range fields {
+		names = append(names, f)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// auditValue converts a scanned column value to a JSON-friendly form.
+func auditValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05")
+	}
+	return v
+}
+
+// runAudited runs fn in a transaction and, if it changed any rows, appends
+// its audit record in the same transaction. fn describes the change through
+// the auditChange it is given, snapshotting old values before writing.
+func runAudited(conn *sql.DB, d Dialect, examiner, operation string, fn func(tx *sql.Tx, a *auditChange) error) error {
+	tx, err := conn.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	a := &auditChange{d: d, operation: operation}
+	if err := fn(tx, a); err != nil {
+		return err
+	}
+	if len(a.ids) > 0 {
+		if err := writeAudit(tx, d, examiner, a); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// writeAudit appends a to audit_log.
+func writeAudit(tx schemaExecer, d Dialect, examiner string, a *auditChange) error {
+	oldJSON, err := json.Marshal(a.old)
+	if err != nil {
+		return fmt.Errorf("encoding audit values: %w", err)
+	}
+	newJSON, err := json.Marshal(a.new)
+	if err != nil {
+		return fmt.Errorf("encoding audit values: %w", err)
+	}
+	_, err = tx.Exec(
+		"INSERT INTO audit_log (created_at, examiner, operation, target, ids, old_values, new_values) VALUES ("+placeholders(d, 7)+")",
+		time.Now().UTC().Format("2006-01-02 15:04:05"), examiner, a.operation, a.target,
+		formatAuditIDs(a.ids), string(oldJSON), string(newJSON))
+	if err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return nil
+}
+
+// formatAuditIDs stores IDs as ",1,2,3," so a single ID can be matched with
+// LIKE '%,2,%'.
+func formatAuditIDs(ids []int64) string {
+	var b strings.Builder
+	b.WriteByte(',')
+	for _, id := range ids {
+		b.WriteString(strconv.FormatInt(id, 10))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+func parseAuditIDs(s string) []int64 {
+	ids := []int64{}
+	for _, f := range strings.Split(strings.Trim(s, ","), ",") {
+		if id, err := strconv.ParseInt(f, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// decodeAuditValues unmarshals a stored values column into v. Whole numbers
+// decode as int64 rather than float64 so they can be written back to
+// integer columns.
+func decodeAuditValues(s string, v interface{}) error {
+	if s == "" {
+		return nil
+	}
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case *map[string]interface{}:
+		fixAuditNumbers(*v)
+	case *map[int64]map[string]interface{}:
+		for _, row := range *v {
+			fixAuditNumbers(row)
+		}
+	}
+	return nil
+}
+
+func fixAuditNumbers(m map[string]interface{}) {
+	for k, val := range m {
+		if n, ok := val.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				m[k] = i
+			} else if f, err := n.Float64(); err == nil {
+				m[k] = f
+			}
+		}
+	}
+}
+
+const auditSelectSQL = "SELECT id, created_at, examiner, operation, target, ids, old_values, new_values FROM audit_log"
+
+// getAuditLog implements Store.GetAuditLog for every backend.
+func getAuditLog(conn *sql.DB, d Dialect, f AuditFilter) ([]AuditEntry, error) {
+	var where []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, strings.Replace(cond, "?", d.Placeholder(len(args)), 1))
+	}
+	if f.Examiner != "" {
+		add("examiner = ?", f.Examiner)
+	}
+	if f.Operation != "" {
+		add("operation = ?", f.Operation)
+	}
+	if f.Target != "" {
+		add("target = ?", f.Target)
+	}
+	if f.EventID != 0 {
+		add("ids LIKE ?", "%,"+strconv.FormatInt(f.EventID, 10)+",%")
+	}
+	if f.Since != "" {
+		since, err := normalizeCaseDate(f.Since)
+		if err != nil {
+			return nil, err
+		}
+		add("created_at >= ?", since)
+	}
+	if f.Until != "" {
+		until, err := normalizeCaseDate(f.Until)
+		if err != nil {
+			return nil, err
+		}
+		if len(strings.TrimSpace(f.Until)) == 10 {
+			until = until[:10] + " 23:59:59"
+		}
+		add("created_at <= ?", until)
+	}
+
+	query := auditSelectSQL
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if f.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", f.Limit)
+	}
+
+	rows, err := conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying audit log: %w", err)
+	}
+	entries, err := scanAuditEntries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// Oldest first, whichever entries the limit kept
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// scanAuditEntries reads every audit_log row from rows and closes it.
+func scanAuditEntries(rows *sql.Rows) ([]AuditEntry, error) {
+	defer rows.Close()
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		var created, examiner, ids, oldJSON, newJSON sql.NullString
+		if err := rows.Scan(&e.ID, &created, &examiner, &e.Operation, &e.Target, &ids, &oldJSON, &newJSON); err != nil {
+			return nil, fmt.Errorf("scanning audit entry: %w", err)
+		}
+		e.CreatedAt = normalizeMergedDatetime(created.String)
+		e.Examiner = examiner.String
+		e.IDs = parseAuditIDs(ids.String)
+		if err := decodeAuditValues(oldJSON.String, &e.OldValues); err != nil {
+			return nil, fmt.Errorf("decoding audit entry %d: %w", e.ID, err)
+		}
+		if err := decodeAuditValues(newJSON.String, &e.NewValues); err != nil {
+			return nil, fmt.Errorf("decoding audit entry %d: %w", e.ID, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// -- Store methods --
+
+// SetExaminer sets the examiner identity recorded with subsequent changes.
+func (db *SQLiteStore) SetExaminer(name string) { db.examiner = name }
+
+// GetAuditLog returns audit entries matching f, oldest first.
+func (db *SQLiteStore) GetAuditLog(f AuditFilter) ([]AuditEntry, error) {
+	return getAuditLog(db.conn, db.dialect, f)
+}
+
+// SetExaminer sets the examiner identity recorded with subsequent changes.
+func (db *PostgresStore) SetExaminer(name string) { db.examiner = name }
+
+// GetAuditLog returns audit entries matching f, oldest first.
+func (db *PostgresStore) GetAuditLog(f AuditFilter) ([]AuditEntry, error) {
+	return getAuditLog(db.conn, db.dialect, f)
+}
+
+// SetExaminer sets the examiner identity recorded with subsequent changes.
+func (db *MySQLStore) SetExaminer(name string) { db.examiner = name }
+
+// GetAuditLog returns audit entries matching f, oldest first.
+func (db *MySQLStore) GetAuditLog(f AuditFilter) ([]AuditEntry, error) {
+	return getAuditLog(db.conn, db.dialect, f)
+}
@@ -0,0 +1,203 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAuditIDsRoundTrip(t *testing.T) {
+	ids := []int64{3, 12, 7}
+	s := formatAuditIDs(ids)
+	if s != ",3,12,7," {
+		t.Errorf("formatAuditIDs = %q", s)
+	}
+	if got := parseAuditIDs(s); !reflect.DeepEqual(got, ids) {
+		t.Errorf("parseAuditIDs(%q) = %v, want %v", s, got, ids)
+	}
+	if got := parseAuditIDs(","); len(got) != 0 {
+		t.Errorf("expected no ids, got %v", got)
+	}
+}
+
+func TestAuditLogIsAppendOnly(t *testing.T) {
+	db := createTestDB(t)
+	if err := db.InsertEvent(sampleEvent()); err != nil {
+		t.Fatalf("InsertEvent failed: %v", err)
+	}
+	if _, err := db.ToggleBookmark(1); err != nil {
+		t.Fatalf("ToggleBookmark failed: %v", err)
+	}
+
+	if _, err := db.conn.Exec("UPDATE audit_log SET examiner = 'someone else'"); err == nil {
+		t.Error("expected updating audit_log to fail")
+	}
+	if _, err := db.conn.Exec("DELETE FROM audit_log"); err == nil {
+		t.Error("expected deleting from audit_log to fail")
+	}
+	log, err := db.GetAuditLog(AuditFilter{})
+	if err != nil || len(log) != 1 {
+		t.Fatalf("expected 1 audit entry, got %+v (%v)", log, err)
+	}
+	if log[0].Operation != AuditToggleBookmark || log[0].NewValues["bookmark"] != int64(1) {
+		t.Errorf("unexpected audit entry: %+v", log[0])
+	}
+}
+
+func TestFailedChangeLeavesNoAuditEntry(t *testing.T) {
+	db := createTestDB(t)
+	if err := db.UpdateEvent(1, map[string]interface{}{"no_such_field": "x"}); err == nil {
+		t.Fatal("expected UpdateEvent with an unknown field to fail")
+	}
+	if _, err := db.ToggleBookmark(42); err == nil {
+		t.Fatal("expected toggling a missing event to fail")
+	}
+	if log, _ := db.GetAuditLog(AuditFilter{}); len(log) != 0 {
+		t.Errorf("expected no audit entries, got %+v", log)
+	}
+}
+
+func TestUndoRedoBulkColor(t *testing.T) {
+	db := createTestDB(t)
+	for i := 0; i < 3; i++ {
+		e := sampleEvent()
+		e.Color = "GREEN"
+		if err := db.InsertEvent(e); err != nil {
+			t.Fatalf("InsertEvent failed: %v", err)
+		}
+	}
+	colors := func() []string {
+		events, _ := db.QueryEvents("", nil, "rowid", 0, 0)
+		var got []string
+		for _, e := range events {
+			got = append(got, e.Color)
+		}
+		return got
+	}
+
+	if err := db.BulkUpdateColor([]int64{1, 2, 3}, "RED"); err != nil {
+		t.Fatalf("BulkUpdateColor failed: %v", err)
+	}
+	if err := db.UpdateEvent(2, map[string]interface{}{"color": "BLUE", "tag": "triage"}); err != nil {
+		t.Fatalf("UpdateEvent failed: %v", err)
+	}
+
+	// Each operation is undone as a unit, most recent first
+	if e, err := db.Undo(); err != nil || e == nil || e.Operation != AuditUpdateEvent {
+		t.Fatalf("Undo = %+v, %v; want the UpdateEvent entry", e, err)
+	}
+	if got := colors(); !reflect.DeepEqual(got, []string{"RED", "RED", "RED"}) {
+		t.Errorf("unexpected colors after first undo: %v", got)
+	}
+	if tags, _ := db.GetDistinctTags(); len(tags) != 0 {
+		t.Errorf("expected undo to remove the tag, got %v", tags)
+	}
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if got := colors(); !reflect.DeepEqual(got, []string{"GREEN", "GREEN", "GREEN"}) {
+		t.Errorf("unexpected colors after second undo: %v", got)
+	}
+	if e, err := db.Undo(); err != nil || e != nil {
+		t.Errorf("expected nothing left to undo, got %+v, %v", e, err)
+	}
+
+	state, _ := db.GetUndoState()
+	if state.UndoCount != 0 || state.RedoCount != 2 || state.RedoOperation != AuditSetColor {
+		t.Errorf("unexpected undo state: %+v", state)
+	}
+	if _, err := db.Redo(); err != nil {
+		t.Fatalf("Redo failed: %v", err)
+	}
+	if got := colors(); !reflect.DeepEqual(got, []string{"RED", "RED", "RED"}) {
+		t.Errorf("unexpected colors after redo: %v", got)
+	}
+
+	// A new change clears the redo stack
+	if _, err := db.ToggleBookmark(1); err != nil {
+		t.Fatalf("ToggleBookmark failed: %v", err)
+	}
+	state, _ = db.GetUndoState()
+	if state.UndoCount != 2 || state.UndoOperation != AuditToggleBookmark || state.RedoCount != 0 {
+		t.Errorf("unexpected undo state after a new change: %+v", state)
+	}
+}
+
+func TestUndoRedoExaminerNotes(t *testing.T) {
+	db := createTestDB(t)
+	keep, _ := db.InsertExaminerNote("2025-01-15 09:00:00", "keep", "", "")
+	gone, _ := db.InsertExaminerNote("2025-01-15 10:00:00", "gone", "triage", "RED")
+	if err := db.DeleteExaminerNote(-gone); err != nil {
+		t.Fatalf("DeleteExaminerNote failed: %v", err)
+	}
+
+	// Undoing a delete recreates the note under its original ID
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	notes, _ := db.GetExaminerNotes()
+	if len(notes) != 2 || notes[1].ID != gone || notes[1].Tag != "triage" || notes[1].Color != "RED" {
+		t.Fatalf("expected the deleted note to be restored, got %+v", notes)
+	}
+
+	// Undoing a create removes the note; redo brings it back
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if notes, _ = db.GetExaminerNotes(); len(notes) != 1 || notes[0].ID != keep {
+		t.Fatalf("expected only the first note, got %+v", notes)
+	}
+	if _, err := db.Redo(); err != nil {
+		t.Fatalf("Redo failed: %v", err)
+	}
+	if _, err := db.Redo(); err != nil {
+		t.Fatalf("Redo failed: %v", err)
+	}
+	if notes, _ = db.GetExaminerNotes(); len(notes) != 1 || notes[0].ID != keep {
+		t.Errorf("expected redo to delete the note again, got %+v", notes)
+	}
+}
+
+func TestUndoStopsAtTagRename(t *testing.T) {
+	db := createTestDB(t)
+	if err := db.InsertEvent(sampleEvent()); err != nil {
+		t.Fatalf("InsertEvent failed: %v", err)
+	}
+	if err := db.BulkAddTag([]int64{1}, "malware"); err != nil {
+		t.Fatalf("BulkAddTag failed: %v", err)
+	}
+	if err := db.RenameTag(nil, "malware", "dropper"); err != nil {
+		t.Fatalf("RenameTag failed: %v", err)
+	}
+	if e, err := db.Undo(); err != nil || e != nil {
+		t.Errorf("expected nothing to undo after a rename, got %+v, %v", e, err)
+	}
+}
+
+func TestUndoHistoryIsBounded(t *testing.T) {
+	db := createTestDB(t)
+	if err := db.InsertEvent(sampleEvent()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < undoHistory+5; i++ {
+		if _, err := db.ToggleBookmark(1); err != nil {
+			t.Fatalf("ToggleBookmark failed: %v", err)
+		}
+	}
+
+	state, err := db.GetUndoState()
+	if err != nil {
+		t.Fatalf("GetUndoState failed: %v", err)
+	}
+	if state.UndoCount != undoHistory {
+		t.Errorf("UndoCount = %d; want %d", state.UndoCount, undoHistory)
+	}
+
+	// An undo at the edge of the window still pairs with its entry
+	if e, err := db.Undo(); err != nil || e == nil {
+		t.Fatalf("Undo = %+v, %v", e, err)
+	}
+	state, _ = db.GetUndoState()
+	if state.RedoCount != 1 {
+		t.Errorf("RedoCount after undo = %d; want 1", state.RedoCount)
+	}
+}
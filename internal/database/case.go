@@ -0,0 +1,572 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Evidence item kinds. Kind is free text in the database; these are the
+// values the application offers.
+const (
+	EvidenceDiskImage = "disk_image"
+	EvidenceHost      = "host"
+	EvidenceLogBundle = "log_bundle"
+	EvidenceOther     = "other"
+)
+
+// CaseInfo describes the case a database belongs to. A database has at most
+// one; an empty CaseInfo means none has been recorded.
+type CaseInfo struct {
+	CaseNumber  string   `json:"caseNumber"`
+	Title       string   `json:"title"`
+	Examiners   []string `json:"examiners"`
+	Description string   `json:"description"`
+	CreatedAt   string   `json:"createdAt"`
+	ClosedAt    string   `json:"closedAt"`
+}
+
+// EvidenceItem is a disk image, host, log bundle or other source that events
+// were extracted from. Hashes are lowercase hex, or empty if unknown.
+type EvidenceItem struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	Path        string `json:"path"`
+	MD5         string `json:"md5"`
+	SHA1        string `json:"sha1"`
+	SHA256      string `json:"sha256"`
+	AddedAt     string `json:"addedAt"`
+}
+
+// ImportBatch records one import of a timeline file. Events carry the batch
+// ID in log2timeline.batch_id; EvidenceID is 0 until the batch is linked to
+// an evidence item. EventCount is computed when batches are listed.
+type ImportBatch struct {
+	ID         int64  `json:"id"`
+	EvidenceID int64  `json:"evidenceId"`
+	SourcePath string `json:"sourcePath"`
+	Format     string `json:"format"`
+	ImportedAt string `json:"importedAt"`
+	EventCount int64  `json:"eventCount"`
+}
+
+// CaseRecord bundles a database's case metadata for export alongside events.
+type CaseRecord struct {
+	Case     *CaseInfo      `json:"case"`
+	Evidence []EvidenceItem `json:"evidence"`
+	Batches  []ImportBatch  `json:"importBatches"`
+}
+
+// GetCaseRecord collects the case metadata, evidence items and import batches
+// of s.
+func GetCaseRecord(s Store) (*CaseRecord, error) {
+	c, err := s.GetCaseInfo()
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.GetEvidenceItems()
+	if err != nil {
+		return nil, err
+	}
+	batches, err := s.GetImportBatches()
+	if err != nil {
+		return nil, err
+	}
+	return &CaseRecord{Case: c, Evidence: items, Batches: batches}, nil
+}
+
+// CopyCaseRecords copies the evidence items and import batches of src into
+// dst, and src's case metadata if dst has none. Rows get fresh IDs in dst;
+// the returned map translates src batch IDs to dst batch IDs so copied events
+// can be relinked.
+func CopyCaseRecords(dst, src Store) (map[int64]int64, int, error) {
+	srcCase, err := src.GetCaseInfo()
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading source case: %w", err)
+	}
+	dstCase, err := dst.GetCaseInfo()
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading destination case: %w", err)
+	}
+	if dstCase.empty() && !srcCase.empty() {
+		if err := dst.SaveCaseInfo(srcCase); err != nil {
+			return nil, 0, fmt.Errorf("copying case: %w", err)
+		}
+	}
+
+	items, err := src.GetEvidenceItems()
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading source evidence: %w", err)
+	}
+	evidenceIDs := make(map[int64]int64, len(items))
+	for i := range items {
+		id, err := dst.InsertEvidenceItem(&items[i])
+		if err != nil {
+			return nil, 0, fmt.Errorf("copying evidence item %q: %w", items[i].Name, err)
+		}
+		evidenceIDs[items[i].ID] = id
+	}
+
+	batches, err := src.GetImportBatches()
+	if err != nil {
+		return nil, len(items), fmt.Errorf("reading source import batches: %w", err)
+	}
+	batchIDs := make(map[int64]int64, len(batches))
+	for i := range batches {
+		b := batches[i]
+		b.EvidenceID = evidenceIDs[b.EvidenceID]
+		id, err := dst.InsertImportBatch(&b)
+		if err != nil {
+			return nil, len(items), fmt.Errorf("copying import batch: %w", err)
+		}
+		batchIDs[batches[i].ID] = id
+	}
+	return batchIDs, len(items), nil
+}
+
+func (c *CaseInfo) empty() bool {
+	return c.CaseNumber == "" && c.Title == "" && len(c.Examiners) == 0 &&
+		c.Description == "" && c.CreatedAt == "" && c.ClosedAt == ""
+}
+
+// validate normalizes dates to "YYYY-MM-DD HH:MM:SS" and trims examiners.
+func (c *CaseInfo) validate() error {
+	var err error
+	if c.CreatedAt, err = normalizeCaseDate(c.CreatedAt); err != nil {
+		return fmt.Errorf("invalid created date: %w", err)
+	}
+	if c.ClosedAt, err = normalizeCaseDate(c.ClosedAt); err != nil {
+		return fmt.Errorf("invalid closed date: %w", err)
+	}
+	examiners := c.Examiners[:0:0]
+	for _, e := range c.Examiners {
+		if e = strings.TrimSpace(e); e != "" {
+			examiners = append(examiners, e)
+		}
+	}
+	c.Examiners = examiners
+	return nil
+}
+
+// validate lowercases the hashes and checks each is hex of the right length.
+func (item *EvidenceItem) validate() error {
+	if strings.TrimSpace(item.Name) == "" {
+		return fmt.Errorf("evidence item name is required")
+	}
+	if item.Kind == "" {
+		item.Kind = EvidenceOther
+	}
+	for _, h := range []struct {
+		name  string
+		value *string
+		size  int
+	}{
+		{"MD5", &item.MD5, 16},
+		{"SHA-1", &item.SHA1, 20},
+		{"SHA-256", &item.SHA256, 32},
+	} {
+		*h.value = strings.ToLower(strings.TrimSpace(*h.value))
+		if *h.value == "" {
+			continue
+		}
+		if b, err := hex.DecodeString(*h.value); err != nil || len(b) != h.size {
+			return fmt.Errorf("invalid %s hash %q", h.name, *h.value)
+		}
+	}
+	return nil
+}
+
+// normalizeCaseDate accepts a date or datetime and returns it in the form
+// every backend stores, or "" for an empty value.
+func normalizeCaseDate(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+	if len(s) == 10 {
+		s += " 00:00:00"
+	}
+	if !validTimestampRe.MatchString(s) {
+		return "", fmt.Errorf("%q is not a date", s)
+	}
+	return s[:10] + " " + s[11:19], nil
+}
+
+// nullIfEmpty maps "" to SQL NULL, for datetime columns.
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// insertReturningID runs an INSERT and returns the new row's id column.
+func insertReturningID(conn schemaExecer, d Dialect, query string, args ...interface{}) (int64, error) {
+	if _, ok := d.(*PostgresDialect); ok {
+		var id int64
+		err := conn.QueryRow(query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+	result, err := conn.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// placeholders returns n comma-separated placeholders starting at index 1.
+func placeholders(d Dialect, n int) string {
+	p := make([]string, n)
+	for i := range p {
+		p[i] = d.Placeholder(i + 1)
+	}
+	return strings.Join(p, ", ")
+}
+
+// The functions below implement the case and evidence methods of Store for
+// every backend; each store delegates to them with its connection and dialect.
+
+func getCaseInfo(conn *sql.DB) (*CaseInfo, error) {
+	var number, title, examiners, desc, created, closed sql.NullString
+	err := conn.QueryRow(
+		"SELECT case_number, title, examiners, description, created_at, closed_at FROM case_info WHERE id = 1",
+	).Scan(&number, &title, &examiners, &desc, &created, &closed)
+	if err == sql.ErrNoRows {
+		return &CaseInfo{Examiners: []string{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading case: %w", err)
+	}
+	c := &CaseInfo{
+		CaseNumber:  number.String,
+		Title:       title.String,
+		Examiners:   []string{},
+		Description: desc.String,
+		CreatedAt:   normalizeMergedDatetime(created.String),
+		ClosedAt:    normalizeMergedDatetime(closed.String),
+	}
+	for _, e := range strings.Split(examiners.String, "\n") {
+		if e != "" {
+			c.Examiners = append(c.Examiners, e)
+		}
+	}
+	return c, nil
+}
+
+// saveCaseInfo replaces the case_info row. Examiners are stored one per line
+// so names may contain commas.
+func saveCaseInfo(conn *sql.DB, d Dialect, c *CaseInfo) error {
+	v := *c
+	if err := v.validate(); err != nil {
+		return err
+	}
+	tx, err := conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("DELETE FROM case_info"); err != nil {
+		return fmt.Errorf("saving case: %w", err)
+	}
+	_, err = tx.Exec(
+		"INSERT INTO case_info (id, case_number, title, examiners, description, created_at, closed_at) VALUES ("+
+			placeholders(d, 7)+")",
+		1, v.CaseNumber, v.Title, strings.Join(v.Examiners, "\n"), v.Description,
+		nullIfEmpty(v.CreatedAt), nullIfEmpty(v.ClosedAt))
+	if err != nil {
+		return fmt.Errorf("saving case: %w", err)
+	}
+	return tx.Commit()
+}
+
+func getEvidenceItems(conn *sql.DB) ([]EvidenceItem, error) {
+	rows, err := conn.Query("SELECT id, name, kind, description, path, md5, sha1, sha256, added_at FROM evidence_items ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("querying evidence items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []EvidenceItem{}
+	for rows.Next() {
+		var item EvidenceItem
+		var name, kind, desc, path, md5, sha1, sha256, added sql.NullString
+		if err := rows.Scan(&item.ID, &name, &kind, &desc, &path, &md5, &sha1, &sha256, &added); err != nil {
+			return nil, fmt.Errorf("scanning evidence item: %w", err)
+		}
+		item.Name, item.Kind, item.Description, item.Path = name.String, kind.String, desc.String, path.String
+		item.MD5, item.SHA1, item.SHA256 = md5.String, sha1.String, sha256.String
+		item.AddedAt = normalizeMergedDatetime(added.String)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func insertEvidenceItem(conn *sql.DB, d Dialect, item *EvidenceItem) (int64, error) {
+	v := *item
+	if err := v.validate(); err != nil {
+		return 0, err
+	}
+	id, err := insertReturningID(conn, d,
+		"INSERT INTO evidence_items (name, kind, description, path, md5, sha1, sha256) VALUES ("+placeholders(d, 7)+")",
+		v.Name, v.Kind, v.Description, v.Path, v.MD5, v.SHA1, v.SHA256)
+	if err != nil {
+		return 0, fmt.Errorf("inserting evidence item: %w", err)
+	}
+	return id, nil
+}
+
+func updateEvidenceItem(conn *sql.DB, d Dialect, item *EvidenceItem) error {
+	v := *item
+	if err := v.validate(); err != nil {
+		return err
+	}
+	result, err := conn.Exec(
+		"UPDATE evidence_items SET name = "+d.Placeholder(1)+", kind = "+d.Placeholder(2)+
+			", description = "+d.Placeholder(3)+", path = "+d.Placeholder(4)+", md5 = "+d.Placeholder(5)+
+			", sha1 = "+d.Placeholder(6)+", sha256 = "+d.Placeholder(7)+" WHERE id = "+d.Placeholder(8),
+		v.Name, v.Kind, v.Description, v.Path, v.MD5, v.SHA1, v.SHA256, v.ID)
+	if err != nil {
+		return fmt.Errorf("updating evidence item: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("evidence item %d not found", v.ID)
+	}
+	return nil
+}
+
+// deleteEvidenceItem removes an evidence item and unlinks its import batches.
+// The batches and their events are kept.
+func deleteEvidenceItem(conn *sql.DB, d Dialect, id int64) error {
+	tx, err := conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("UPDATE import_batches SET evidence_id = 0 WHERE evidence_id = "+d.Placeholder(1), id); err != nil {
+		return fmt.Errorf("unlinking import batches: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM evidence_items WHERE id = "+d.Placeholder(1), id); err != nil {
+		return fmt.Errorf("deleting evidence item: %w", err)
+	}
+	return tx.Commit()
+}
+
+func getImportBatches(conn *sql.DB) ([]ImportBatch, error) {
+	rows, err := conn.Query(
+		"SELECT b.id, b.evidence_id, b.source_path, b.format, b.imported_at, COALESCE(c.n, 0) " +
+			"FROM import_batches b LEFT JOIN " +
+			"(SELECT batch_id, COUNT(*) AS n FROM log2timeline GROUP BY batch_id) c ON c.batch_id = b.id " +
+			"ORDER BY b.id")
+	if err != nil {
+		return nil, fmt.Errorf("querying import batches: %w", err)
+	}
+	defer rows.Close()
+
+	batches := []ImportBatch{}
+	for rows.Next() {
+		var b ImportBatch
+		var evidenceID sql.NullInt64
+		var path, format, imported sql.NullString
+		if err := rows.Scan(&b.ID, &evidenceID, &path, &format, &imported, &b.EventCount); err != nil {
+			return nil, fmt.Errorf("scanning import batch: %w", err)
+		}
+		b.EvidenceID, b.SourcePath, b.Format = evidenceID.Int64, path.String, format.String
+		b.ImportedAt = normalizeMergedDatetime(imported.String)
+		batches = append(batches, b)
+	}
+	return batches, rows.Err()
+}
+
+// insertImportBatch records a batch. ImportedAt defaults to now; copied
+// batches keep their original import time.
+func insertImportBatch(conn *sql.DB, d Dialect, b *ImportBatch) (int64, error) {
+	imported, err := normalizeCaseDate(b.ImportedAt)
+	if err != nil {
+		return 0, fmt.Errorf("invalid import time: %w", err)
+	}
+	cols, args := "evidence_id, source_path, format", []interface{}{b.EvidenceID, b.SourcePath, b.Format}
+	if imported != "" {
+		cols += ", imported_at"
+		args = append(args, imported)
+	}
+	id, err := insertReturningID(conn, d,
+		"INSERT INTO import_batches ("+cols+") VALUES ("+placeholders(d, len(args))+")", args...)
+	if err != nil {
+		return 0, fmt.Errorf("inserting import batch: %w", err)
+	}
+	return id, nil
+}
+
+// setImportBatchEvidence links a batch to an evidence item, or unlinks it
+// when evidenceID is 0.
+func setImportBatchEvidence(conn *sql.DB, d Dialect, batchID, evidenceID int64) error {
+	if evidenceID != 0 {
+		var n int
+		if err := conn.QueryRow("SELECT COUNT(*) FROM evidence_items WHERE id = "+d.Placeholder(1), evidenceID).Scan(&n); err != nil {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("evidence item %d not found", evidenceID)
+		}
+	}
+	result, err := conn.Exec("UPDATE import_batches SET evidence_id = "+d.Placeholder(1)+" WHERE id = "+d.Placeholder(2),
+		evidenceID, batchID)
+	if err != nil {
+		return fmt.Errorf("linking import batch: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("import batch %d not found", batchID)
+	}
+	return nil
+}
+
+// evidenceFromDiskConfig turns a configured l2t_disk row, which earlier
+// releases created but never used, into a disk image evidence item.
+func evidenceFromDiskConfig(tx schemaExecer, d Dialect) error {
+	var existing int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM evidence_items").Scan(&existing); err != nil {
+		return err
+	}
+	if existing > 0 {
+		return nil
+	}
+	var ddPath, mountPath, storageFile sql.NullString
+	err := tx.QueryRow("SELECT dd_path, mount_path, storage_file FROM l2t_disk").Scan(&ddPath, &mountPath, &storageFile)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	path := ddPath.String
+	if path == "" {
+		path = mountPath.String
+	}
+	if path == "" {
+		path = storageFile.String
+	}
+	if path == "" {
+		return nil
+	}
+	_, err = tx.Exec(
+		"INSERT INTO evidence_items (name, kind, description, path, md5, sha1, sha256) VALUES ("+placeholders(d, 7)+")",
+		filepath.Base(path), EvidenceDiskImage, "From the l2t_disk configuration", path, "", "", "")
+	return err
+}
+
+// -- Store methods --
+
+// GetCaseInfo returns the case metadata, or an empty CaseInfo if none is set.
+func (db *SQLiteStore) GetCaseInfo() (*CaseInfo, error) { return getCaseInfo(db.conn) }
+
+// SaveCaseInfo replaces the case metadata.
+func (db *SQLiteStore) SaveCaseInfo(c *CaseInfo) error { return saveCaseInfo(db.conn, db.dialect, c) }
+
+// GetEvidenceItems returns all evidence items in the order they were added.
+func (db *SQLiteStore) GetEvidenceItems() ([]EvidenceItem, error) { return getEvidenceItems(db.conn) }
+
+// InsertEvidenceItem adds an evidence item and returns its ID. item.ID is ignored.
+func (db *SQLiteStore) InsertEvidenceItem(item *EvidenceItem) (int64, error) {
+	return insertEvidenceItem(db.conn, db.dialect, item)
+}
+
+// UpdateEvidenceItem overwrites the evidence item with ID item.ID.
+func (db *SQLiteStore) UpdateEvidenceItem(item *EvidenceItem) error {
+	return updateEvidenceItem(db.conn, db.dialect, item)
+}
+
+// DeleteEvidenceItem deletes an evidence item, leaving its batches unlinked.
+func (db *SQLiteStore) DeleteEvidenceItem(id int64) error {
+	return deleteEvidenceItem(db.conn, db.dialect, id)
+}
+
+// GetImportBatches returns all import batches with their event counts.
+func (db *SQLiteStore) GetImportBatches() ([]ImportBatch, error) { return getImportBatches(db.conn) }
+
+// InsertImportBatch records an import batch and returns its ID.
+func (db *SQLiteStore) InsertImportBatch(b *ImportBatch) (int64, error) {
+	return insertImportBatch(db.conn, db.dialect, b)
+}
+
+// SetImportBatchEvidence links an import batch to an evidence item (0 unlinks).
+func (db *SQLiteStore) SetImportBatchEvidence(batchID, evidenceID int64) error {
+	return setImportBatchEvidence(db.conn, db.dialect, batchID, evidenceID)
+}
+
+// GetCaseInfo returns the case metadata, or an empty CaseInfo if none is set.
+func (db *PostgresStore) GetCaseInfo() (*CaseInfo, error) { return getCaseInfo(db.conn) }
+
+// SaveCaseInfo replaces the case metadata.
+func (db *PostgresStore) SaveCaseInfo(c *CaseInfo) error { return saveCaseInfo(db.conn, db.dialect, c) }
+
+// GetEvidenceItems returns all evidence items in the order they were added.
+func (db *PostgresStore) GetEvidenceItems() ([]EvidenceItem, error) { return getEvidenceItems(db.conn) }
+
+// InsertEvidenceItem adds an evidence item and returns its ID. item.ID is ignored.
+func (db *PostgresStore) InsertEvidenceItem(item *EvidenceItem) (int64, error) {
+	return insertEvidenceItem(db.conn, db.dialect, item)
+}
+
+// UpdateEvidenceItem overwrites the evidence item with ID item.ID.
+func (db *PostgresStore) UpdateEvidenceItem(item *EvidenceItem) error {
+	return updateEvidenceItem(db.conn, db.dialect, item)
+}
+
+// DeleteEvidenceItem deletes an evidence item, leaving its batches unlinked.
+func (db *PostgresStore) DeleteEvidenceItem(id int64) error {
+	return deleteEvidenceItem(db.conn, db.dialect, id)
+}
+
+// GetImportBatches returns all import batches with their event counts.
+func (db *PostgresStore) GetImportBatches() ([]ImportBatch, error) { return getImportBatches(db.conn) }
+
+// InsertImportBatch records an import batch and returns its ID.
+func (db *PostgresStore) InsertImportBatch(b *ImportBatch) (int64, error) {
+	return insertImportBatch(db.conn, db.dialect, b)
+}
+
+// SetImportBatchEvidence links an import batch to an evidence item (0 unlinks).
+func (db *PostgresStore) SetImportBatchEvidence(batchID, evidenceID int64) error {
+	return setImportBatchEvidence(db.conn, db.dialect, batchID, evidenceID)
+}
+
+// GetCaseInfo returns the case metadata, or an empty CaseInfo if none is set.
+func (db *MySQLStore) GetCaseInfo() (*CaseInfo, error) { return getCaseInfo(db.conn) }
+
+// SaveCaseInfo replaces the case metadata.
+func (db *MySQLStore) SaveCaseInfo(c *CaseInfo) error { return saveCaseInfo(db.conn, db.dialect, c) }
+
+// GetEvidenceItems returns all evidence items in the order they were added.
+func (db *MySQLStore) GetEvidenceItems() ([]EvidenceItem, error) { return getEvidenceItems(db.conn) }
+
+// InsertEvidenceItem adds an evidence item and returns its ID. item.ID is ignored.
+func (db *MySQLStore) InsertEvidenceItem(item *EvidenceItem) (int64, error) {
+	return insertEvidenceItem(db.conn, db.dialect, item)
+}
+
+// UpdateEvidenceItem overwrites the evidence item with ID item.ID.
+func (db *MySQLStore) UpdateEvidenceItem(item *EvidenceItem) error {
+	return updateEvidenceItem(db.conn, db.dialect, item)
+}
+
+// DeleteEvidenceItem deletes an evidence item, leaving its batches unlinked.
+func (db *MySQLStore) DeleteEvidenceItem(id int64) error {
+	return deleteEvidenceItem(db.conn, db.dialect, id)
+}
+
+// GetImportBatches returns all import batches with their event counts.
+func (db *MySQLStore) GetImportBatches() ([]ImportBatch, error) { return getImportBatches(db.conn) }
+
+// InsertImportBatch records an import batch and returns its ID.
+func (db *MySQLStore) InsertImportBatch(b *ImportBatch) (int64, error) {
+	return insertImportBatch(db.conn, db.dialect, b)
+}
+
+// SetImportBatchEvidence links an import batch to an evidence item (0 unlinks).
+func (db *MySQLStore) SetImportBatchEvidence(batchID, evidenceID int64) error {
+	return setImportBatchEvidence(db.conn, db.dialect, batchID, evidenceID)
+}
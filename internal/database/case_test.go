@@ -0,0 +1,81 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeCaseDate(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"", "", true},
+		{"2025-03-01", "2025-03-01 00:00:00", true},
+		{"2025-03-01 14:05:00", "2025-03-01 14:05:00", true},
+		{"2025-03-01T14:05:00Z", "2025-03-01 14:05:00", true},
+		{"March 1st", "", false},
+	}
+	for _, tt := range tests {
+		got, err := normalizeCaseDate(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("normalizeCaseDate(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestEvidenceItemValidate(t *testing.T) {
+	item := EvidenceItem{Name: "image.E01", MD5: " D41D8CD98F00B204E9800998ECF8427E "}
+	if err := item.validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	if item.MD5 != "d41d8cd98f00b204e9800998ecf8427e" || item.Kind != EvidenceOther {
+		t.Errorf("expected normalized hash and default kind, got %+v", item)
+	}
+
+	for _, bad := range []EvidenceItem{
+		{Name: ""},
+		{Name: "x", SHA1: "d41d8cd98f00b204e9800998ecf8427e"},
+		{Name: "x", SHA256: "not hex"},
+	} {
+		if err := bad.validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestMigrateDiskConfigToEvidence(t *testing.T) {
+	path := loadFixture(t, "v0.8.0.sql")
+	db, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	items, _ := db.GetEvidenceItems()
+	db.Close()
+	if len(items) != 0 {
+		t.Fatalf("expected the default l2t_disk row to be ignored, got %+v", items)
+	}
+
+	path = loadFixture(t, "v0.8.0.sql")
+	db, err = OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer db.Close()
+	// Rewind to before the evidence tables and configure a disk image
+	for _, stmt := range []string{
+		"DROP TABLE evidence_items", "DELETE FROM schema_version WHERE version >= 5",
+		`UPDATE l2t_disk SET dd_path = 'C:\Cases\WKSTN01.dd'`,
+	} {
+		if _, err := db.conn.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	items, _ = db.GetEvidenceItems()
+	if len(items) != 1 || items[0].Kind != EvidenceDiskImage || !strings.HasSuffix(items[0].Path, "WKSTN01.dd") {
+		t.Errorf("expected disk image evidence from l2t_disk, got %+v", items)
+	}
+}
@@ -0,0 +1,291 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RuleFilter is one condition of a color rule: a field, an operator as
+// accepted by the query package ("=", "LIKE", "HAS TAG", ...) and a value.
+type RuleFilter struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+// ColorRule gives Color to the events matching its Filters, combined with
+// Logic ("AND" or "OR"), or matching the WHERE clause of the saved query
+// named SavedQuery when that is set. When rules overlap, the one with the
+// higher Priority wins.
+type ColorRule struct {
+	ID         int64        `json:"id"`
+	Name       string       `json:"name"`
+	Filters    []RuleFilter `json:"filters"`
+	Logic      string       `json:"logic"`
+	SavedQuery string       `json:"savedQuery"`
+	Color      string       `json:"color"`
+	Priority   int          `json:"priority"`
+	Enabled    bool         `json:"enabled"`
+}
+
+// ColorRuleMatch is a color rule compiled to a WHERE fragment over
+// log2timeline. Callers build it with the query package, which stores do
+// not depend on.
+type ColorRuleMatch struct {
+	Where string
+	Args  []interface{}
+	Color string
+}
+
+// The functions below implement the color rule methods of Store for every
+// backend.
+
+func getColorRules(conn *sql.DB) ([]ColorRule, error) {
+	rows, err := conn.Query("SELECT id, name, filters, logic, saved_query, color, priority, enabled FROM color_rules ORDER BY priority DESC, id")
+	if err != nil {
+		return nil, fmt.Errorf("querying color rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []ColorRule{}
+	for rows.Next() {
+		var r ColorRule
+		var name, filters, logic, saved, color sql.NullString
+		var priority, enabled sql.NullInt64
+		if err := rows.Scan(&r.ID, &name, &filters, &logic, &saved, &color, &priority, &enabled); err != nil {
+			return nil, fmt.Errorf("scanning color rule: %w", err)
+		}
+		r.Name = name.String
+		r.Logic = logic.String
+		r.SavedQuery = saved.String
+		r.Color = color.String
+		r.Priority = int(priority.Int64)
+		r.Enabled = enabled.Int64 != 0
+		r.Filters = []RuleFilter{}
+		if filters.String != "" {
+			if err := json.Unmarshal([]byte(filters.String), &r.Filters); err != nil {
+				return nil, fmt.Errorf("decoding color rule %d: %w", r.ID, err)
+			}
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+// validateColorRule normalizes r and rejects rules that cannot match
+// anything or would color nothing.
+func validateColorRule(r *ColorRule) error {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Color = strings.ToUpper(strings.TrimSpace(r.Color))
+	r.SavedQuery = strings.TrimSpace(r.SavedQuery)
+	r.Logic = strings.ToUpper(strings.TrimSpace(r.Logic))
+	if r.Logic != "OR" {
+		r.Logic = "AND"
+	}
+	if r.Color == "" {
+		return fmt.Errorf("color rule needs a color")
+	}
+	if len(r.Filters) == 0 && r.SavedQuery == "" {
+		return fmt.Errorf("color rule needs filters or a saved query")
+	}
+	if r.Name == "" {
+		r.Name = r.SavedQuery
+		if r.Name == "" {
+			f := r.Filters[0]
+			r.Name = f.Field + " " + f.Operator + " " + f.Value
+		}
+	}
+	return nil
+}
+
+func saveColorRule(conn *sql.DB, d Dialect, r *ColorRule) error {
+	if err := validateColorRule(r); err != nil {
+		return err
+	}
+	if r.Filters == nil {
+		r.Filters = []RuleFilter{}
+	}
+	filters, err := json.Marshal(r.Filters)
+	if err != nil {
+		return fmt.Errorf("encoding color rule: %w", err)
+	}
+	enabled := 0
+	if r.Enabled {
+		enabled = 1
+	}
+	args := []interface{}{r.Name, string(filters), r.Logic, r.SavedQuery, r.Color, r.Priority, enabled}
+
+	if r.ID == 0 {
+		id, err := insertReturningID(conn, d,
+			"INSERT INTO color_rules (name, filters, logic, saved_query, color, priority, enabled) VALUES ("+placeholders(d, len(args))+")",
+			args...)
+		if err != nil {
+			return fmt.Errorf("inserting color rule: %w", err)
+		}
+		r.ID = id
+		return nil
+	}
+
+	sets := []string{"name", "filters", "logic", "saved_query", "color", "priority", "enabled"}
+	for i, col := range sets {
+		sets[i] = col + " = " + d.Placeholder(i+1)
+	}
+	args = append(args, r.ID)
+	res, err := conn.Exec("UPDATE color_rules SET "+strings.Join(sets, ", ")+" WHERE id = "+d.Placeholder(len(args)), args...)
+	if err != nil {
+		return fmt.Errorf("updating color rule: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("color rule %d not found", r.ID)
+	}
+	return nil
+}
+
+func deleteColorRule(conn *sql.DB, d Dialect, id int64) error {
+	if _, err := conn.Exec("DELETE FROM color_rules WHERE id = "+d.Placeholder(1), id); err != nil {
+		return fmt.Errorf("deleting color rule: %w", err)
+	}
+	return nil
+}
+
+// applyColorRules colors the events each match selects, in order, so a
+// later match overrides an earlier one on the events both select. The whole
+// application is one audited change and is undone as a unit. It returns the
+// number of events whose color changed.
+func applyColorRules(conn *sql.DB, d Dialect, examiner string, matches []ColorRuleMatch) (int64, error) {
+	var changed int64
+	err := runAudited(conn, d, examiner, AuditApplyColorRules, func(tx *sql.Tx, a *auditChange) error {
+		idCol := d.IDColumn()
+		colors := make(map[int64]string)
+		current := make(map[int64]string)
+		var matched []int64
+		for _, m := range matches {
+			where := ""
+			if strings.TrimSpace(m.Where) != "" {
+				where = " WHERE " + m.Where
+			}
+			rows, err := tx.Query("SELECT "+idCol+", color FROM log2timeline"+where, m.Args...)
+			if err != nil {
+				return fmt.Errorf("matching color rule: %w", err)
+			}
+			for rows.Next() {
+				var id int64
+				var color sql.NullString
+				if err := rows.Scan(&id, &color); err != nil {
+					rows.Close()
+					return fmt.Errorf("matching color rule: %w", err)
+				}
+				if _, seen := colors[id]; !seen {
+					matched = append(matched, id)
+					current[id] = color.String
+				}
+				colors[id] = m.Color
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return err
+			}
+		}
+
+		// Leave events that already have their rule's color alone
+		var order []int64
+		for _, id := range matched {
+			if colors[id] != current[id] {
+				order = append(order, id)
+			}
+		}
+		if len(order) == 0 {
+			return nil
+		}
+
+		if err := a.snapshot(tx, "log2timeline", []string{"color"}, order); err != nil {
+			return err
+		}
+		byColor := make(map[string][]int64)
+		for _, id := range order {
+			byColor[colors[id]] = append(byColor[colors[id]], id)
+		}
+		counts := make(map[string]interface{}, len(byColor))
+		for color, ids := range byColor {
+			counts[color] = len(ids)
+			for _, chunk := range chunkIDs(ids) {
+				ph := make([]string, len(chunk))
+				for i := range chunk {
+					ph[i] = d.Placeholder(i + 2)
+				}
+				args := append([]interface{}{color}, idArgs(chunk)...)
+				_, err := tx.Exec("UPDATE log2timeline SET color = "+d.Placeholder(1)+
+					" WHERE "+idCol+" IN ("+strings.Join(ph, ", ")+")", args...)
+				if err != nil {
+					return fmt.Errorf("applying color rules: %w", err)
+				}
+			}
+		}
+		a.new = counts
+		changed = int64(len(order))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
+// -- Store methods --
+
+// GetColorRules returns every color rule, highest priority first.
+func (db *SQLiteStore) GetColorRules() ([]ColorRule, error) { return getColorRules(db.conn) }
+
+// SaveColorRule inserts r when r.ID is 0 and updates it otherwise.
+func (db *SQLiteStore) SaveColorRule(r *ColorRule) error {
+	return saveColorRule(db.conn, db.dialect, r)
+}
+
+// DeleteColorRule deletes the color rule with the given ID.
+func (db *SQLiteStore) DeleteColorRule(id int64) error {
+	return deleteColorRule(db.conn, db.dialect, id)
+}
+
+// ApplyColorRules colors the events each match selects, later matches
+// winning, and returns how many events changed.
+func (db *SQLiteStore) ApplyColorRules(matches []ColorRuleMatch) (int64, error) {
+	return applyColorRules(db.conn, db.dialect, db.examiner, matches)
+}
+
+// GetColorRules returns every color rule, highest priority first.
+func (db *PostgresStore) GetColorRules() ([]ColorRule, error) { return getColorRules(db.conn) }
+
+// SaveColorRule inserts r when r.ID is 0 and updates it otherwise.
+func (db *PostgresStore) SaveColorRule(r *ColorRule) error {
+	return saveColorRule(db.conn, db.dialect, r)
+}
+
+// DeleteColorRule deletes the color rule with the given ID.
+func (db *PostgresStore) DeleteColorRule(id int64) error {
+	return deleteColorRule(db.conn, db.dialect, id)
+}
+
+// ApplyColorRules colors the events each match selects, later matches
+// winning, and returns how many events changed.
+func (db *PostgresStore) ApplyColorRules(matches []ColorRuleMatch) (int64, error) {
+	return applyColorRules(db.conn, db.dialect, db.examiner, matches)
+}
+
+// GetColorRules returns every color rule, highest priority first.
+func (db *MySQLStore) GetColorRules() ([]ColorRule, error) { return getColorRules(db.conn) }
+
+// SaveColorRule inserts r when r.ID is 0 and updates it otherwise.
+func (db *MySQLStore) SaveColorRule(r *ColorRule) error { return saveColorRule(db.conn, db.dialect, r) }
+
+// DeleteColorRule deletes the color rule with the given ID.
+func (db *MySQLStore) DeleteColorRule(id int64) error {
+	return deleteColorRule(db.conn, db.dialect, id)
+}
+
+// ApplyColorRules colors the events each match selects, later matches
+// winning, and returns how many events changed.
+func (db *MySQLStore) ApplyColorRules(matches []ColorRuleMatch) (int64, error) {
+	return applyColorRules(db.conn, db.dialect, db.examiner, matches)
+}
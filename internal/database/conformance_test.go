@@ -0,0 +1,1588 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cdtdelta/4n6time/internal/model"
+	"github.com/cdtdelta/4n6time/internal/query"
+	"github.com/cdtdelta/4n6time/internal/querylang"
+	"github.com/cdtdelta/4n6time/internal/sigma"
+)
+
+// storeBackend describes one Store implementation for the conformance suite.
+// newStore must return an empty, freshly created store and arrange for it to
+// be closed (and, for server backends, dropped) when the test finishes.
+type storeBackend struct {
+	dialect  Dialect
+	newStore func(t *testing.T) Store
+}
+
+// TestSQLiteStoreConformance runs the shared Store suite against SQLite.
+func TestSQLiteStoreConformance(t *testing.T) {
+	runStoreConformance(t, storeBackend{
+		dialect: &SQLiteDialect{},
+		newStore: func(t *testing.T) Store {
+			db, err := CreateStore("sqlite", filepath.Join(t.TempDir(), "conformance.db"), nil)
+			if err != nil {
+				t.Fatalf("CreateStore failed: %v", err)
+			}
+			t.Cleanup(func() { db.Close() })
+			return db
+		},
+	})
+}
+
+// TestPostgresStoreConformance runs the shared Store suite against a live
+// PostgreSQL server. It is skipped unless FOURN6TIME_TEST_POSTGRES_DSN points
+// at an empty, disposable database, for example:
+//
+//	docker run -d -p 5432:5432 -e POSTGRES_PASSWORD=pw -e POSTGRES_DB=l2t postgres
+//	FOURN6TIME_TEST_POSTGRES_DSN=postgres://postgres:pw@127.0.0.1:5432/l2t?sslmode=disable go test ./internal/database
+func TestPostgresStoreConformance(t *testing.T) {
+	runServerStoreConformance(t, "postgres", "FOURN6TIME_TEST_POSTGRES_DSN", &PostgresDialect{})
+}
+
+// TestMySQLStoreConformance runs the shared Store suite against a live MySQL
+// or MariaDB server. It is skipped unless FOURN6TIME_TEST_MYSQL_DSN points at
+// an empty, disposable database, for example:
+//
+//	docker run -d -p 3306:3306 -e MARIADB_ROOT_PASSWORD=pw -e MARIADB_DATABASE=l2t mariadb
+//	FOURN6TIME_TEST_MYSQL_DSN=mysql://root:pw@127.0.0.1:3306/l2t go test ./internal/database
+func TestMySQLStoreConformance(t *testing.T) {
+	runServerStoreConformance(t, "mysql", "FOURN6TIME_TEST_MYSQL_DSN", &MySQLDialect{})
+}
+
+// runServerStoreConformance runs the suite against a database server named by
+// an environment variable. Each subtest creates the schema from scratch and
+// drops every table afterwards so subtests cannot see each other's rows.
+func runServerStoreConformance(t *testing.T, driver, envVar string, d Dialect) {
+	dsn := os.Getenv(envVar)
+	if dsn == "" {
+		t.Skip(envVar + " not set")
+	}
+	dropAll := func() {
+		conn, err := sql.Open(d.DriverName(), d.DSN(dsn))
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for _, table := range storeTestTables() {
+			conn.Exec("DROP TABLE IF EXISTS " + table)
+		}
+	}
+	dropAll()
+
+	runStoreConformance(t, storeBackend{
+		dialect: d,
+		newStore: func(t *testing.T) Store {
+			db, err := CreateStore(driver, dsn, nil)
+			if err != nil {
+				t.Fatalf("CreateStore(%s) failed: %v", driver, err)
+			}
+			// Cleanups run last-in first-out: close the store, then drop.
+			t.Cleanup(dropAll)
+			t.Cleanup(func() { db.Close() })
+			return db
+		},
+	})
+}
+
+// storeTestTables lists every table createSchema creates, for test cleanup.
+func storeTestTables() []string {
+	tables := []string{"log2timeline", "l2t_tags", "l2t_saved_query", "l2t_disk", "examiner_notes", "l2t_provenance", "schema_version",
+		"case_info", "evidence_items", "import_batches", "tags", "event_tags", "audit_log", "color_rules"}
+	for _, f := range metadataFields {
+		tables = append(tables, "l2t_"+f+"s")
+	}
+	return tables
+}
+
+// conformanceEvents returns three events on two hosts spread over two days,
+// with distinct datetimes so every backend sorts them identically.
+func conformanceEvents() []*model.Event {
+	a := sampleEvent()
+	a.Datetime = "2025-01-15 10:30:00"
+	a.Desc = "alpha"
+
+	b := sampleEvent()
+	b.Datetime = "2025-01-15 12:00:00"
+	b.Desc = "bravo"
+	b.Source = "REG"
+	b.Tag = "persistence"
+
+	c := sampleEvent()
+	c.Datetime = "2025-01-16 08:15:00"
+	c.Desc = "charlie"
+	c.Host = "SERVER1"
+	c.User = "svc"
+	c.Tag = "lateral,persistence"
+
+	return []*model.Event{a, b, c}
+}
+
+// insertConformanceEvents loads conformanceEvents into s and returns the
+// stored events in datetime order.
+func insertConformanceEvents(t *testing.T, s Store) []*model.Event {
+	t.Helper()
+	if _, err := s.InsertEvents(conformanceEvents(), nil); err != nil {
+		t.Fatalf("InsertEvents failed: %v", err)
+	}
+	events, err := s.QueryEvents("", nil, "datetime", 0, 0)
+	if err != nil {
+		t.Fatalf("QueryEvents failed: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 stored events, got %d", len(events))
+	}
+	return events
+}
+
+// runStoreConformance checks that a Store implementation behaves like every
+// other backend. Assertions normalize datetimes because PostgreSQL returns
+// TIMESTAMP columns in RFC 3339 form.
+func runStoreConformance(t *testing.T, b storeBackend) {
+	d := b.dialect
+	ph := d.Placeholder
+
+	t.Run("InsertAndQueryEvents", func(t *testing.T) {
+		s := b.newStore(t)
+		if err := s.InsertEvent(sampleEvent()); err != nil {
+			t.Fatalf("InsertEvent failed: %v", err)
+		}
+		var progress []int
+		events := make([]*model.Event, 10001)
+		for i := range events {
+			events[i] = sampleEvent()
+		}
+		n, err := s.InsertEvents(events, func(count int) { progress = append(progress, count) })
+		if err != nil || n != len(events) {
+			t.Fatalf("InsertEvents = %d, %v; want %d", n, err, len(events))
+		}
+		if len(progress) != 1 || progress[0] != 10000 {
+			t.Errorf("expected one progress callback at 10000, got %v", progress)
+		}
+
+		got, err := s.QueryEvents("", nil, "", 1, 0)
+		if err != nil {
+			t.Fatalf("QueryEvents failed: %v", err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("expected 1 event with limit, got %d", len(got))
+		}
+		e := got[0]
+		want := sampleEvent()
+		if e.ID <= 0 || e.Host != want.Host || e.User != want.User || e.Desc != want.Desc ||
+			e.StoreNumber != want.StoreNumber || e.UserSID != want.UserSID ||
+			normalizeMergedDatetime(e.Datetime) != want.Datetime {
+			t.Errorf("event did not round-trip: %+v", e)
+		}
+	})
+
+	t.Run("QueryEventsFilterOrderPaging", func(t *testing.T) {
+		s := b.newStore(t)
+		insertConformanceEvents(t, s)
+
+		where := d.QuoteColumn("user") + " = " + ph(1)
+		got, err := s.QueryEvents(where, []interface{}{"admin"}, d.QuoteColumn("desc")+" DESC", 0, 0)
+		if err != nil {
+			t.Fatalf("QueryEvents failed: %v", err)
+		}
+		if len(got) != 2 || got[0].Desc != "bravo" || got[1].Desc != "alpha" {
+			t.Errorf("expected bravo, alpha; got %+v", got)
+		}
+
+		page, err := s.QueryEvents("", nil, "datetime", 1, 1)
+		if err != nil {
+			t.Fatalf("QueryEvents failed: %v", err)
+		}
+		if len(page) != 1 || page[0].Desc != "bravo" {
+			t.Errorf("expected second event on page 2, got %+v", page)
+		}
+
+		count, err := s.CountEvents("host = "+ph(1), []interface{}{"SERVER1"})
+		if err != nil || count != 1 {
+			t.Errorf("CountEvents = %d, %v; want 1", count, err)
+		}
+		total, err := s.CountEvents("", nil)
+		if err != nil || total != 3 {
+			t.Errorf("CountEvents = %d, %v; want 3", total, err)
+		}
+	})
+
+	t.Run("UpdateEventAndBookmark", func(t *testing.T) {
+		s := b.newStore(t)
+		events := insertConformanceEvents(t, s)
+		id := events[0].ID
+
+		err := s.UpdateEvent(id, map[string]interface{}{"desc": "edited", "user": "root", "color": "RED"})
+		if err != nil {
+			t.Fatalf("UpdateEvent failed: %v", err)
+		}
+		if err := s.UpdateEvent(id, map[string]interface{}{"bogus": "x"}); err == nil {
+			t.Error("expected error for invalid field")
+		}
+
+		if v, err := s.ToggleBookmark(id); err != nil || v != 1 {
+			t.Errorf("ToggleBookmark = %d, %v; want 1", v, err)
+		}
+		if v, err := s.ToggleBookmark(id); err != nil || v != 0 {
+			t.Errorf("ToggleBookmark = %d, %v; want 0", v, err)
+		}
+
+		got, _ := s.QueryEvents(d.IDColumn()+" = "+ph(1), []interface{}{id}, "", 0, 0)
+		if len(got) != 1 || got[0].Desc != "edited" || got[0].User != "root" || got[0].Color != "RED" {
+			t.Errorf("update not applied: %+v", got)
+		}
+	})
+
+	t.Run("ExecuteQueryExaminerNotesUnion", func(t *testing.T) {
+		s := b.newStore(t)
+		insertConformanceEvents(t, s)
+		noteID, err := s.InsertExaminerNote("2025-01-15 11:00:00", "analyst note", "triage", "BLUE")
+		if err != nil {
+			t.Fatalf("InsertExaminerNote failed: %v", err)
+		}
+		if noteID >= 0 {
+			t.Fatalf("expected negative note id, got %d", noteID)
+		}
+
+		q := query.New(100)
+		q.SetDialect(d)
+		q.OrderBy("datetime")
+		sqlStr, args := q.Build()
+		all, err := s.ExecuteQuery(sqlStr, args)
+		if err != nil {
+			t.Fatalf("ExecuteQuery failed: %v", err)
+		}
+		var descs []string
+		for _, e := range all {
+			descs = append(descs, e.Desc)
+		}
+		if strings.Join(descs, ",") != "alpha,analyst note,bravo,charlie" {
+			t.Errorf("expected note interleaved by datetime, got %v", descs)
+		}
+		note := all[1]
+		if note.ID != noteID || note.Source != "EXAMINER" || note.SourceType != "Examiner Note" ||
+			note.Tag != "triage" || note.Color != "BLUE" {
+			t.Errorf("unexpected examiner note row: %+v", note)
+		}
+		// Pattern B scan order puts datetime second; a misaligned scan would
+		// land the timezone here instead.
+		if normalizeMergedDatetime(all[0].Datetime) != "2025-01-15 10:30:00" || all[0].Timezone != "UTC" {
+			t.Errorf("Pattern B columns misaligned: %+v", all[0])
+		}
+
+		countSQL, countArgs := q.BuildCount()
+		if n, err := s.ExecuteCountQuery(countSQL, countArgs); err != nil || n != 4 {
+			t.Errorf("ExecuteCountQuery = %d, %v; want 4", n, err)
+		}
+
+		// Paging applies to events and notes together
+		q2 := query.New(2)
+		q2.SetDialect(d)
+		q2.OrderBy("datetime")
+		q2.SetPage(2)
+		sqlStr, args = q2.Build()
+		page, err := s.ExecuteQuery(sqlStr, args)
+		if err != nil {
+			t.Fatalf("ExecuteQuery failed: %v", err)
+		}
+		if len(page) != 2 || page[0].Desc != "bravo" {
+			t.Errorf("expected bravo to start page 2, got %+v", page)
+		}
+
+		// A literal source filter other than EXAMINER drops the union
+		rq := query.New(100)
+		rq.SetDialect(d)
+		rq.OrderBy("datetime")
+		sqlStr, _ = rq.Build()
+		sqlStr = strings.Replace(sqlStr, " ORDER BY ", " WHERE source = 'REG' ORDER BY ", 1)
+		reg, err := s.ExecuteQuery(sqlStr, nil)
+		if err != nil {
+			t.Fatalf("ExecuteQuery failed: %v", err)
+		}
+		if len(reg) != 1 || reg[0].Desc != "bravo" {
+			t.Errorf("expected only the REG event, got %+v", reg)
+		}
+		countSQL = "SELECT COUNT(" + d.IDColumn() + ") FROM log2timeline WHERE source = 'REG'"
+		if n, err := s.ExecuteCountQuery(countSQL, nil); err != nil || n != 1 {
+			t.Errorf("ExecuteCountQuery = %d, %v; want 1", n, err)
+		}
+
+		// Named parameters are bound as arguments, not spliced into the SQL.
+		// The source is no longer literal, so examiner notes are unioned in.
+		matching := func(source string) []string {
+			pred, err := query.ParseWithParams("source = :source", map[string]string{"source": source})
+			if err != nil {
+				t.Fatalf("ParseWithParams failed: %v", err)
+			}
+			q := query.New(100)
+			q.SetDialect(d)
+			q.AddPredicate(pred)
+			q.OrderBy("datetime")
+			sqlStr, args := q.Build()
+			got, err := s.ExecuteQuery(sqlStr, args)
+			if err != nil {
+				t.Fatalf("ExecuteQuery failed: %v", err)
+			}
+			var descs []string
+			for _, e := range got {
+				if e.Source != "EXAMINER" {
+					descs = append(descs, e.Desc)
+				}
+			}
+			return descs
+		}
+		if got := matching("REG' OR '1'='1"); len(got) != 0 {
+			t.Errorf("expected no events for a literal parameter value, got %v", got)
+		}
+		if got := matching("REG"); len(got) != 1 || got[0] != "bravo" {
+			t.Errorf("expected the REG event for a bound parameter, got %v", got)
+		}
+	})
+
+	t.Run("NumericTextRanges", func(t *testing.T) {
+		s := b.newStore(t)
+		var events []*model.Event
+		for _, id := range []string{"4624", "4625", "46245", "500", "", "n/a"} {
+			e := sampleEvent()
+			e.EventID = id
+			events = append(events, e)
+		}
+		if _, err := s.InsertEvents(events, nil); err != nil {
+			t.Fatalf("InsertEvents failed: %v", err)
+		}
+		for _, tt := range []struct {
+			pred *query.Predicate
+			want int64
+		}{
+			{query.Between("event_identifier", "4624", "4625"), 2},
+			{query.Compare("event_identifier", query.GreaterThan, "4624"), 2},
+			{query.Compare("event_identifier", query.LessThan, "4624"), 1},
+		} {
+			where, args, _ := tt.pred.WhereClauseFor(d, 1)
+			if n, err := s.CountEvents(where, args); err != nil || n != tt.want {
+				t.Errorf("CountEvents(%s) = %d, %v; want %d", where, n, err, tt.want)
+			}
+		}
+	})
+
+	t.Run("MultiColumnSort", func(t *testing.T) {
+		s := b.newStore(t)
+		insertConformanceEvents(t, s)
+		// Two more events in the same second as bravo, inserted after it
+		var ties []*model.Event
+		for _, desc := range []string{"echo", "foxtrot"} {
+			e := sampleEvent()
+			e.Datetime = "2025-01-15 12:00:00"
+			e.Desc = desc
+			ties = append(ties, e)
+		}
+		if _, err := s.InsertEvents(ties, nil); err != nil {
+			t.Fatalf("InsertEvents failed: %v", err)
+		}
+		if _, err := s.InsertExaminerNote("2025-01-15 11:00:00", "analyst note", "", ""); err != nil {
+			t.Fatalf("InsertExaminerNote failed: %v", err)
+		}
+
+		sorted := func(pageSize, page int) []string {
+			q := query.New(pageSize)
+			q.SetDialect(d)
+			if err := q.SetSort(query.SortKey{Field: "host"}, query.SortKey{Field: "datetime", Desc: true}); err != nil {
+				t.Fatalf("SetSort failed: %v", err)
+			}
+			q.SetPage(page)
+			sqlStr, args := q.Build()
+			got, err := s.ExecuteQuery(sqlStr, args)
+			if err != nil {
+				t.Fatalf("ExecuteQuery failed: %v", err)
+			}
+			var descs []string
+			for _, e := range got {
+				descs = append(descs, e.Desc)
+			}
+			return descs
+		}
+
+		// The note's empty host sorts first; ties on both keys fall back to id
+		want := "analyst note,charlie,bravo,echo,foxtrot,alpha"
+		if got := strings.Join(sorted(100, 1), ","); got != want {
+			t.Errorf("sorted = %s, want %s", got, want)
+		}
+		var paged []string
+		for page := 1; page <= 3; page++ {
+			paged = append(paged, sorted(2, page)...)
+		}
+		if got := strings.Join(paged, ","); got != want {
+			t.Errorf("paged = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("ParsedPredicates", func(t *testing.T) {
+		s := b.newStore(t)
+		insertConformanceEvents(t, s)
+
+		tests := []struct {
+			input string
+			want  string
+		}{
+			{"source IN ('REG', 'X')", "bravo"},
+			{"desc NOT IN ('alpha', 'bravo')", "charlie"},
+			{"datetime BETWEEN '2025-01-15 11:00:00' AND '2025-01-16 00:00:00'", "bravo"},
+			{"tag IS EMPTY", "alpha"},
+			{"NOT (host = 'SERVER1' OR source = 'REG')", "alpha"},
+			{"user <> 'admin' AND desc LIKE 'ch%'", "charlie"},
+		}
+		for _, tt := range tests {
+			pred, err := query.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.input, err)
+			}
+			q := query.New(100)
+			q.SetDialect(d)
+			q.AddPredicate(pred)
+			q.OrderBy("datetime")
+			sqlStr, args := q.Build()
+			got, err := s.ExecuteQuery(sqlStr, args)
+			if err != nil {
+				t.Fatalf("ExecuteQuery(%q) failed: %v", tt.input, err)
+			}
+			var descs []string
+			for _, e := range got {
+				descs = append(descs, e.Desc)
+			}
+			if strings.Join(descs, ",") != tt.want {
+				t.Errorf("%s matched %v, want %s", tt.input, descs, tt.want)
+			}
+		}
+	})
+
+	t.Run("FilterOperators", func(t *testing.T) {
+		s := b.newStore(t)
+		insertConformanceEvents(t, s)
+
+		tests := []struct {
+			field string
+			op    query.Operator
+			value string
+			want  string
+		}{
+			{"desc", query.InList, "alpha, charlie", "alpha,charlie"},
+			{"desc", query.NotInList, "alpha", "bravo,charlie"},
+			{"datetime", query.InRange, "2025-01-15 11:00:00,2025-01-16 00:00:00", "bravo"},
+			{"tag", query.Empty, "", "alpha"},
+			{"tag", query.NotEmpty, "", "bravo,charlie"},
+			{"desc", query.Regexp, "^(alpha|ch.*e)$", "alpha,charlie"},
+			{"host", query.NotRegexp, "[0-9]$", ""},
+			{"host", query.Regexp, "server", ""},
+			{"host", query.LikeCase, "SERV", "charlie"},
+			{"host", query.LikeCase, "serv", ""},
+		}
+		for _, tt := range tests {
+			pred := query.Simple(tt.field, tt.op, tt.value)
+			if pred == nil {
+				t.Fatalf("Simple(%s %s %q) returned nil", tt.field, tt.op, tt.value)
+			}
+			where, args, _ := pred.WhereClauseFor(d, 1)
+			got, err := s.QueryEvents(where, args, "datetime", 0, 0)
+			if err != nil {
+				t.Fatalf("%s %s %q failed: %v", tt.field, tt.op, tt.value, err)
+			}
+			var descs []string
+			for _, e := range got {
+				descs = append(descs, e.Desc)
+			}
+			if strings.Join(descs, ",") != tt.want {
+				t.Errorf("%s %s %q matched %v, want %s", tt.field, tt.op, tt.value, descs, tt.want)
+			}
+		}
+
+		// An invalid pattern is reported rather than matching nothing
+		where, args, _ := query.Simple("desc", query.Regexp, "(").WhereClauseFor(d, 1)
+		if _, err := s.QueryEvents(where, args, "", 0, 0); err == nil {
+			t.Error("expected an error for an invalid regular expression")
+		}
+	})
+
+	t.Run("FieldQueries", func(t *testing.T) {
+		s := b.newStore(t)
+		insertConformanceEvents(t, s)
+
+		tests := []struct {
+			input string
+			want  string
+		}{
+			{"host:WORKSTATION1 AND user:adm* AND NOT source:REG", "alpha"},
+			{`desc:(alpha OR "charlie")`, "alpha,charlie"},
+			{`datetime:["2025-01-15 11:00:00" TO *]`, "bravo,charlie"},
+			{"tag:persistence -host:SERVER1", "bravo"},
+			{"host:SERVER?", "charlie"},
+		}
+		for _, tt := range tests {
+			pred, err := querylang.Parse(tt.input, nil)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.input, err)
+			}
+			where, args, _ := pred.WhereClauseFor(d, 1)
+			got, err := s.QueryEvents(where, args, "datetime", 0, 0)
+			if err != nil {
+				t.Fatalf("QueryEvents(%q) failed: %v", tt.input, err)
+			}
+			var descs []string
+			for _, e := range got {
+				descs = append(descs, e.Desc)
+			}
+			if strings.Join(descs, ",") != tt.want {
+				t.Errorf("%s matched %v, want %s", tt.input, descs, tt.want)
+			}
+		}
+	})
+
+	t.Run("FilterSetAgreement", func(t *testing.T) {
+		s := b.newStore(t)
+		events := insertConformanceEvents(t, s)
+		if _, err := s.ToggleBookmark(events[1].ID); err != nil {
+			t.Fatalf("ToggleBookmark failed: %v", err)
+		}
+		// A zeroed FILETIME: listed by the grid, left off the chart
+		junk := sampleEvent()
+		junk.Datetime = "1601-01-01 00:00:00"
+		junk.Desc = "delta"
+		if _, err := s.InsertEvents([]*model.Event{junk}, nil); err != nil {
+			t.Fatalf("InsertEvents failed: %v", err)
+		}
+		expand := func(value string, end bool) string {
+			if len(value) == len("2025-01-15") && end {
+				return value + " 23:59:59"
+			}
+			return value
+		}
+
+		tests := []struct {
+			name string
+			set  query.FilterSet
+			want string
+		}{
+			{"everything", query.FilterSet{}, "delta,alpha,bravo,charlie"},
+			{"filters", query.FilterSet{FilterGroup: query.FilterGroup{Filters: []query.Filter{
+				{Field: "host", Operator: "=", Value: "WORKSTATION1"},
+				{Field: "datetime", Operator: "<=", Value: "2025-01-15"},
+			}}}, "delta,alpha,bravo"},
+			{"groups", query.FilterSet{FilterGroup: query.FilterGroup{Logic: "OR",
+				Filters: []query.Filter{{Field: "desc", Operator: "=", Value: "delta"}},
+				Groups: []query.FilterGroup{{Filters: []query.Filter{
+					{Field: "source", Operator: "=", Value: "REG"},
+					{Field: "tag", Operator: "has tag", Value: "persistence"},
+				}}},
+			}}, "delta,bravo"},
+			{"invalid field skipped", query.FilterSet{FilterGroup: query.FilterGroup{Filters: []query.Filter{
+				{Field: "desc; DROP TABLE log2timeline", Operator: "=", Value: "x"},
+				{Field: "host", Operator: "=", Value: "SERVER1"},
+			}}}, "charlie"},
+			{"search", query.FilterSet{SearchText: "charlie"}, "charlie"},
+			{"bookmarks", query.FilterSet{FilterGroup: query.FilterGroup{Logic: "OR", Filters: []query.Filter{
+				{Field: "desc", Operator: "=", Value: "alpha"},
+				{Field: "desc", Operator: "=", Value: "bravo"},
+			}}, BookmarkOnly: true}, "bravo"},
+		}
+		for _, tt := range tests {
+			pred := tt.set.Predicate(expand)
+
+			// The grid and the export run the same query, paged or not
+			q := query.New(0)
+			q.SetDialect(d)
+			q.AddPredicate(pred)
+			q.OrderBy("datetime")
+			sqlStr, args := q.Build()
+			got, err := s.ExecuteQuery(sqlStr, args)
+			if err != nil {
+				t.Fatalf("%s: ExecuteQuery failed: %v", tt.name, err)
+			}
+			var descs []string
+			var charted int64
+			for _, e := range got {
+				descs = append(descs, e.Desc)
+				if e.Desc != "delta" {
+					charted++
+				}
+			}
+			if strings.Join(descs, ",") != tt.want {
+				t.Errorf("%s: grid matched %v, want %s", tt.name, descs, tt.want)
+			}
+
+			countSQL, countArgs := q.BuildCount()
+			if n, err := s.ExecuteCountQuery(countSQL, countArgs); err != nil || n != int64(len(got)) {
+				t.Errorf("%s: count = %d, %v; want %d", tt.name, n, err, len(got))
+			}
+
+			where, whereArgs, _ := query.Combine([]*query.Predicate{query.Chartable(), pred}, query.AND).WhereClauseFor(d, 1)
+			buckets, err := s.GetTimelineHistogram("WHERE "+where, whereArgs)
+			if err != nil {
+				t.Fatalf("%s: GetTimelineHistogram failed: %v", tt.name, err)
+			}
+			var total int64
+			for _, bk := range buckets {
+				total += bk.Count
+			}
+			if total != charted {
+				t.Errorf("%s: histogram counted %d events, want %d", tt.name, total, charted)
+			}
+		}
+	})
+
+	t.Run("Pivots", func(t *testing.T) {
+		s := b.newStore(t)
+		var events []*model.Event
+		for i, u := range []string{
+			"https://evil.example/payload",
+			"http://user@evil.example:8080",
+			"https://evil.example.net/",
+			"https://good.example/?next=https://evil.example/",
+		} {
+			e := sampleEvent()
+			e.Datetime = fmt.Sprintf("2025-01-15 10:%02d:00", i)
+			e.Desc = fmt.Sprintf("url%d", i)
+			e.URL = u
+			if i%2 == 1 {
+				e.SourceType = "WEBHIST"
+			}
+			events = append(events, e)
+		}
+		if _, err := s.InsertEvents(events, nil); err != nil {
+			t.Fatalf("InsertEvents failed: %v", err)
+		}
+
+		pred, host, err := query.Pivot(events[0], query.URLDomain)
+		if err != nil || host != "evil.example" {
+			t.Fatalf("Pivot(url_domain) = %q, %v", host, err)
+		}
+		where, args, _ := pred.WhereClauseFor(d, 1)
+		got, err := s.QueryEvents(where, args, "datetime", 0, 0)
+		if err != nil {
+			t.Fatalf("QueryEvents failed: %v", err)
+		}
+		var descs []string
+		for _, e := range got {
+			descs = append(descs, e.Desc)
+		}
+		// The query string of url3 names the host too
+		if strings.Join(descs, ",") != "url0,url1,url3" {
+			t.Errorf("url_domain pivot matched %v", descs)
+		}
+
+		counts, err := s.CountByField("sourcetype", where, args)
+		if err != nil {
+			t.Fatalf("CountByField failed: %v", err)
+		}
+		if len(counts) != 2 || counts["OS:NTFS:MFT"] != 1 || counts["WEBHIST"] != 2 {
+			t.Errorf("CountByField = %v", counts)
+		}
+		if _, err := s.CountByField("nosuch", "", nil); err == nil {
+			t.Error("expected an error for an invalid field")
+		}
+
+		pred, _, _ = query.Pivot(events[0], "user_sid")
+		where, args, _ = pred.WhereClauseFor(d, 1)
+		if n, err := s.CountEvents(where, args); err != nil || n != 4 {
+			t.Errorf("user_sid pivot counted %d, %v; want 4", n, err)
+		}
+		var batches [][]int64
+		err = s.EachMatchingID(where, args, 3, func(ids []int64) error {
+			batches = append(batches, ids)
+			return nil
+		})
+		if err != nil || len(batches) != 2 || len(batches[0]) != 3 || len(batches[1]) != 1 {
+			t.Fatalf("EachMatchingID = %v, %v; want batches of 3 and 1 ids", batches, err)
+		}
+		if batches[1][0] <= batches[0][2] {
+			t.Errorf("EachMatchingID batches out of id order: %v", batches)
+		}
+	})
+
+	t.Run("Sigma", func(t *testing.T) {
+		s := b.newStore(t)
+		var events []*model.Event
+		for i, ev := range []struct{ provider, id, extra string }{
+			{"Microsoft-Windows-Sysmon", "1", "CommandLine: m.exe sekurlsa::logonpasswords; Image: m.exe"},
+			{"Microsoft-Windows-Sysmon", "1", "CommandLine: notepad.exe"},
+			{"Microsoft-Windows-Security-Auditing", "4688", "CommandLine: m.exe sekurlsa::pth"},
+			{"Microsoft-Windows-Sysmon", "1", `Image: C:\Windows\Temp\50%_Mimi.EXE`},
+			{"Microsoft-Windows-Sysmon", "1", `Image: C:\Windows\Temp\5000_mimi.exe`},
+		} {
+			e := sampleEvent()
+			e.Datetime = fmt.Sprintf("2025-01-15 10:%02d:00", i)
+			e.Desc = fmt.Sprintf("proc%d", i)
+			e.SourceName = ev.provider
+			e.EventID = ev.id
+			e.Extra = ev.extra
+			events = append(events, e)
+		}
+		if _, err := s.InsertEvents(events, nil); err != nil {
+			t.Fatalf("InsertEvents failed: %v", err)
+		}
+
+		for rule, want := range map[string]string{
+			"logsource: {service: sysmon}\ndetection:\n  sel:\n    EventID: 1\n    CommandLine|contains: 'sekurlsa::'\n  condition: sel\n": "proc0",
+			"detection:\n  sel:\n    CommandLine|re: 'sekurlsa::[a-z]+'\n  filter:\n    EventID: 1\n  condition: sel and not filter\n":     "proc2",
+			// Backslashes, % and _ match themselves, and case is ignored
+			"logsource: {service: sysmon}\ndetection:\n  sel:\n    Image|endswith: '\\temp\\50%_mimi.exe'\n  condition: sel\n": "proc3",
+		} {
+			r, err := sigma.Parse([]byte("title: t\n" + rule))
+			if err != nil {
+				t.Fatal(err)
+			}
+			pred, err := sigma.Compile(r, sigma.DefaultFields)
+			if err != nil {
+				t.Fatalf("Compile failed: %v", err)
+			}
+			where, args, _ := pred.WhereClauseFor(d, 1)
+			got, err := s.QueryEvents(where, args, "datetime", 0, 0)
+			if err != nil {
+				t.Fatalf("QueryEvents failed: %v", err)
+			}
+			if len(got) != 1 || got[0].Desc != want {
+				t.Errorf("rule matched %d events, want only %s:\n%s", len(got), want, rule)
+			}
+		}
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		s := b.newStore(t)
+		events := insertConformanceEvents(t, s)
+
+		// Backends with a full-text index and the LIKE fallback must agree
+		q := query.New(0)
+		q.SetDialect(d)
+		q.AddPredicate(query.Search("charlie"))
+		sqlStr, args := q.Build()
+		got, err := s.ExecuteQuery(sqlStr, args)
+		if err != nil {
+			t.Fatalf("ExecuteQuery failed: %v", err)
+		}
+		if len(got) != 1 || got[0].Desc != "charlie" {
+			t.Errorf("expected only charlie, got %+v", got)
+		}
+
+		ids := []int64{events[0].ID, events[1].ID, events[2].ID}
+		matches, err := s.SearchSnippets("charlie", ids)
+		if err != nil {
+			t.Fatalf("SearchSnippets failed: %v", err)
+		}
+		for _, m := range matches {
+			if m.ID != events[2].ID {
+				t.Errorf("snippet returned for non-matching event: %+v", m)
+			}
+		}
+	})
+
+	t.Run("KeysetPagination", func(t *testing.T) {
+		s := b.newStore(t)
+		insertConformanceEvents(t, s)
+		// Ties on datetime, including between an event and a note, exercise
+		// the id tiebreaker; the note's negative id sorts first.
+		extra := conformanceEvents()
+		for i, e := range extra {
+			e.Desc = fmt.Sprintf("tie%d", i)
+			e.Datetime = "2025-01-15 12:00:00"
+		}
+		if _, err := s.InsertEvents(extra, nil); err != nil {
+			t.Fatalf("InsertEvents failed: %v", err)
+		}
+		if _, err := s.InsertExaminerNote("2025-01-15 12:00:00", "tied note", "", ""); err != nil {
+			t.Fatalf("InsertExaminerNote failed: %v", err)
+		}
+		if _, err := s.InsertExaminerNote("2025-01-14 09:00:00", "early note", "", ""); err != nil {
+			t.Fatalf("InsertExaminerNote failed: %v", err)
+		}
+		want := "early note,alpha,tied note,bravo,tie0,tie1,tie2,charlie"
+
+		fetch := func(mode query.PageMode, c *query.Cursor) ([]*model.Event, *query.Query) {
+			t.Helper()
+			q := query.New(3)
+			q.SetDialect(d)
+			q.OrderBy("datetime")
+			if err := q.SetKeyset(mode, c); err != nil {
+				t.Fatalf("SetKeyset failed: %v", err)
+			}
+			sqlStr, args := q.Build()
+			events, err := s.ExecuteQuery(sqlStr, args)
+			if err != nil {
+				t.Fatalf("ExecuteQuery failed: %v", err)
+			}
+			return events, q
+		}
+		descs := func(events []*model.Event) []string {
+			var out []string
+			for _, e := range events {
+				out = append(out, e.Desc)
+			}
+			return out
+		}
+
+		// Forward from the first page, round-tripping cursors through Encode
+		var forward []string
+		page, q := fetch(query.PageFirst, nil)
+		for i := 0; len(page) > 0 && i < 10; i++ {
+			forward = append(forward, descs(page)...)
+			c, err := query.DecodeCursor(q.CursorFor(page[len(page)-1]).Encode())
+			if err != nil {
+				t.Fatalf("DecodeCursor failed: %v", err)
+			}
+			page, q = fetch(query.PageAfter, c)
+		}
+		if strings.Join(forward, ",") != want {
+			t.Errorf("forward pages = %v, want %s", forward, want)
+		}
+
+		// Backward from the last page; each page is still in ascending order
+		var backward []string
+		page, q = fetch(query.PageLast, nil)
+		if strings.Join(descs(page), ",") != "tie1,tie2,charlie" {
+			t.Errorf("last page = %v", descs(page))
+		}
+		for i := 0; len(page) > 0 && i < 10; i++ {
+			backward = append(descs(page), backward...)
+			page, q = fetch(query.PageBefore, q.CursorFor(page[0]))
+		}
+		if strings.Join(backward, ",") != want {
+			t.Errorf("backward pages = %v, want %s", backward, want)
+		}
+
+		// Seeking starts at the first row at or after the datetime
+		page, _ = fetch(query.PageAfter, query.SeekCursor("datetime", "2025-01-15 12:00:00"))
+		if strings.Join(descs(page), ",") != "tied note,bravo,tie0" {
+			t.Errorf("seek page = %v", descs(page))
+		}
+
+		// Pages either side of a note read as GetExaminerNotes returns it,
+		// as the event context window does
+		notes, err := s.GetExaminerNotes()
+		if err != nil || len(notes) != 2 || notes[1].Desc != "tied note" {
+			t.Fatalf("GetExaminerNotes = %+v, %v", notes, err)
+		}
+		page, _ = fetch(query.PageBefore, q.CursorFor(notes[1]))
+		if strings.Join(descs(page), ",") != "early note,alpha" {
+			t.Errorf("page before note = %v", descs(page))
+		}
+		page, _ = fetch(query.PageAfter, q.CursorFor(notes[1]))
+		if strings.Join(descs(page), ",") != "bravo,tie0,tie1" {
+			t.Errorf("page after note = %v", descs(page))
+		}
+
+		// Descending and compound sorts page through the rows in the order
+		// the unpaged query returns them, both ways
+		for _, keys := range [][]query.SortKey{
+			{{Field: "datetime", Desc: true}},
+			{{Field: "source"}, {Field: "datetime", Desc: true}},
+		} {
+			sorted := func(mode query.PageMode, c *query.Cursor, size int) ([]*model.Event, *query.Query) {
+				t.Helper()
+				q := query.New(size)
+				q.SetDialect(d)
+				q.SetSort(keys...)
+				if size > 0 {
+					if err := q.SetKeyset(mode, c); err != nil {
+						t.Fatalf("SetKeyset failed: %v", err)
+					}
+				}
+				sqlStr, args := q.Build()
+				events, err := s.ExecuteQuery(sqlStr, args)
+				if err != nil {
+					t.Fatalf("ExecuteQuery failed: %v", err)
+				}
+				return events, q
+			}
+			all, _ := sorted(query.PageOffset, nil, 0)
+			want := strings.Join(descs(all), ",")
+
+			forward = nil
+			page, q = sorted(query.PageFirst, nil, 3)
+			for i := 0; len(page) > 0 && i < 10; i++ {
+				forward = append(forward, descs(page)...)
+				c, err := query.DecodeCursor(q.CursorFor(page[len(page)-1]).Encode())
+				if err != nil {
+					t.Fatalf("DecodeCursor failed: %v", err)
+				}
+				page, q = sorted(query.PageAfter, c, 3)
+			}
+			if strings.Join(forward, ",") != want {
+				t.Errorf("%v forward pages = %v, want %s", keys, forward, want)
+			}
+
+			backward = nil
+			page, q = sorted(query.PageLast, nil, 3)
+			for i := 0; len(page) > 0 && i < 10; i++ {
+				backward = append(descs(page), backward...)
+				page, q = sorted(query.PageBefore, q.CursorFor(page[0]), 3)
+			}
+			if strings.Join(backward, ",") != want {
+				t.Errorf("%v backward pages = %v, want %s", keys, backward, want)
+			}
+		}
+	})
+
+	t.Run("ExaminerNotes", func(t *testing.T) {
+		s := b.newStore(t)
+		later, err := s.InsertExaminerNote("2025-01-16 09:00:00", "second", "", "")
+		if err != nil {
+			t.Fatalf("InsertExaminerNote failed: %v", err)
+		}
+		earlier, err := s.InsertExaminerNote("2025-01-15 09:00:00", "first", "", "")
+		if err != nil {
+			t.Fatalf("InsertExaminerNote failed: %v", err)
+		}
+
+		if err := s.UpdateExaminerNoteColor(-earlier, "GREEN"); err != nil {
+			t.Fatalf("UpdateExaminerNoteColor failed: %v", err)
+		}
+		if v, err := s.ToggleExaminerNoteBookmark(-earlier); err != nil || v != 1 {
+			t.Errorf("ToggleExaminerNoteBookmark = %d, %v; want 1", v, err)
+		}
+
+		notes, err := s.GetExaminerNotes()
+		if err != nil {
+			t.Fatalf("GetExaminerNotes failed: %v", err)
+		}
+		if len(notes) != 2 || notes[0].ID != earlier || notes[1].ID != later {
+			t.Fatalf("expected notes ordered by datetime, got %+v", notes)
+		}
+		if notes[0].Color != "GREEN" || notes[0].Bookmark != 1 || notes[0].Source != "EXAMINER" {
+			t.Errorf("unexpected note fields: %+v", notes[0])
+		}
+
+		if err := s.DeleteExaminerNote(-later); err != nil {
+			t.Fatalf("DeleteExaminerNote failed: %v", err)
+		}
+		notes, _ = s.GetExaminerNotes()
+		if len(notes) != 1 || notes[0].Desc != "first" {
+			t.Errorf("expected only the first note to remain, got %+v", notes)
+		}
+	})
+
+	t.Run("BulkOperations", func(t *testing.T) {
+		s := b.newStore(t)
+		events := insertConformanceEvents(t, s)
+		ids := []int64{events[0].ID, events[2].ID}
+
+		if err := s.BulkUpdateColor(ids, "YELLOW"); err != nil {
+			t.Fatalf("BulkUpdateColor failed: %v", err)
+		}
+		if err := s.BulkAddTag(ids, "persistence"); err != nil {
+			t.Fatalf("BulkAddTag failed: %v", err)
+		}
+		if err := s.BulkSetBookmark(ids, 1); err != nil {
+			t.Fatalf("BulkSetBookmark failed: %v", err)
+		}
+		if err := s.BulkAddTag(nil, "ignored"); err != nil {
+			t.Errorf("BulkAddTag with no ids should be a no-op, got %v", err)
+		}
+
+		got, _ := s.QueryEvents("", nil, "datetime", 0, 0)
+		if got[0].Color != "YELLOW" || got[0].Tag != "persistence" || got[0].Bookmark != 1 {
+			t.Errorf("bulk ops not applied to first event: %+v", got[0])
+		}
+		if got[1].Color != "" || got[1].Bookmark != 0 {
+			t.Errorf("bulk ops leaked onto unselected event: %+v", got[1])
+		}
+		// The tag was already present on the third event and is not duplicated
+		if got[2].Tag != "lateral,persistence" {
+			t.Errorf("expected deduplicated tag list, got %q", got[2].Tag)
+		}
+
+		a, _ := s.InsertExaminerNote("2025-01-15 09:00:00", "a", "", "")
+		n, _ := s.InsertExaminerNote("2025-01-15 09:30:00", "b", "", "")
+		noteIDs := []int64{-a, -n}
+		if err := s.BulkUpdateExaminerNoteColor(noteIDs, "PURPLE"); err != nil {
+			t.Fatalf("BulkUpdateExaminerNoteColor failed: %v", err)
+		}
+		if err := s.BulkSetExaminerNoteBookmark(noteIDs, 1); err != nil {
+			t.Fatalf("BulkSetExaminerNoteBookmark failed: %v", err)
+		}
+		notes, _ := s.GetExaminerNotes()
+		for _, note := range notes {
+			if note.Color != "PURPLE" || note.Bookmark != 1 {
+				t.Errorf("bulk note ops not applied: %+v", note)
+			}
+		}
+	})
+
+	t.Run("DistinctValuesAndTags", func(t *testing.T) {
+		s := b.newStore(t)
+		insertConformanceEvents(t, s)
+		if _, err := s.InsertExaminerNote("2025-01-15 11:00:00", "note", "triage", ""); err != nil {
+			t.Fatalf("InsertExaminerNote failed: %v", err)
+		}
+
+		hosts, err := s.GetDistinctValues("host")
+		if err != nil {
+			t.Fatalf("GetDistinctValues failed: %v", err)
+		}
+		if len(hosts) != 2 || hosts["WORKSTATION1"] != 2 || hosts["SERVER1"] != 1 {
+			t.Errorf("unexpected host values: %v", hosts)
+		}
+
+		users, err := s.GetDistinctValues("user")
+		if err != nil || users["admin"] != 2 || users["svc"] != 1 {
+			t.Errorf("unexpected user values: %v, %v", users, err)
+		}
+
+		sources, _ := s.GetDistinctValues("source")
+		if sources["EXAMINER"] != 1 || sources["FILE"] != 2 {
+			t.Errorf("expected examiner notes counted as a source, got %v", sources)
+		}
+		sourceTypes, _ := s.GetDistinctValues("sourcetype")
+		if sourceTypes["Examiner Note"] != 1 {
+			t.Errorf("expected examiner notes counted as a sourcetype, got %v", sourceTypes)
+		}
+		tagValues, _ := s.GetDistinctValues("tag")
+		if tagValues["triage"] != 1 || tagValues["persistence"] != 1 {
+			t.Errorf("unexpected tag values: %v", tagValues)
+		}
+
+		if _, err := s.GetDistinctValues("bogus"); err == nil {
+			t.Error("expected error for invalid field")
+		}
+
+		tags, err := s.GetDistinctTags()
+		if err != nil {
+			t.Fatalf("GetDistinctTags failed: %v", err)
+		}
+		seen := make(map[string]bool)
+		for _, tag := range tags {
+			seen[tag] = true
+		}
+		if len(tags) != 2 || !seen["persistence"] || !seen["lateral"] {
+			t.Errorf("expected split, deduplicated tags, got %v", tags)
+		}
+	})
+
+	t.Run("MinMaxDateAndHistogram", func(t *testing.T) {
+		s := b.newStore(t)
+		insertConformanceEvents(t, s)
+
+		minDate, maxDate, err := s.GetMinMaxDate()
+		if err != nil {
+			t.Fatalf("GetMinMaxDate failed: %v", err)
+		}
+		if normalizeMergedDatetime(minDate) != "2025-01-15 10:30:00" || normalizeMergedDatetime(maxDate) != "2025-01-16 08:15:00" {
+			t.Errorf("GetMinMaxDate = %q, %q", minDate, maxDate)
+		}
+
+		// Two days in the same month: daily buckets
+		buckets, err := s.GetTimelineHistogram("", nil)
+		if err != nil {
+			t.Fatalf("GetTimelineHistogram failed: %v", err)
+		}
+		if len(buckets) != 2 || buckets[0].Timestamp != "2025-01-15" || buckets[0].Count != 2 ||
+			buckets[1].Timestamp != "2025-01-16" || buckets[1].Count != 1 {
+			t.Errorf("unexpected daily buckets: %+v", buckets)
+		}
+
+		// Filtered to a single day: hourly buckets
+		where := "WHERE host = " + ph(1) + " AND datetime < '2025-01-16'"
+		buckets, err = s.GetTimelineHistogram(where, []interface{}{"WORKSTATION1"})
+		if err != nil {
+			t.Fatalf("GetTimelineHistogram failed: %v", err)
+		}
+		if len(buckets) != 2 || buckets[0].Timestamp != "2025-01-15 10:00:00" || buckets[1].Timestamp != "2025-01-15 12:00:00" {
+			t.Errorf("unexpected hourly buckets: %+v", buckets)
+		}
+
+		// No matches: empty, not an error
+		buckets, err = s.GetTimelineHistogram("WHERE host = "+ph(1), []interface{}{"NOPE"})
+		if err != nil || len(buckets) != 0 {
+			t.Errorf("expected no buckets, got %+v, %v", buckets, err)
+		}
+	})
+
+	t.Run("SavedQueries", func(t *testing.T) {
+		s := b.newStore(t)
+		if err := s.SaveQuery("admins", "user = 'admin'"); err != nil {
+			t.Fatalf("SaveQuery failed: %v", err)
+		}
+		if err := s.SaveQuery("servers", "host LIKE 'SERVER%'"); err != nil {
+			t.Fatalf("SaveQuery failed: %v", err)
+		}
+		if err := s.DeleteQuery("admins"); err != nil {
+			t.Fatalf("DeleteQuery failed: %v", err)
+		}
+		queries, err := s.GetSavedQueries()
+		if err != nil {
+			t.Fatalf("GetSavedQueries failed: %v", err)
+		}
+		if len(queries) != 1 || queries[0].Name != "servers" || queries[0].Query != "host LIKE 'SERVER%'" {
+			t.Errorf("unexpected saved queries: %+v", queries)
+		}
+
+		// Upserting replaces the query of the same name with its metadata
+		pack := SavedQuery{Name: "servers", Query: "host LIKE 'SRV%'", Description: "Server logons",
+			Category: "Lateral movement", Author: "jdoe", OS: "Windows", EventIDs: "4624,4625",
+			Parameters: []QueryParameter{{Name: "start", Type: ParamDatetime, Default: "2025-01-01", Label: "From"}}}
+		if err := s.UpsertSavedQuery(pack); err != nil {
+			t.Fatalf("UpsertSavedQuery failed: %v", err)
+		}
+		queries, err = s.GetSavedQueries()
+		if err != nil {
+			t.Fatalf("GetSavedQueries failed: %v", err)
+		}
+		if len(queries) != 1 || !reflect.DeepEqual(queries[0], pack) {
+			t.Errorf("expected the upserted query, got %+v", queries)
+		}
+	})
+
+	t.Run("Provenance", func(t *testing.T) {
+		s := b.newStore(t)
+		if err := s.RecordProvenance("merge", "first"); err != nil {
+			t.Fatalf("RecordProvenance failed: %v", err)
+		}
+		if err := s.RecordProvenance("merge", "second"); err != nil {
+			t.Fatalf("RecordProvenance failed: %v", err)
+		}
+		entries, err := s.GetProvenance()
+		if err != nil {
+			t.Fatalf("GetProvenance failed: %v", err)
+		}
+		if len(entries) != 2 || entries[0].Detail != "first" || entries[1].Detail != "second" || entries[0].CreatedAt == "" {
+			t.Errorf("unexpected provenance entries: %+v", entries)
+		}
+	})
+
+	t.Run("CaseAndEvidence", func(t *testing.T) {
+		s := b.newStore(t)
+		c, err := s.GetCaseInfo()
+		if err != nil || c.CaseNumber != "" || len(c.Examiners) != 0 {
+			t.Fatalf("expected empty case, got %+v (%v)", c, err)
+		}
+		want := &CaseInfo{
+			CaseNumber: "2025-017", Title: "Lateral movement", Examiners: []string{"Doe, J.", "A. Smith"},
+			CreatedAt: "2025-03-01", ClosedAt: "",
+		}
+		if err := s.SaveCaseInfo(want); err != nil {
+			t.Fatalf("SaveCaseInfo failed: %v", err)
+		}
+		want.Title = "Lateral movement (updated)"
+		if err := s.SaveCaseInfo(want); err != nil {
+			t.Fatalf("SaveCaseInfo (update) failed: %v", err)
+		}
+		c, _ = s.GetCaseInfo()
+		if c.Title != want.Title || len(c.Examiners) != 2 || c.Examiners[0] != "Doe, J." ||
+			c.CreatedAt != "2025-03-01 00:00:00" || c.ClosedAt != "" {
+			t.Errorf("unexpected case: %+v", c)
+		}
+
+		evID, err := s.InsertEvidenceItem(&EvidenceItem{
+			Name: "WKSTN01.E01", Kind: EvidenceDiskImage, SHA256: strings.Repeat("ab", 32),
+		})
+		if err != nil {
+			t.Fatalf("InsertEvidenceItem failed: %v", err)
+		}
+		if err := s.UpdateEvidenceItem(&EvidenceItem{ID: evID, Name: "WKSTN01.E01", Kind: EvidenceDiskImage, Description: "C: drive"}); err != nil {
+			t.Fatalf("UpdateEvidenceItem failed: %v", err)
+		}
+		items, err := s.GetEvidenceItems()
+		if err != nil || len(items) != 1 || items[0].Description != "C: drive" || items[0].AddedAt == "" {
+			t.Fatalf("unexpected evidence items: %+v (%v)", items, err)
+		}
+
+		batchID, err := s.InsertImportBatch(&ImportBatch{SourcePath: "timeline.csv", Format: "CSV"})
+		if err != nil {
+			t.Fatalf("InsertImportBatch failed: %v", err)
+		}
+		events := conformanceEvents()
+		events[0].BatchID = batchID
+		events[1].BatchID = batchID
+		if _, err := s.InsertEvents(events, nil); err != nil {
+			t.Fatalf("InsertEvents failed: %v", err)
+		}
+		if err := s.SetImportBatchEvidence(batchID, evID); err != nil {
+			t.Fatalf("SetImportBatchEvidence failed: %v", err)
+		}
+		if err := s.SetImportBatchEvidence(batchID, evID+100); err == nil {
+			t.Error("expected linking to a missing evidence item to fail")
+		}
+		batches, err := s.GetImportBatches()
+		if err != nil || len(batches) != 1 || batches[0].EvidenceID != evID || batches[0].EventCount != 2 || batches[0].ImportedAt == "" {
+			t.Fatalf("unexpected import batches: %+v (%v)", batches, err)
+		}
+
+		// batch_id is a regular column for queries
+		got, err := s.QueryEvents("batch_id = "+b.dialect.Placeholder(1), []interface{}{batchID}, "", 0, 0)
+		if err != nil || len(got) != 2 || got[0].BatchID != batchID {
+			t.Errorf("expected 2 events in batch %d, got %+v (%v)", batchID, got, err)
+		}
+
+		if err := s.DeleteEvidenceItem(evID); err != nil {
+			t.Fatalf("DeleteEvidenceItem failed: %v", err)
+		}
+		batches, _ = s.GetImportBatches()
+		if len(batches) != 1 || batches[0].EvidenceID != 0 {
+			t.Errorf("expected batch to survive unlinked, got %+v", batches)
+		}
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		s := b.newStore(t)
+		events := insertConformanceEvents(t, s)
+		a, c := events[0].ID, events[2].ID
+
+		tagged := func(name string) []string {
+			t.Helper()
+			q := query.New(0)
+			q.SetDialect(d)
+			q.AddPredicate(query.Simple("tag", query.HasTag, name))
+			q.OrderBy("datetime")
+			sqlStr, args := q.Build()
+			got, err := s.ExecuteQuery(sqlStr, args)
+			if err != nil {
+				t.Fatalf("HAS TAG query failed: %v", err)
+			}
+			var descs []string
+			for _, e := range got {
+				if e.ID > 0 {
+					descs = append(descs, e.Desc)
+				}
+			}
+			return descs
+		}
+		if got := tagged("PERSISTENCE"); len(got) != 2 || got[0] != "bravo" || got[1] != "charlie" {
+			t.Errorf("expected inserted tags to be ingested and matched ignoring case, got %v", got)
+		}
+
+		// Selection-scoped rename moves only the listed events
+		if err := s.RenameTag([]int64{c}, "persistence", "Persist"); err != nil {
+			t.Fatalf("RenameTag (selection) failed: %v", err)
+		}
+		got, _ := s.QueryEvents("", nil, "datetime", 0, 0)
+		if got[1].Tag != "persistence" || got[2].Tag != "lateral,Persist" {
+			t.Errorf("unexpected tags after selection rename: %q, %q", got[1].Tag, got[2].Tag)
+		}
+
+		// A global merge folds both into one tag and deletes the sources
+		if err := s.MergeTags(nil, []string{"persistence", "Persist"}, "persistence-all"); err != nil {
+			t.Fatalf("MergeTags failed: %v", err)
+		}
+		if err := s.SaveTag(&Tag{Name: "persistence-all", Color: "RED", Description: "Autoruns"}); err != nil {
+			t.Fatalf("SaveTag failed: %v", err)
+		}
+		tags, err := s.GetTags()
+		if err != nil || len(tags) != 2 {
+			t.Fatalf("expected 2 tags after merge, got %+v (%v)", tags, err)
+		}
+		if tags[1].Name != "persistence-all" || tags[1].EventCount != 2 || tags[1].Color != "RED" || tags[1].Description != "Autoruns" {
+			t.Errorf("unexpected merged tag: %+v", tags[1])
+		}
+
+		// A global rename keeps the tag's color
+		if err := s.RenameTag(nil, "persistence-all", "autostart"); err != nil {
+			t.Fatalf("RenameTag failed: %v", err)
+		}
+		if err := s.BulkAddTag([]int64{a}, "autostart"); err != nil {
+			t.Fatalf("BulkAddTag failed: %v", err)
+		}
+		if err := s.BulkRemoveTag([]int64{c}, "lateral"); err != nil {
+			t.Fatalf("BulkRemoveTag failed: %v", err)
+		}
+		got, _ = s.QueryEvents("", nil, "datetime", 0, 0)
+		for i, e := range got {
+			if e.Tag != "autostart" {
+				t.Errorf("event %d: expected tag column \"autostart\", got %q", i, e.Tag)
+			}
+		}
+		tags, _ = s.GetTags()
+		if tags[0].Name != "autostart" || tags[0].Color != "RED" || tags[0].EventCount != 3 {
+			t.Errorf("expected renamed tag to keep its color, got %+v", tags)
+		}
+		if names, _ := s.GetDistinctTags(); len(names) != 1 || names[0] != "autostart" {
+			t.Errorf("expected only tags in use, got %v", names)
+		}
+
+		// Writing the tag column through UpdateEvent replaces the event's tags
+		if err := s.UpdateEvent(a, map[string]interface{}{"tag": "triage, Autostart,triage", "color": "BLUE"}); err != nil {
+			t.Fatalf("UpdateEvent failed: %v", err)
+		}
+		got, _ = s.QueryEvents("", nil, "datetime", 0, 0)
+		if got[0].Tag != "autostart,triage" || got[0].Color != "BLUE" {
+			t.Errorf("unexpected event after UpdateEvent: tag=%q color=%q", got[0].Tag, got[0].Color)
+		}
+
+		if err := s.DeleteTag("autostart"); err != nil {
+			t.Fatalf("DeleteTag failed: %v", err)
+		}
+		if got := tagged("autostart"); len(got) != 0 {
+			t.Errorf("expected deleted tag to match nothing, got %v", got)
+		}
+		got, _ = s.QueryEvents("", nil, "datetime", 0, 0)
+		if got[0].Tag != "triage" || got[1].Tag != "" {
+			t.Errorf("expected tag column to drop the deleted tag, got %q, %q", got[0].Tag, got[1].Tag)
+		}
+		if err := s.RenameTag(nil, "missing", "x"); err == nil {
+			t.Error("expected renaming a missing tag to fail")
+		}
+		if err := s.SaveTag(&Tag{Name: "a,b"}); err == nil {
+			t.Error("expected a tag name containing a comma to be rejected")
+		}
+	})
+
+	t.Run("AuditLog", func(t *testing.T) {
+		s := b.newStore(t)
+		events := insertConformanceEvents(t, s)
+		a, c := events[0].ID, events[2].ID
+		s.SetExaminer("jdoe")
+
+		if err := s.BulkUpdateColor([]int64{a, c}, "RED"); err != nil {
+			t.Fatalf("BulkUpdateColor failed: %v", err)
+		}
+		if err := s.UpdateEvent(a, map[string]interface{}{"color": "BLUE", "tag": "triage"}); err != nil {
+			t.Fatalf("UpdateEvent failed: %v", err)
+		}
+		s.SetExaminer("asmith")
+		if err := s.BulkAddTag([]int64{c}, "exfil"); err != nil {
+			t.Fatalf("BulkAddTag failed: %v", err)
+		}
+		note, err := s.InsertExaminerNote("2025-01-15 09:00:00", "note", "", "")
+		if err != nil {
+			t.Fatalf("InsertExaminerNote failed: %v", err)
+		}
+		if err := s.DeleteExaminerNote(-note); err != nil {
+			t.Fatalf("DeleteExaminerNote failed: %v", err)
+		}
+		// Changes that touch nothing are not recorded
+		if err := s.BulkRemoveTag([]int64{a}, "missing"); err != nil {
+			t.Fatalf("BulkRemoveTag failed: %v", err)
+		}
+
+		log, err := s.GetAuditLog(AuditFilter{})
+		if err != nil {
+			t.Fatalf("GetAuditLog failed: %v", err)
+		}
+		if len(log) != 5 {
+			t.Fatalf("expected 5 audit entries, got %+v", log)
+		}
+		color := log[0]
+		if color.Operation != AuditSetColor || color.Target != AuditTargetEvent || color.Examiner != "jdoe" ||
+			len(color.IDs) != 2 || color.NewValues["color"] != "RED" {
+			t.Errorf("unexpected color entry: %+v", color)
+		}
+		if color.OldValues[a]["color"] != events[0].Color || color.OldValues[c]["color"] != events[2].Color {
+			t.Errorf("expected old colors to be recorded, got %+v", color.OldValues)
+		}
+		update := log[1]
+		if update.Operation != AuditUpdateEvent || update.OldValues[a]["color"] != "RED" ||
+			update.OldValues[a]["tag"] != events[0].Tag || update.NewValues["tag"] != "triage" {
+			t.Errorf("unexpected update entry: %+v", update)
+		}
+		if log[2].Operation != AuditAddTag || log[2].Examiner != "asmith" || log[2].OldValues[c]["tag"] != "lateral,persistence" {
+			t.Errorf("unexpected tag entry: %+v", log[2])
+		}
+		if log[3].Operation != AuditAddNote || log[3].Target != AuditTargetExaminerNote || log[3].IDs[0] != -note {
+			t.Errorf("unexpected note entry: %+v", log[3])
+		}
+		if log[4].Operation != AuditDeleteNote || log[4].OldValues[-note]["description"] != "note" {
+			t.Errorf("unexpected note deletion entry: %+v", log[4])
+		}
+
+		if got, _ := s.GetAuditLog(AuditFilter{Examiner: "jdoe"}); len(got) != 2 {
+			t.Errorf("expected 2 entries by jdoe, got %d", len(got))
+		}
+		if got, _ := s.GetAuditLog(AuditFilter{EventID: c, Target: AuditTargetEvent}); len(got) != 2 {
+			t.Errorf("expected 2 entries touching event %d, got %d", c, len(got))
+		}
+		if got, _ := s.GetAuditLog(AuditFilter{Limit: 1}); len(got) != 1 || got[0].Operation != AuditDeleteNote {
+			t.Errorf("expected the limit to keep the newest entry, got %+v", got)
+		}
+		if got, _ := s.GetAuditLog(AuditFilter{Since: "2000-01-01", Until: "2000-12-31"}); len(got) != 0 {
+			t.Errorf("expected no entries in 2000, got %d", len(got))
+		}
+	})
+
+	t.Run("UndoRedo", func(t *testing.T) {
+		s := b.newStore(t)
+		events := insertConformanceEvents(t, s)
+		a, c := events[0].ID, events[2].ID
+
+		if err := s.BulkUpdateColor([]int64{a, c}, "RED"); err != nil {
+			t.Fatalf("BulkUpdateColor failed: %v", err)
+		}
+		if err := s.BulkRemoveTag([]int64{c}, "lateral"); err != nil {
+			t.Fatalf("BulkRemoveTag failed: %v", err)
+		}
+		note, err := s.InsertExaminerNote("2025-01-15 09:00:00", "note", "", "BLUE")
+		if err != nil {
+			t.Fatalf("InsertExaminerNote failed: %v", err)
+		}
+		if err := s.DeleteExaminerNote(-note); err != nil {
+			t.Fatalf("DeleteExaminerNote failed: %v", err)
+		}
+
+		for i := 0; i < 4; i++ {
+			if e, err := s.Undo(); err != nil || e == nil {
+				t.Fatalf("Undo %d = %+v, %v", i, e, err)
+			}
+			if i == 0 {
+				if notes, _ := s.GetExaminerNotes(); len(notes) != 1 || notes[0].ID != note || notes[0].Color != "BLUE" {
+					t.Errorf("expected undo to restore the deleted note, got %+v", notes)
+				}
+			}
+		}
+		got, _ := s.QueryEvents("", nil, "datetime", 0, 0)
+		if got[0].Color != events[0].Color || got[2].Color != events[2].Color || got[2].Tag != "lateral,persistence" {
+			t.Errorf("expected every change undone, got color=%q/%q tag=%q", got[0].Color, got[2].Color, got[2].Tag)
+		}
+		if notes, _ := s.GetExaminerNotes(); len(notes) != 0 {
+			t.Errorf("expected the note creation undone, got %+v", notes)
+		}
+
+		if _, err := s.Redo(); err != nil {
+			t.Fatalf("Redo failed: %v", err)
+		}
+		got, _ = s.QueryEvents("", nil, "datetime", 0, 0)
+		if got[0].Color != "RED" || got[2].Color != "RED" {
+			t.Errorf("expected redo to reapply the color, got %q, %q", got[0].Color, got[2].Color)
+		}
+		state, err := s.GetUndoState()
+		if err != nil || state.UndoCount != 1 || state.RedoCount != 3 || state.RedoOperation != AuditRemoveTag {
+			t.Errorf("unexpected undo state: %+v (%v)", state, err)
+		}
+	})
+
+	t.Run("ColorRules", func(t *testing.T) {
+		s := b.newStore(t)
+		events := insertConformanceEvents(t, s)
+
+		low := &ColorRule{Filters: []RuleFilter{{Field: "host", Operator: "=", Value: "SERVER1"}}, Color: "blue", Enabled: true}
+		high := &ColorRule{Name: "lateral", Filters: []RuleFilter{{Field: "tag", Operator: "HAS TAG", Value: "lateral"}},
+			Color: "RED", Priority: 10, Enabled: true}
+		for _, r := range []*ColorRule{low, high} {
+			if err := s.SaveColorRule(r); err != nil {
+				t.Fatalf("SaveColorRule failed: %v", err)
+			}
+		}
+		if err := s.SaveColorRule(&ColorRule{Color: "RED"}); err == nil {
+			t.Error("expected a rule without filters or a saved query to be rejected")
+		}
+		rules, err := s.GetColorRules()
+		if err != nil || len(rules) != 2 {
+			t.Fatalf("expected 2 rules, got %+v (%v)", rules, err)
+		}
+		if rules[0].ID != high.ID || rules[1].Name != "host = SERVER1" || rules[1].Color != "BLUE" || !rules[1].Enabled {
+			t.Errorf("unexpected rules: %+v", rules)
+		}
+
+		// Lowest priority first, so the lateral rule wins on charlie
+		n, err := s.ApplyColorRules([]ColorRuleMatch{
+			{Where: "host = " + ph(1), Args: []interface{}{"SERVER1"}, Color: "BLUE"},
+			{Where: "source = " + ph(1), Args: []interface{}{"REG"}, Color: "RED"},
+			{Where: d.QuoteColumn("desc") + " = " + ph(1), Args: []interface{}{"charlie"}, Color: "RED"},
+		})
+		if err != nil || n != 2 {
+			t.Fatalf("ApplyColorRules = %d, %v; want 2", n, err)
+		}
+		got, _ := s.QueryEvents("", nil, "datetime", 0, 0)
+		if got[0].Color != events[0].Color || got[1].Color != "RED" || got[2].Color != "RED" {
+			t.Errorf("unexpected colors: %q, %q, %q", got[0].Color, got[1].Color, got[2].Color)
+		}
+
+		// Applying again changes nothing; undo restores the previous colors
+		if n, _ := s.ApplyColorRules([]ColorRuleMatch{{Where: "source = " + ph(1), Args: []interface{}{"REG"}, Color: "RED"}}); n != 0 {
+			t.Errorf("expected reapplying to change nothing, changed %d", n)
+		}
+		if e, err := s.Undo(); err != nil || e == nil || e.Operation != AuditApplyColorRules {
+			t.Fatalf("Undo = %+v, %v; want the color rule application", e, err)
+		}
+		got, _ = s.QueryEvents("", nil, "datetime", 0, 0)
+		if got[1].Color != events[1].Color || got[2].Color != events[2].Color {
+			t.Errorf("expected undo to restore colors, got %q, %q", got[1].Color, got[2].Color)
+		}
+
+		low.Enabled = false
+		low.Priority = 20
+		if err := s.SaveColorRule(low); err != nil {
+			t.Fatalf("SaveColorRule (update) failed: %v", err)
+		}
+		if err := s.DeleteColorRule(high.ID); err != nil {
+			t.Fatalf("DeleteColorRule failed: %v", err)
+		}
+		if rules, _ = s.GetColorRules(); len(rules) != 1 || rules[0].Enabled || rules[0].Priority != 20 {
+			t.Errorf("unexpected rules after update and delete: %+v", rules)
+		}
+	})
+
+	t.Run("MetadataIndexesAndMigrate", func(t *testing.T) {
+		s := b.newStore(t)
+		insertConformanceEvents(t, s)
+
+		if err := s.UpdateMetadata(); err != nil {
+			t.Fatalf("UpdateMetadata failed: %v", err)
+		}
+		// Running it twice must replace, not append
+		if err := s.UpdateMetadata(); err != nil {
+			t.Fatalf("UpdateMetadata failed: %v", err)
+		}
+		if err := s.RebuildIndexes([]string{"host", "desc", "datetime"}); err != nil {
+			t.Fatalf("RebuildIndexes failed: %v", err)
+		}
+		if err := s.RebuildIndexes(DefaultIndexFields); err != nil {
+			t.Fatalf("RebuildIndexes failed: %v", err)
+		}
+		if err := s.Migrate(); err != nil {
+			t.Fatalf("Migrate failed: %v", err)
+		}
+		if n, err := s.CountEvents("", nil); err != nil || n != 3 {
+			t.Errorf("CountEvents after maintenance = %d, %v; want 3", n, err)
+		}
+	})
+
+	t.Run("QueryPlansAndIndexes", func(t *testing.T) {
+		s := b.newStore(t)
+		insertConformanceEvents(t, s)
+
+		name, err := s.CreateIndex([]string{"source", "datetime"})
+		if err != nil {
+			t.Fatalf("CreateIndex failed: %v", err)
+		}
+		if _, err := s.CreateIndex([]string{"source", "datetime"}); err != nil {
+			t.Fatalf("CreateIndex twice failed: %v", err)
+		}
+		indexes, err := s.ListIndexes()
+		if err != nil {
+			t.Fatalf("ListIndexes failed: %v", err)
+		}
+		found := false
+		for _, idx := range indexes {
+			if idx.Name == name {
+				found = len(idx.Columns) == 2 && idx.Columns[0] == "source" && idx.Columns[1] == "datetime"
+			}
+		}
+		if !found {
+			t.Errorf("ListIndexes = %+v; want %s on (source, datetime)", indexes, name)
+		}
+
+		query := "SELECT " + d.IDColumn() + " FROM log2timeline WHERE source = " + ph(1) + " ORDER BY datetime"
+		plan, err := s.ExplainQuery(query, []interface{}{"FILE"})
+		if err != nil {
+			t.Fatalf("ExplainQuery failed: %v", err)
+		}
+		if len(plan.Lines) == 0 || plan.DurationMs < 0 {
+			t.Errorf("unexpected plan %+v", plan)
+		}
+	})
+
+	t.Run("PathAndClose", func(t *testing.T) {
+		s := b.newStore(t)
+		if s.Path() == "" {
+			t.Error("expected a non-empty Path")
+		}
+		if err := s.Close(); err != nil {
+			t.Errorf("Close failed: %v", err)
+		}
+		if _, err := s.CountEvents("", nil); err == nil {
+			t.Error("expected an error using a closed store")
+		}
+	})
+}
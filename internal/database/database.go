@@ -21,9 +21,10 @@ var metadataFields = []string{"sourcetype", "source", "user", "host", "MACB", "c
 // SQLiteStore manages all SQLite operations for a 4n6time database.
 // It implements the Store interface.
 type SQLiteStore struct {
-	path    string
-	conn    *sql.DB
-	dialect Dialect
+	path     string
+	conn     *sql.DB
+	dialect  Dialect
+	examiner string // recorded in audit_log with each change
 }
 
 // OpenSQLite opens an existing 4n6time SQLite database.
@@ -41,45 +42,32 @@ func OpenSQLite(path string) (*SQLiteStore, error) {
 		return nil, fmt.Errorf("connecting to database: %w", err)
 	}
 
-	db := &SQLiteStore{path: path, conn: conn, dialect: d}
-
-	// Migrate: add bookmark column if it doesn't exist (for pre-0.8.0 databases)
-	db.migrate()
-
-	return db, nil
-}
-
-// migrate applies schema migrations for backward compatibility.
-func (db *SQLiteStore) migrate() {
-	// Add bookmark column if missing
-	var count int
-	err := db.conn.QueryRow(
-		db.dialect.SchemaCheckColumnSQL("log2timeline", "bookmark"),
-	).Scan(&count)
-	if err == nil && count == 0 {
-		db.conn.Exec("ALTER TABLE log2timeline ADD COLUMN bookmark INT DEFAULT 0")
+	// Bring databases from earlier releases up to the current schema
+	if err := migrateSchema(conn, d); err != nil {
+		conn.Close()
+		return nil, err
 	}
 
-	// Create examiner_notes table if missing
-	db.conn.Exec(db.dialect.CreateExaminerNotesTableSQL())
+	return &SQLiteStore{path: path, conn: conn, dialect: d}, nil
 }
 
 // ToggleBookmark toggles the bookmark flag on an event and returns the new value.
 func (db *SQLiteStore) ToggleBookmark(rowid int64) (int64, error) {
 	idCol := db.dialect.IDColumn()
-	_, err := db.conn.Exec(
-		"UPDATE log2timeline SET bookmark = CASE WHEN bookmark = 1 THEN 0 ELSE 1 END WHERE "+idCol+" = "+db.dialect.Placeholder(1),
-		rowid,
-	)
-	if err != nil {
-		return 0, err
-	}
-
 	var val int64
-	err = db.conn.QueryRow(
-		"SELECT bookmark FROM log2timeline WHERE "+idCol+" = "+db.dialect.Placeholder(1),
-		rowid,
-	).Scan(&val)
+	err := runAudited(db.conn, db.dialect, db.examiner, AuditToggleBookmark, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "log2timeline", []string{"bookmark"}, []int64{rowid}); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE log2timeline SET bookmark = CASE WHEN bookmark = 1 THEN 0 ELSE 1 END WHERE "+idCol+" = "+db.dialect.Placeholder(1), rowid); err != nil {
+			return err
+		}
+		if err := tx.QueryRow("SELECT bookmark FROM log2timeline WHERE "+idCol+" = "+db.dialect.Placeholder(1), rowid).Scan(&val); err != nil {
+			return err
+		}
+		a.new = map[string]interface{}{"bookmark": val}
+		return nil
+	})
 	return val, err
 }
 
@@ -178,6 +166,46 @@ func (db *SQLiteStore) createSchema(indexFields []string) error {
 		return fmt.Errorf("creating examiner_notes table: %w", err)
 	}
 
+	// Provenance table
+	_, err = tx.Exec(db.dialect.CreateProvenanceTableSQL())
+	if err != nil {
+		return fmt.Errorf("creating l2t_provenance table: %w", err)
+	}
+
+	// Case, evidence item and import batch tables
+	for _, stmt := range append(caseTablesSQL(db.dialect),
+		db.dialect.CreateIndexSQL(batchIndexName, "log2timeline", "batch_id")) {
+		if _, err = tx.Exec(stmt); err != nil {
+			return fmt.Errorf("creating case tables: %w", err)
+		}
+	}
+
+	// Tag and event_tags tables
+	for _, stmt := range tagTablesSQL(db.dialect) {
+		if _, err = tx.Exec(stmt); err != nil {
+			return fmt.Errorf("creating tag tables: %w", err)
+		}
+	}
+
+	// Audit log
+	for _, stmt := range db.dialect.CreateAuditLogSQL() {
+		if _, err = tx.Exec(stmt); err != nil {
+			return fmt.Errorf("creating audit_log table: %w", err)
+		}
+	}
+
+	// Color rules
+	if _, err = tx.Exec(db.dialect.CreateColorRulesTableSQL()); err != nil {
+		return fmt.Errorf("creating color_rules table: %w", err)
+	}
+
+	// Full-text search index and its sync triggers
+	for _, stmt := range (&SQLiteDialect{}).CreateFullTextSQL() {
+		if _, err = tx.Exec(stmt); err != nil {
+			return fmt.Errorf("creating full-text index: %w", err)
+		}
+	}
+
 	// Create indexes
 	for _, field := range indexFields {
 		_, err = tx.Exec(db.dialect.CreateIndexSQL(field+"_idx", "log2timeline", field))
@@ -186,21 +214,39 @@ func (db *SQLiteStore) createSchema(indexFields []string) error {
 		}
 	}
 
+	if err := stampSchemaVersion(tx, db.dialect); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
 // InsertEvent inserts a single event into the database.
 func (db *SQLiteStore) InsertEvent(e *model.Event) error {
-	_, err := db.conn.Exec(db.dialect.InsertEventSQL(),
+	// Only this event's tags are ingested, not every tagged event's
+	var after int64
+	tagged := len(splitTags(e.Tag)) > 0
+	if tagged {
+		var err error
+		if after, err = lastEventID(db.conn, db.dialect); err != nil {
+			return err
+		}
+	}
+	if _, err := db.conn.Exec(db.dialect.InsertEventSQL(),
 		e.Timezone, e.MACB, e.Source, e.SourceType, e.Type,
 		e.User, e.Host, e.Desc, e.Filename, e.Inode,
 		e.Notes, e.Format, e.Extra, e.Datetime, e.ReportNotes,
 		e.InReport, e.Tag, e.Color, e.Offset, e.StoreNumber,
 		e.StoreIndex, e.VSSStoreNumber, e.URL, e.RecordNumber,
 		e.EventID, e.EventType, e.SourceName, e.UserSID, e.ComputerName,
-		e.Bookmark,
-	)
-	return err
+		e.Bookmark, e.BatchID,
+	); err != nil {
+		return err
+	}
+	if !tagged {
+		return nil
+	}
+	return ingestEventTags(db.conn, db.dialect, after)
 }
 
 // InsertEvents inserts a batch of events inside a single transaction.
@@ -219,6 +265,15 @@ func (db *SQLiteStore) InsertEvents(events []*model.Event, onProgress func(count
 	}
 	defer stmt.Close()
 
+	// The tags of the events inserted here are ingested below
+	tagged := hasTags(events)
+	var after int64
+	if tagged {
+		if after, err = lastEventID(tx, db.dialect); err != nil {
+			return 0, err
+		}
+	}
+
 	inserted := 0
 	for _, e := range events {
 		_, err := stmt.Exec(
@@ -228,7 +283,7 @@ func (db *SQLiteStore) InsertEvents(events []*model.Event, onProgress func(count
 			e.InReport, e.Tag, e.Color, e.Offset, e.StoreNumber,
 			e.StoreIndex, e.VSSStoreNumber, e.URL, e.RecordNumber,
 			e.EventID, e.EventType, e.SourceName, e.UserSID, e.ComputerName,
-			e.Bookmark,
+			e.Bookmark, e.BatchID,
 		)
 		if err != nil {
 			return inserted, fmt.Errorf("inserting event %d: %w", inserted+1, err)
@@ -239,6 +294,12 @@ func (db *SQLiteStore) InsertEvents(events []*model.Event, onProgress func(count
 		}
 	}
 
+	if tagged {
+		if err := ingestEventTags(tx, db.dialect, after); err != nil {
+			return inserted, err
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		return inserted, fmt.Errorf("committing transaction: %w", err)
 	}
@@ -255,7 +316,7 @@ func (db *SQLiteStore) QueryEvents(whereClause string, args []interface{}, order
 		"desc, filename, inode, notes, format, extra, datetime, reportnotes, " +
 		"inreport, tag, color, offset, store_number, store_index, vss_store_number, " +
 		"URL, record_number, event_identifier, event_type, source_name, user_sid, " +
-		"computer_name, bookmark FROM log2timeline"
+		"computer_name, bookmark, batch_id FROM log2timeline"
 
 	if whereClause != "" {
 		query += " WHERE " + whereClause
@@ -366,42 +427,18 @@ func (db *SQLiteStore) GetDistinctValues(fieldName string) (map[string]int64, er
 	return result, nil
 }
 
-// GetDistinctTags returns all unique tags from the events table.
-// Tags can be comma-separated within a single field, so this splits them.
-func (db *SQLiteStore) GetDistinctTags() ([]string, error) {
-	rows, err := db.conn.Query("SELECT DISTINCT tag FROM log2timeline")
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	seen := make(map[string]bool)
-	var tags []string
-
-	for rows.Next() {
-		var tagStr string
-		if err := rows.Scan(&tagStr); err != nil {
-			return nil, err
-		}
-		if tagStr == "" {
-			continue
-		}
-		for _, t := range strings.Split(tagStr, ",") {
-			t = strings.TrimSpace(t)
-			if t != "" && !seen[t] {
-				seen[t] = true
-				tags = append(tags, t)
-			}
-		}
-	}
-	return tags, rows.Err()
-}
-
 // UpdateEvent updates specific fields of an event identified by rowid.
 func (db *SQLiteStore) UpdateEvent(rowid int64, fields map[string]interface{}) error {
 	if len(fields) == 0 {
 		return nil
 	}
+	changes := fields
+
+	// Tags are stored in event_tags; the tag column is rendered from them
+	fields, tags, setTags, err := takeTagField(fields)
+	if err != nil {
+		return err
+	}
 
 	// Validate all field names
 	setClauses := make([]string, 0, len(fields))
@@ -422,8 +459,21 @@ func (db *SQLiteStore) UpdateEvent(rowid int64, fields map[string]interface{}) e
 	query := fmt.Sprintf("UPDATE log2timeline SET %s WHERE %s = %s",
 		strings.Join(setClauses, ", "), idCol, db.dialect.Placeholder(paramIdx))
 
-	_, err := db.conn.Exec(query, args...)
-	return err
+	return runAudited(db.conn, db.dialect, db.examiner, AuditUpdateEvent, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "log2timeline", fieldNames(changes), []int64{rowid}); err != nil {
+			return err
+		}
+		a.new = changes
+		if len(setClauses) > 0 {
+			if _, err := tx.Exec(query, args...); err != nil {
+				return err
+			}
+		}
+		if setTags {
+			return setEventTags(tx, db.dialect, rowid, tags)
+		}
+		return nil
+	})
 }
 
 // UpdateMetadata refreshes all metadata tables (l2t_sources, l2t_hosts, etc.)
@@ -453,49 +503,8 @@ func (db *SQLiteStore) UpdateMetadata() error {
 		}
 	}
 
-	// Update tags table
-	_, err = tx.Exec("DELETE FROM l2t_tags")
-	if err != nil {
-		return fmt.Errorf("clearing l2t_tags: %w", err)
-	}
-
-	// Get distinct tags (need to split comma-separated values in Go)
-	rows, err := tx.Query("SELECT DISTINCT tag FROM log2timeline WHERE tag <> ''")
-	if err != nil {
-		return fmt.Errorf("querying tags: %w", err)
-	}
-
-	seen := make(map[string]bool)
-	tagStmt, err := tx.Prepare("INSERT INTO l2t_tags (tag) VALUES (" + db.dialect.Placeholder(1) + ")")
-	if err != nil {
-		rows.Close()
-		return fmt.Errorf("preparing tag insert: %w", err)
-	}
-
-	for rows.Next() {
-		var tagStr string
-		if err := rows.Scan(&tagStr); err != nil {
-			rows.Close()
-			tagStmt.Close()
-			return err
-		}
-		for _, t := range strings.Split(tagStr, ",") {
-			t = strings.TrimSpace(t)
-			if t != "" && !seen[t] {
-				seen[t] = true
-				_, err = tagStmt.Exec(t)
-				if err != nil {
-					rows.Close()
-					tagStmt.Close()
-					return fmt.Errorf("inserting tag: %w", err)
-				}
-			}
-		}
-	}
-	rows.Close()
-	tagStmt.Close()
-
-	if err := rows.Err(); err != nil {
+	// Tags in use, for releases that read l2t_tags
+	if err := populateTagMetadata(tx); err != nil {
 		return err
 	}
 
@@ -529,101 +538,100 @@ func (db *SQLiteStore) RebuildIndexes(indexFields []string) error {
 	return tx.Commit()
 }
 
-// SavedQuery represents a named query stored in the database.
-type SavedQuery struct {
-	Name  string
-	Query string
+// Migrate applies any pending schema migrations.
+func (db *SQLiteStore) Migrate() error {
+	return migrateSchema(db.conn, db.dialect)
 }
 
-// GetSavedQueries returns all saved queries from the database.
-func (db *SQLiteStore) GetSavedQueries() ([]SavedQuery, error) {
-	rows, err := db.conn.Query("SELECT name, query FROM l2t_saved_query")
+// RecordProvenance appends an entry to the l2t_provenance table.
+func (db *SQLiteStore) RecordProvenance(action, detail string) error {
+	_, err := db.conn.Exec(
+		"INSERT INTO l2t_provenance (action, detail) VALUES ("+db.dialect.Placeholder(1)+", "+db.dialect.Placeholder(2)+")",
+		action, detail,
+	)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("recording provenance: %w", err)
+	}
+	return nil
+}
+
+// GetProvenance returns all provenance entries in the order they were recorded.
+func (db *SQLiteStore) GetProvenance() ([]ProvenanceEntry, error) {
+	rows, err := db.conn.Query("SELECT id, action, detail, created_at FROM l2t_provenance ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("querying provenance: %w", err)
 	}
 	defer rows.Close()
 
-	var queries []SavedQuery
+	var entries []ProvenanceEntry
 	for rows.Next() {
-		var q SavedQuery
-		if err := rows.Scan(&q.Name, &q.Query); err != nil {
-			return nil, err
+		var pe ProvenanceEntry
+		if err := rows.Scan(&pe.ID, &pe.Action, &pe.Detail, &pe.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning provenance entry: %w", err)
 		}
-		queries = append(queries, q)
+		entries = append(entries, pe)
 	}
-	return queries, rows.Err()
-}
-
-// SaveQuery stores a named query in the database.
-func (db *SQLiteStore) SaveQuery(name, query string) error {
-	_, err := db.conn.Exec(
-		"INSERT INTO l2t_saved_query (name, query) VALUES ("+db.dialect.Placeholder(1)+", "+db.dialect.Placeholder(2)+")",
-		name, query,
-	)
-	return err
-}
-
-// DeleteQuery removes a saved query by name.
-func (db *SQLiteStore) DeleteQuery(name string) error {
-	_, err := db.conn.Exec(
-		"DELETE FROM l2t_saved_query WHERE name = "+db.dialect.Placeholder(1),
-		name,
-	)
-	return err
-}
-
-// Migrate applies any pending schema migrations.
-func (db *SQLiteStore) Migrate() error {
-	db.migrate()
-	return nil
+	return entries, rows.Err()
 }
 
 // InsertExaminerNote inserts a new examiner note and returns its negated ID.
 func (db *SQLiteStore) InsertExaminerNote(datetime, description, tag, color string) (int64, error) {
-	result, err := db.conn.Exec(db.dialect.InsertExaminerNoteSQL(),
-		datetime, description, tag, color, 0)
-	if err != nil {
-		return 0, fmt.Errorf("inserting examiner note: %w", err)
-	}
-	id, err := result.LastInsertId()
+	var id int64
+	err := runAudited(db.conn, db.dialect, db.examiner, AuditAddNote, func(tx *sql.Tx, a *auditChange) error {
+		result, err := tx.Exec(db.dialect.InsertExaminerNoteSQL(),
+			datetime, description, tag, color, 0)
+		if err != nil {
+			return fmt.Errorf("inserting examiner note: %w", err)
+		}
+		if id, err = result.LastInsertId(); err != nil {
+			return fmt.Errorf("getting examiner note ID: %w", err)
+		}
+		a.touch("examiner_notes", []int64{id})
+		a.new = map[string]interface{}{
+			"datetime": datetime, "description": description, "tag": tag, "color": color, "bookmark": 0,
+		}
+		return nil
+	})
 	if err != nil {
-		return 0, fmt.Errorf("getting examiner note ID: %w", err)
+		return 0, err
 	}
 	return -id, nil
 }
 
 // DeleteExaminerNote deletes an examiner note by its positive internal ID.
 func (db *SQLiteStore) DeleteExaminerNote(id int64) error {
-	_, err := db.conn.Exec("DELETE FROM examiner_notes WHERE id = "+db.dialect.Placeholder(1), id)
-	if err != nil {
-		return fmt.Errorf("deleting examiner note: %w", err)
-	}
-	return nil
+	return runAudited(db.conn, db.dialect, db.examiner, AuditDeleteNote, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "examiner_notes", examinerNoteFields, []int64{id}); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM examiner_notes WHERE id = "+db.dialect.Placeholder(1), id); err != nil {
+			return fmt.Errorf("deleting examiner note: %w", err)
+		}
+		return nil
+	})
 }
 
 // UpdateExaminerNoteColor updates the color of an examiner note by its positive internal ID.
 func (db *SQLiteStore) UpdateExaminerNoteColor(id int64, color string) error {
-	_, err := db.conn.Exec(
-		"UPDATE examiner_notes SET color = "+db.dialect.Placeholder(1)+" WHERE id = "+db.dialect.Placeholder(2),
-		color, id)
-	if err != nil {
-		return fmt.Errorf("updating examiner note color: %w", err)
-	}
-	return nil
+	return db.BulkUpdateExaminerNoteColor([]int64{id}, color)
 }
 
 // ToggleExaminerNoteBookmark toggles the bookmark flag on an examiner note and returns the new value.
 func (db *SQLiteStore) ToggleExaminerNoteBookmark(id int64) (int64, error) {
-	_, err := db.conn.Exec(
-		"UPDATE examiner_notes SET bookmark = CASE WHEN bookmark = 1 THEN 0 ELSE 1 END WHERE id = "+db.dialect.Placeholder(1),
-		id)
-	if err != nil {
-		return 0, err
-	}
 	var val int64
-	err = db.conn.QueryRow(
-		"SELECT bookmark FROM examiner_notes WHERE id = "+db.dialect.Placeholder(1),
-		id).Scan(&val)
+	err := runAudited(db.conn, db.dialect, db.examiner, AuditToggleBookmark, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "examiner_notes", []string{"bookmark"}, []int64{id}); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE examiner_notes SET bookmark = CASE WHEN bookmark = 1 THEN 0 ELSE 1 END WHERE id = "+db.dialect.Placeholder(1), id); err != nil {
+			return err
+		}
+		if err := tx.QueryRow("SELECT bookmark FROM examiner_notes WHERE id = "+db.dialect.Placeholder(1), id).Scan(&val); err != nil {
+			return err
+		}
+		a.new = map[string]interface{}{"bookmark": val}
+		return nil
+	})
 	return val, err
 }
 
@@ -662,40 +670,18 @@ func (db *SQLiteStore) BulkUpdateColor(ids []int64, color string) error {
 	if len(ids) == 0 {
 		return nil
 	}
-	tx, err := db.conn.Begin()
-	if err != nil {
-		return fmt.Errorf("beginning transaction: %w", err)
-	}
-	defer tx.Rollback()
-	for _, id := range ids {
-		if _, err := tx.Exec("UPDATE log2timeline SET color = ? WHERE rowid = ?", color, id); err != nil {
-			return fmt.Errorf("updating color for event %d: %w", id, err)
-		}
-	}
-	return tx.Commit()
-}
-
-// BulkAddTag appends a tag to multiple log2timeline events, avoiding duplicates.
-func (db *SQLiteStore) BulkAddTag(ids []int64, tag string) error {
-	if len(ids) == 0 || tag == "" {
-		return nil
-	}
-	tx, err := db.conn.Begin()
-	if err != nil {
-		return fmt.Errorf("beginning transaction: %w", err)
-	}
-	defer tx.Rollback()
-	for _, id := range ids {
-		var existing string
-		if err := tx.QueryRow("SELECT tag FROM log2timeline WHERE rowid = ?", id).Scan(&existing); err != nil {
-			return fmt.Errorf("reading tag for event %d: %w", id, err)
+	return runAudited(db.conn, db.dialect, db.examiner, AuditSetColor, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "log2timeline", []string{"color"}, ids); err != nil {
+			return err
 		}
-		newTag := appendTagDedup(existing, tag)
-		if _, err := tx.Exec("UPDATE log2timeline SET tag = ? WHERE rowid = ?", newTag, id); err != nil {
-			return fmt.Errorf("updating tag for event %d: %w", id, err)
+		a.new = map[string]interface{}{"color": color}
+		for _, id := range ids {
+			if _, err := tx.Exec("UPDATE log2timeline SET color = ? WHERE rowid = ?", color, id); err != nil {
+				return fmt.Errorf("updating color for event %d: %w", id, err)
+			}
 		}
-	}
-	return tx.Commit()
+		return nil
+	})
 }
 
 // BulkSetBookmark sets the bookmark value on multiple log2timeline events.
@@ -703,17 +689,18 @@ func (db *SQLiteStore) BulkSetBookmark(ids []int64, bookmark int64) error {
 	if len(ids) == 0 {
 		return nil
 	}
-	tx, err := db.conn.Begin()
-	if err != nil {
-		return fmt.Errorf("beginning transaction: %w", err)
-	}
-	defer tx.Rollback()
-	for _, id := range ids {
-		if _, err := tx.Exec("UPDATE log2timeline SET bookmark = ? WHERE rowid = ?", bookmark, id); err != nil {
-			return fmt.Errorf("updating bookmark for event %d: %w", id, err)
+	return runAudited(db.conn, db.dialect, db.examiner, AuditSetBookmark, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "log2timeline", []string{"bookmark"}, ids); err != nil {
+			return err
 		}
-	}
-	return tx.Commit()
+		a.new = map[string]interface{}{"bookmark": bookmark}
+		for _, id := range ids {
+			if _, err := tx.Exec("UPDATE log2timeline SET bookmark = ? WHERE rowid = ?", bookmark, id); err != nil {
+				return fmt.Errorf("updating bookmark for event %d: %w", id, err)
+			}
+		}
+		return nil
+	})
 }
 
 // BulkUpdateExaminerNoteColor sets the color on multiple examiner notes.
@@ -721,17 +708,18 @@ func (db *SQLiteStore) BulkUpdateExaminerNoteColor(ids []int64, color string) er
 	if len(ids) == 0 {
 		return nil
 	}
-	tx, err := db.conn.Begin()
-	if err != nil {
-		return fmt.Errorf("beginning transaction: %w", err)
-	}
-	defer tx.Rollback()
-	for _, id := range ids {
-		if _, err := tx.Exec("UPDATE examiner_notes SET color = ? WHERE id = ?", color, id); err != nil {
-			return fmt.Errorf("updating examiner note color for %d: %w", id, err)
+	return runAudited(db.conn, db.dialect, db.examiner, AuditSetColor, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "examiner_notes", []string{"color"}, ids); err != nil {
+			return err
 		}
-	}
-	return tx.Commit()
+		a.new = map[string]interface{}{"color": color}
+		for _, id := range ids {
+			if _, err := tx.Exec("UPDATE examiner_notes SET color = ? WHERE id = ?", color, id); err != nil {
+				return fmt.Errorf("updating examiner note color for %d: %w", id, err)
+			}
+		}
+		return nil
+	})
 }
 
 // BulkSetExaminerNoteBookmark sets the bookmark value on multiple examiner notes.
@@ -739,34 +727,18 @@ func (db *SQLiteStore) BulkSetExaminerNoteBookmark(ids []int64, bookmark int64)
 	if len(ids) == 0 {
 		return nil
 	}
-	tx, err := db.conn.Begin()
-	if err != nil {
-		return fmt.Errorf("beginning transaction: %w", err)
-	}
-	defer tx.Rollback()
-	for _, id := range ids {
-		if _, err := tx.Exec("UPDATE examiner_notes SET bookmark = ? WHERE id = ?", bookmark, id); err != nil {
-			return fmt.Errorf("updating examiner note bookmark for %d: %w", id, err)
+	return runAudited(db.conn, db.dialect, db.examiner, AuditSetBookmark, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "examiner_notes", []string{"bookmark"}, ids); err != nil {
+			return err
 		}
-	}
-	return tx.Commit()
-}
-
-// appendTagDedup appends newTag to existing comma-separated tags, avoiding duplicates.
-func appendTagDedup(existing, newTag string) string {
-	if existing == "" {
-		return newTag
-	}
-	parts := strings.Split(existing, ",")
-	for i := range parts {
-		parts[i] = strings.TrimSpace(parts[i])
-	}
-	for _, p := range parts {
-		if strings.EqualFold(p, newTag) {
-			return existing
+		a.new = map[string]interface{}{"bookmark": bookmark}
+		for _, id := range ids {
+			if _, err := tx.Exec("UPDATE examiner_notes SET bookmark = ? WHERE id = ?", bookmark, id); err != nil {
+				return fmt.Errorf("updating examiner note bookmark for %d: %w", id, err)
+			}
 		}
-	}
-	return existing + "," + newTag
+		return nil
+	})
 }
 
 // examinerNotesUnionPatternB returns a UNION ALL SELECT for examiner notes
@@ -776,7 +748,8 @@ func examinerNotesUnionPatternB(dialect Dialect) string {
 	// Pattern B: id, datetime, timezone, MACB, source, sourcetype, type, user, host, desc,
 	//            filename, inode, notes, format, extra, reportnotes, inreport, tag, color,
 	//            offset, store_number, store_index, vss_store_number, URL, record_number,
-	//            event_identifier, event_type, source_name, user_sid, computer_name, bookmark
+	//            event_identifier, event_type, source_name, user_sid, computer_name, bookmark,
+	//            batch_id
 	return " UNION ALL SELECT " +
 		"-id, datetime, '' AS timezone, '' AS " + dialect.QuoteColumn("MACB") + ", " +
 		"'EXAMINER' AS source, 'Examiner Note' AS sourcetype, '' AS type, '' AS " + dialect.QuoteColumn("user") + ", " +
@@ -786,7 +759,7 @@ func examinerNotesUnionPatternB(dialect Dialect) string {
 		"0 AS " + dialect.QuoteColumn("offset") + ", 0 AS store_number, 0 AS store_index, " +
 		"0 AS vss_store_number, '' AS URL, '' AS record_number, " +
 		"'' AS event_identifier, '' AS event_type, '' AS source_name, " +
-		"'' AS user_sid, '' AS computer_name, bookmark " +
+		"'' AS user_sid, '' AS computer_name, bookmark, 0 AS batch_id " +
 		"FROM examiner_notes"
 }
 
@@ -827,8 +800,9 @@ func (db *SQLiteStore) ExecuteCountQuery(sqlStr string, args []interface{}) (int
 // whereArgs are the parameter values for any placeholders in the clause.
 // The bucket size is automatically chosen based on the filtered date range.
 func (db *SQLiteStore) GetTimelineHistogram(whereClause string, whereArgs []interface{}) ([]TimelineBucket, error) {
-	// Get date range to determine bucket size
-	rangeSQL := "SELECT MIN(datetime), MAX(datetime) FROM log2timeline"
+	// Get date range to determine bucket size. COALESCE keeps an empty
+	// filtered result from scanning NULL into a string.
+	rangeSQL := "SELECT COALESCE(MIN(datetime), ''), COALESCE(MAX(datetime), '') FROM log2timeline"
 	if whereClause != "" {
 		rangeSQL += " " + whereClause
 	}
@@ -925,6 +899,10 @@ func shouldIncludeExaminerNotes(sqlStr string) bool {
 // SELECT and the examiner notes UNION ALL in a subquery, and re-applies the
 // ORDER BY and pagination on the outer query.
 //
+// Keyset pages from query.Query.SetKeyset are already wrapped in a subquery
+// aliased "combined" that applies the cursor, ordering and limit, so the
+// union is inserted just before that alias.
+//
 // If the query filters on a specific source that is not 'EXAMINER', the UNION
 // is skipped entirely and the original query is returned unchanged.
 func wrapWithExaminerNotesUnion(sqlStr string, dialect Dialect) string {
@@ -932,6 +910,10 @@ func wrapWithExaminerNotesUnion(sqlStr string, dialect Dialect) string {
 		return sqlStr
 	}
 
+	if i := strings.Index(sqlStr, ") AS combined"); i >= 0 {
+		return sqlStr[:i] + examinerNotesUnionPatternB(dialect) + sqlStr[i:]
+	}
+
 	// Find ORDER BY clause position (case-insensitive search for " ORDER BY ")
 	upper := strings.ToUpper(sqlStr)
 	orderIdx := strings.Index(upper, " ORDER BY ")
@@ -966,7 +948,8 @@ func wrapCountWithExaminerNotes(sqlStr string, dialect Dialect) string {
 //	rowid, datetime, timezone, MACB, source, sourcetype, type, user, host, desc,
 //	filename, inode, notes, format, extra, reportnotes, inreport, tag, color,
 //	offset, store_number, store_index, vss_store_number, URL, record_number,
-//	event_identifier, event_type, source_name, user_sid, computer_name, bookmark
+//	event_identifier, event_type, source_name, user_sid, computer_name, bookmark,
+//	batch_id
 //
 // Note: datetime is at position 2 (right after rowid), NOT at position 15.
 // This is Pattern B, distinct from scanEvents which uses Pattern A.
@@ -981,7 +964,7 @@ func scanFieldsOrderEvents(rows *sql.Rows) ([]*model.Event, error) {
 			&e.InReport, &e.Tag, &e.Color, &e.Offset, &e.StoreNumber,
 			&e.StoreIndex, &e.VSSStoreNumber, &e.URL, &e.RecordNumber,
 			&e.EventID, &e.EventType, &e.SourceName, &e.UserSID, &e.ComputerName,
-			&e.Bookmark,
+			&e.Bookmark, &e.BatchID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning event row: %w", err)
@@ -1003,7 +986,7 @@ func scanEvents(rows *sql.Rows) ([]*model.Event, error) {
 			&e.ReportNotes, &e.InReport, &e.Tag, &e.Color, &e.Offset,
 			&e.StoreNumber, &e.StoreIndex, &e.VSSStoreNumber, &e.URL,
 			&e.RecordNumber, &e.EventID, &e.EventType, &e.SourceName,
-			&e.UserSID, &e.ComputerName, &e.Bookmark,
+			&e.UserSID, &e.ComputerName, &e.Bookmark, &e.BatchID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning event row: %w", err)
@@ -51,14 +51,15 @@ type Dialect interface {
 	// InsertDefaultDiskSQL returns the INSERT statement for the default disk config row.
 	InsertDefaultDiskSQL() string
 
-	// CreateIndexSQL returns DDL to create an index on a table column.
-	CreateIndexSQL(indexName, tableName, column string) string
+	// CreateIndexSQL returns DDL to create an index on one or more table
+	// columns, in order.
+	CreateIndexSQL(indexName, tableName string, columns ...string) string
 
 	// DropIndexSQL returns DDL to drop an index by name.
 	DropIndexSQL(indexName string) string
 
 	// InsertEventSQL returns the parameterized INSERT statement for a single event.
-	// The statement has 30 columns and 30 placeholders.
+	// The statement has 31 columns and 31 placeholders.
 	InsertEventSQL() string
 
 	// QuoteColumn returns the column name quoted appropriately for the dialect.
@@ -73,4 +74,50 @@ type Dialect interface {
 
 	// InsertExaminerNoteSQL returns the parameterized INSERT statement for a single examiner note.
 	InsertExaminerNoteSQL() string
+
+	// CreateProvenanceTableSQL returns DDL for the l2t_provenance table, an
+	// append-only record of operations that changed where the database's
+	// contents came from (e.g. merging in another 4n6time database).
+	CreateProvenanceTableSQL() string
+
+	// CreateSchemaVersionTableSQL returns DDL for the schema_version table,
+	// which records each migration applied to the database (see migrate.go).
+	CreateSchemaVersionTableSQL() string
+
+	// CreateCaseInfoTableSQL returns DDL for the case_info table, which holds
+	// at most one row describing the case the database belongs to.
+	CreateCaseInfoTableSQL() string
+
+	// CreateEvidenceTableSQL returns DDL for the evidence_items table: the
+	// disk images, hosts and log bundles events were extracted from.
+	CreateEvidenceTableSQL() string
+
+	// CreateImportBatchTableSQL returns DDL for the import_batches table. Each
+	// import creates a batch, optionally linked to an evidence item, and
+	// stamps its ID on the imported events' batch_id column.
+	CreateImportBatchTableSQL() string
+
+	// CreateTagDefinitionsTableSQL returns DDL for the tags table: one row per
+	// tag name with its display color and description.
+	CreateTagDefinitionsTableSQL() string
+
+	// CreateEventTagsTableSQL returns DDL for the event_tags join table
+	// linking log2timeline rows to tags.
+	CreateEventTagsTableSQL() string
+
+	// TagListSQL returns a scalar subquery rendering the tags of the event
+	// whose ID is the SQL expression eventID as a comma-separated list sorted
+	// by name, or '' when it has none. Stores use it to keep the legacy
+	// log2timeline.tag column in step with event_tags.
+	TagListSQL(eventID string) string
+
+	// CreateAuditLogSQL returns the statements creating the append-only
+	// audit_log table and, where the backend allows it, triggers rejecting
+	// updates and deletes of its rows.
+	CreateAuditLogSQL() []string
+
+	// CreateColorRulesTableSQL returns DDL for the color_rules table: filter
+	// conditions, or saved query names, paired with the color to give the
+	// events they match.
+	CreateColorRulesTableSQL() string
 }
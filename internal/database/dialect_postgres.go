@@ -1,6 +1,9 @@
 package database
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // pgQuoteCol wraps a column name in double quotes if it is a PostgreSQL reserved word.
 // Columns like "user", "desc", and "offset" require quoting to avoid conflicts
@@ -25,7 +28,12 @@ var strftimeToPostgres = map[string]string{
 
 // PostgresDialect implements the Dialect interface for PostgreSQL databases.
 // It also satisfies query.QueryDialect through structural typing.
-type PostgresDialect struct{}
+//
+// Trigram enables substring matching in search predicates and should be set
+// only when the pg_trgm index exists (see PostgresStore.HasTrigramIndex).
+type PostgresDialect struct {
+	Trigram bool
+}
 
 func (d *PostgresDialect) DriverName() string              { return "pgx" }
 func (d *PostgresDialect) DSN(pathOrConnStr string) string  { return pathOrConnStr }
@@ -46,6 +54,36 @@ func (d *PostgresDialect) DateFormatSQL(column, format string) string {
 	return fmt.Sprintf("to_char(%s, '%s')", column, pgFmt)
 }
 
+// NullsLast implements query.TimestampDialect: PostgreSQL sorts NULLs last in
+// ascending order.
+func (d *PostgresDialect) NullsLast() bool { return true }
+
+// RegexpSQL implements query.MatchDialect with PostgreSQL's POSIX regular
+// expression operators.
+func (d *PostgresDialect) RegexpSQL(column, placeholder string, negate bool) string {
+	if negate {
+		return column + " !~ " + placeholder
+	}
+	return column + " ~ " + placeholder
+}
+
+// ContainsCaseSQL implements query.MatchDialect.
+func (d *PostgresDialect) ContainsCaseSQL(column, placeholder string) string {
+	return "strpos(" + column + ", " + placeholder + ") > 0"
+}
+
+// LikeSQL implements query.MatchDialect. LIKE matches case on PostgreSQL,
+// so ILIKE is used instead.
+func (d *PostgresDialect) LikeSQL(column, placeholder string) string {
+	return column + " ILIKE " + placeholder + ` ESCAPE '\'`
+}
+
+// IntegerSQL implements query.IntegerDialect. The cast is guarded, since
+// PostgreSQL fails the whole query on text that is not a number.
+func (d *PostgresDialect) IntegerSQL(column string) string {
+	return "(CASE WHEN " + column + " ~ '^[0-9]{1,18}$' THEN CAST(" + column + " AS BIGINT) END)"
+}
+
 func (d *PostgresDialect) SchemaCheckColumnSQL(table, column string) string {
 	return fmt.Sprintf(
 		"SELECT COUNT(*) FROM information_schema.columns WHERE table_name='%s' AND column_name='%s'",
@@ -63,7 +101,7 @@ func (d *PostgresDialect) CreateTableSQL() string {
 		store_index INT, vss_store_number INT, URL TEXT,
 		record_number TEXT, event_identifier TEXT, event_type TEXT,
 		source_name TEXT, user_sid TEXT, computer_name TEXT,
-		bookmark INT DEFAULT 0
+		bookmark INT DEFAULT 0, batch_id INT DEFAULT 0
 	)`
 }
 
@@ -77,7 +115,7 @@ func (d *PostgresDialect) CreateTagsTableSQL() string {
 }
 
 func (d *PostgresDialect) CreateSavedQueryTableSQL() string {
-	return "CREATE TABLE IF NOT EXISTS l2t_saved_query (name TEXT, query TEXT)"
+	return "CREATE TABLE IF NOT EXISTS l2t_saved_query (name TEXT, query TEXT, description TEXT, category TEXT, author TEXT, os TEXT, event_ids TEXT, parameters TEXT)"
 }
 
 func (d *PostgresDialect) CreateDiskTableSQL() string {
@@ -93,9 +131,13 @@ func (d *PostgresDialect) InsertDefaultDiskSQL() string {
 		VALUES (0, '', '', '', '', '')`
 }
 
-func (d *PostgresDialect) CreateIndexSQL(indexName, tableName, column string) string {
+func (d *PostgresDialect) CreateIndexSQL(indexName, tableName string, columns ...string) string {
+	cols := make([]string, len(columns))
+	for i, c := range columns {
+		cols[i] = pgQuoteCol(c)
+	}
 	return fmt.Sprintf(
-		"CREATE INDEX IF NOT EXISTS %s ON %s (%s)", indexName, tableName, pgQuoteCol(column))
+		"CREATE INDEX IF NOT EXISTS %s ON %s (%s)", indexName, tableName, strings.Join(cols, ", "))
 }
 
 func (d *PostgresDialect) DropIndexSQL(indexName string) string {
@@ -107,8 +149,9 @@ func (d *PostgresDialect) InsertEventSQL() string {
 		timezone, MACB, source, sourcetype, type, "user", host, "desc", filename,
 		inode, notes, format, extra, datetime, reportnotes, inreport, tag, color,
 		"offset", store_number, store_index, vss_store_number, URL, record_number,
-		event_identifier, event_type, source_name, user_sid, computer_name, bookmark
-	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30)`
+		event_identifier, event_type, source_name, user_sid, computer_name, bookmark,
+		batch_id
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31)`
 }
 
 func (d *PostgresDialect) CreateExaminerNotesTableSQL() string {
@@ -126,3 +169,116 @@ func (d *PostgresDialect) CreateExaminerNotesTableSQL() string {
 func (d *PostgresDialect) InsertExaminerNoteSQL() string {
 	return `INSERT INTO examiner_notes (datetime, description, tag, color, bookmark) VALUES ($1, $2, $3, $4, $5) RETURNING id`
 }
+
+func (d *PostgresDialect) CreateProvenanceTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS l2t_provenance (
+		id SERIAL PRIMARY KEY,
+		action TEXT,
+		detail TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+}
+
+func (d *PostgresDialect) CreateSchemaVersionTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS schema_version (
+		version INT PRIMARY KEY,
+		description TEXT,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+}
+
+func (d *PostgresDialect) CreateCaseInfoTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS case_info (
+		id INT PRIMARY KEY,
+		case_number TEXT,
+		title TEXT,
+		examiners TEXT,
+		description TEXT,
+		created_at TIMESTAMP,
+		closed_at TIMESTAMP
+	)`
+}
+
+func (d *PostgresDialect) CreateEvidenceTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS evidence_items (
+		id SERIAL PRIMARY KEY,
+		name TEXT,
+		kind TEXT,
+		description TEXT,
+		path TEXT,
+		md5 TEXT,
+		sha1 TEXT,
+		sha256 TEXT,
+		added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+}
+
+func (d *PostgresDialect) CreateImportBatchTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS import_batches (
+		id SERIAL PRIMARY KEY,
+		evidence_id INT DEFAULT 0,
+		source_path TEXT,
+		format TEXT,
+		imported_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+}
+
+func (d *PostgresDialect) CreateTagDefinitionsTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS tags (
+		id SERIAL PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		color TEXT DEFAULT '',
+		description TEXT DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+}
+
+func (d *PostgresDialect) CreateEventTagsTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS event_tags (
+		event_id BIGINT NOT NULL,
+		tag_id INT NOT NULL,
+		PRIMARY KEY (event_id, tag_id),
+		UNIQUE (tag_id, event_id)
+	)`
+}
+
+func (d *PostgresDialect) TagListSQL(eventID string) string {
+	return "(SELECT COALESCE(string_agg(t.name, ',' ORDER BY t.name), '') FROM event_tags et " +
+		"JOIN tags t ON t.id = et.tag_id WHERE et.event_id = " + eventID + ")"
+}
+
+func (d *PostgresDialect) CreateColorRulesTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS color_rules (
+		id SERIAL PRIMARY KEY,
+		name TEXT,
+		filters TEXT,
+		logic TEXT DEFAULT 'AND',
+		saved_query TEXT DEFAULT '',
+		color TEXT,
+		priority INT DEFAULT 0,
+		enabled INT DEFAULT 1
+	)`
+}
+
+func (d *PostgresDialect) CreateAuditLogSQL() []string {
+	return []string{
+		`CREATE TABLE IF NOT EXISTS audit_log (
+		id SERIAL PRIMARY KEY,
+		created_at TIMESTAMP,
+		examiner TEXT,
+		operation TEXT,
+		target TEXT,
+		ids TEXT,
+		old_values TEXT,
+		new_values TEXT
+	)`,
+		`CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+	BEGIN
+		RAISE EXCEPTION 'audit_log is append-only';
+	END;
+	$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log`,
+		`CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+	FOR EACH ROW EXECUTE FUNCTION audit_log_append_only()`,
+	}
+}
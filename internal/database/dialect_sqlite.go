@@ -1,6 +1,9 @@
 package database
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // SQLiteDialect implements the Dialect interface for SQLite databases.
 // It also satisfies query.QueryDialect through structural typing.
@@ -35,7 +38,7 @@ func (d *SQLiteDialect) CreateTableSQL() string {
 		store_index INT, vss_store_number INT, URL TEXT,
 		record_number TEXT, event_identifier TEXT, event_type TEXT,
 		source_name TEXT, user_sid TEXT, computer_name TEXT,
-		bookmark INT DEFAULT 0
+		bookmark INT DEFAULT 0, batch_id INT DEFAULT 0
 	)`
 }
 
@@ -49,7 +52,7 @@ func (d *SQLiteDialect) CreateTagsTableSQL() string {
 }
 
 func (d *SQLiteDialect) CreateSavedQueryTableSQL() string {
-	return "CREATE TABLE IF NOT EXISTS l2t_saved_query (name TEXT, query TEXT)"
+	return "CREATE TABLE IF NOT EXISTS l2t_saved_query (name TEXT, query TEXT, description TEXT, category TEXT, author TEXT, os TEXT, event_ids TEXT, parameters TEXT)"
 }
 
 func (d *SQLiteDialect) CreateDiskTableSQL() string {
@@ -65,9 +68,9 @@ func (d *SQLiteDialect) InsertDefaultDiskSQL() string {
 		VALUES (0, '', '', '', '', '')`
 }
 
-func (d *SQLiteDialect) CreateIndexSQL(indexName, tableName, column string) string {
+func (d *SQLiteDialect) CreateIndexSQL(indexName, tableName string, columns ...string) string {
 	return fmt.Sprintf(
-		"CREATE INDEX IF NOT EXISTS %s ON %s (%s)", indexName, tableName, column)
+		"CREATE INDEX IF NOT EXISTS %s ON %s (%s)", indexName, tableName, strings.Join(columns, ", "))
 }
 
 func (d *SQLiteDialect) DropIndexSQL(indexName string) string {
@@ -79,8 +82,9 @@ func (d *SQLiteDialect) InsertEventSQL() string {
 		timezone, MACB, source, sourcetype, type, user, host, desc, filename,
 		inode, notes, format, extra, datetime, reportnotes, inreport, tag, color,
 		offset, store_number, store_index, vss_store_number, URL, record_number,
-		event_identifier, event_type, source_name, user_sid, computer_name, bookmark
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		event_identifier, event_type, source_name, user_sid, computer_name, bookmark,
+		batch_id
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 }
 
 func (d *SQLiteDialect) CreateExaminerNotesTableSQL() string {
@@ -98,3 +102,112 @@ func (d *SQLiteDialect) CreateExaminerNotesTableSQL() string {
 func (d *SQLiteDialect) InsertExaminerNoteSQL() string {
 	return `INSERT INTO examiner_notes (datetime, description, tag, color, bookmark) VALUES (?, ?, ?, ?, ?)`
 }
+
+func (d *SQLiteDialect) CreateProvenanceTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS l2t_provenance (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		action TEXT,
+		detail TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`
+}
+
+func (d *SQLiteDialect) CreateSchemaVersionTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		description TEXT,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`
+}
+
+func (d *SQLiteDialect) CreateCaseInfoTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS case_info (
+		id INTEGER PRIMARY KEY,
+		case_number TEXT,
+		title TEXT,
+		examiners TEXT,
+		description TEXT,
+		created_at DATETIME,
+		closed_at DATETIME
+	)`
+}
+
+func (d *SQLiteDialect) CreateEvidenceTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS evidence_items (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT,
+		kind TEXT,
+		description TEXT,
+		path TEXT,
+		md5 TEXT,
+		sha1 TEXT,
+		sha256 TEXT,
+		added_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`
+}
+
+func (d *SQLiteDialect) CreateImportBatchTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS import_batches (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		evidence_id INT DEFAULT 0,
+		source_path TEXT,
+		format TEXT,
+		imported_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`
+}
+
+func (d *SQLiteDialect) CreateTagDefinitionsTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS tags (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+		color TEXT DEFAULT '',
+		description TEXT DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`
+}
+
+func (d *SQLiteDialect) CreateEventTagsTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS event_tags (
+		event_id INTEGER NOT NULL,
+		tag_id INTEGER NOT NULL,
+		PRIMARY KEY (event_id, tag_id),
+		UNIQUE (tag_id, event_id)
+	)`
+}
+
+func (d *SQLiteDialect) TagListSQL(eventID string) string {
+	return "(SELECT COALESCE(group_concat(t.name, ',' ORDER BY t.name), '') FROM event_tags et " +
+		"JOIN tags t ON t.id = et.tag_id WHERE et.event_id = " + eventID + ")"
+}
+
+func (d *SQLiteDialect) CreateColorRulesTableSQL() string {
+	return `CREATE TABLE IF NOT EXISTS color_rules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT,
+		filters TEXT,
+		logic TEXT DEFAULT 'AND',
+		saved_query TEXT DEFAULT '',
+		color TEXT,
+		priority INT DEFAULT 0,
+		enabled INT DEFAULT 1
+	)`
+}
+
+func (d *SQLiteDialect) CreateAuditLogSQL() []string {
+	return []string{
+		`CREATE TABLE IF NOT EXISTS audit_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		created_at DATETIME,
+		examiner TEXT,
+		operation TEXT,
+		target TEXT,
+		ids TEXT,
+		old_values TEXT,
+		new_values TEXT
+	)`,
+		`CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+	BEGIN SELECT RAISE(ABORT, 'audit_log is append-only'); END`,
+		`CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+	BEGIN SELECT RAISE(ABORT, 'audit_log is append-only'); END`,
+	}
+}
@@ -0,0 +1,399 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// QueryPlan is how the backend runs a query: its plan, one step per line
+// and indented by depth, the tables it reads in full, the indexes it uses
+// and how long the query took.
+type QueryPlan struct {
+	Lines      []string `json:"lines"`
+	FullScans  []string `json:"fullScans"`
+	Indexes    []string `json:"indexes"`
+	DurationMs float64  `json:"durationMs"`
+}
+
+// IndexInfo is an index on log2timeline and its columns, in order.
+type IndexInfo struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+}
+
+// IndexRecommendation is an index that would let a query avoid a full
+// scan of log2timeline.
+type IndexRecommendation struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Reason  string   `json:"reason"`
+}
+
+// unindexedFields are never recommended for an index: long free text,
+// which only substring searches use, and tags, which are matched through
+// the event_tags table.
+var unindexedFields = map[string]bool{
+	"desc": true, "extra": true, "notes": true, "reportnotes": true, "tag": true,
+}
+
+// IndexName returns the name 4n6time gives an index on columns, such as
+// log2timeline_source_datetime_idx. Like batchIndexName, it avoids the
+// "<field>_idx" names that RebuildIndexes drops.
+func IndexName(columns []string) string {
+	return "log2timeline_" + strings.Join(columns, "_") + "_idx"
+}
+
+// RecommendIndexes suggests indexes for a query filtering on fields and
+// sorted by sortField, skipping any an existing index already covers. A
+// field filtered on alongside a datetime sort or filter is paired with
+// datetime, as in (source, datetime), so one index both narrows and orders
+// the rows; other fields get single-column indexes.
+func RecommendIndexes(fields []string, sortField string, existing []IndexInfo) []IndexRecommendation {
+	withDatetime := sortField == "datetime" || slices.Contains(fields, "datetime")
+
+	var recs []IndexRecommendation
+	add := func(reason string, columns ...string) {
+		for _, idx := range existing {
+			if len(idx.Columns) >= len(columns) && slices.Equal(idx.Columns[:len(columns)], columns) {
+				return
+			}
+		}
+		for _, r := range recs {
+			if slices.Equal(r.Columns, columns) {
+				return
+			}
+		}
+		recs = append(recs, IndexRecommendation{Name: IndexName(columns), Columns: columns, Reason: reason})
+	}
+
+	for _, f := range fields {
+		if f == "datetime" || unindexedFields[f] || !isValidField(f) {
+			continue
+		}
+		if withDatetime {
+			add(fmt.Sprintf("filters on %s and orders or filters by datetime", f), f, "datetime")
+		} else {
+			add(fmt.Sprintf("filters on %s", f), f)
+		}
+	}
+	if len(recs) == 0 && withDatetime {
+		add("orders or filters by datetime", "datetime")
+	}
+	if len(recs) == 0 && sortField != "" && isValidField(sortField) && !unindexedFields[sortField] {
+		add(fmt.Sprintf("orders by %s", sortField), sortField)
+	}
+	return recs
+}
+
+// sqlitePlanIndex matches the index named in an EXPLAIN QUERY PLAN step.
+var sqlitePlanIndex = regexp.MustCompile(`USING (?:COVERING )?INDEX (\S+)`)
+
+// explainSQLite reads the plan from EXPLAIN QUERY PLAN, then times the
+// query itself, since SQLite cannot report both at once.
+func explainSQLite(conn *sql.DB, query string, args []interface{}) (*QueryPlan, error) {
+	rows, err := conn.Query("EXPLAIN QUERY PLAN "+query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("explaining query: %w", err)
+	}
+	defer rows.Close()
+
+	plan := &QueryPlan{}
+	depth := make(map[int64]int)
+	for rows.Next() {
+		var id, parent, notUsed int64
+		var detail string
+		if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+			return nil, fmt.Errorf("reading query plan: %w", err)
+		}
+		depth[id] = depth[parent] + 1
+		plan.Lines = append(plan.Lines, strings.Repeat("  ", depth[id]-1)+detail)
+
+		// "SCAN log2timeline", or "SCAN TABLE log2timeline" before 3.36
+		if f := strings.Fields(detail); len(f) > 1 && f[0] == "SCAN" {
+			table := f[1]
+			if table == "TABLE" && len(f) > 2 {
+				table = f[2]
+			}
+			plan.FullScans = appendUnique(plan.FullScans, table)
+		}
+		if m := sqlitePlanIndex.FindStringSubmatch(detail); m != nil {
+			plan.Indexes = appendUnique(plan.Indexes, m[1])
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	plan.DurationMs, err = timeQuery(conn, query, args)
+	return plan, err
+}
+
+// pgPlanNode is a node of a PostgreSQL JSON plan.
+type pgPlanNode struct {
+	NodeType   string       `json:"Node Type"`
+	Relation   string       `json:"Relation Name"`
+	Index      string       `json:"Index Name"`
+	ActualRows float64      `json:"Actual Rows"`
+	ActualTime float64      `json:"Actual Total Time"`
+	Plans      []pgPlanNode `json:"Plans"`
+}
+
+// explainPostgres runs the query under EXPLAIN ANALYZE, which reports the
+// plan and the time it took together.
+func explainPostgres(conn *sql.DB, query string, args []interface{}) (*QueryPlan, error) {
+	var raw []byte
+	if err := conn.QueryRow("EXPLAIN (ANALYZE, FORMAT JSON) "+query, args...).Scan(&raw); err != nil {
+		return nil, fmt.Errorf("explaining query: %w", err)
+	}
+	var out []struct {
+		Plan          pgPlanNode `json:"Plan"`
+		PlanningTime  float64    `json:"Planning Time"`
+		ExecutionTime float64    `json:"Execution Time"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("reading query plan: %w", err)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("reading query plan: empty plan")
+	}
+
+	plan := &QueryPlan{DurationMs: out[0].PlanningTime + out[0].ExecutionTime}
+	var walk func(n pgPlanNode, depth int)
+	walk = func(n pgPlanNode, depth int) {
+		line := n.NodeType
+		if n.Relation != "" {
+			line += " on " + n.Relation
+		}
+		if n.Index != "" {
+			line += " using " + n.Index
+			plan.Indexes = appendUnique(plan.Indexes, n.Index)
+		}
+		if n.NodeType == "Seq Scan" && n.Relation != "" {
+			plan.FullScans = appendUnique(plan.FullScans, n.Relation)
+		}
+		line += fmt.Sprintf(" (rows=%.0f, %.2f ms)", n.ActualRows, n.ActualTime)
+		plan.Lines = append(plan.Lines, strings.Repeat("  ", depth)+line)
+		for _, child := range n.Plans {
+			walk(child, depth+1)
+		}
+	}
+	walk(out[0].Plan, 0)
+	return plan, nil
+}
+
+// explainMySQL reads the plan from EXPLAIN, one line per table accessed,
+// then times the query itself.
+func explainMySQL(conn *sql.DB, query string, args []interface{}) (*QueryPlan, error) {
+	rows, err := conn.Query("EXPLAIN "+query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("explaining query: %w", err)
+	}
+	defer rows.Close()
+
+	// The EXPLAIN columns differ between MySQL and MariaDB versions, so
+	// they are read by name
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	plan := &QueryPlan{}
+	for rows.Next() {
+		values := make([]sql.NullString, len(cols))
+		ptrs := make([]interface{}, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("reading query plan: %w", err)
+		}
+		row := make(map[string]string, len(cols))
+		var parts []string
+		for i, c := range cols {
+			row[strings.ToLower(c)] = values[i].String
+			if values[i].Valid && values[i].String != "" {
+				parts = append(parts, c+"="+values[i].String)
+			}
+		}
+		plan.Lines = append(plan.Lines, strings.Join(parts, " "))
+		if row["type"] == "ALL" && row["table"] != "" {
+			plan.FullScans = appendUnique(plan.FullScans, row["table"])
+		}
+		if row["key"] != "" {
+			plan.Indexes = appendUnique(plan.Indexes, row["key"])
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	plan.DurationMs, err = timeQuery(conn, query, args)
+	return plan, err
+}
+
+// timeQuery runs a query, reading every row, and returns how long it took
+// in milliseconds.
+func timeQuery(conn *sql.DB, query string, args []interface{}) (float64, error) {
+	start := time.Now()
+	rows, err := conn.Query(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("timing query: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("timing query: %w", err)
+	}
+	return float64(time.Since(start).Microseconds()) / 1000, nil
+}
+
+func appendUnique(list []string, s string) []string {
+	if slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
+
+// listIndexes returns the indexes on log2timeline, read with the query
+// for the backend, which yields one row per index column in order.
+func listIndexes(conn *sql.DB, query string) ([]IndexInfo, error) {
+	rows, err := conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("listing indexes: %w", err)
+	}
+	defer rows.Close()
+
+	var indexes []IndexInfo
+	for rows.Next() {
+		var name, column string
+		if err := rows.Scan(&name, &column); err != nil {
+			return nil, fmt.Errorf("listing indexes: %w", err)
+		}
+		if n := len(indexes); n > 0 && indexes[n-1].Name == name {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, column)
+		} else {
+			indexes = append(indexes, IndexInfo{Name: name, Columns: []string{column}})
+		}
+	}
+	return indexes, rows.Err()
+}
+
+const (
+	sqliteIndexesSQL = `SELECT il.name, ii.name
+		FROM pragma_index_list('log2timeline') il, pragma_index_info(il.name) ii
+		WHERE ii.name IS NOT NULL
+		ORDER BY il.name, ii.seqno`
+
+	postgresIndexesSQL = `SELECT i.relname, a.attname
+		FROM pg_index x
+		JOIN pg_class i ON i.oid = x.indexrelid
+		JOIN pg_class t ON t.oid = x.indrelid
+		CROSS JOIN LATERAL unnest(x.indkey) WITH ORDINALITY AS k(attnum, ord)
+		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		WHERE t.relname = 'log2timeline' AND t.relnamespace = to_regnamespace(current_schema())::oid
+		ORDER BY i.relname, k.ord`
+
+	mysqlIndexesSQL = `SELECT index_name, column_name
+		FROM information_schema.statistics
+		WHERE table_schema = DATABASE() AND table_name = 'log2timeline'
+		ORDER BY index_name, seq_in_index`
+)
+
+// validateIndexColumns checks the columns of a new index.
+func validateIndexColumns(columns []string) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("an index needs at least one column")
+	}
+	for i, c := range columns {
+		if !isValidField(c) {
+			return fmt.Errorf("invalid field name: %s", c)
+		}
+		if slices.Contains(columns[:i], c) {
+			return fmt.Errorf("column %s is listed twice", c)
+		}
+	}
+	return nil
+}
+
+// -- Store methods --
+
+// ExplainQuery reports how SQLite runs a query and how long it takes.
+func (db *SQLiteStore) ExplainQuery(query string, args []interface{}) (*QueryPlan, error) {
+	return explainSQLite(db.conn, query, args)
+}
+
+// ExplainQuery reports how PostgreSQL runs a query and how long it takes.
+// The query is executed.
+func (db *PostgresStore) ExplainQuery(query string, args []interface{}) (*QueryPlan, error) {
+	return explainPostgres(db.conn, query, args)
+}
+
+// ExplainQuery reports how MySQL runs a query and how long it takes.
+func (db *MySQLStore) ExplainQuery(query string, args []interface{}) (*QueryPlan, error) {
+	return explainMySQL(db.conn, query, args)
+}
+
+// ListIndexes returns the indexes on log2timeline.
+func (db *SQLiteStore) ListIndexes() ([]IndexInfo, error) {
+	return listIndexes(db.conn, sqliteIndexesSQL)
+}
+
+// ListIndexes returns the indexes on log2timeline.
+func (db *PostgresStore) ListIndexes() ([]IndexInfo, error) {
+	return listIndexes(db.conn, postgresIndexesSQL)
+}
+
+// ListIndexes returns the indexes on log2timeline.
+func (db *MySQLStore) ListIndexes() ([]IndexInfo, error) {
+	return listIndexes(db.conn, mysqlIndexesSQL)
+}
+
+// CreateIndex creates an index on columns of log2timeline, named by
+// IndexName, unless it exists. Returns the index name.
+func (db *SQLiteStore) CreateIndex(columns []string) (string, error) {
+	if err := validateIndexColumns(columns); err != nil {
+		return "", err
+	}
+	name := IndexName(columns)
+	if _, err := db.conn.Exec(db.dialect.CreateIndexSQL(name, "log2timeline", columns...)); err != nil {
+		return "", fmt.Errorf("creating index %s: %w", name, err)
+	}
+	return name, nil
+}
+
+// CreateIndex creates an index on columns of log2timeline, named by
+// IndexName, unless it exists. Returns the index name.
+func (db *PostgresStore) CreateIndex(columns []string) (string, error) {
+	if err := validateIndexColumns(columns); err != nil {
+		return "", err
+	}
+	name := IndexName(columns)
+	if _, err := db.conn.Exec(db.dialect.CreateIndexSQL(name, "log2timeline", columns...)); err != nil {
+		return "", fmt.Errorf("creating index %s: %w", name, err)
+	}
+	return name, nil
+}
+
+// CreateIndex creates an index on columns of log2timeline, named by
+// IndexName, unless it exists. Returns the index name.
+func (db *MySQLStore) CreateIndex(columns []string) (string, error) {
+	if err := validateIndexColumns(columns); err != nil {
+		return "", err
+	}
+	name := IndexName(columns)
+	exists, err := db.indexExists(name)
+	if err != nil {
+		return "", fmt.Errorf("checking index %s: %w", name, err)
+	}
+	if !exists {
+		if _, err := db.conn.Exec(db.dialect.CreateIndexSQL(name, "log2timeline", columns...)); err != nil {
+			return "", fmt.Errorf("creating index %s: %w", name, err)
+		}
+	}
+	return name, nil
+}
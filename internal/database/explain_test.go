@@ -0,0 +1,97 @@
+package database
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExplainQuerySQLite(t *testing.T) {
+	db := createTestDB(t)
+	if err := db.InsertEvent(sampleEvent()); err != nil {
+		t.Fatal(err)
+	}
+
+	query := "SELECT rowid FROM log2timeline WHERE filename = ? ORDER BY datetime"
+	args := []interface{}{"/Users/admin/test.txt"}
+	plan, err := db.ExplainQuery(query, args)
+	if err != nil {
+		t.Fatalf("ExplainQuery failed: %v", err)
+	}
+	if len(plan.Lines) == 0 || !slices.Contains(plan.FullScans, "log2timeline") {
+		t.Errorf("expected a full scan of log2timeline, got %+v", plan)
+	}
+
+	name, err := db.CreateIndex([]string{"filename", "datetime"})
+	if err != nil {
+		t.Fatalf("CreateIndex failed: %v", err)
+	}
+	if name != "log2timeline_filename_datetime_idx" {
+		t.Errorf("CreateIndex name = %q", name)
+	}
+	// Creating it again is a no-op
+	if _, err := db.CreateIndex([]string{"filename", "datetime"}); err != nil {
+		t.Fatalf("CreateIndex twice failed: %v", err)
+	}
+
+	plan, err = db.ExplainQuery(query, args)
+	if err != nil {
+		t.Fatalf("ExplainQuery failed: %v", err)
+	}
+	if len(plan.FullScans) != 0 || !slices.Contains(plan.Indexes, "log2timeline_filename_datetime_idx") {
+		t.Errorf("expected the new index to be used, got %+v", plan)
+	}
+
+	// Rebuilding the field indexes keeps it, even on a single column
+	if _, err := db.CreateIndex([]string{"source"}); err != nil {
+		t.Fatalf("CreateIndex failed: %v", err)
+	}
+	if err := db.RebuildIndexes([]string{"source"}); err != nil {
+		t.Fatalf("RebuildIndexes failed: %v", err)
+	}
+	indexes, err := db.ListIndexes()
+	if err != nil {
+		t.Fatalf("ListIndexes failed: %v", err)
+	}
+	var names []string
+	for _, idx := range indexes {
+		names = append(names, idx.Name)
+	}
+	for _, want := range []string{"log2timeline_filename_datetime_idx", "log2timeline_source_idx", "source_idx"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("after RebuildIndexes, indexes = %v; want %s", names, want)
+		}
+	}
+
+	for _, cols := range [][]string{nil, {"nope"}, {"host", "host"}} {
+		if _, err := db.CreateIndex(cols); err == nil {
+			t.Errorf("CreateIndex(%v): expected an error", cols)
+		}
+	}
+}
+
+func TestRecommendIndexes(t *testing.T) {
+	existing := []IndexInfo{
+		{Name: "host_idx", Columns: []string{"host"}},
+		{Name: "user_datetime_idx", Columns: []string{"user", "datetime"}},
+	}
+
+	recs := RecommendIndexes([]string{"source", "user", "desc", "tag"}, "datetime", existing)
+	if len(recs) != 1 || recs[0].Name != "log2timeline_source_datetime_idx" || !slices.Equal(recs[0].Columns, []string{"source", "datetime"}) {
+		t.Errorf("RecommendIndexes = %+v", recs)
+	}
+
+	// Without datetime the fields are indexed alone, and host is covered
+	recs = RecommendIndexes([]string{"host", "filename"}, "", existing)
+	if len(recs) != 1 || recs[0].Name != "log2timeline_filename_idx" {
+		t.Errorf("RecommendIndexes without datetime = %+v", recs)
+	}
+
+	// A bare sort still gets an index
+	recs = RecommendIndexes(nil, "datetime", existing)
+	if len(recs) != 1 || recs[0].Name != "log2timeline_datetime_idx" {
+		t.Errorf("RecommendIndexes for a sort = %+v", recs)
+	}
+	if recs = RecommendIndexes([]string{"desc"}, "", nil); len(recs) != 0 {
+		t.Errorf("expected no recommendations for free text, got %+v", recs)
+	}
+}
@@ -0,0 +1,266 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/cdtdelta/4n6time/internal/model"
+)
+
+// Snippet highlight markers. Search snippets wrap each matched term in these
+// control characters rather than HTML so the frontend can split on them and
+// render highlights without interpreting event text as markup.
+const (
+	SnippetMarkOpen  = "\x02"
+	SnippetMarkClose = "\x03"
+)
+
+// SearchMatch is a ranked full-text match for one event, used to highlight
+// search hits in the grid. Lower Rank values are more relevant.
+type SearchMatch struct {
+	ID      int64   `json:"id"`
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
+
+// ftsColumnList returns model.SearchFields as a quoted column list, with each
+// name prefixed by prefix (e.g. "new.") for use inside triggers.
+func ftsColumnList(prefix string) string {
+	cols := make([]string, len(model.SearchFields))
+	for i, f := range model.SearchFields {
+		cols[i] = prefix + `"` + f + `"`
+	}
+	return strings.Join(cols, ", ")
+}
+
+// CreateFullTextSQL returns the DDL for the log2timeline_fts FTS5 index and
+// the triggers that keep it in sync with log2timeline. The index uses
+// log2timeline as external content, so it stores only the inverted index and
+// not a second copy of the text. The update trigger fires only when a
+// searchable column changes, so bookmark and color edits do not touch it.
+func (d *SQLiteDialect) CreateFullTextSQL() []string {
+	cols := ftsColumnList("")
+	newCols := ftsColumnList("new.")
+	oldCols := ftsColumnList("old.")
+	return []string{
+		"CREATE VIRTUAL TABLE IF NOT EXISTS log2timeline_fts USING fts5(" + cols +
+			", content='log2timeline', content_rowid='rowid')",
+		"CREATE TRIGGER IF NOT EXISTS log2timeline_fts_ai AFTER INSERT ON log2timeline BEGIN " +
+			"INSERT INTO log2timeline_fts(rowid, " + cols + ") VALUES (new.rowid, " + newCols + "); END",
+		"CREATE TRIGGER IF NOT EXISTS log2timeline_fts_ad AFTER DELETE ON log2timeline BEGIN " +
+			"INSERT INTO log2timeline_fts(log2timeline_fts, rowid, " + cols + ") VALUES ('delete', old.rowid, " + oldCols + "); END",
+		"CREATE TRIGGER IF NOT EXISTS log2timeline_fts_au AFTER UPDATE OF " + cols + " ON log2timeline BEGIN " +
+			"INSERT INTO log2timeline_fts(log2timeline_fts, rowid, " + cols + ") VALUES ('delete', old.rowid, " + oldCols + "); " +
+			"INSERT INTO log2timeline_fts(rowid, " + cols + ") VALUES (new.rowid, " + newCols + "); END",
+	}
+}
+
+// FullTextPredicate implements query.FullTextDialect by matching the FTS5
+// index with the text converted by fts5Query.
+func (d *SQLiteDialect) FullTextPredicate(text string, paramIdx int) (string, []interface{}, bool) {
+	q := fts5Query(text)
+	if q == "" {
+		return "", nil, false
+	}
+	return "(rowid IN (SELECT rowid FROM log2timeline_fts WHERE log2timeline_fts MATCH ?))",
+		[]interface{}{q}, true
+}
+
+// Kinds of searchToken.
+const (
+	searchTerm = iota
+	searchOp
+	searchOpen
+	searchClose
+)
+
+// searchToken is one element of parsed search bar text. For terms, text is
+// the word or phrase and prefix reports a trailing '*'; for operators, text
+// is AND, OR or NOT.
+type searchToken struct {
+	kind   int
+	text   string
+	prefix bool
+}
+
+// parseSearch splits search bar text into terms, operators and parentheses.
+//
+// Supported syntax:
+//
+//	word          matches the token anywhere in the searchable columns
+//	word*         prefix match
+//	"two words"   phrase match ("two words"* for a phrase prefix)
+//	a AND b       both (also the default between terms)
+//	a OR b        either
+//	a NOT b       a but not b
+//	( ... )       grouping
+//
+// Operators in invalid positions are dropped, as are parentheses if they are
+// unbalanced, so the result is always a well-formed expression. Returns nil
+// if the text contains no searchable terms.
+func parseSearch(text string) []searchToken {
+	var tokens []searchToken
+	depth, balanced := 0, true
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, searchToken{kind: searchOpen})
+			depth++
+			i++
+		case r == ')':
+			tokens = append(tokens, searchToken{kind: searchClose})
+			depth--
+			if depth < 0 {
+				balanced = false
+			}
+			i++
+		case r == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				j++
+			}
+			phrase := string(runes[i+1 : j])
+			i = j + 1
+			prefix := i < len(runes) && runes[i] == '*'
+			if prefix {
+				i++
+			}
+			if hasSearchableRune(phrase) {
+				tokens = append(tokens, searchToken{searchTerm, phrase, prefix})
+			}
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && runes[j] != '"' && runes[j] != '(' && runes[j] != ')' {
+				j++
+			}
+			word := string(runes[i:j])
+			i = j
+			if word == "AND" || word == "OR" || word == "NOT" {
+				tokens = append(tokens, searchToken{kind: searchOp, text: word})
+				continue
+			}
+			prefix := strings.HasSuffix(word, "*")
+			word = strings.TrimRight(word, "*")
+			if hasSearchableRune(word) {
+				tokens = append(tokens, searchToken{searchTerm, word, prefix})
+			}
+		}
+	}
+	if depth != 0 {
+		balanced = false
+	}
+
+	var out []searchToken
+	terms := 0
+	for _, t := range tokens {
+		if !balanced && (t.kind == searchOpen || t.kind == searchClose) {
+			continue
+		}
+		switch t.kind {
+		case searchOp:
+			if len(out) == 0 || out[len(out)-1].kind == searchOp || out[len(out)-1].kind == searchOpen {
+				continue
+			}
+		case searchClose:
+			for len(out) > 0 && out[len(out)-1].kind == searchOp {
+				out = out[:len(out)-1]
+			}
+			if len(out) > 0 && out[len(out)-1].kind == searchOpen {
+				out = out[:len(out)-1]
+				continue
+			}
+		case searchTerm:
+			terms++
+		}
+		out = append(out, t)
+	}
+	for len(out) > 0 && out[len(out)-1].kind == searchOp {
+		out = out[:len(out)-1]
+	}
+	if terms == 0 {
+		return nil
+	}
+	return out
+}
+
+// fts5Query converts search bar text (see parseSearch) into an FTS5 MATCH
+// expression. Every term is emitted as a quoted FTS5 string, so punctuation
+// in event text (paths, URLs, SIDs) can never produce an FTS5 syntax error.
+// Returns "" if the text contains no searchable terms.
+func fts5Query(text string) string {
+	tokens := parseSearch(text)
+	parts := make([]string, len(tokens))
+	for i, t := range tokens {
+		switch t.kind {
+		case searchTerm:
+			parts[i] = fts5String(t.text, t.prefix)
+		case searchOp:
+			parts[i] = t.text
+		case searchOpen:
+			parts[i] = "("
+		case searchClose:
+			parts[i] = ")"
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// fts5String quotes s as an FTS5 string, doubling embedded quotes.
+func fts5String(s string, prefix bool) string {
+	q := `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	if prefix {
+		q += "*"
+	}
+	return q
+}
+
+// hasSearchableRune reports whether s contains a letter or digit, i.e.
+// whether the FTS5 tokenizer will produce at least one token from it.
+func hasSearchableRune(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchSnippets returns ranked snippets for the events in ids that match the
+// search text, most relevant first. Events that do not match (for example
+// examiner notes, which are not indexed) are omitted.
+func (db *SQLiteStore) SearchSnippets(text string, ids []int64) ([]SearchMatch, error) {
+	q := fts5Query(text)
+	if q == "" || len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := []interface{}{SnippetMarkOpen, SnippetMarkClose, q}
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+	rows, err := db.conn.Query(
+		"SELECT rowid, snippet(log2timeline_fts, -1, ?, ?, '…', 16), bm25(log2timeline_fts) "+
+			"FROM log2timeline_fts WHERE log2timeline_fts MATCH ? AND rowid IN ("+strings.Join(placeholders, ", ")+") "+
+			"ORDER BY rank", args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying search snippets: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []SearchMatch
+	for rows.Next() {
+		var m SearchMatch
+		if err := rows.Scan(&m.ID, &m.Snippet, &m.Rank); err != nil {
+			return nil, fmt.Errorf("scanning search snippet: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
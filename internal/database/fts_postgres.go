@@ -0,0 +1,168 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cdtdelta/4n6time/internal/model"
+)
+
+// pgSearchDocument returns the expression that concatenates every searchable
+// column into one text value. It is built only from immutable operators so it
+// can back both the generated tsvector column and the trigram expression
+// index; predicates must use the identical expression to hit the index.
+func pgSearchDocument() string {
+	parts := make([]string, len(model.SearchFields))
+	for i, f := range model.SearchFields {
+		parts[i] = "coalesce(" + pgQuoteCol(f) + ", '')"
+	}
+	return "(" + strings.Join(parts, " || ' ' || ") + ")"
+}
+
+// CreateFullTextSQL returns the DDL for the search_vector column and its GIN
+// index. The column is generated from pgSearchDocument, so PostgreSQL keeps it
+// in sync on every insert and edit without triggers. Requires PostgreSQL 12.
+// The 'simple' configuration lowercases tokens without stemming or stop
+// words, which suits file names, hashes and other forensic artifacts.
+func (d *PostgresDialect) CreateFullTextSQL() []string {
+	return []string{
+		"ALTER TABLE log2timeline ADD COLUMN IF NOT EXISTS search_vector tsvector " +
+			"GENERATED ALWAYS AS (to_tsvector('simple', " + pgSearchDocument() + ")) STORED",
+		"CREATE INDEX IF NOT EXISTS log2timeline_search_idx ON log2timeline USING GIN (search_vector)",
+	}
+}
+
+// CreateTrigramSQL returns the DDL for the optional pg_trgm index, which lets
+// search match arbitrary substrings (e.g. "katz" in "mimikatz") without a
+// sequential scan. Creating the extension needs the CREATE privilege on the
+// database, so callers treat failure as "trigram search unavailable".
+func (d *PostgresDialect) CreateTrigramSQL() []string {
+	return []string{
+		"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+		"CREATE INDEX IF NOT EXISTS log2timeline_search_trgm_idx ON log2timeline USING GIN (" +
+			pgSearchDocument() + " gin_trgm_ops)",
+	}
+}
+
+// FullTextPredicate implements query.FullTextDialect. Terms are matched
+// against search_vector; when the trigram index exists, rows containing the
+// search text as a substring also match, preserving the behaviour of the old
+// LIKE search.
+func (d *PostgresDialect) FullTextPredicate(text string, paramIdx int) (string, []interface{}, bool) {
+	tsq := tsQuery(text)
+	substring := "%" + strings.TrimSpace(text) + "%"
+	match := "search_vector @@ to_tsquery('simple', " + d.Placeholder(paramIdx) + ")"
+	switch {
+	case tsq != "" && d.Trigram:
+		return "(" + match + " OR " + pgSearchDocument() + " ILIKE " + d.Placeholder(paramIdx+1) + ")",
+			[]interface{}{tsq, substring}, true
+	case tsq != "":
+		return "(" + match + ")", []interface{}{tsq}, true
+	case d.Trigram:
+		return "(" + pgSearchDocument() + " ILIKE " + d.Placeholder(paramIdx) + ")",
+			[]interface{}{substring}, true
+	}
+	return "", nil, false
+}
+
+// tsQuery converts search bar text (see parseSearch) into to_tsquery syntax:
+// AND becomes &, OR becomes |, NOT becomes & !, and adjacent terms are joined
+// with &. Each term is a quoted lexeme, so to_tsquery turns multi-word
+// phrases into <-> sequences and punctuation cannot cause a syntax error.
+// Returns "" if the text contains no searchable terms.
+func tsQuery(text string) string {
+	var b strings.Builder
+	prevOperand := false
+	for _, t := range parseSearch(text) {
+		if (t.kind == searchTerm || t.kind == searchOpen) && prevOperand {
+			b.WriteString(" & ")
+		}
+		switch t.kind {
+		case searchTerm:
+			b.WriteString("'" + strings.NewReplacer(`\`, `\\`, "'", "''").Replace(t.text) + "'")
+			if t.prefix {
+				b.WriteString(":*")
+			}
+		case searchOp:
+			switch t.text {
+			case "AND":
+				b.WriteString(" & ")
+			case "OR":
+				b.WriteString(" | ")
+			case "NOT":
+				b.WriteString(" & !")
+			}
+		case searchOpen:
+			b.WriteString("(")
+		case searchClose:
+			b.WriteString(")")
+		}
+		prevOperand = t.kind == searchTerm || t.kind == searchClose
+	}
+	return b.String()
+}
+
+// enableTrigramSearch creates the pg_trgm index if the extension can be
+// installed, and records whether the index is present. Failure is not an
+// error: search then uses search_vector alone.
+func (db *PostgresStore) enableTrigramSearch() {
+	var count int
+	err := db.conn.QueryRow(
+		"SELECT COUNT(*) FROM pg_indexes WHERE tablename = 'log2timeline' AND indexname = 'log2timeline_search_trgm_idx'",
+	).Scan(&count)
+	if err == nil && count == 0 {
+		for _, stmt := range (&PostgresDialect{}).CreateTrigramSQL() {
+			if _, err = db.conn.Exec(stmt); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			count = 1
+		}
+	}
+	db.trigram = err == nil && count > 0
+}
+
+// HasTrigramIndex reports whether the pg_trgm substring index is available.
+// The app uses it to configure PostgresDialect.Trigram for search queries.
+func (db *PostgresStore) HasTrigramIndex() bool {
+	return db.trigram
+}
+
+// SearchSnippets returns ranked ts_headline snippets for the events in ids
+// whose search_vector matches the text, most relevant first. Rows that match
+// only as a trigram substring have no lexemes to highlight and are omitted.
+func (db *PostgresStore) SearchSnippets(text string, ids []int64) ([]SearchMatch, error) {
+	q := tsQuery(text)
+	if q == "" || len(ids) == 0 {
+		return nil, nil
+	}
+
+	options := fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxFragments=1, MaxWords=16, MinWords=4, FragmentDelimiter="…"`,
+		SnippetMarkOpen, SnippetMarkClose)
+	args := []interface{}{q, options}
+	placeholders := make([]string, len(ids))
+	for i, id := range ids {
+		placeholders[i] = db.dialect.Placeholder(i + 3)
+		args = append(args, id)
+	}
+	rows, err := db.conn.Query(
+		"SELECT id, ts_headline('simple', "+pgSearchDocument()+", to_tsquery('simple', $1), $2), "+
+			"-ts_rank(search_vector, to_tsquery('simple', $1))::float8 AS rank "+
+			"FROM log2timeline WHERE search_vector @@ to_tsquery('simple', $1) "+
+			"AND id IN ("+strings.Join(placeholders, ", ")+") ORDER BY rank", args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying search snippets: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []SearchMatch
+	for rows.Next() {
+		var m SearchMatch
+		if err := rows.Scan(&m.ID, &m.Snippet, &m.Rank); err != nil {
+			return nil, fmt.Errorf("scanning search snippet: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
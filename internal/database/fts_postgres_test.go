@@ -0,0 +1,61 @@
+package database
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cdtdelta/4n6time/internal/query"
+)
+
+func TestTSQuery(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"mimikatz", `'mimikatz'`},
+		{"power*", `'power':*`},
+		{`"lateral movement"`, `'lateral movement'`},
+		{"psexec wmic", `'psexec' & 'wmic'`},
+		{"psexec OR wmic", `'psexec' | 'wmic'`},
+		{"cmd NOT admin", `'cmd' & !'admin'`},
+		{"(a OR b) c", `('a' | 'b') & 'c'`},
+		{"c (a OR b)", `'c' & ('a' | 'b')`},
+		{`o'brien C:\Temp`, `'o''brien' & 'C:\\Temp'`},
+		{"OR foo AND", `'foo'`},
+		{"* - ()", ""},
+	}
+	for _, tt := range tests {
+		if got := tsQuery(tt.in); got != tt.want {
+			t.Errorf("tsQuery(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPostgresFullTextPredicate(t *testing.T) {
+	p := query.Combine([]*query.Predicate{query.Simple("host", query.Equal, "PC1"), query.Search("mimikatz")}, query.AND)
+
+	sql, args, next := p.WhereClauseFor(&PostgresDialect{}, 1)
+	if !strings.Contains(sql, "search_vector @@ to_tsquery('simple', $2)") || strings.Contains(sql, "ILIKE") {
+		t.Errorf("expected tsvector match only, got: %s", sql)
+	}
+	if len(args) != 2 || args[1] != "'mimikatz'" || next != 3 {
+		t.Errorf("unexpected args %v, next %d", args, next)
+	}
+
+	sql, args, next = p.WhereClauseFor(&PostgresDialect{Trigram: true}, 1)
+	if !strings.Contains(sql, " OR (coalesce(\"desc\", '')") || !strings.Contains(sql, " ILIKE $3)") {
+		t.Errorf("expected trigram substring arm, got: %s", sql)
+	}
+	if len(args) != 3 || args[2] != "%mimikatz%" || next != 4 {
+		t.Errorf("unexpected args %v, next %d", args, next)
+	}
+
+	// Text with no lexemes uses the trigram index if present, else LIKE
+	sql, _, _ = query.Search("::").WhereClauseFor(&PostgresDialect{Trigram: true}, 1)
+	if strings.Contains(sql, "search_vector") || !strings.Contains(sql, "ILIKE $1") {
+		t.Errorf("expected trigram-only match, got: %s", sql)
+	}
+	sql, _, _ = query.Search("::").WhereClauseFor(&PostgresDialect{}, 1)
+	if strings.Count(sql, " LIKE $") != 14 {
+		t.Errorf("expected LIKE fallback, got: %s", sql)
+	}
+}
@@ -0,0 +1,187 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cdtdelta/4n6time/internal/model"
+	"github.com/cdtdelta/4n6time/internal/query"
+)
+
+func TestFTS5Query(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"mimikatz", `"mimikatz"`},
+		{"svchost.exe", `"svchost.exe"`},
+		{"power*", `"power"*`},
+		{`"lateral movement"`, `"lateral movement"`},
+		{`"run key"*`, `"run key"*`},
+		{"psexec OR wmic", `"psexec" OR "wmic"`},
+		{"cmd NOT admin", `"cmd" NOT "admin"`},
+		{"(a OR b) c", `( "a" OR "b" ) "c"`},
+		{"OR foo AND", `"foo"`},
+		{"foo AND OR bar", `"foo" AND "bar"`},
+		{"(foo", `"foo"`},
+		{"() foo", `"foo"`},
+		{`say "hi`, `"say" "hi"`},
+		{`a"b`, `"a" "b"`},
+		{"and or", `"and" "or"`},
+		{"* - ()", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := fts5Query(tt.in); got != tt.want {
+			t.Errorf("fts5Query(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// searchDescs runs a Search predicate through ExecuteQuery and returns the
+// descriptions of matching evidence events.
+func searchDescs(t *testing.T, db *SQLiteStore, text string) []string {
+	t.Helper()
+	q := query.New(0)
+	q.SetDialect(&SQLiteDialect{})
+	q.AddPredicate(query.Search(text))
+	q.OrderBy("datetime")
+	sqlStr, args := q.Build()
+	if !strings.Contains(sqlStr, "log2timeline_fts MATCH") {
+		t.Fatalf("expected search to use the FTS5 index, got: %s", sqlStr)
+	}
+	events, err := db.ExecuteQuery(sqlStr, args)
+	if err != nil {
+		t.Fatalf("ExecuteQuery(%q) failed: %v", text, err)
+	}
+	var descs []string
+	for _, e := range events {
+		if e.ID > 0 {
+			descs = append(descs, e.Desc)
+		}
+	}
+	return descs
+}
+
+func insertSearchEvents(t *testing.T, db *SQLiteStore) {
+	t.Helper()
+	descs := []string{
+		"Process created: powershell.exe -enc",
+		"Lateral movement via psexec",
+		"Registry run key added",
+	}
+	var events []*model.Event
+	for i, d := range descs {
+		e := sampleEvent()
+		e.Desc = d
+		e.Datetime = fmt.Sprintf("2025-01-15 10:%02d:00", i)
+		events = append(events, e)
+	}
+	if _, err := db.InsertEvents(events, nil); err != nil {
+		t.Fatalf("InsertEvents failed: %v", err)
+	}
+}
+
+func TestFullTextSearchSyntax(t *testing.T) {
+	db := createTestDB(t)
+	insertSearchEvents(t, db)
+
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"psexec", 1},
+		{"power*", 1},
+		{`"run key"`, 1},
+		{`"key run"`, 0},
+		{"psexec OR registry", 2},
+		{"movement NOT psexec", 0},
+		{"WORKSTATION1", 3}, // host column
+		{"mft NOT powershell", 2},
+	}
+	for _, tt := range tests {
+		if got := searchDescs(t, db, tt.text); len(got) != tt.want {
+			t.Errorf("search %q matched %v, want %d events", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFullTextIndexFollowsEdits(t *testing.T) {
+	db := createTestDB(t)
+	insertSearchEvents(t, db)
+	events, _ := db.QueryEvents("", nil, "datetime", 0, 0)
+
+	if err := db.BulkAddTag([]int64{events[0].ID}, "cobaltstrike"); err != nil {
+		t.Fatalf("BulkAddTag failed: %v", err)
+	}
+	if got := searchDescs(t, db, "cobaltstrike"); len(got) != 1 || got[0] != events[0].Desc {
+		t.Errorf("expected tagged event to be searchable, got %v", got)
+	}
+
+	if err := db.UpdateEvent(events[1].ID, map[string]interface{}{"desc": "renamed entry"}); err != nil {
+		t.Fatalf("UpdateEvent failed: %v", err)
+	}
+	if got := searchDescs(t, db, "psexec"); len(got) != 0 {
+		t.Errorf("expected old text to leave the index, got %v", got)
+	}
+	if got := searchDescs(t, db, "renamed"); len(got) != 1 {
+		t.Errorf("expected new text in the index, got %v", got)
+	}
+}
+
+func TestFullTextIndexMigration(t *testing.T) {
+	path := tempDBPath(t)
+	db, err := CreateSQLite(path, nil)
+	if err != nil {
+		t.Fatalf("CreateSQLite failed: %v", err)
+	}
+	insertSearchEvents(t, db)
+
+	// Simulate a database from before the index existed
+	for _, stmt := range []string{
+		"DROP TRIGGER log2timeline_fts_ai", "DROP TRIGGER log2timeline_fts_ad",
+		"DROP TRIGGER log2timeline_fts_au", "DROP TABLE log2timeline_fts",
+		"DELETE FROM schema_version", "INSERT INTO schema_version (version) VALUES (3)",
+	} {
+		if _, err := db.conn.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	db.Close()
+
+	db, err = OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer db.Close()
+	if got := searchDescs(t, db, "psexec"); len(got) != 1 {
+		t.Errorf("expected existing rows to be indexed on open, got %v", got)
+	}
+}
+
+func TestSearchSnippets(t *testing.T) {
+	db := createTestDB(t)
+	insertSearchEvents(t, db)
+	events, _ := db.QueryEvents("", nil, "datetime", 0, 0)
+	ids := []int64{events[0].ID, events[1].ID, events[2].ID, -1}
+
+	matches, err := db.SearchSnippets("psexec OR powershell", ids)
+	if err != nil {
+		t.Fatalf("SearchSnippets failed: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %+v", matches)
+	}
+	for _, m := range matches {
+		if !strings.Contains(m.Snippet, SnippetMarkOpen) || !strings.Contains(m.Snippet, SnippetMarkClose) {
+			t.Errorf("expected highlighted snippet, got %q", m.Snippet)
+		}
+	}
+	if matches[0].Rank > matches[1].Rank {
+		t.Errorf("expected matches ordered by rank, got %+v", matches)
+	}
+
+	if matches, err := db.SearchSnippets("***", ids); err != nil || matches != nil {
+		t.Errorf("expected no matches for unsearchable text, got %+v, %v", matches, err)
+	}
+}
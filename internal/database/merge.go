@@ -0,0 +1,438 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cdtdelta/4n6time/internal/model"
+)
+
+// mergeBatchSize is how many events MergeStore reads from either database
+// at a time.
+const mergeBatchSize = 10000
+
+// MergeOptions controls how MergeStore copies one database into another.
+type MergeOptions struct {
+	// Dedup skips source events whose fingerprint already exists in the
+	// destination, or repeats one copied earlier in the same merge. The
+	// skipped event's tags are added to the matching destination event, its
+	// color is copied if that event has none, and its bookmark is kept, so
+	// no analyst work is lost.
+	Dedup bool
+
+	// SourceLabel describes the source database in the provenance record.
+	// Callers should mask credentials before passing a connection string.
+	SourceLabel string
+}
+
+// MergeResult summarizes what MergeStore copied.
+type MergeResult struct {
+	EventsCopied    int `json:"eventsCopied"`
+	EventsSkipped   int `json:"eventsSkipped"`
+	NotesCopied     int `json:"notesCopied"`
+	QueriesCopied   int `json:"queriesCopied"`
+	TagsMerged      int `json:"tagsMerged"`
+	ColorsMerged    int `json:"colorsMerged"`
+	BookmarksMerged int `json:"bookmarksMerged"`
+	EvidenceCopied  int `json:"evidenceCopied"`
+}
+
+// summary describes the merge for the provenance record.
+func (r *MergeResult) summary(label string) string {
+	return fmt.Sprintf("merged %s: %d events copied, %d duplicates skipped, %d examiner notes, %d saved queries",
+		label, r.EventsCopied, r.EventsSkipped, r.NotesCopied, r.QueriesCopied)
+}
+
+// mergeTarget is a destination event a duplicate's annotations fold into.
+// An event copied by this merge has no id until dst is read back, so the
+// annotations of its duplicates wait in tags, color and bookmark.
+type mergeTarget struct {
+	id         int64
+	colored    bool
+	bookmarked bool
+
+	tags     []string
+	color    string
+	bookmark bool
+}
+
+// fold adds the annotations of e, a duplicate of t, to folds, or holds them
+// on t while its id is unknown.
+func (t *mergeTarget) fold(e *model.Event, folds *mergeFolds) {
+	var tags []string
+	for _, tag := range strings.Split(e.Tag, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	color := ""
+	if e.Color != "" && !t.colored {
+		color = e.Color
+		t.colored = true
+	}
+	bookmark := e.Bookmark != 0 && !t.bookmarked
+	if bookmark {
+		t.bookmarked = true
+	}
+	if t.id == 0 {
+		t.tags = append(t.tags, tags...)
+		if color != "" {
+			t.color = color
+		}
+		t.bookmark = t.bookmark || bookmark
+		return
+	}
+	folds.add(t.id, tags, color, bookmark)
+}
+
+// waiting reports whether t holds annotations for an event whose id is
+// not yet known.
+func (t *mergeTarget) waiting() bool {
+	return t.id == 0 && (len(t.tags) > 0 || t.color != "" || t.bookmark)
+}
+
+// mergeFolds collects the annotations of skipped duplicates by destination
+// event, so each tag, color and the bookmark is applied in one bulk update.
+type mergeFolds struct {
+	tags      map[string][]int64
+	colors    map[string][]int64
+	bookmarks []int64
+}
+
+func newMergeFolds() *mergeFolds {
+	return &mergeFolds{tags: make(map[string][]int64), colors: make(map[string][]int64)}
+}
+
+func (f *mergeFolds) add(id int64, tags []string, color string, bookmark bool) {
+	for _, tag := range tags {
+		f.tags[tag] = append(f.tags[tag], id)
+	}
+	if color != "" {
+		f.colors[color] = append(f.colors[color], id)
+	}
+	if bookmark {
+		f.bookmarks = append(f.bookmarks, id)
+	}
+}
+
+// apply writes the collected annotations to dst.
+func (f *mergeFolds) apply(dst Store, result *MergeResult) error {
+	for tag, ids := range f.tags {
+		if err := dst.BulkAddTag(ids, tag); err != nil {
+			return fmt.Errorf("merging tag %q: %w", tag, err)
+		}
+		result.TagsMerged += len(ids)
+	}
+	for color, ids := range f.colors {
+		if err := dst.BulkUpdateColor(ids, color); err != nil {
+			return fmt.Errorf("merging color %s: %w", color, err)
+		}
+		result.ColorsMerged += len(ids)
+	}
+	if len(f.bookmarks) > 0 {
+		if err := dst.BulkSetBookmark(f.bookmarks, 1); err != nil {
+			return fmt.Errorf("merging bookmarks: %w", err)
+		}
+		result.BookmarksMerged += len(f.bookmarks)
+	}
+	return nil
+}
+
+// fingerprintKey shortens an event's fingerprint to 128 bits, which keeps
+// the destination index of a multi-million-event merge small while leaving
+// collisions vanishingly unlikely.
+func fingerprintKey(e *model.Event) [16]byte {
+	sum := e.FingerprintSum()
+	return [16]byte(sum[:16])
+}
+
+// MergeStore copies the events, examiner notes, saved queries, tags, evidence
+// items and import batches of src into dst. Examiner notes and import batches
+// are renumbered by the destination's sequences, saved
+// queries whose name already exists with different SQL are copied under a
+// " (merged)" suffix, and the merge is recorded in dst's provenance table.
+// Events are read from both databases in batches, so neither timeline is
+// held in memory; with Dedup, only a fingerprint index of dst and the copied
+// events is.
+//
+// The merge is not one transaction. If it fails after writing to dst, a
+// "merge-incomplete" provenance record notes how far it got.
+//
+// onProgress, if non-nil, is called with a phase name ("reading",
+// "indexing", "inserting", "notes", "metadata") and the current and total
+// counts for that phase.
+func MergeStore(dst, src Store, opts MergeOptions, onProgress func(phase string, count, total int)) (result *MergeResult, err error) {
+	if dst.Path() == src.Path() {
+		return nil, fmt.Errorf("cannot merge a database into itself")
+	}
+	progress := func(phase string, count, total int) {
+		if onProgress != nil {
+			onProgress(phase, count, total)
+		}
+	}
+	result = &MergeResult{}
+	label := opts.SourceLabel
+	if label == "" {
+		label = src.Path()
+	}
+
+	progress("reading", 0, 0)
+	srcTotal, err := src.CountEvents("", nil)
+	if err != nil {
+		return nil, fmt.Errorf("counting source events: %w", err)
+	}
+
+	// Index the destination's existing events by fingerprint so duplicates can
+	// be skipped and their annotations folded into the surviving event.
+	var existing map[[16]byte]*mergeTarget
+	if opts.Dedup {
+		dstTotal, err := dst.CountEvents("", nil)
+		if err != nil {
+			return nil, fmt.Errorf("counting destination events: %w", err)
+		}
+		existing = make(map[[16]byte]*mergeTarget, dstTotal)
+		progress("indexing", 0, int(dstTotal))
+		err = dst.EachEvent(mergeBatchSize, func(events []*model.Event) error {
+			for _, e := range events {
+				existing[fingerprintKey(e)] = &mergeTarget{id: e.ID, colored: e.Color != "", bookmarked: e.Bookmark != 0}
+			}
+			progress("indexing", len(existing), int(dstTotal))
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("reading destination events: %w", err)
+		}
+	}
+
+	// From here on dst is written to, so a failure leaves a record of what
+	// was merged before it
+	recorded := false
+	defer func() {
+		if err != nil && !recorded {
+			detail := result.summary(label) + " before failing: " + err.Error()
+			if perr := dst.RecordProvenance("merge-incomplete", detail); perr != nil {
+				err = fmt.Errorf("%w (recording provenance: %v)", err, perr)
+			}
+		}
+	}()
+
+	// Evidence and import batches get fresh IDs; relink events to them
+	batchIDs, evidenceCopied, err := CopyCaseRecords(dst, src)
+	result.EvidenceCopied = evidenceCopied
+	if err != nil {
+		return result, err
+	}
+
+	total := int(srcTotal)
+	progress("inserting", 0, total)
+	read := 0
+	err = src.EachEvent(mergeBatchSize, func(events []*model.Event) error {
+		read += len(events)
+		if err := mergeEventBatch(dst, events, batchIDs, existing, result, func(count int) {
+			progress("inserting", read-len(events)+count, total)
+		}); err != nil {
+			return err
+		}
+		progress("inserting", read, total)
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+	if err := foldIntoCopies(dst, existing, result); err != nil {
+		return result, err
+	}
+
+	// Examiner notes get fresh IDs from the destination
+	notes, err := src.GetExaminerNotes()
+	if err != nil {
+		return result, fmt.Errorf("reading source examiner notes: %w", err)
+	}
+	seenNotes := make(map[string]bool)
+	if opts.Dedup {
+		dstNotes, err := dst.GetExaminerNotes()
+		if err != nil {
+			return result, fmt.Errorf("reading destination examiner notes: %w", err)
+		}
+		for _, n := range dstNotes {
+			seenNotes[normalizeMergedDatetime(n.Datetime)+"\x1f"+n.Desc] = true
+		}
+	}
+	progress("notes", 0, len(notes))
+	for i, n := range notes {
+		dt := normalizeMergedDatetime(n.Datetime)
+		if opts.Dedup {
+			key := dt + "\x1f" + n.Desc
+			if seenNotes[key] {
+				continue
+			}
+			seenNotes[key] = true
+		}
+		newID, err := dst.InsertExaminerNote(dt, n.Desc, n.Tag, n.Color)
+		if err != nil {
+			return result, fmt.Errorf("copying examiner note: %w", err)
+		}
+		if n.Bookmark == 1 {
+			if _, err := dst.ToggleExaminerNoteBookmark(-newID); err != nil {
+				return result, fmt.Errorf("copying examiner note bookmark: %w", err)
+			}
+		}
+		result.NotesCopied++
+		progress("notes", i+1, len(notes))
+	}
+
+	// Saved queries
+	srcQueries, err := src.GetSavedQueries()
+	if err != nil {
+		return result, fmt.Errorf("reading source saved queries: %w", err)
+	}
+	dstQueries, err := dst.GetSavedQueries()
+	if err != nil {
+		return result, fmt.Errorf("reading destination saved queries: %w", err)
+	}
+	byName := make(map[string]string, len(dstQueries))
+	for _, q := range dstQueries {
+		byName[q.Name] = q.Query
+	}
+	for _, q := range srcQueries {
+		name := q.Name
+		if existingSQL, ok := byName[name]; ok {
+			if existingSQL == q.Query {
+				continue
+			}
+			name += " (merged)"
+			if _, ok := byName[name]; ok {
+				continue
+			}
+		}
+		copied := q
+		copied.Name = name
+		if err := dst.UpsertSavedQuery(copied); err != nil {
+			return result, fmt.Errorf("copying saved query %q: %w", q.Name, err)
+		}
+		byName[name] = q.Query
+		result.QueriesCopied++
+	}
+
+	progress("metadata", 0, 0)
+	if err := dst.UpdateMetadata(); err != nil {
+		return result, fmt.Errorf("updating metadata: %w", err)
+	}
+
+	recorded = true
+	if err := dst.RecordProvenance("merge", result.summary(label)); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// mergeEventBatch inserts a batch of source events into dst, relinked to
+// the copied import batches. With existing set, duplicates are skipped and
+// their tags, color and bookmark applied to the destination event instead,
+// and the inserted events are added to existing so their own duplicates
+// are skipped too.
+func mergeEventBatch(dst Store, events []*model.Event, batchIDs map[int64]int64, existing map[[16]byte]*mergeTarget, result *MergeResult, onProgress func(int)) error {
+	toInsert := make([]*model.Event, 0, len(events))
+	folds := newMergeFolds()
+	for _, e := range events {
+		e.BatchID = batchIDs[e.BatchID]
+		e.Datetime = normalizeMergedDatetime(e.Datetime)
+		if existing != nil {
+			key := fingerprintKey(e)
+			if t, ok := existing[key]; ok {
+				result.EventsSkipped++
+				t.fold(e, folds)
+				continue
+			}
+			existing[key] = &mergeTarget{colored: e.Color != "", bookmarked: e.Bookmark != 0}
+		}
+		toInsert = append(toInsert, e)
+	}
+
+	inserted, err := dst.InsertEvents(toInsert, onProgress)
+	result.EventsCopied += inserted
+	if err != nil {
+		return fmt.Errorf("inserting events (inserted %d of %d in this batch before failure): %w", inserted, len(toInsert), err)
+	}
+	return folds.apply(dst, result)
+}
+
+// foldIntoCopies applies the annotations of duplicates of events copied by
+// this merge, reading dst back to find the copies' ids. It does nothing when
+// no duplicate is waiting.
+func foldIntoCopies(dst Store, existing map[[16]byte]*mergeTarget, result *MergeResult) error {
+	waiting := 0
+	for _, t := range existing {
+		if t.waiting() {
+			waiting++
+		}
+	}
+	if waiting == 0 {
+		return nil
+	}
+	return dst.EachEvent(mergeBatchSize, func(events []*model.Event) error {
+		folds := newMergeFolds()
+		for _, e := range events {
+			t := existing[fingerprintKey(e)]
+			if t == nil || !t.waiting() {
+				continue
+			}
+			t.id = e.ID
+			folds.add(t.id, t.tags, t.color, t.bookmark)
+		}
+		return folds.apply(dst, result)
+	})
+}
+
+// eachEvent calls fn with the events of s in id order, batchSize at a time,
+// paging by id so each batch is an indexed range read.
+func eachEvent(s Store, d Dialect, batchSize int, fn func([]*model.Event) error) error {
+	idCol := d.IDColumn()
+	var last int64
+	for {
+		events, err := s.QueryEvents(idCol+" > "+d.Placeholder(1), []interface{}{last}, idCol, batchSize, 0)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		if err := fn(events); err != nil {
+			return err
+		}
+		if len(events) < batchSize {
+			return nil
+		}
+		last = events[len(events)-1].ID
+	}
+}
+
+// normalizeMergedDatetime converts the RFC 3339 rendering PostgreSQL
+// timestamps get when scanned into strings ("2025-01-15T10:30:00Z") back to
+// the "YYYY-MM-DD HH:MM:SS" form stored by SQLite, so merged rows sort and
+// filter alongside natively imported ones.
+func normalizeMergedDatetime(s string) string {
+	if len(s) >= 19 && s[10] == 'T' {
+		s = s[:10] + " " + s[11:]
+		s = strings.TrimSuffix(s, "Z")
+	}
+	return s
+}
+
+// -- Store methods --
+
+// EachEvent calls fn with every event, in id order, batchSize at a time.
+func (db *SQLiteStore) EachEvent(batchSize int, fn func([]*model.Event) error) error {
+	return eachEvent(db, db.dialect, batchSize, fn)
+}
+
+// EachEvent calls fn with every event, in id order, batchSize at a time.
+func (db *PostgresStore) EachEvent(batchSize int, fn func([]*model.Event) error) error {
+	return eachEvent(db, db.dialect, batchSize, fn)
+}
+
+// EachEvent calls fn with every event, in id order, batchSize at a time.
+func (db *MySQLStore) EachEvent(batchSize int, fn func([]*model.Event) error) error {
+	return eachEvent(db, db.dialect, batchSize, fn)
+}
@@ -0,0 +1,306 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cdtdelta/4n6time/internal/model"
+)
+
+func createNamedTestDB(t *testing.T, name string) *SQLiteStore {
+	t.Helper()
+	db, err := CreateSQLite(filepath.Join(t.TempDir(), name), nil)
+	if err != nil {
+		t.Fatalf("failed to create %s: %v", name, err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMergeStoreCopiesEverything(t *testing.T) {
+	dst := createNamedTestDB(t, "dst.db")
+	src := createNamedTestDB(t, "src.db")
+
+	e := sampleEvent()
+	e.Host = "HOST-B"
+	e.Tag = "lateral_movement"
+	if err := src.InsertEvent(e); err != nil {
+		t.Fatalf("InsertEvent failed: %v", err)
+	}
+	noteID, err := src.InsertExaminerNote("2025-01-15 11:00:00", "USB inserted", "usb", "RED")
+	if err != nil {
+		t.Fatalf("InsertExaminerNote failed: %v", err)
+	}
+	if _, err := src.ToggleExaminerNoteBookmark(-noteID); err != nil {
+		t.Fatalf("ToggleExaminerNoteBookmark failed: %v", err)
+	}
+	if err := src.SaveQuery("hosts", "host = 'HOST-B'"); err != nil {
+		t.Fatalf("SaveQuery failed: %v", err)
+	}
+
+	// A pre-existing note in dst forces the merged note to be renumbered
+	if _, err := dst.InsertExaminerNote("2025-01-01 00:00:00", "case opened", "", ""); err != nil {
+		t.Fatalf("InsertExaminerNote failed: %v", err)
+	}
+	if err := dst.SaveQuery("hosts", "host = 'HOST-A'"); err != nil {
+		t.Fatalf("SaveQuery failed: %v", err)
+	}
+
+	res, err := MergeStore(dst, src, MergeOptions{}, nil)
+	if err != nil {
+		t.Fatalf("MergeStore failed: %v", err)
+	}
+	if res.EventsCopied != 1 || res.NotesCopied != 1 || res.QueriesCopied != 1 {
+		t.Errorf("unexpected merge result: %+v", res)
+	}
+
+	events, _ := dst.QueryEvents("", nil, "", 0, 0)
+	if len(events) != 1 || events[0].Tag != "lateral_movement" {
+		t.Errorf("expected merged event with tag, got %+v", events)
+	}
+
+	notes, _ := dst.GetExaminerNotes()
+	if len(notes) != 2 {
+		t.Fatalf("expected 2 examiner notes, got %d", len(notes))
+	}
+	merged := notes[1]
+	if merged.Desc != "USB inserted" || merged.ID != -2 || merged.Bookmark != 1 {
+		t.Errorf("expected renumbered bookmarked note, got %+v", merged)
+	}
+
+	queries, _ := dst.GetSavedQueries()
+	names := make(map[string]bool)
+	for _, q := range queries {
+		names[q.Name] = true
+	}
+	if !names["hosts"] || !names["hosts (merged)"] {
+		t.Errorf("expected conflicting saved query to be copied with suffix, got %v", queries)
+	}
+
+	tags, _ := dst.GetDistinctTags()
+	if len(tags) != 1 || tags[0] != "lateral_movement" {
+		t.Errorf("expected merged tag, got %v", tags)
+	}
+
+	prov, err := dst.GetProvenance()
+	if err != nil {
+		t.Fatalf("GetProvenance failed: %v", err)
+	}
+	if len(prov) != 1 || prov[0].Action != "merge" || !strings.Contains(prov[0].Detail, "1 events copied") {
+		t.Errorf("expected merge provenance entry, got %+v", prov)
+	}
+}
+
+func TestMergeStoreDedup(t *testing.T) {
+	dst := createNamedTestDB(t, "dst.db")
+	src := createNamedTestDB(t, "src.db")
+
+	shared := sampleEvent()
+	if err := dst.InsertEvent(shared); err != nil {
+		t.Fatalf("InsertEvent failed: %v", err)
+	}
+
+	dup := sampleEvent()
+	dup.Tag = "reviewed"
+	dup.Color = "RED"
+	dup.Bookmark = 1
+	unique := sampleEvent()
+	unique.Desc = "only in source"
+	for _, e := range []*model.Event{dup, unique} {
+		if err := src.InsertEvent(e); err != nil {
+			t.Fatalf("InsertEvent failed: %v", err)
+		}
+	}
+
+	res, err := MergeStore(dst, src, MergeOptions{Dedup: true}, nil)
+	if err != nil {
+		t.Fatalf("MergeStore failed: %v", err)
+	}
+	if res.EventsCopied != 1 || res.EventsSkipped != 1 || res.TagsMerged != 1 || res.ColorsMerged != 1 || res.BookmarksMerged != 1 {
+		t.Errorf("unexpected merge result: %+v", res)
+	}
+
+	count, _ := dst.CountEvents("", nil)
+	if count != 2 {
+		t.Errorf("expected 2 events after dedup merge, got %d", count)
+	}
+	tagged, _ := dst.QueryEvents("tag = ?", []interface{}{"reviewed"}, "", 0, 0)
+	if len(tagged) != 1 || tagged[0].Desc != shared.Desc {
+		t.Fatalf("expected duplicate's tag to land on existing event, got %+v", tagged)
+	}
+	if tagged[0].Color != "RED" || tagged[0].Bookmark != 1 {
+		t.Errorf("expected duplicate's color and bookmark on existing event, got %+v", tagged[0])
+	}
+}
+
+func TestMergeStoreDedupWithinSource(t *testing.T) {
+	dst := createNamedTestDB(t, "dst.db")
+	src := createNamedTestDB(t, "src.db")
+
+	first := sampleEvent()
+	first.Tag = "reviewed"
+	again := sampleEvent()
+	again.Tag = "malware"
+	again.Color = "RED"
+	again.Bookmark = 1
+	for _, e := range []*model.Event{first, again} {
+		if err := src.InsertEvent(e); err != nil {
+			t.Fatalf("InsertEvent failed: %v", err)
+		}
+	}
+
+	res, err := MergeStore(dst, src, MergeOptions{Dedup: true}, nil)
+	if err != nil {
+		t.Fatalf("MergeStore failed: %v", err)
+	}
+	if res.EventsCopied != 1 || res.EventsSkipped != 1 || res.TagsMerged != 1 || res.ColorsMerged != 1 || res.BookmarksMerged != 1 {
+		t.Errorf("unexpected merge result: %+v", res)
+	}
+	events, _ := dst.QueryEvents("", nil, "", 0, 0)
+	if len(events) != 1 {
+		t.Fatalf("expected the repeated source event to be copied once, got %d", len(events))
+	}
+	if e := events[0]; e.Tag != "malware,reviewed" || e.Color != "RED" || e.Bookmark != 1 {
+		t.Errorf("expected the repeat's annotations on the copy, got %+v", e)
+	}
+}
+
+func TestMergeStoreKeepsDestinationColor(t *testing.T) {
+	dst := createNamedTestDB(t, "dst.db")
+	src := createNamedTestDB(t, "src.db")
+
+	kept := sampleEvent()
+	kept.Color = "GREEN"
+	if err := dst.InsertEvent(kept); err != nil {
+		t.Fatalf("InsertEvent failed: %v", err)
+	}
+	dup := sampleEvent()
+	dup.Color = "RED"
+	if err := src.InsertEvent(dup); err != nil {
+		t.Fatalf("InsertEvent failed: %v", err)
+	}
+
+	res, err := MergeStore(dst, src, MergeOptions{Dedup: true}, nil)
+	if err != nil {
+		t.Fatalf("MergeStore failed: %v", err)
+	}
+	events, _ := dst.QueryEvents("", nil, "", 0, 0)
+	if res.ColorsMerged != 0 || len(events) != 1 || events[0].Color != "GREEN" {
+		t.Errorf("expected the destination's color to win, got %+v / %+v", res, events)
+	}
+}
+
+func TestEachEvent(t *testing.T) {
+	db := createTestDB(t)
+	for i := 0; i < 5; i++ {
+		if err := db.InsertEvent(sampleEvent()); err != nil {
+			t.Fatalf("InsertEvent failed: %v", err)
+		}
+	}
+
+	var sizes []int
+	var ids []int64
+	err := db.EachEvent(2, func(events []*model.Event) error {
+		sizes = append(sizes, len(events))
+		for _, e := range events {
+			ids = append(ids, e.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EachEvent failed: %v", err)
+	}
+	if len(sizes) != 3 || sizes[2] != 1 || len(ids) != 5 || ids[0] != 1 || ids[4] != 5 {
+		t.Errorf("EachEvent batches %v, ids %v", sizes, ids)
+	}
+}
+
+// failingQueryStore fails to save queries, to interrupt a merge after its
+// events are copied.
+type failingQueryStore struct {
+	Store
+}
+
+func (failingQueryStore) UpsertSavedQuery(SavedQuery) error {
+	return errors.New("disk full")
+}
+
+func TestMergeStoreRecordsIncompleteMerge(t *testing.T) {
+	dst := createNamedTestDB(t, "dst.db")
+	src := createNamedTestDB(t, "src.db")
+	if err := src.InsertEvent(sampleEvent()); err != nil {
+		t.Fatalf("InsertEvent failed: %v", err)
+	}
+	if err := src.SaveQuery("hosts", "host = 'HOST-B'"); err != nil {
+		t.Fatalf("SaveQuery failed: %v", err)
+	}
+
+	if _, err := MergeStore(failingQueryStore{dst}, src, MergeOptions{}, nil); err == nil {
+		t.Fatal("expected the merge to fail")
+	}
+	prov, _ := dst.GetProvenance()
+	if len(prov) != 1 || prov[0].Action != "merge-incomplete" ||
+		!strings.Contains(prov[0].Detail, "1 events copied") || !strings.Contains(prov[0].Detail, "disk full") {
+		t.Errorf("expected an incomplete merge provenance entry, got %+v", prov)
+	}
+}
+
+func TestMergeStoreRejectsSelf(t *testing.T) {
+	db := createTestDB(t)
+	if _, err := MergeStore(db, db, MergeOptions{}, nil); err == nil {
+		t.Fatal("expected error merging a database into itself")
+	}
+}
+
+func TestMergeStoreRelinksImportBatches(t *testing.T) {
+	dst := createNamedTestDB(t, "dst.db")
+	src := createNamedTestDB(t, "src.db")
+
+	// dst already has a batch, so src's batch 1 must be renumbered
+	if _, err := dst.InsertImportBatch(&ImportBatch{SourcePath: "dst.csv", Format: "CSV"}); err != nil {
+		t.Fatalf("InsertImportBatch failed: %v", err)
+	}
+	if err := src.SaveCaseInfo(&CaseInfo{CaseNumber: "2025-017", Examiners: []string{"J. Doe"}}); err != nil {
+		t.Fatalf("SaveCaseInfo failed: %v", err)
+	}
+	evID, err := src.InsertEvidenceItem(&EvidenceItem{Name: "WKSTN01.E01", Kind: EvidenceDiskImage})
+	if err != nil {
+		t.Fatalf("InsertEvidenceItem failed: %v", err)
+	}
+	batchID, err := src.InsertImportBatch(&ImportBatch{EvidenceID: evID, SourcePath: "wkstn01.plaso.jsonl", Format: "JSONL"})
+	if err != nil {
+		t.Fatalf("InsertImportBatch failed: %v", err)
+	}
+	e := sampleEvent()
+	e.BatchID = batchID
+	if err := src.InsertEvent(e); err != nil {
+		t.Fatalf("InsertEvent failed: %v", err)
+	}
+
+	res, err := MergeStore(dst, src, MergeOptions{}, nil)
+	if err != nil {
+		t.Fatalf("MergeStore failed: %v", err)
+	}
+	if res.EvidenceCopied != 1 {
+		t.Errorf("expected 1 evidence item copied, got %+v", res)
+	}
+
+	c, _ := dst.GetCaseInfo()
+	if c.CaseNumber != "2025-017" {
+		t.Errorf("expected case to be copied into a database without one, got %+v", c)
+	}
+	batches, _ := dst.GetImportBatches()
+	if len(batches) != 2 || batches[1].SourcePath != "wkstn01.plaso.jsonl" || batches[1].EventCount != 1 {
+		t.Fatalf("expected copied batch holding the merged event, got %+v", batches)
+	}
+	items, _ := dst.GetEvidenceItems()
+	if len(items) != 1 || batches[1].EvidenceID != items[0].ID {
+		t.Errorf("expected copied batch linked to copied evidence, got %+v / %+v", batches, items)
+	}
+	events, _ := dst.QueryEvents("", nil, "", 0, 0)
+	if len(events) != 1 || events[0].BatchID != batches[1].ID {
+		t.Errorf("expected merged event relinked to batch %d, got %+v", batches[1].ID, events)
+	}
+}
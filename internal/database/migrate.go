@@ -0,0 +1,281 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migration is one step in the schema's history. A migration either returns
+// plain statements from up or, when it must inspect the existing schema,
+// runs Go code in apply. Either may do nothing for dialects it does not
+// concern. Databases created before schema_version existed are at version 0
+// and may already have some of these changes, so every migration must be
+// idempotent.
+type migration struct {
+	version     int
+	description string
+	up          func(d Dialect) []string
+	apply       func(tx schemaExecer, d Dialect) error
+}
+
+// schemaExecer is the subset of *sql.DB and *sql.Tx used to change the schema.
+type schemaExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// migrations lists every schema change in order. Append new migrations at
+// the end with the next version number; never edit or reorder released ones.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "add log2timeline.bookmark (0.8.0)",
+		apply: func(tx schemaExecer, d Dialect) error {
+			var count int
+			if err := tx.QueryRow(d.SchemaCheckColumnSQL("log2timeline", "bookmark")).Scan(&count); err != nil {
+				return err
+			}
+			if count > 0 {
+				return nil
+			}
+			_, err := tx.Exec("ALTER TABLE log2timeline ADD COLUMN bookmark INT DEFAULT 0")
+			return err
+		},
+	},
+	{
+		version:     2,
+		description: "create examiner_notes (0.10.0)",
+		up: func(d Dialect) []string {
+			return []string{d.CreateExaminerNotesTableSQL()}
+		},
+	},
+	{
+		version:     3,
+		description: "create l2t_provenance",
+		up: func(d Dialect) []string {
+			return []string{d.CreateProvenanceTableSQL()}
+		},
+	},
+	{
+		version:     4,
+		description: "create full-text search index",
+		up: func(d Dialect) []string {
+			switch d := d.(type) {
+			case *SQLiteDialect:
+				// External-content FTS5 tables start empty; index existing rows
+				return append(d.CreateFullTextSQL(), "INSERT INTO log2timeline_fts(log2timeline_fts) VALUES ('rebuild')")
+			case *PostgresDialect:
+				return d.CreateFullTextSQL()
+			}
+			return nil
+		},
+	},
+	{
+		version:     5,
+		description: "create case, evidence and import batch tables",
+		apply: func(tx schemaExecer, d Dialect) error {
+			for _, stmt := range caseTablesSQL(d) {
+				if _, err := tx.Exec(stmt); err != nil {
+					return err
+				}
+			}
+			var count int
+			if err := tx.QueryRow(d.SchemaCheckColumnSQL("log2timeline", "batch_id")).Scan(&count); err != nil {
+				return err
+			}
+			if count == 0 {
+				// Existing events get batch 0, meaning "not from a recorded import"
+				if _, err := tx.Exec("ALTER TABLE log2timeline ADD COLUMN batch_id INT DEFAULT 0"); err != nil {
+					return err
+				}
+				if _, err := tx.Exec(d.CreateIndexSQL(batchIndexName, "log2timeline", "batch_id")); err != nil {
+					return err
+				}
+			}
+			return evidenceFromDiskConfig(tx, d)
+		},
+	},
+	{
+		version:     6,
+		description: "create tags and event_tags",
+		apply: func(tx schemaExecer, d Dialect) error {
+			for _, stmt := range tagTablesSQL(d) {
+				if _, err := tx.Exec(stmt); err != nil {
+					return err
+				}
+			}
+			// Split the comma-separated tag column into tag rows
+			return ingestEventTags(tx, d, 0)
+		},
+	},
+	{
+		version:     7,
+		description: "create audit_log",
+		up: func(d Dialect) []string {
+			return d.CreateAuditLogSQL()
+		},
+	},
+	{
+		version:     8,
+		description: "create color_rules",
+		up: func(d Dialect) []string {
+			return []string{d.CreateColorRulesTableSQL()}
+		},
+	},
+	{
+		version:     9,
+		description: "add saved query metadata columns",
+		apply: func(tx schemaExecer, d Dialect) error {
+			return addTextColumns(tx, d, "l2t_saved_query", savedQueryMetadataColumns...)
+		},
+	},
+	{
+		version:     10,
+		description: "add l2t_saved_query.parameters",
+		apply: func(tx schemaExecer, d Dialect) error {
+			return addTextColumns(tx, d, "l2t_saved_query", "parameters")
+		},
+	},
+}
+
+// addTextColumns adds each of columns to table as TEXT unless it exists.
+func addTextColumns(tx schemaExecer, d Dialect, table string, columns ...string) error {
+	for _, col := range columns {
+		var count int
+		if err := tx.QueryRow(d.SchemaCheckColumnSQL(table, col)).Scan(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		if _, err := tx.Exec("ALTER TABLE " + table + " ADD COLUMN " + col + " TEXT"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batchIndexName names the index on log2timeline.batch_id. It avoids the
+// "<field>_idx" names that RebuildIndexes manages.
+const batchIndexName = "log2timeline_batch_idx"
+
+// caseTablesSQL returns the DDL for the case, evidence and import batch tables.
+func caseTablesSQL(d Dialect) []string {
+	return []string{d.CreateCaseInfoTableSQL(), d.CreateEvidenceTableSQL(), d.CreateImportBatchTableSQL()}
+}
+
+// tagTablesSQL returns the DDL for the tags and event_tags tables.
+func tagTablesSQL(d Dialect) []string {
+	return []string{d.CreateTagDefinitionsTableSQL(), d.CreateEventTagsTableSQL()}
+}
+
+// SchemaVersion is the schema version this build creates and understands.
+var SchemaVersion = migrations[len(migrations)-1].version
+
+// currentSchemaVersion returns the highest version recorded in schema_version,
+// or 0 if no migration has been recorded.
+func currentSchemaVersion(tx schemaExecer) (int, error) {
+	var version int
+	err := tx.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version)
+	return version, err
+}
+
+// stampSchemaVersion creates schema_version and records the schema as fully
+// migrated. Used by createSchema, which builds the latest schema directly.
+// A database that already records a version is left for migrateSchema.
+func stampSchemaVersion(tx schemaExecer, d Dialect) error {
+	if _, err := tx.Exec(d.CreateSchemaVersionTableSQL()); err != nil {
+		return fmt.Errorf("creating schema_version table: %w", err)
+	}
+	version, err := currentSchemaVersion(tx)
+	if err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+	if version > 0 {
+		return nil
+	}
+	_, err = tx.Exec(
+		"INSERT INTO schema_version (version, description) VALUES ("+d.Placeholder(1)+", "+d.Placeholder(2)+")",
+		SchemaVersion, "create schema")
+	if err != nil {
+		return fmt.Errorf("recording schema version: %w", err)
+	}
+	return nil
+}
+
+// migrateSchema brings an existing database up to SchemaVersion. Each pending
+// migration runs in its own transaction together with its schema_version row,
+// so an interrupted upgrade resumes where it stopped. MySQL commits DDL
+// implicitly, which is why migrations must also be safe to re-run.
+//
+// A database whose version is newer than SchemaVersion was written by a later
+// release and is refused rather than risk misreading its schema.
+func migrateSchema(conn *sql.DB, d Dialect) error {
+	if _, err := conn.Exec(d.CreateSchemaVersionTableSQL()); err != nil {
+		return fmt.Errorf("creating schema_version table: %w", err)
+	}
+	version, err := currentSchemaVersion(conn)
+	if err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+	if version > SchemaVersion {
+		return fmt.Errorf("database schema version %d is newer than this release supports (%d); upgrade 4n6time to open it",
+			version, SchemaVersion)
+	}
+
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+		if err := runMigration(conn, d, m); err != nil {
+			return fmt.Errorf("migrating schema to version %d (%s): %w", m.version, m.description, err)
+		}
+	}
+	return nil
+}
+
+// runMigration applies m and records it in one transaction. The version is
+// re-read inside the transaction so two clients opening a server database at
+// once apply each migration only once.
+func runMigration(conn *sql.DB, d Dialect, m migration) error {
+	tx, err := conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, ok := d.(*PostgresDialect); ok {
+		if _, err := tx.Exec("LOCK TABLE schema_version IN EXCLUSIVE MODE"); err != nil {
+			return err
+		}
+	}
+	version, err := currentSchemaVersion(tx)
+	if err != nil {
+		return err
+	}
+	if version >= m.version {
+		return nil
+	}
+
+	if m.up != nil {
+		for _, stmt := range m.up(d) {
+			if _, err := tx.Exec(stmt); err != nil {
+				return err
+			}
+		}
+	}
+	if m.apply != nil {
+		if err := m.apply(tx, d); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO schema_version (version, description) VALUES ("+d.Placeholder(1)+", "+d.Placeholder(2)+")",
+		m.version, m.description)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
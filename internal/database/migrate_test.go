@@ -0,0 +1,185 @@
+package database
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// releaseFixtures maps every past release to the SQL script that recreates a
+// database written by it. Releases that did not change the schema share a
+// fixture.
+var releaseFixtures = []struct {
+	release, fixture string
+	notes            int
+}{
+	{"0.7.0", "v0.7.0.sql", 0},
+	{"0.8.0", "v0.8.0.sql", 0},
+	{"0.8.1", "v0.8.0.sql", 0},
+	{"0.9.0", "v0.8.0.sql", 0},
+	{"0.10.0", "v0.10.0.sql", 1},
+	{"0.10.1", "v0.10.0.sql", 1},
+}
+
+// loadFixture builds a SQLite database from a script in testdata without
+// going through OpenSQLite, so no migration has run yet.
+func loadFixture(t *testing.T, name string) string {
+	t.Helper()
+	script, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	path := tempDBPath(t)
+	d := &SQLiteDialect{}
+	conn, err := sql.Open(d.DriverName(), d.DSN(path))
+	if err != nil {
+		t.Fatalf("opening fixture database: %v", err)
+	}
+	defer conn.Close()
+	for _, stmt := range strings.Split(string(script), ";\n") {
+		if strings.TrimSpace(stmt) == "" {
+			continue
+		}
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v\n%s", name, err, stmt)
+		}
+	}
+	return path
+}
+
+func schemaVersionOf(t *testing.T, db *SQLiteStore) int {
+	t.Helper()
+	version, err := currentSchemaVersion(db.conn)
+	if err != nil {
+		t.Fatalf("reading schema version: %v", err)
+	}
+	return version
+}
+
+func TestMigrateReleaseFixtures(t *testing.T) {
+	for _, rf := range releaseFixtures {
+		t.Run(rf.release, func(t *testing.T) {
+			path := loadFixture(t, rf.fixture)
+
+			db, err := OpenSQLite(path)
+			if err != nil {
+				t.Fatalf("OpenSQLite failed: %v", err)
+			}
+			defer db.Close()
+
+			if got := schemaVersionOf(t, db); got != SchemaVersion {
+				t.Errorf("expected schema version %d, got %d", SchemaVersion, got)
+			}
+
+			// Existing events survive and gain a working bookmark column
+			events, err := db.QueryEvents("", nil, "datetime", 0, 0)
+			if err != nil {
+				t.Fatalf("QueryEvents failed: %v", err)
+			}
+			if len(events) != 3 || events[0].Host != "WKSTN01" || events[0].Tag != "lateral" || events[0].BatchID != 0 {
+				t.Fatalf("expected fixture events to be preserved, got %+v", events)
+			}
+			if _, err := db.ToggleBookmark(events[2].ID); err != nil {
+				t.Errorf("ToggleBookmark failed: %v", err)
+			}
+
+			// Existing metadata and saved queries survive
+			hosts, err := db.GetDistinctValues("host")
+			if err != nil || hosts["WKSTN01"] != 2 {
+				t.Errorf("expected host metadata to be preserved, got %v (%v)", hosts, err)
+			}
+			saved, err := db.GetSavedQueries()
+			if err != nil || len(saved) != 1 || saved[0].Name != "PsExec" {
+				t.Errorf("expected saved query to be preserved, got %v (%v)", saved, err)
+			}
+			saved[0].Category = "Lateral movement"
+			if err := db.UpsertSavedQuery(saved[0]); err != nil {
+				t.Errorf("UpsertSavedQuery failed: %v", err)
+			}
+
+			// Examiner notes and provenance work whether or not the tables existed
+			notes, err := db.GetExaminerNotes()
+			if err != nil || len(notes) != rf.notes {
+				t.Errorf("expected %d examiner notes, got %d (%v)", rf.notes, len(notes), err)
+			}
+			if _, err := db.InsertExaminerNote("2025-01-16 09:00:00", "Reviewed", "", ""); err != nil {
+				t.Errorf("InsertExaminerNote failed: %v", err)
+			}
+			if err := db.RecordProvenance("merge", "fixture"); err != nil {
+				t.Errorf("RecordProvenance failed: %v", err)
+			}
+
+			// Comma-separated tags are split into the tags table
+			tags, err := db.GetTags()
+			if err != nil || len(tags) != 1 || tags[0].Name != "lateral" || tags[0].EventCount != 1 {
+				t.Errorf("expected the fixture tag to be migrated, got %+v (%v)", tags, err)
+			}
+
+			// Rows written before the index existed are searchable
+			if got := searchDescs(t, db, "mimikatz*"); len(got) != 1 {
+				t.Errorf("expected pre-existing rows to be indexed, got %v", got)
+			}
+		})
+	}
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	path := loadFixture(t, "v0.7.0.sql")
+	for i := 0; i < 2; i++ {
+		db, err := OpenSQLite(path)
+		if err != nil {
+			t.Fatalf("open %d failed: %v", i, err)
+		}
+		if err := db.Migrate(); err != nil {
+			t.Errorf("Migrate failed: %v", err)
+		}
+		var rows int
+		db.conn.QueryRow("SELECT COUNT(*) FROM schema_version").Scan(&rows)
+		if rows != len(migrations) {
+			t.Errorf("expected one schema_version row per migration, got %d", rows)
+		}
+		db.Close()
+	}
+}
+
+func TestCreateStampsSchemaVersion(t *testing.T) {
+	db := createTestDB(t)
+	if got := schemaVersionOf(t, db); got != SchemaVersion {
+		t.Errorf("expected new database at version %d, got %d", SchemaVersion, got)
+	}
+	if err := db.createSchema(nil); err != nil {
+		t.Errorf("re-running createSchema failed: %v", err)
+	}
+	if err := db.Migrate(); err != nil {
+		t.Errorf("Migrate on a new database failed: %v", err)
+	}
+}
+
+func TestOpenRefusesNewerSchema(t *testing.T) {
+	path := tempDBPath(t)
+	db, err := CreateSQLite(path, nil)
+	if err != nil {
+		t.Fatalf("CreateSQLite failed: %v", err)
+	}
+	if _, err := db.conn.Exec("INSERT INTO schema_version (version, description) VALUES (?, 'from the future')", SchemaVersion+1); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	db.Close()
+
+	if _, err := OpenSQLite(path); err == nil || !strings.Contains(err.Error(), "newer than this release") {
+		t.Errorf("expected newer schema to be refused, got %v", err)
+	}
+}
+
+func TestMigrationsAreOrdered(t *testing.T) {
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Errorf("migration %q has version %d, want %d", m.description, m.version, i+1)
+		}
+		if (m.up == nil) == (m.apply == nil) {
+			t.Errorf("migration %d must set exactly one of up or apply", m.version)
+		}
+	}
+}
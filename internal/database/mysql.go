@@ -0,0 +1,841 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/cdtdelta/4n6time/internal/model"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// mysqlSanitizeDatetime returns the datetime in the "YYYY-MM-DD HH:MM:SS" form
+// MySQL's DATETIME column accepts, or nil (SQL NULL) if it is empty, a zero
+// sentinel, or otherwise unparseable. Strict-mode MySQL rejects the ISO 8601
+// "T" separator and timezone suffixes that other 4n6time sources can contain.
+func mysqlSanitizeDatetime(s string) interface{} {
+	if !validTimestampRe.MatchString(s) {
+		return nil
+	}
+	if s[:4] == "0000" {
+		return nil
+	}
+	return s[:10] + " " + s[11:19]
+}
+
+// MySQLStore manages all MySQL and MariaDB operations for a 4n6time database.
+// It implements the Store interface.
+//
+// DATETIME values are returned by the driver as "YYYY-MM-DD HH:MM:SS" text
+// (the DSN does not set parseTime), which matches the format SQLite stores.
+type MySQLStore struct {
+	connStr  string
+	conn     *sql.DB
+	dialect  Dialect
+	examiner string // recorded in audit_log with each change
+}
+
+// OpenMySQL opens an existing 4n6time MySQL or MariaDB database.
+func OpenMySQL(connStr string) (*MySQLStore, error) {
+	d := &MySQLDialect{}
+
+	conn, err := sql.Open(d.DriverName(), d.DSN(connStr))
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("connecting to database: %w", err)
+	}
+
+	// Bring databases from earlier releases up to the current schema
+	if err := migrateSchema(conn, d); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &MySQLStore{connStr: connStr, conn: conn, dialect: d}, nil
+}
+
+// CreateMySQL creates a new 4n6time schema on a MySQL or MariaDB database.
+// The database itself must already exist; this creates the tables and indexes.
+func CreateMySQL(connStr string, indexFields []string) (*MySQLStore, error) {
+	d := &MySQLDialect{}
+
+	conn, err := sql.Open(d.DriverName(), d.DSN(connStr))
+	if err != nil {
+		return nil, fmt.Errorf("creating database: %w", err)
+	}
+
+	db := &MySQLStore{connStr: connStr, conn: conn, dialect: d}
+
+	if err := db.createSchema(indexFields); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("creating schema: %w", err)
+	}
+
+	return db, nil
+}
+
+// Close closes the database connection.
+func (db *MySQLStore) Close() error {
+	if db.conn != nil {
+		return db.conn.Close()
+	}
+	return nil
+}
+
+// Path returns the connection string used to connect to the database.
+func (db *MySQLStore) Path() string {
+	return db.connStr
+}
+
+// Conn returns the underlying *sql.DB connection for advanced query usage.
+func (db *MySQLStore) Conn() *sql.DB {
+	return db.conn
+}
+
+// Migrate applies any pending schema migrations.
+func (db *MySQLStore) Migrate() error {
+	return migrateSchema(db.conn, db.dialect)
+}
+
+// indexExists reports whether the named index exists on log2timeline.
+// MySQL has no CREATE INDEX IF NOT EXISTS or DROP INDEX IF EXISTS.
+func (db *MySQLStore) indexExists(indexName string) (bool, error) {
+	var count int
+	err := db.conn.QueryRow(
+		"SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = 'log2timeline' AND index_name = ?",
+		indexName,
+	).Scan(&count)
+	return count > 0, err
+}
+
+// createSchema builds all tables and indexes for a new database.
+// MySQL commits DDL implicitly, so unlike the other backends the statements
+// are not wrapped in a transaction; every statement is idempotent instead.
+func (db *MySQLStore) createSchema(indexFields []string) error {
+	if indexFields == nil {
+		indexFields = DefaultIndexFields
+	}
+
+	// Main event table
+	if _, err := db.conn.Exec(db.dialect.CreateTableSQL()); err != nil {
+		return fmt.Errorf("creating log2timeline table: %w", err)
+	}
+
+	// Metadata tables for filter dropdowns (distinct values + frequency)
+	for _, f := range metadataFields {
+		tableName := "l2t_" + f + "s"
+		if _, err := db.conn.Exec(db.dialect.CreateMetadataTableSQL(tableName, f)); err != nil {
+			return fmt.Errorf("creating metadata table %s: %w", tableName, err)
+		}
+	}
+
+	// Tags table
+	if _, err := db.conn.Exec(db.dialect.CreateTagsTableSQL()); err != nil {
+		return fmt.Errorf("creating l2t_tags table: %w", err)
+	}
+
+	// Saved queries table
+	if _, err := db.conn.Exec(db.dialect.CreateSavedQueryTableSQL()); err != nil {
+		return fmt.Errorf("creating l2t_saved_query table: %w", err)
+	}
+
+	// Disk image config table, with its default row if the table is new
+	if _, err := db.conn.Exec(db.dialect.CreateDiskTableSQL()); err != nil {
+		return fmt.Errorf("creating l2t_disk table: %w", err)
+	}
+	var diskRows int
+	if err := db.conn.QueryRow("SELECT COUNT(*) FROM l2t_disk").Scan(&diskRows); err != nil {
+		return fmt.Errorf("checking l2t_disk: %w", err)
+	}
+	if diskRows == 0 {
+		if _, err := db.conn.Exec(db.dialect.InsertDefaultDiskSQL()); err != nil {
+			return fmt.Errorf("inserting default disk config: %w", err)
+		}
+	}
+
+	// Examiner notes table
+	if _, err := db.conn.Exec(db.dialect.CreateExaminerNotesTableSQL()); err != nil {
+		return fmt.Errorf("creating examiner_notes table: %w", err)
+	}
+
+	// Provenance table
+	if _, err := db.conn.Exec(db.dialect.CreateProvenanceTableSQL()); err != nil {
+		return fmt.Errorf("creating l2t_provenance table: %w", err)
+	}
+
+	// Case, evidence item and import batch tables
+	for _, stmt := range append(caseTablesSQL(db.dialect),
+		db.dialect.CreateIndexSQL(batchIndexName, "log2timeline", "batch_id")) {
+		if _, err := db.conn.Exec(stmt); err != nil {
+			return fmt.Errorf("creating case tables: %w", err)
+		}
+	}
+
+	// Tag and event_tags tables
+	for _, stmt := range tagTablesSQL(db.dialect) {
+		if _, err := db.conn.Exec(stmt); err != nil {
+			return fmt.Errorf("creating tag tables: %w", err)
+		}
+	}
+
+	// Audit log
+	for _, stmt := range db.dialect.CreateAuditLogSQL() {
+		if _, err := db.conn.Exec(stmt); err != nil {
+			return fmt.Errorf("creating audit_log table: %w", err)
+		}
+	}
+
+	// Color rules
+	if _, err := db.conn.Exec(db.dialect.CreateColorRulesTableSQL()); err != nil {
+		return fmt.Errorf("creating color_rules table: %w", err)
+	}
+
+	// Create indexes
+	for _, field := range indexFields {
+		exists, err := db.indexExists(field + "_idx")
+		if err != nil {
+			return fmt.Errorf("checking index on %s: %w", field, err)
+		}
+		if exists {
+			continue
+		}
+		if _, err := db.conn.Exec(db.dialect.CreateIndexSQL(field+"_idx", "log2timeline", field)); err != nil {
+			return fmt.Errorf("creating index on %s: %w", field, err)
+		}
+	}
+
+	return stampSchemaVersion(db.conn, db.dialect)
+}
+
+// RecordProvenance appends an entry to the l2t_provenance table.
+func (db *MySQLStore) RecordProvenance(action, detail string) error {
+	_, err := db.conn.Exec("INSERT INTO l2t_provenance (action, detail) VALUES (?, ?)", action, detail)
+	if err != nil {
+		return fmt.Errorf("recording provenance: %w", err)
+	}
+	return nil
+}
+
+// GetProvenance returns all provenance entries in the order they were recorded.
+func (db *MySQLStore) GetProvenance() ([]ProvenanceEntry, error) {
+	rows, err := db.conn.Query(
+		"SELECT id, action, detail, COALESCE(DATE_FORMAT(created_at, '%Y-%m-%d %H:%i:%s'), '') FROM l2t_provenance ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("querying provenance: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []ProvenanceEntry
+	for rows.Next() {
+		var pe ProvenanceEntry
+		var action, detail sql.NullString
+		if err := rows.Scan(&pe.ID, &action, &detail, &pe.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning provenance entry: %w", err)
+		}
+		pe.Action = action.String
+		pe.Detail = detail.String
+		entries = append(entries, pe)
+	}
+	return entries, rows.Err()
+}
+
+// SearchSnippets returns nil: MySQL has no full-text index, so search
+// falls back to LIKE matching and the grid shows no snippets.
+func (db *MySQLStore) SearchSnippets(text string, ids []int64) ([]SearchMatch, error) {
+	return nil, nil
+}
+
+// InsertExaminerNote inserts a new examiner note and returns its negated ID.
+func (db *MySQLStore) InsertExaminerNote(datetime, description, tag, color string) (int64, error) {
+	var id int64
+	err := runAudited(db.conn, db.dialect, db.examiner, AuditAddNote, func(tx *sql.Tx, a *auditChange) error {
+		result, err := tx.Exec(db.dialect.InsertExaminerNoteSQL(),
+			mysqlSanitizeDatetime(datetime), description, tag, color, 0)
+		if err != nil {
+			return fmt.Errorf("inserting examiner note: %w", err)
+		}
+		if id, err = result.LastInsertId(); err != nil {
+			return fmt.Errorf("getting examiner note id: %w", err)
+		}
+		a.touch("examiner_notes", []int64{id})
+		a.new = map[string]interface{}{
+			"datetime": datetime, "description": description, "tag": tag, "color": color, "bookmark": 0,
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return -id, nil
+}
+
+// DeleteExaminerNote deletes an examiner note by its positive internal ID.
+func (db *MySQLStore) DeleteExaminerNote(id int64) error {
+	return runAudited(db.conn, db.dialect, db.examiner, AuditDeleteNote, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "examiner_notes", examinerNoteFields, []int64{id}); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM examiner_notes WHERE id = ?", id); err != nil {
+			return fmt.Errorf("deleting examiner note: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateExaminerNoteColor updates the color of an examiner note by its positive internal ID.
+func (db *MySQLStore) UpdateExaminerNoteColor(id int64, color string) error {
+	return db.BulkUpdateExaminerNoteColor([]int64{id}, color)
+}
+
+// ToggleExaminerNoteBookmark toggles the bookmark flag on an examiner note and returns the new value.
+func (db *MySQLStore) ToggleExaminerNoteBookmark(id int64) (int64, error) {
+	var val sql.NullInt64
+	err := runAudited(db.conn, db.dialect, db.examiner, AuditToggleBookmark, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "examiner_notes", []string{"bookmark"}, []int64{id}); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE examiner_notes SET bookmark = CASE WHEN bookmark = 1 THEN 0 ELSE 1 END WHERE id = ?", id); err != nil {
+			return err
+		}
+		if err := tx.QueryRow("SELECT bookmark FROM examiner_notes WHERE id = ?", id).Scan(&val); err != nil {
+			return err
+		}
+		a.new = map[string]interface{}{"bookmark": val.Int64}
+		return nil
+	})
+	return val.Int64, err
+}
+
+// GetExaminerNotes returns all examiner notes as Event objects with negated IDs.
+func (db *MySQLStore) GetExaminerNotes() ([]*model.Event, error) {
+	rows, err := db.conn.Query("SELECT id, datetime, description, tag, color, bookmark FROM examiner_notes ORDER BY datetime")
+	if err != nil {
+		return nil, fmt.Errorf("querying examiner notes: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []*model.Event
+	for rows.Next() {
+		var id int64
+		var datetime, description, tag, color sql.NullString
+		var bookmark sql.NullInt64
+		if err := rows.Scan(&id, &datetime, &description, &tag, &color, &bookmark); err != nil {
+			return nil, fmt.Errorf("scanning examiner note: %w", err)
+		}
+		notes = append(notes, &model.Event{
+			ID:         -id,
+			Datetime:   datetime.String,
+			Desc:       description.String,
+			Source:     "EXAMINER",
+			SourceType: "Examiner Note",
+			Tag:        tag.String,
+			Color:      color.String,
+			Bookmark:   bookmark.Int64,
+		})
+	}
+	return notes, rows.Err()
+}
+
+// BulkUpdateColor sets the color on multiple log2timeline events in a single transaction.
+func (db *MySQLStore) BulkUpdateColor(ids []int64, color string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return runAudited(db.conn, db.dialect, db.examiner, AuditSetColor, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "log2timeline", []string{"color"}, ids); err != nil {
+			return err
+		}
+		a.new = map[string]interface{}{"color": color}
+		for _, id := range ids {
+			if _, err := tx.Exec("UPDATE log2timeline SET color = ? WHERE id = ?", color, id); err != nil {
+				return fmt.Errorf("updating color for event %d: %w", id, err)
+			}
+		}
+		return nil
+	})
+}
+
+// BulkSetBookmark sets the bookmark value on multiple log2timeline events.
+func (db *MySQLStore) BulkSetBookmark(ids []int64, bookmark int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return runAudited(db.conn, db.dialect, db.examiner, AuditSetBookmark, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "log2timeline", []string{"bookmark"}, ids); err != nil {
+			return err
+		}
+		a.new = map[string]interface{}{"bookmark": bookmark}
+		for _, id := range ids {
+			if _, err := tx.Exec("UPDATE log2timeline SET bookmark = ? WHERE id = ?", bookmark, id); err != nil {
+				return fmt.Errorf("updating bookmark for event %d: %w", id, err)
+			}
+		}
+		return nil
+	})
+}
+
+// BulkUpdateExaminerNoteColor sets the color on multiple examiner notes.
+func (db *MySQLStore) BulkUpdateExaminerNoteColor(ids []int64, color string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return runAudited(db.conn, db.dialect, db.examiner, AuditSetColor, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "examiner_notes", []string{"color"}, ids); err != nil {
+			return err
+		}
+		a.new = map[string]interface{}{"color": color}
+		for _, id := range ids {
+			if _, err := tx.Exec("UPDATE examiner_notes SET color = ? WHERE id = ?", color, id); err != nil {
+				return fmt.Errorf("updating examiner note color for %d: %w", id, err)
+			}
+		}
+		return nil
+	})
+}
+
+// BulkSetExaminerNoteBookmark sets the bookmark value on multiple examiner notes.
+func (db *MySQLStore) BulkSetExaminerNoteBookmark(ids []int64, bookmark int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return runAudited(db.conn, db.dialect, db.examiner, AuditSetBookmark, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "examiner_notes", []string{"bookmark"}, ids); err != nil {
+			return err
+		}
+		a.new = map[string]interface{}{"bookmark": bookmark}
+		for _, id := range ids {
+			if _, err := tx.Exec("UPDATE examiner_notes SET bookmark = ? WHERE id = ?", bookmark, id); err != nil {
+				return fmt.Errorf("updating examiner note bookmark for %d: %w", id, err)
+			}
+		}
+		return nil
+	})
+}
+
+// ToggleBookmark toggles the bookmark flag on an event and returns the new value.
+func (db *MySQLStore) ToggleBookmark(rowid int64) (int64, error) {
+	var val sql.NullInt64
+	err := runAudited(db.conn, db.dialect, db.examiner, AuditToggleBookmark, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "log2timeline", []string{"bookmark"}, []int64{rowid}); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE log2timeline SET bookmark = CASE WHEN bookmark = 1 THEN 0 ELSE 1 END WHERE id = ?", rowid); err != nil {
+			return err
+		}
+		if err := tx.QueryRow("SELECT bookmark FROM log2timeline WHERE id = ?", rowid).Scan(&val); err != nil {
+			return err
+		}
+		a.new = map[string]interface{}{"bookmark": val.Int64}
+		return nil
+	})
+	return val.Int64, err
+}
+
+// mysqlEventArgs returns the 31 InsertEventSQL arguments for an event.
+func mysqlEventArgs(e *model.Event) []interface{} {
+	return []interface{}{
+		e.Timezone, e.MACB, e.Source, e.SourceType, e.Type,
+		e.User, e.Host, e.Desc, e.Filename, e.Inode,
+		e.Notes, e.Format, e.Extra, mysqlSanitizeDatetime(e.Datetime), e.ReportNotes,
+		e.InReport, e.Tag, e.Color, e.Offset, e.StoreNumber,
+		e.StoreIndex, e.VSSStoreNumber, e.URL, e.RecordNumber, e.EventID,
+		e.EventType, e.SourceName, e.UserSID, e.ComputerName, e.Bookmark,
+		e.BatchID,
+	}
+}
+
+// InsertEvent inserts a single event into the database.
+func (db *MySQLStore) InsertEvent(e *model.Event) error {
+	// Only this event's tags are ingested, not every tagged event's
+	var after int64
+	tagged := len(splitTags(e.Tag)) > 0
+	if tagged {
+		var err error
+		if after, err = lastEventID(db.conn, db.dialect); err != nil {
+			return err
+		}
+	}
+	if _, err := db.conn.Exec(db.dialect.InsertEventSQL(), mysqlEventArgs(e)...); err != nil {
+		return err
+	}
+	if !tagged {
+		return nil
+	}
+	return ingestEventTags(db.conn, db.dialect, after)
+}
+
+// InsertEvents inserts a batch of events inside a single transaction.
+// The onProgress callback is called every 10,000 events with the current count.
+// Pass nil for onProgress if you don't need progress updates.
+func (db *MySQLStore) InsertEvents(events []*model.Event, onProgress func(count int)) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(db.dialect.InsertEventSQL())
+	if err != nil {
+		return 0, fmt.Errorf("preparing insert statement: %w", err)
+	}
+	defer stmt.Close()
+
+	// The tags of the events inserted here are ingested below
+	tagged := hasTags(events)
+	var after int64
+	if tagged {
+		if after, err = lastEventID(tx, db.dialect); err != nil {
+			return 0, err
+		}
+	}
+
+	inserted := 0
+	for _, e := range events {
+		if _, err := stmt.Exec(mysqlEventArgs(e)...); err != nil {
+			return inserted, fmt.Errorf("inserting event %d: %w", inserted+1, err)
+		}
+		inserted++
+		if onProgress != nil && inserted%10000 == 0 {
+			onProgress(inserted)
+		}
+	}
+
+	if tagged {
+		if err := ingestEventTags(tx, db.dialect, after); err != nil {
+			return inserted, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return inserted, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return inserted, nil
+}
+
+// QueryEvents runs a SQL query and returns the matching events.
+// Uses Pattern A column order with MySQL keywords backtick-quoted.
+func (db *MySQLStore) QueryEvents(whereClause string, args []interface{}, orderBy string, limit, offset int) ([]*model.Event, error) {
+	query := "SELECT id, timezone, MACB, source, sourcetype, type, `user`, host, " +
+		"`desc`, filename, inode, notes, format, extra, datetime, reportnotes, " +
+		"inreport, tag, color, `offset`, store_number, store_index, vss_store_number, " +
+		"URL, record_number, event_identifier, event_type, source_name, user_sid, " +
+		"computer_name, bookmark, batch_id FROM log2timeline"
+
+	if whereClause != "" {
+		query += " WHERE " + whereClause
+	}
+
+	if orderBy != "" {
+		query += " ORDER BY " + orderBy
+	}
+
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+		if offset > 0 {
+			query += fmt.Sprintf(" OFFSET %d", offset)
+		}
+	}
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying events: %w", err)
+	}
+	defer rows.Close()
+
+	return pgScanEvents(rows)
+}
+
+// CountEvents returns the total number of events, optionally filtered by a WHERE clause.
+func (db *MySQLStore) CountEvents(whereClause string, args []interface{}) (int64, error) {
+	query := "SELECT COUNT(id) FROM log2timeline"
+	if whereClause != "" {
+		query += " WHERE " + whereClause
+	}
+
+	var count int64
+	err := db.conn.QueryRow(query, args...).Scan(&count)
+	return count, err
+}
+
+// GetMinMaxDate returns the earliest and latest datetime values in the database.
+func (db *MySQLStore) GetMinMaxDate() (minDate, maxDate string, err error) {
+	err = db.conn.QueryRow(
+		`SELECT COALESCE(DATE_FORMAT(MIN(datetime), '%Y-%m-%d %H:%i:%s'), ''),
+		        COALESCE(DATE_FORMAT(MAX(datetime), '%Y-%m-%d %H:%i:%s'), '')
+		 FROM log2timeline WHERE datetime > '1970-01-01' AND datetime < '2100-01-01'`,
+	).Scan(&minDate, &maxDate)
+	return
+}
+
+// GetDistinctValues returns a map of distinct values and their counts for a given column.
+// Includes values from examiner_notes for source, sourcetype, and tag columns.
+func (db *MySQLStore) GetDistinctValues(fieldName string) (map[string]int64, error) {
+	if !isValidField(fieldName) {
+		return nil, fmt.Errorf("invalid field name: %s", fieldName)
+	}
+
+	col := mysqlQuoteCol(fieldName)
+	query := fmt.Sprintf(
+		"SELECT %s, COUNT(%s) FROM log2timeline GROUP BY %s", col, col, col)
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]int64)
+	for rows.Next() {
+		var value sql.NullString
+		var count int64
+		if err := rows.Scan(&value, &count); err != nil {
+			return nil, err
+		}
+		if value.String != "" {
+			result[value.String] = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Include examiner_notes values for relevant columns
+	switch fieldName {
+	case "source":
+		var cnt int64
+		err := db.conn.QueryRow("SELECT COUNT(*) FROM examiner_notes").Scan(&cnt)
+		if err == nil && cnt > 0 {
+			result["EXAMINER"] = result["EXAMINER"] + cnt
+		}
+	case "sourcetype":
+		var cnt int64
+		err := db.conn.QueryRow("SELECT COUNT(*) FROM examiner_notes").Scan(&cnt)
+		if err == nil && cnt > 0 {
+			result["Examiner Note"] = result["Examiner Note"] + cnt
+		}
+	case "tag":
+		tagRows, err := db.conn.Query("SELECT tag, COUNT(tag) FROM examiner_notes WHERE tag != '' GROUP BY tag")
+		if err == nil {
+			defer tagRows.Close()
+			for tagRows.Next() {
+				var val string
+				var cnt int64
+				if err := tagRows.Scan(&val, &cnt); err == nil && val != "" {
+					result[val] = result[val] + cnt
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// UpdateEvent updates specific fields of an event identified by its id.
+func (db *MySQLStore) UpdateEvent(rowid int64, fields map[string]interface{}) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	changes := fields
+
+	// Tags are stored in event_tags; the tag column is rendered from them
+	fields, tags, setTags, err := takeTagField(fields)
+	if err != nil {
+		return err
+	}
+
+	setClauses := make([]string, 0, len(fields))
+	args := make([]interface{}, 0, len(fields)+1)
+
+	for field, value := range fields {
+		if !isValidField(field) {
+			return fmt.Errorf("invalid field name: %s", field)
+		}
+		setClauses = append(setClauses, mysqlQuoteCol(field)+" = ?")
+		args = append(args, value)
+	}
+	args = append(args, rowid)
+
+	query := fmt.Sprintf("UPDATE log2timeline SET %s WHERE id = ?", strings.Join(setClauses, ", "))
+
+	return runAudited(db.conn, db.dialect, db.examiner, AuditUpdateEvent, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "log2timeline", fieldNames(changes), []int64{rowid}); err != nil {
+			return err
+		}
+		a.new = changes
+		if len(setClauses) > 0 {
+			if _, err := tx.Exec(query, args...); err != nil {
+				return err
+			}
+		}
+		if setTags {
+			return setEventTags(tx, db.dialect, rowid, tags)
+		}
+		return nil
+	})
+}
+
+// UpdateMetadata refreshes all metadata tables with current distinct values.
+func (db *MySQLStore) UpdateMetadata() error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, f := range metadataFields {
+		tableName := "l2t_" + f + "s"
+		col := mysqlQuoteCol(f)
+
+		// Clear existing metadata
+		_, err = tx.Exec(fmt.Sprintf("DELETE FROM %s", tableName))
+		if err != nil {
+			return fmt.Errorf("clearing %s: %w", tableName, err)
+		}
+
+		// Repopulate with current values
+		_, err = tx.Exec(fmt.Sprintf(
+			"INSERT INTO %s (%s, frequency) SELECT %s, COUNT(%s) FROM log2timeline WHERE %s <> '' GROUP BY %s",
+			tableName, col, col, col, col, col))
+		if err != nil {
+			return fmt.Errorf("populating %s: %w", tableName, err)
+		}
+	}
+
+	// Tags in use, for releases that read l2t_tags
+	if err := populateTagMetadata(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RebuildIndexes drops all existing indexes and creates new ones for the given fields.
+// Like createSchema this runs outside a transaction because MySQL DDL commits implicitly.
+func (db *MySQLStore) RebuildIndexes(indexFields []string) error {
+	// Drop all existing indexes
+	for _, f := range model.Fields {
+		exists, err := db.indexExists(f + "_idx")
+		if err != nil {
+			return fmt.Errorf("checking index %s_idx: %w", f, err)
+		}
+		if !exists {
+			continue
+		}
+		if _, err := db.conn.Exec(db.dialect.DropIndexSQL(f + "_idx")); err != nil {
+			return fmt.Errorf("dropping index %s_idx: %w", f, err)
+		}
+	}
+
+	// Create new indexes
+	for _, f := range indexFields {
+		if _, err := db.conn.Exec(db.dialect.CreateIndexSQL(f+"_idx", "log2timeline", f)); err != nil {
+			return fmt.Errorf("creating index %s_idx: %w", f, err)
+		}
+	}
+
+	return nil
+}
+
+// ExecuteQuery runs a pre-built SQL SELECT and scans results using model.Fields
+// column order (Pattern B: id, datetime, timezone, MACB, ...).
+// Examiner notes are merged via UNION ALL with negated IDs.
+func (db *MySQLStore) ExecuteQuery(sqlStr string, args []interface{}) ([]*model.Event, error) {
+	sqlStr = wrapWithExaminerNotesUnion(sqlStr, db.dialect)
+
+	rows, err := db.conn.Query(sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("executing query: %w", err)
+	}
+	defer rows.Close()
+	return pgScanFieldsOrderEvents(rows)
+}
+
+// ExecuteCountQuery runs a pre-built COUNT query and returns the result.
+// Examiner notes are included in the count via UNION ALL.
+func (db *MySQLStore) ExecuteCountQuery(sqlStr string, args []interface{}) (int64, error) {
+	sqlStr = wrapCountWithExaminerNotes(sqlStr, db.dialect)
+
+	var count int64
+	err := db.conn.QueryRow(sqlStr, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("executing count query: %w", err)
+	}
+	return count, nil
+}
+
+// GetTimelineHistogram returns event counts bucketed by time interval.
+// Uses DATE_FORMAT for both the date range detection and the buckets.
+func (db *MySQLStore) GetTimelineHistogram(whereClause string, whereArgs []interface{}) ([]TimelineBucket, error) {
+	rangeSQL := `SELECT COALESCE(DATE_FORMAT(MIN(datetime), '%Y-%m-%d %H:%i:%s'), ''),
+	                    COALESCE(DATE_FORMAT(MAX(datetime), '%Y-%m-%d %H:%i:%s'), '')
+	             FROM log2timeline`
+	if whereClause != "" {
+		rangeSQL += " " + whereClause
+	}
+
+	var minDate, maxDate string
+	if err := db.conn.QueryRow(rangeSQL, whereArgs...).Scan(&minDate, &maxDate); err != nil {
+		return nil, fmt.Errorf("getting date range: %w", err)
+	}
+
+	if minDate == "" || maxDate == "" {
+		return []TimelineBucket{}, nil
+	}
+
+	// Choose bucket format based on date range span
+	bucketFormat := "%Y-%m-%d %H:00:00" // default: hourly
+
+	if len(minDate) >= 10 && len(maxDate) >= 10 {
+		minDay := minDate[:10]
+		maxDay := maxDate[:10]
+
+		if minDay == maxDay {
+			bucketFormat = "%Y-%m-%d %H:00:00"
+		} else {
+			minYM := minDate[:7]
+			maxYM := maxDate[:7]
+			if minYM == maxYM {
+				bucketFormat = "%Y-%m-%d"
+			} else {
+				minYear := minDate[:4]
+				maxYear := maxDate[:4]
+				if minYear != maxYear {
+					bucketFormat = "%Y-%m"
+				} else {
+					bucketFormat = "%Y-%m-%d"
+				}
+			}
+		}
+	}
+
+	// Build and run histogram query using dialect-specific date formatting
+	bucketExpr := db.dialect.DateFormatSQL("datetime", bucketFormat)
+	histSQL := "SELECT " + bucketExpr + " as bucket, COUNT(*) as cnt FROM log2timeline"
+	if whereClause != "" {
+		histSQL += " " + whereClause
+	}
+	histSQL += " GROUP BY bucket ORDER BY bucket"
+
+	rows, err := db.conn.Query(histSQL, whereArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("histogram query: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []TimelineBucket
+	for rows.Next() {
+		var b TimelineBucket
+		if err := rows.Scan(&b.Timestamp, &b.Count); err != nil {
+			return nil, fmt.Errorf("scanning bucket: %w", err)
+		}
+		buckets = append(buckets, b)
+	}
+
+	return buckets, rows.Err()
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+)
+
+// countByField returns the number of events matching where for each
+// distinct value of field, as used to break a pivot down by source type.
+// Empty and NULL values are counted under "".
+func countByField(conn *sql.DB, d Dialect, field, where string, args []interface{}) (map[string]int64, error) {
+	if !isValidField(field) {
+		return nil, fmt.Errorf("invalid field name: %s", field)
+	}
+	col := d.QuoteColumn(field)
+	query := "SELECT " + col + ", COUNT(*) FROM log2timeline"
+	if where != "" {
+		query += " WHERE " + where
+	}
+	query += " GROUP BY " + col
+
+	rows, err := conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("counting by %s: %w", field, err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var value sql.NullString
+		var count int64
+		if err := rows.Scan(&value, &count); err != nil {
+			return nil, fmt.Errorf("scanning %s count: %w", field, err)
+		}
+		counts[value.String] += count
+	}
+	return counts, rows.Err()
+}
+
+// eachMatchingID calls fn with the ids of the events matching where, in id
+// order, batchSize at a time, as used to tag the hits of a batch search
+// without holding them all in memory. Paging on the id means fn may change
+// the events it is given without disturbing the batches that follow.
+func eachMatchingID(conn *sql.DB, d Dialect, where string, args []interface{}, batchSize int, fn func([]int64) error) error {
+	idCol := d.IDColumn()
+	query := "SELECT " + idCol + " FROM log2timeline WHERE "
+	if where != "" {
+		query += "(" + where + ") AND "
+	}
+	query += idCol + " > " + d.Placeholder(len(args)+1) + " ORDER BY " + idCol + " LIMIT " + strconv.Itoa(batchSize)
+
+	var last int64
+	for {
+		ids, err := scanIDs(conn, query, append(args[:len(args):len(args)], last))
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := fn(ids); err != nil {
+			return err
+		}
+		if len(ids) < batchSize {
+			return nil
+		}
+		last = ids[len(ids)-1]
+	}
+}
+
+func scanIDs(conn *sql.DB, query string, args []interface{}) ([]int64, error) {
+	rows, err := conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("matching events: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scanning event id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// -- Store methods --
+
+// CountByField returns the number of events matching where for each
+// distinct value of field.
+func (db *SQLiteStore) CountByField(field, where string, args []interface{}) (map[string]int64, error) {
+	return countByField(db.conn, db.dialect, field, where, args)
+}
+
+// CountByField returns the number of events matching where for each
+// distinct value of field.
+func (db *PostgresStore) CountByField(field, where string, args []interface{}) (map[string]int64, error) {
+	return countByField(db.conn, db.dialect, field, where, args)
+}
+
+// CountByField returns the number of events matching where for each
+// distinct value of field.
+func (db *MySQLStore) CountByField(field, where string, args []interface{}) (map[string]int64, error) {
+	return countByField(db.conn, db.dialect, field, where, args)
+}
+
+// EachMatchingID calls fn with the ids of the events matching where, in
+// id order, batchSize at a time.
+func (db *SQLiteStore) EachMatchingID(where string, args []interface{}, batchSize int, fn func([]int64) error) error {
+	return eachMatchingID(db.conn, db.dialect, where, args, batchSize, fn)
+}
+
+// EachMatchingID calls fn with the ids of the events matching where, in
+// id order, batchSize at a time.
+func (db *PostgresStore) EachMatchingID(where string, args []interface{}, batchSize int, fn func([]int64) error) error {
+	return eachMatchingID(db.conn, db.dialect, where, args, batchSize, fn)
+}
+
+// EachMatchingID calls fn with the ids of the events matching where, in
+// id order, batchSize at a time.
+func (db *MySQLStore) EachMatchingID(where string, args []interface{}, batchSize int, fn func([]int64) error) error {
+	return eachMatchingID(db.conn, db.dialect, where, args, batchSize, fn)
+}
@@ -47,9 +47,11 @@ func pgSanitizeDatetime(s string) interface{} {
 // PostgresStore manages all PostgreSQL operations for a 4n6time database.
 // It implements the Store interface.
 type PostgresStore struct {
-	connStr string
-	conn    *sql.DB
-	dialect Dialect
+	connStr  string
+	conn     *sql.DB
+	dialect  Dialect
+	trigram  bool   // pg_trgm search index present
+	examiner string // recorded in audit_log with each change
 }
 
 // OpenPostgres opens an existing 4n6time PostgreSQL database.
@@ -66,10 +68,14 @@ func OpenPostgres(connStr string) (*PostgresStore, error) {
 		return nil, fmt.Errorf("connecting to database: %w", err)
 	}
 
-	db := &PostgresStore{connStr: connStr, conn: conn, dialect: d}
+	// Bring databases from earlier releases up to the current schema
+	if err := migrateSchema(conn, d); err != nil {
+		conn.Close()
+		return nil, err
+	}
 
-	// Migrate: add bookmark column if it doesn't exist (for pre-0.8.0 databases)
-	db.migrate()
+	db := &PostgresStore{connStr: connStr, conn: conn, dialect: d}
+	db.enableTrigramSearch()
 
 	return db, nil
 }
@@ -112,72 +118,102 @@ func (db *PostgresStore) Conn() *sql.DB {
 	return db.conn
 }
 
-// migrate applies schema migrations for backward compatibility.
-func (db *PostgresStore) migrate() {
-	// Add bookmark column if missing
-	var count int
-	err := db.conn.QueryRow(
-		db.dialect.SchemaCheckColumnSQL("log2timeline", "bookmark"),
-	).Scan(&count)
-	if err == nil && count == 0 {
-		db.conn.Exec("ALTER TABLE log2timeline ADD COLUMN bookmark INT DEFAULT 0")
-	}
-
-	// Create examiner_notes table if missing
-	db.conn.Exec(db.dialect.CreateExaminerNotesTableSQL())
-}
-
 // Migrate applies any pending schema migrations.
 func (db *PostgresStore) Migrate() error {
-	db.migrate()
+	return migrateSchema(db.conn, db.dialect)
+}
+
+// RecordProvenance appends an entry to the l2t_provenance table.
+func (db *PostgresStore) RecordProvenance(action, detail string) error {
+	_, err := db.conn.Exec(
+		"INSERT INTO l2t_provenance (action, detail) VALUES ("+db.dialect.Placeholder(1)+", "+db.dialect.Placeholder(2)+")",
+		pgSanitizeString(action), pgSanitizeString(detail),
+	)
+	if err != nil {
+		return fmt.Errorf("recording provenance: %w", err)
+	}
 	return nil
 }
 
+// GetProvenance returns all provenance entries in the order they were recorded.
+func (db *PostgresStore) GetProvenance() ([]ProvenanceEntry, error) {
+	rows, err := db.conn.Query(
+		"SELECT id, action, detail, COALESCE(to_char(created_at, 'YYYY-MM-DD HH24:MI:SS'), '') FROM l2t_provenance ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("querying provenance: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []ProvenanceEntry
+	for rows.Next() {
+		var pe ProvenanceEntry
+		var action, detail sql.NullString
+		if err := rows.Scan(&pe.ID, &action, &detail, &pe.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning provenance entry: %w", err)
+		}
+		pe.Action = action.String
+		pe.Detail = detail.String
+		entries = append(entries, pe)
+	}
+	return entries, rows.Err()
+}
+
 // InsertExaminerNote inserts a new examiner note and returns its negated ID.
 func (db *PostgresStore) InsertExaminerNote(datetime, description, tag, color string) (int64, error) {
 	var id int64
-	err := db.conn.QueryRow(db.dialect.InsertExaminerNoteSQL(),
-		pgSanitizeDatetime(datetime), pgSanitizeString(description),
-		pgSanitizeString(tag), pgSanitizeString(color), 0,
-	).Scan(&id)
+	err := runAudited(db.conn, db.dialect, db.examiner, AuditAddNote, func(tx *sql.Tx, a *auditChange) error {
+		if err := tx.QueryRow(db.dialect.InsertExaminerNoteSQL(),
+			pgSanitizeDatetime(datetime), pgSanitizeString(description),
+			pgSanitizeString(tag), pgSanitizeString(color), 0,
+		).Scan(&id); err != nil {
+			return fmt.Errorf("inserting examiner note: %w", err)
+		}
+		a.touch("examiner_notes", []int64{id})
+		a.new = map[string]interface{}{
+			"datetime": datetime, "description": description, "tag": tag, "color": color, "bookmark": 0,
+		}
+		return nil
+	})
 	if err != nil {
-		return 0, fmt.Errorf("inserting examiner note: %w", err)
+		return 0, err
 	}
 	return -id, nil
 }
 
 // DeleteExaminerNote deletes an examiner note by its positive internal ID.
 func (db *PostgresStore) DeleteExaminerNote(id int64) error {
-	_, err := db.conn.Exec("DELETE FROM examiner_notes WHERE id = "+db.dialect.Placeholder(1), id)
-	if err != nil {
-		return fmt.Errorf("deleting examiner note: %w", err)
-	}
-	return nil
+	return runAudited(db.conn, db.dialect, db.examiner, AuditDeleteNote, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "examiner_notes", examinerNoteFields, []int64{id}); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM examiner_notes WHERE id = "+db.dialect.Placeholder(1), id); err != nil {
+			return fmt.Errorf("deleting examiner note: %w", err)
+		}
+		return nil
+	})
 }
 
 // UpdateExaminerNoteColor updates the color of an examiner note by its positive internal ID.
 func (db *PostgresStore) UpdateExaminerNoteColor(id int64, color string) error {
-	_, err := db.conn.Exec(
-		"UPDATE examiner_notes SET color = "+db.dialect.Placeholder(1)+" WHERE id = "+db.dialect.Placeholder(2),
-		pgSanitizeString(color), id)
-	if err != nil {
-		return fmt.Errorf("updating examiner note color: %w", err)
-	}
-	return nil
+	return db.BulkUpdateExaminerNoteColor([]int64{id}, color)
 }
 
 // ToggleExaminerNoteBookmark toggles the bookmark flag on an examiner note and returns the new value.
 func (db *PostgresStore) ToggleExaminerNoteBookmark(id int64) (int64, error) {
-	_, err := db.conn.Exec(
-		"UPDATE examiner_notes SET bookmark = CASE WHEN bookmark = 1 THEN 0 ELSE 1 END WHERE id = "+db.dialect.Placeholder(1),
-		id)
-	if err != nil {
-		return 0, err
-	}
 	var val sql.NullInt64
-	err = db.conn.QueryRow(
-		"SELECT bookmark FROM examiner_notes WHERE id = "+db.dialect.Placeholder(1),
-		id).Scan(&val)
+	err := runAudited(db.conn, db.dialect, db.examiner, AuditToggleBookmark, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "examiner_notes", []string{"bookmark"}, []int64{id}); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE examiner_notes SET bookmark = CASE WHEN bookmark = 1 THEN 0 ELSE 1 END WHERE id = "+db.dialect.Placeholder(1), id); err != nil {
+			return err
+		}
+		if err := tx.QueryRow("SELECT bookmark FROM examiner_notes WHERE id = "+db.dialect.Placeholder(1), id).Scan(&val); err != nil {
+			return err
+		}
+		a.new = map[string]interface{}{"bookmark": val.Int64}
+		return nil
+	})
 	return val.Int64, err
 }
 
@@ -216,40 +252,18 @@ func (db *PostgresStore) BulkUpdateColor(ids []int64, color string) error {
 	if len(ids) == 0 {
 		return nil
 	}
-	tx, err := db.conn.Begin()
-	if err != nil {
-		return fmt.Errorf("beginning transaction: %w", err)
-	}
-	defer tx.Rollback()
-	for _, id := range ids {
-		if _, err := tx.Exec("UPDATE log2timeline SET color = $1 WHERE id = $2", color, id); err != nil {
-			return fmt.Errorf("updating color for event %d: %w", id, err)
-		}
-	}
-	return tx.Commit()
-}
-
-// BulkAddTag appends a tag to multiple log2timeline events, avoiding duplicates.
-func (db *PostgresStore) BulkAddTag(ids []int64, tag string) error {
-	if len(ids) == 0 || tag == "" {
-		return nil
-	}
-	tx, err := db.conn.Begin()
-	if err != nil {
-		return fmt.Errorf("beginning transaction: %w", err)
-	}
-	defer tx.Rollback()
-	for _, id := range ids {
-		var existing sql.NullString
-		if err := tx.QueryRow("SELECT tag FROM log2timeline WHERE id = $1", id).Scan(&existing); err != nil {
-			return fmt.Errorf("reading tag for event %d: %w", id, err)
+	return runAudited(db.conn, db.dialect, db.examiner, AuditSetColor, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "log2timeline", []string{"color"}, ids); err != nil {
+			return err
 		}
-		newTag := appendTagDedup(existing.String, tag)
-		if _, err := tx.Exec("UPDATE log2timeline SET tag = $1 WHERE id = $2", newTag, id); err != nil {
-			return fmt.Errorf("updating tag for event %d: %w", id, err)
+		a.new = map[string]interface{}{"color": color}
+		for _, id := range ids {
+			if _, err := tx.Exec("UPDATE log2timeline SET color = $1 WHERE id = $2", color, id); err != nil {
+				return fmt.Errorf("updating color for event %d: %w", id, err)
+			}
 		}
-	}
-	return tx.Commit()
+		return nil
+	})
 }
 
 // BulkSetBookmark sets the bookmark value on multiple log2timeline events.
@@ -257,17 +271,18 @@ func (db *PostgresStore) BulkSetBookmark(ids []int64, bookmark int64) error {
 	if len(ids) == 0 {
 		return nil
 	}
-	tx, err := db.conn.Begin()
-	if err != nil {
-		return fmt.Errorf("beginning transaction: %w", err)
-	}
-	defer tx.Rollback()
-	for _, id := range ids {
-		if _, err := tx.Exec("UPDATE log2timeline SET bookmark = $1 WHERE id = $2", bookmark, id); err != nil {
-			return fmt.Errorf("updating bookmark for event %d: %w", id, err)
+	return runAudited(db.conn, db.dialect, db.examiner, AuditSetBookmark, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "log2timeline", []string{"bookmark"}, ids); err != nil {
+			return err
 		}
-	}
-	return tx.Commit()
+		a.new = map[string]interface{}{"bookmark": bookmark}
+		for _, id := range ids {
+			if _, err := tx.Exec("UPDATE log2timeline SET bookmark = $1 WHERE id = $2", bookmark, id); err != nil {
+				return fmt.Errorf("updating bookmark for event %d: %w", id, err)
+			}
+		}
+		return nil
+	})
 }
 
 // BulkUpdateExaminerNoteColor sets the color on multiple examiner notes.
@@ -275,17 +290,18 @@ func (db *PostgresStore) BulkUpdateExaminerNoteColor(ids []int64, color string)
 	if len(ids) == 0 {
 		return nil
 	}
-	tx, err := db.conn.Begin()
-	if err != nil {
-		return fmt.Errorf("beginning transaction: %w", err)
-	}
-	defer tx.Rollback()
-	for _, id := range ids {
-		if _, err := tx.Exec("UPDATE examiner_notes SET color = $1 WHERE id = $2", color, id); err != nil {
-			return fmt.Errorf("updating examiner note color for %d: %w", id, err)
+	return runAudited(db.conn, db.dialect, db.examiner, AuditSetColor, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "examiner_notes", []string{"color"}, ids); err != nil {
+			return err
 		}
-	}
-	return tx.Commit()
+		a.new = map[string]interface{}{"color": color}
+		for _, id := range ids {
+			if _, err := tx.Exec("UPDATE examiner_notes SET color = $1 WHERE id = $2", pgSanitizeString(color), id); err != nil {
+				return fmt.Errorf("updating examiner note color for %d: %w", id, err)
+			}
+		}
+		return nil
+	})
 }
 
 // BulkSetExaminerNoteBookmark sets the bookmark value on multiple examiner notes.
@@ -293,35 +309,37 @@ func (db *PostgresStore) BulkSetExaminerNoteBookmark(ids []int64, bookmark int64
 	if len(ids) == 0 {
 		return nil
 	}
-	tx, err := db.conn.Begin()
-	if err != nil {
-		return fmt.Errorf("beginning transaction: %w", err)
-	}
-	defer tx.Rollback()
-	for _, id := range ids {
-		if _, err := tx.Exec("UPDATE examiner_notes SET bookmark = $1 WHERE id = $2", bookmark, id); err != nil {
-			return fmt.Errorf("updating examiner note bookmark for %d: %w", id, err)
+	return runAudited(db.conn, db.dialect, db.examiner, AuditSetBookmark, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "examiner_notes", []string{"bookmark"}, ids); err != nil {
+			return err
 		}
-	}
-	return tx.Commit()
+		a.new = map[string]interface{}{"bookmark": bookmark}
+		for _, id := range ids {
+			if _, err := tx.Exec("UPDATE examiner_notes SET bookmark = $1 WHERE id = $2", bookmark, id); err != nil {
+				return fmt.Errorf("updating examiner note bookmark for %d: %w", id, err)
+			}
+		}
+		return nil
+	})
 }
 
 // ToggleBookmark toggles the bookmark flag on an event and returns the new value.
 func (db *PostgresStore) ToggleBookmark(rowid int64) (int64, error) {
 	idCol := db.dialect.IDColumn()
-	_, err := db.conn.Exec(
-		"UPDATE log2timeline SET bookmark = CASE WHEN bookmark = 1 THEN 0 ELSE 1 END WHERE "+idCol+" = "+db.dialect.Placeholder(1),
-		rowid,
-	)
-	if err != nil {
-		return 0, err
-	}
-
 	var val int64
-	err = db.conn.QueryRow(
-		"SELECT bookmark FROM log2timeline WHERE "+idCol+" = "+db.dialect.Placeholder(1),
-		rowid,
-	).Scan(&val)
+	err := runAudited(db.conn, db.dialect, db.examiner, AuditToggleBookmark, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "log2timeline", []string{"bookmark"}, []int64{rowid}); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE log2timeline SET bookmark = CASE WHEN bookmark = 1 THEN 0 ELSE 1 END WHERE "+idCol+" = "+db.dialect.Placeholder(1), rowid); err != nil {
+			return err
+		}
+		if err := tx.QueryRow("SELECT bookmark FROM log2timeline WHERE "+idCol+" = "+db.dialect.Placeholder(1), rowid).Scan(&val); err != nil {
+			return err
+		}
+		a.new = map[string]interface{}{"bookmark": val}
+		return nil
+	})
 	return val, err
 }
 
@@ -382,6 +400,46 @@ func (db *PostgresStore) createSchema(indexFields []string) error {
 		return fmt.Errorf("creating examiner_notes table: %w", err)
 	}
 
+	// Provenance table
+	_, err = tx.Exec(db.dialect.CreateProvenanceTableSQL())
+	if err != nil {
+		return fmt.Errorf("creating l2t_provenance table: %w", err)
+	}
+
+	// Case, evidence item and import batch tables
+	for _, stmt := range append(caseTablesSQL(db.dialect),
+		db.dialect.CreateIndexSQL(batchIndexName, "log2timeline", "batch_id")) {
+		if _, err = tx.Exec(stmt); err != nil {
+			return fmt.Errorf("creating case tables: %w", err)
+		}
+	}
+
+	// Tag and event_tags tables
+	for _, stmt := range tagTablesSQL(db.dialect) {
+		if _, err = tx.Exec(stmt); err != nil {
+			return fmt.Errorf("creating tag tables: %w", err)
+		}
+	}
+
+	// Audit log
+	for _, stmt := range db.dialect.CreateAuditLogSQL() {
+		if _, err = tx.Exec(stmt); err != nil {
+			return fmt.Errorf("creating audit_log table: %w", err)
+		}
+	}
+
+	// Color rules
+	if _, err = tx.Exec(db.dialect.CreateColorRulesTableSQL()); err != nil {
+		return fmt.Errorf("creating color_rules table: %w", err)
+	}
+
+	// Full-text search column and index
+	for _, stmt := range (&PostgresDialect{}).CreateFullTextSQL() {
+		if _, err = tx.Exec(stmt); err != nil {
+			return fmt.Errorf("creating full-text index: %w", err)
+		}
+	}
+
 	// Create indexes
 	for _, field := range indexFields {
 		_, err = tx.Exec(db.dialect.CreateIndexSQL(field+"_idx", "log2timeline", field))
@@ -390,12 +448,32 @@ func (db *PostgresStore) createSchema(indexFields []string) error {
 		}
 	}
 
-	return tx.Commit()
+	if err := stampSchemaVersion(tx, db.dialect); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Trigram search is optional and created outside the transaction, since
+	// a failed CREATE EXTENSION would abort it.
+	db.enableTrigramSearch()
+	return nil
 }
 
 // InsertEvent inserts a single event into the database.
 func (db *PostgresStore) InsertEvent(e *model.Event) error {
-	_, err := db.conn.Exec(db.dialect.InsertEventSQL(),
+	// Only this event's tags are ingested, not every tagged event's
+	var after int64
+	tagged := len(splitTags(e.Tag)) > 0
+	if tagged {
+		var err error
+		if after, err = lastEventID(db.conn, db.dialect); err != nil {
+			return err
+		}
+	}
+	if _, err := db.conn.Exec(db.dialect.InsertEventSQL(),
 		pgSanitizeString(e.Timezone), pgSanitizeString(e.MACB),
 		pgSanitizeString(e.Source), pgSanitizeString(e.SourceType), pgSanitizeString(e.Type),
 		pgSanitizeString(e.User), pgSanitizeString(e.Host), pgSanitizeString(e.Desc),
@@ -409,9 +487,14 @@ func (db *PostgresStore) InsertEvent(e *model.Event) error {
 		pgSanitizeString(e.EventID), pgSanitizeString(e.EventType),
 		pgSanitizeString(e.SourceName), pgSanitizeString(e.UserSID),
 		pgSanitizeString(e.ComputerName),
-		e.Bookmark,
-	)
-	return err
+		e.Bookmark, e.BatchID,
+	); err != nil {
+		return err
+	}
+	if !tagged {
+		return nil
+	}
+	return ingestEventTags(db.conn, db.dialect, after)
 }
 
 // InsertEvents inserts a batch of events inside a single transaction.
@@ -430,6 +513,15 @@ func (db *PostgresStore) InsertEvents(events []*model.Event, onProgress func(cou
 	}
 	defer stmt.Close()
 
+	// The tags of the events inserted here are ingested below
+	tagged := hasTags(events)
+	var after int64
+	if tagged {
+		if after, err = lastEventID(tx, db.dialect); err != nil {
+			return 0, err
+		}
+	}
+
 	inserted := 0
 	for _, e := range events {
 		_, err := stmt.Exec(
@@ -446,7 +538,7 @@ func (db *PostgresStore) InsertEvents(events []*model.Event, onProgress func(cou
 			pgSanitizeString(e.EventID), pgSanitizeString(e.EventType),
 			pgSanitizeString(e.SourceName), pgSanitizeString(e.UserSID),
 			pgSanitizeString(e.ComputerName),
-			e.Bookmark,
+			e.Bookmark, e.BatchID,
 		)
 		if err != nil {
 			return inserted, fmt.Errorf("inserting event %d: %w", inserted+1, err)
@@ -457,6 +549,12 @@ func (db *PostgresStore) InsertEvents(events []*model.Event, onProgress func(cou
 		}
 	}
 
+	if tagged {
+		if err := ingestEventTags(tx, db.dialect, after); err != nil {
+			return inserted, err
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		return inserted, fmt.Errorf("committing transaction: %w", err)
 	}
@@ -472,7 +570,7 @@ func (db *PostgresStore) QueryEvents(whereClause string, args []interface{}, ord
 		`"desc", filename, inode, notes, format, extra, datetime, reportnotes, ` +
 		`inreport, tag, color, "offset", store_number, store_index, vss_store_number, ` +
 		`URL, record_number, event_identifier, event_type, source_name, user_sid, ` +
-		`computer_name, bookmark FROM log2timeline`
+		`computer_name, bookmark, batch_id FROM log2timeline`
 
 	if whereClause != "" {
 		query += " WHERE " + whereClause
@@ -587,43 +685,19 @@ func (db *PostgresStore) GetDistinctValues(fieldName string) (map[string]int64,
 	return result, nil
 }
 
-// GetDistinctTags returns all unique tags from the events table.
-// Tags can be comma-separated within a single field, so this splits them.
-func (db *PostgresStore) GetDistinctTags() ([]string, error) {
-	rows, err := db.conn.Query("SELECT DISTINCT tag FROM log2timeline")
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	seen := make(map[string]bool)
-	var tags []string
-
-	for rows.Next() {
-		var tagStr string
-		if err := rows.Scan(&tagStr); err != nil {
-			return nil, err
-		}
-		if tagStr == "" {
-			continue
-		}
-		for _, t := range strings.Split(tagStr, ",") {
-			t = strings.TrimSpace(t)
-			if t != "" && !seen[t] {
-				seen[t] = true
-				tags = append(tags, t)
-			}
-		}
-	}
-	return tags, rows.Err()
-}
-
 // UpdateEvent updates specific fields of an event identified by its id.
 // Uses pgQuoteCol to handle PostgreSQL reserved word columns in SET clauses.
 func (db *PostgresStore) UpdateEvent(rowid int64, fields map[string]interface{}) error {
 	if len(fields) == 0 {
 		return nil
 	}
+	changes := fields
+
+	// Tags are stored in event_tags; the tag column is rendered from them
+	fields, tags, setTags, err := takeTagField(fields)
+	if err != nil {
+		return err
+	}
 
 	setClauses := make([]string, 0, len(fields))
 	args := make([]interface{}, 0, len(fields)+1)
@@ -643,8 +717,21 @@ func (db *PostgresStore) UpdateEvent(rowid int64, fields map[string]interface{})
 	query := fmt.Sprintf("UPDATE log2timeline SET %s WHERE %s = %s",
 		strings.Join(setClauses, ", "), idCol, db.dialect.Placeholder(paramIdx))
 
-	_, err := db.conn.Exec(query, args...)
-	return err
+	return runAudited(db.conn, db.dialect, db.examiner, AuditUpdateEvent, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "log2timeline", fieldNames(changes), []int64{rowid}); err != nil {
+			return err
+		}
+		a.new = changes
+		if len(setClauses) > 0 {
+			if _, err := tx.Exec(query, args...); err != nil {
+				return err
+			}
+		}
+		if setTags {
+			return setEventTags(tx, db.dialect, rowid, tags)
+		}
+		return nil
+	})
 }
 
 // UpdateMetadata refreshes all metadata tables with current distinct values.
@@ -675,54 +762,11 @@ func (db *PostgresStore) UpdateMetadata() error {
 		}
 	}
 
-	// Update tags table
-	_, err = tx.Exec("DELETE FROM l2t_tags")
-	if err != nil {
-		return fmt.Errorf("clearing l2t_tags: %w", err)
-	}
-
-	// Get distinct tags (need to split comma-separated values in Go).
-	// Collect all results first and close the cursor before doing inserts,
-	// because PostgreSQL does not allow concurrent operations on a single connection.
-	rows, err := tx.Query("SELECT DISTINCT tag FROM log2timeline WHERE tag <> ''")
-	if err != nil {
-		return fmt.Errorf("querying tags: %w", err)
-	}
-
-	var rawTags []string
-	for rows.Next() {
-		var tagStr string
-		if err := rows.Scan(&tagStr); err != nil {
-			rows.Close()
-			return err
-		}
-		rawTags = append(rawTags, tagStr)
-	}
-	rows.Close()
-	if err := rows.Err(); err != nil {
+	// Tags in use, for releases that read l2t_tags
+	if err := populateTagMetadata(tx); err != nil {
 		return err
 	}
 
-	// Now that the cursor is closed, prepare and execute the inserts
-	seen := make(map[string]bool)
-	tagStmt, err := tx.Prepare("INSERT INTO l2t_tags (tag) VALUES (" + db.dialect.Placeholder(1) + ")")
-	if err != nil {
-		return fmt.Errorf("preparing tag insert: %w", err)
-	}
-	defer tagStmt.Close()
-
-	for _, tagStr := range rawTags {
-		for _, t := range strings.Split(tagStr, ",") {
-			t = strings.TrimSpace(t)
-			if t != "" && !seen[t] {
-				seen[t] = true
-				if _, err = tagStmt.Exec(t); err != nil {
-					return fmt.Errorf("inserting tag: %w", err)
-				}
-			}
-		}
-	}
-
 	return tx.Commit()
 }
 
@@ -753,43 +797,6 @@ func (db *PostgresStore) RebuildIndexes(indexFields []string) error {
 	return tx.Commit()
 }
 
-// GetSavedQueries returns all saved queries from the database.
-func (db *PostgresStore) GetSavedQueries() ([]SavedQuery, error) {
-	rows, err := db.conn.Query("SELECT name, query FROM l2t_saved_query")
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var queries []SavedQuery
-	for rows.Next() {
-		var q SavedQuery
-		if err := rows.Scan(&q.Name, &q.Query); err != nil {
-			return nil, err
-		}
-		queries = append(queries, q)
-	}
-	return queries, rows.Err()
-}
-
-// SaveQuery stores a named query in the database.
-func (db *PostgresStore) SaveQuery(name, query string) error {
-	_, err := db.conn.Exec(
-		"INSERT INTO l2t_saved_query (name, query) VALUES ("+db.dialect.Placeholder(1)+", "+db.dialect.Placeholder(2)+")",
-		name, query,
-	)
-	return err
-}
-
-// DeleteQuery removes a saved query by name.
-func (db *PostgresStore) DeleteQuery(name string) error {
-	_, err := db.conn.Exec(
-		"DELETE FROM l2t_saved_query WHERE name = "+db.dialect.Placeholder(1),
-		name,
-	)
-	return err
-}
-
 // ExecuteQuery runs a pre-built SQL SELECT and scans results using model.Fields
 // column order (Pattern B: id, datetime, timezone, MACB, ...).
 // Examiner notes are merged via UNION ALL with negated IDs.
@@ -903,7 +910,7 @@ func (db *PostgresStore) GetTimelineHistogram(whereClause string, whereArgs []in
 //	filename, inode, notes, format, extra, datetime, reportnotes,
 //	inreport, tag, color, offset, store_number, store_index,
 //	vss_store_number, URL, record_number, event_identifier, event_type,
-//	source_name, user_sid, computer_name, bookmark
+//	source_name, user_sid, computer_name, bookmark, batch_id
 func pgScanEvents(rows *sql.Rows) ([]*model.Event, error) {
 	var events []*model.Event
 	for rows.Next() {
@@ -916,7 +923,7 @@ func pgScanEvents(rows *sql.Rows) ([]*model.Event, error) {
 			offset, storeNumber, storeIndex, vssStoreNumber         sql.NullInt64
 			url, recordNumber, eventID, eventType                   sql.NullString
 			sourceName, userSID, computerName                       sql.NullString
-			bookmark                                                sql.NullInt64
+			bookmark, batchID                                       sql.NullInt64
 		)
 
 		err := rows.Scan(
@@ -926,7 +933,7 @@ func pgScanEvents(rows *sql.Rows) ([]*model.Event, error) {
 			&reportnotes, &inreport, &tag, &color, &offset,
 			&storeNumber, &storeIndex, &vssStoreNumber, &url,
 			&recordNumber, &eventID, &eventType, &sourceName,
-			&userSID, &computerName, &bookmark,
+			&userSID, &computerName, &bookmark, &batchID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning event row: %w", err)
@@ -964,6 +971,7 @@ func pgScanEvents(rows *sql.Rows) ([]*model.Event, error) {
 			UserSID:        userSID.String,
 			ComputerName:   computerName.String,
 			Bookmark:       bookmark.Int64,
+			BatchID:        batchID.Int64,
 		}
 		events = append(events, e)
 	}
@@ -979,7 +987,8 @@ func pgScanEvents(rows *sql.Rows) ([]*model.Event, error) {
 //	id, datetime, timezone, MACB, source, sourcetype, type, user, host, desc,
 //	filename, inode, notes, format, extra, reportnotes, inreport, tag, color,
 //	offset, store_number, store_index, vss_store_number, URL, record_number,
-//	event_identifier, event_type, source_name, user_sid, computer_name, bookmark
+//	event_identifier, event_type, source_name, user_sid, computer_name, bookmark,
+//	batch_id
 func pgScanFieldsOrderEvents(rows *sql.Rows) ([]*model.Event, error) {
 	var events []*model.Event
 	for rows.Next() {
@@ -992,7 +1001,7 @@ func pgScanFieldsOrderEvents(rows *sql.Rows) ([]*model.Event, error) {
 			offset, storeNumber, storeIndex, vssStoreNumber         sql.NullInt64
 			url, recordNumber, eventID, eventType                   sql.NullString
 			sourceName, userSID, computerName                       sql.NullString
-			bookmark                                                sql.NullInt64
+			bookmark, batchID                                       sql.NullInt64
 		)
 
 		err := rows.Scan(
@@ -1002,7 +1011,7 @@ func pgScanFieldsOrderEvents(rows *sql.Rows) ([]*model.Event, error) {
 			&inreport, &tag, &color, &offset, &storeNumber,
 			&storeIndex, &vssStoreNumber, &url, &recordNumber,
 			&eventID, &eventType, &sourceName, &userSID, &computerName,
-			&bookmark,
+			&bookmark, &batchID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning event row: %w", err)
@@ -1040,6 +1049,7 @@ func pgScanFieldsOrderEvents(rows *sql.Rows) ([]*model.Event, error) {
 			UserSID:        userSID.String,
 			ComputerName:   computerName.String,
 			Bookmark:       bookmark.Int64,
+			BatchID:        batchID.Int64,
 		}
 		events = append(events, e)
 	}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"modernc.org/sqlite"
+)
+
+// SQLite parses "X REGEXP Y" but leaves the matching to an application
+// function regexp(Y, X). Registering it here gives every SQLite connection the
+// query.Regexp operator.
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("regexp", 2, sqliteRegexp)
+}
+
+// regexpCache holds compiled patterns, since SQLite calls regexp once per
+// row with the same pattern. It is cleared when it grows past
+// regexpCacheSize.
+var (
+	regexpCacheMu sync.Mutex
+	regexpCache   = make(map[string]*regexp.Regexp)
+)
+
+const regexpCacheSize = 64
+
+// sqliteRegexp implements regexp(pattern, value). It returns NULL for a NULL
+// value, so NOT REGEXP does not match NULLs either.
+func sqliteRegexp(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	if args[0] == nil || args[1] == nil {
+		return nil, nil
+	}
+	re, err := compileRegexp(fmt.Sprint(args[0]))
+	if err != nil {
+		return nil, err
+	}
+	var value string
+	switch v := args[1].(type) {
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		value = fmt.Sprint(v)
+	}
+	return re.MatchString(value), nil
+}
+
+func compileRegexp(pattern string) (*regexp.Regexp, error) {
+	regexpCacheMu.Lock()
+	defer regexpCacheMu.Unlock()
+	if re, ok := regexpCache[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+	if len(regexpCache) >= regexpCacheSize {
+		regexpCache = make(map[string]*regexp.Regexp)
+	}
+	regexpCache[pattern] = re
+	return re, nil
+}
@@ -0,0 +1,202 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SavedQuery is a named query stored in l2t_saved_query. Query is either a
+// WHERE clause, as in 4n6time's legacy query packs, or the JSON filter state
+// the frontend saves. The remaining fields describe the query for sharing in
+// query packs; EventIDs and OS are free text such as "4624,4625" and
+// "Windows". A WHERE clause may use named parameters (":username"), which
+// Parameters declares.
+type SavedQuery struct {
+	Name        string           `json:"name"`
+	Query       string           `json:"query"`
+	Description string           `json:"description"`
+	Category    string           `json:"category"`
+	Author      string           `json:"author"`
+	OS          string           `json:"os"`
+	EventIDs    string           `json:"eventIds"`
+	Parameters  []QueryParameter `json:"parameters"`
+}
+
+// Saved query parameter types.
+const (
+	ParamString   = "string"
+	ParamInteger  = "integer"
+	ParamDatetime = "datetime"
+)
+
+// QueryParameter declares a named parameter of a saved query: its type, the
+// value used when none is given and a label to prompt with. A parameter
+// without a declaration is a string with no default.
+type QueryParameter struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Default string `json:"default"`
+	Label   string `json:"label"`
+}
+
+// savedQueryColumns lists the l2t_saved_query columns in SavedQuery order.
+var savedQueryColumns = []string{"name", "query", "description", "category", "author", "os", "event_ids", "parameters"}
+
+// savedQueryMetadataColumns lists the descriptive columns added to
+// l2t_saved_query after 0.10.x, which only had name and query.
+var savedQueryMetadataColumns = []string{"description", "category", "author", "os", "event_ids"}
+
+// The functions below implement the saved query methods of Store for every
+// backend.
+
+func getSavedQueries(conn *sql.DB) ([]SavedQuery, error) {
+	rows, err := conn.Query("SELECT " + strings.Join(savedQueryColumns, ", ") + " FROM l2t_saved_query")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var queries []SavedQuery
+	for rows.Next() {
+		var cols [8]sql.NullString
+		dest := make([]interface{}, len(cols))
+		for i := range cols {
+			dest[i] = &cols[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		q := SavedQuery{
+			Name:        cols[0].String,
+			Query:       cols[1].String,
+			Description: cols[2].String,
+			Category:    cols[3].String,
+			Author:      cols[4].String,
+			OS:          cols[5].String,
+			EventIDs:    cols[6].String,
+		}
+		if cols[7].String != "" {
+			if err := json.Unmarshal([]byte(cols[7].String), &q.Parameters); err != nil {
+				return nil, fmt.Errorf("decoding parameters of saved query %q: %w", q.Name, err)
+			}
+		}
+		queries = append(queries, q)
+	}
+	return queries, rows.Err()
+}
+
+// insertSavedQuery adds q without checking for an existing query of the
+// same name.
+func insertSavedQuery(conn schemaExecer, d Dialect, q SavedQuery) error {
+	var params string
+	if len(q.Parameters) > 0 {
+		data, err := json.Marshal(q.Parameters)
+		if err != nil {
+			return fmt.Errorf("encoding parameters: %w", err)
+		}
+		params = string(data)
+	}
+	_, err := conn.Exec(
+		"INSERT INTO l2t_saved_query ("+strings.Join(savedQueryColumns, ", ")+") VALUES ("+placeholders(d, len(savedQueryColumns))+")",
+		q.Name, q.Query, q.Description, q.Category, q.Author, q.OS, q.EventIDs, params,
+	)
+	return err
+}
+
+func saveQuery(conn *sql.DB, d Dialect, examiner, name, query string) error {
+	return insertSavedQuery(conn, d, SavedQuery{Name: name, Query: query, Author: examiner})
+}
+
+// upsertSavedQuery replaces every saved query named q.Name with q, or adds
+// it if there is none.
+func upsertSavedQuery(conn *sql.DB, d Dialect, q SavedQuery) error {
+	q.Name = strings.TrimSpace(q.Name)
+	if q.Name == "" {
+		return fmt.Errorf("saved query needs a name")
+	}
+	for i := range q.Parameters {
+		p := &q.Parameters[i]
+		p.Name = strings.TrimPrefix(strings.TrimSpace(p.Name), ":")
+		switch p.Type {
+		case "":
+			p.Type = ParamString
+		case ParamString, ParamInteger, ParamDatetime:
+		default:
+			return fmt.Errorf("parameter :%s has unknown type %q", p.Name, p.Type)
+		}
+	}
+	tx, err := conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("DELETE FROM l2t_saved_query WHERE name = "+d.Placeholder(1), q.Name); err != nil {
+		return fmt.Errorf("replacing saved query %q: %w", q.Name, err)
+	}
+	if err := insertSavedQuery(tx, d, q); err != nil {
+		return fmt.Errorf("saving query %q: %w", q.Name, err)
+	}
+	return tx.Commit()
+}
+
+func deleteQuery(conn *sql.DB, d Dialect, name string) error {
+	_, err := conn.Exec("DELETE FROM l2t_saved_query WHERE name = "+d.Placeholder(1), name)
+	return err
+}
+
+// -- Store methods --
+
+// GetSavedQueries returns all saved queries from the database.
+func (db *SQLiteStore) GetSavedQueries() ([]SavedQuery, error) { return getSavedQueries(db.conn) }
+
+// SaveQuery stores a named query in the database, authored by the current
+// examiner.
+func (db *SQLiteStore) SaveQuery(name, query string) error {
+	return saveQuery(db.conn, db.dialect, db.examiner, name, query)
+}
+
+// UpsertSavedQuery replaces the saved query named q.Name with q, or adds it.
+func (db *SQLiteStore) UpsertSavedQuery(q SavedQuery) error {
+	return upsertSavedQuery(db.conn, db.dialect, q)
+}
+
+// DeleteQuery removes a saved query by name.
+func (db *SQLiteStore) DeleteQuery(name string) error { return deleteQuery(db.conn, db.dialect, name) }
+
+// GetSavedQueries returns all saved queries from the database.
+func (db *PostgresStore) GetSavedQueries() ([]SavedQuery, error) { return getSavedQueries(db.conn) }
+
+// SaveQuery stores a named query in the database, authored by the current
+// examiner.
+func (db *PostgresStore) SaveQuery(name, query string) error {
+	return saveQuery(db.conn, db.dialect, db.examiner, name, query)
+}
+
+// UpsertSavedQuery replaces the saved query named q.Name with q, or adds it.
+func (db *PostgresStore) UpsertSavedQuery(q SavedQuery) error {
+	return upsertSavedQuery(db.conn, db.dialect, q)
+}
+
+// DeleteQuery removes a saved query by name.
+func (db *PostgresStore) DeleteQuery(name string) error {
+	return deleteQuery(db.conn, db.dialect, name)
+}
+
+// GetSavedQueries returns all saved queries from the database.
+func (db *MySQLStore) GetSavedQueries() ([]SavedQuery, error) { return getSavedQueries(db.conn) }
+
+// SaveQuery stores a named query in the database, authored by the current
+// examiner.
+func (db *MySQLStore) SaveQuery(name, query string) error {
+	return saveQuery(db.conn, db.dialect, db.examiner, name, query)
+}
+
+// UpsertSavedQuery replaces the saved query named q.Name with q, or adds it.
+func (db *MySQLStore) UpsertSavedQuery(q SavedQuery) error {
+	return upsertSavedQuery(db.conn, db.dialect, q)
+}
+
+// DeleteQuery removes a saved query by name.
+func (db *MySQLStore) DeleteQuery(name string) error { return deleteQuery(db.conn, db.dialect, name) }
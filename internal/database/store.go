@@ -8,6 +8,14 @@ type TimelineBucket struct {
 	Count     int64  `json:"count"`
 }
 
+// ProvenanceEntry is a single row of the l2t_provenance table.
+type ProvenanceEntry struct {
+	ID        int64  `json:"id"`
+	Action    string `json:"action"`
+	Detail    string `json:"detail"`
+	CreatedAt string `json:"createdAt"`
+}
+
 // Store defines the interface for all database operations.
 // Every method that the application needs is captured here so that
 // app.go depends on the interface, not on a concrete database type.
@@ -16,6 +24,7 @@ type Store interface {
 	InsertEvent(e *model.Event) error
 	InsertEvents(events []*model.Event, onProgress func(int)) (int, error)
 	QueryEvents(where string, args []interface{}, orderBy string, limit, offset int) ([]*model.Event, error)
+	EachEvent(batchSize int, fn func([]*model.Event) error) error
 	CountEvents(where string, args []interface{}) (int64, error)
 	UpdateEvent(id int64, fields map[string]interface{}) error
 	ToggleBookmark(id int64) (int64, error)
@@ -30,10 +39,13 @@ type Store interface {
 	GetDistinctTags() ([]string, error)
 	GetMinMaxDate() (string, string, error)
 	GetTimelineHistogram(whereClause string, whereArgs []interface{}) ([]TimelineBucket, error)
+	CountByField(field, where string, args []interface{}) (map[string]int64, error)
+	EachMatchingID(where string, args []interface{}, batchSize int, fn func([]int64) error) error
 
-	// Saved queries
+	// Saved queries (see savedqueries.go)
 	GetSavedQueries() ([]SavedQuery, error)
 	SaveQuery(name, query string) error
+	UpsertSavedQuery(q SavedQuery) error
 	DeleteQuery(name string) error
 
 	// Examiner notes
@@ -46,15 +58,65 @@ type Store interface {
 	// Bulk operations
 	BulkUpdateColor(ids []int64, color string) error
 	BulkAddTag(ids []int64, tag string) error
+	BulkRemoveTag(ids []int64, tag string) error
 	BulkSetBookmark(ids []int64, bookmark int64) error
 	BulkUpdateExaminerNoteColor(ids []int64, color string) error
 	BulkSetExaminerNoteBookmark(ids []int64, bookmark int64) error
 
+	// Full-text search. SearchSnippets returns ranked, highlighted snippets
+	// for the given event IDs; backends without a full-text index return nil.
+	SearchSnippets(text string, ids []int64) ([]SearchMatch, error)
+
+	// Provenance
+	RecordProvenance(action, detail string) error
+	GetProvenance() ([]ProvenanceEntry, error)
+
+	// Case, evidence items and import batches (see case.go)
+	GetCaseInfo() (*CaseInfo, error)
+	SaveCaseInfo(c *CaseInfo) error
+	GetEvidenceItems() ([]EvidenceItem, error)
+	InsertEvidenceItem(item *EvidenceItem) (int64, error)
+	UpdateEvidenceItem(item *EvidenceItem) error
+	DeleteEvidenceItem(id int64) error
+	GetImportBatches() ([]ImportBatch, error)
+	InsertImportBatch(b *ImportBatch) (int64, error)
+	SetImportBatchEvidence(batchID, evidenceID int64) error
+
+	// Tags (see tags.go). RenameTag and MergeTags apply to the whole
+	// database when ids is empty and only to the listed events otherwise.
+	GetTags() ([]Tag, error)
+	SaveTag(t *Tag) error
+	RenameTag(ids []int64, oldName, newName string) error
+	MergeTags(ids []int64, sources []string, target string) error
+	DeleteTag(name string) error
+
+	// Color rules (see colorrules.go). ApplyColorRules runs matches in order,
+	// so later matches win where they overlap.
+	GetColorRules() ([]ColorRule, error)
+	SaveColorRule(r *ColorRule) error
+	DeleteColorRule(id int64) error
+	ApplyColorRules(matches []ColorRuleMatch) (int64, error)
+
+	// Audit log (see audit.go). Every annotation change above is recorded
+	// under the examiner last passed to SetExaminer.
+	SetExaminer(name string)
+	GetAuditLog(f AuditFilter) ([]AuditEntry, error)
+
+	// Undo and redo (see undo.go), replayed from the audit log
+	Undo() (*AuditEntry, error)
+	Redo() (*AuditEntry, error)
+	GetUndoState() (*UndoState, error)
+
 	// Schema and maintenance
 	UpdateMetadata() error
 	RebuildIndexes(fields []string) error
 	Migrate() error
 
+	// Query plans and indexes (see explain.go)
+	ExplainQuery(query string, args []interface{}) (*QueryPlan, error)
+	ListIndexes() ([]IndexInfo, error)
+	CreateIndex(columns []string) (string, error)
+
 	// Lifecycle
 	Close() error
 	Path() string
@@ -0,0 +1,686 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/cdtdelta/4n6time/internal/model"
+)
+
+// Tag is a label applied to events through the event_tags table. Names are
+// unique ignoring case. EventCount is computed when tags are listed.
+//
+// log2timeline.tag is kept as a comma-separated rendering of an event's tags,
+// sorted by name, so filters, exports and older releases that read the column
+// keep working. Writes to the column through InsertEvent(s) and UpdateEvent
+// are parsed back into event_tags.
+type Tag struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+	EventCount  int64  `json:"eventCount"`
+}
+
+// tagChunkSize bounds how many event IDs are bound into a single IN list.
+const tagChunkSize = 500
+
+// validateTagName trims name and rejects names that cannot round-trip
+// through the comma-separated tag column.
+func validateTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("tag name is required")
+	}
+	if strings.Contains(name, ",") {
+		return "", fmt.Errorf("tag name %q must not contain a comma", name)
+	}
+	return name, nil
+}
+
+// splitTags parses a comma-separated tag list, dropping blanks and
+// duplicates that differ only in case.
+func splitTags(s string) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[strings.ToLower(t)] {
+			continue
+		}
+		seen[strings.ToLower(t)] = true
+		tags = append(tags, t)
+	}
+	return tags
+}
+
+// chunkIDs splits ids into slices of at most tagChunkSize.
+func chunkIDs(ids []int64) [][]int64 {
+	var chunks [][]int64
+	for len(ids) > tagChunkSize {
+		chunks = append(chunks, ids[:tagChunkSize])
+		ids = ids[tagChunkSize:]
+	}
+	if len(ids) > 0 {
+		chunks = append(chunks, ids)
+	}
+	return chunks
+}
+
+// idArgs converts ids to query arguments.
+func idArgs(ids []int64) []interface{} {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
+
+// findTag returns the ID of the tag named name ignoring case, or 0 if there
+// is none.
+func findTag(tx schemaExecer, d Dialect, name string) (int64, error) {
+	var id int64
+	err := tx.QueryRow("SELECT id FROM tags WHERE LOWER(name) = LOWER("+d.Placeholder(1)+")", name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// ensureTag returns the ID of the tag named name, creating it if needed.
+func ensureTag(tx schemaExecer, d Dialect, name string) (int64, error) {
+	id, err := findTag(tx, d, name)
+	if err != nil || id != 0 {
+		return id, err
+	}
+	id, err = insertReturningID(tx, d,
+		"INSERT INTO tags (name, color, description) VALUES ("+placeholders(d, 3)+")", name, "", "")
+	if err != nil {
+		return 0, fmt.Errorf("creating tag %q: %w", name, err)
+	}
+	return id, nil
+}
+
+// linkEvents tags the given events with tagID, skipping events already
+// tagged and IDs that match no event.
+func linkEvents(tx schemaExecer, d Dialect, ids []int64, tagID int64) error {
+	idCol := d.IDColumn()
+	for _, chunk := range chunkIDs(ids) {
+		_, err := tx.Exec(fmt.Sprintf(
+			"INSERT INTO event_tags (event_id, tag_id) SELECT %[1]s, %[2]d FROM log2timeline WHERE %[1]s IN (%[3]s) "+
+				"AND NOT EXISTS (SELECT 1 FROM event_tags x WHERE x.event_id = log2timeline.%[1]s AND x.tag_id = %[2]d)",
+			idCol, tagID, placeholders(d, len(chunk))), idArgs(chunk)...)
+		if err != nil {
+			return fmt.Errorf("tagging events: %w", err)
+		}
+	}
+	return nil
+}
+
+// moveEventTags retags events from one tag to another. With no ids every
+// event tagged from is moved; otherwise only the listed events are.
+func moveEventTags(tx schemaExecer, d Dialect, from, to int64, ids []int64) error {
+	scopes, scopeArgs := []string{""}, [][]interface{}{nil}
+	if len(ids) > 0 {
+		scopes, scopeArgs = nil, nil
+		for _, chunk := range chunkIDs(ids) {
+			scopes = append(scopes, " AND event_id IN ("+placeholders(d, len(chunk))+")")
+			scopeArgs = append(scopeArgs, idArgs(chunk))
+		}
+	}
+	for i, scope := range scopes {
+		_, err := tx.Exec(fmt.Sprintf(
+			"INSERT INTO event_tags (event_id, tag_id) SELECT s.event_id, %[2]d FROM event_tags s WHERE s.tag_id = %[1]d%[3]s "+
+				"AND NOT EXISTS (SELECT 1 FROM event_tags x WHERE x.event_id = s.event_id AND x.tag_id = %[2]d)",
+			from, to, strings.Replace(scope, "event_id", "s.event_id", 1)), scopeArgs[i]...)
+		if err != nil {
+			return fmt.Errorf("retagging events: %w", err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM event_tags WHERE tag_id = %d%s", from, scope), scopeArgs[i]...); err != nil {
+			return fmt.Errorf("retagging events: %w", err)
+		}
+	}
+	return nil
+}
+
+// taggedEventIDs returns the IDs of the events tagged with tagID.
+func taggedEventIDs(tx schemaExecer, tagID int64) ([]int64, error) {
+	rows, err := tx.Query(fmt.Sprintf("SELECT event_id FROM event_tags WHERE tag_id = %d", tagID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// refreshTagColumn re-renders log2timeline.tag for the given events from
+// event_tags.
+func refreshTagColumn(tx schemaExecer, d Dialect, ids []int64) error {
+	idCol := d.IDColumn()
+	for _, chunk := range chunkIDs(ids) {
+		_, err := tx.Exec(fmt.Sprintf("UPDATE log2timeline SET tag = %s WHERE %s IN (%s)",
+			d.TagListSQL("log2timeline."+idCol), idCol, placeholders(d, len(chunk))), idArgs(chunk)...)
+		if err != nil {
+			return fmt.Errorf("updating tag column: %w", err)
+		}
+	}
+	return nil
+}
+
+// refreshTaggedWith re-renders log2timeline.tag for every event tagged with
+// tagID.
+func refreshTaggedWith(tx schemaExecer, d Dialect, tagID int64) error {
+	idCol := d.IDColumn()
+	_, err := tx.Exec(fmt.Sprintf("UPDATE log2timeline SET tag = %s WHERE %s IN (SELECT event_id FROM event_tags WHERE tag_id = %d)",
+		d.TagListSQL("log2timeline."+idCol), idCol, tagID))
+	if err != nil {
+		return fmt.Errorf("updating tag column: %w", err)
+	}
+	return nil
+}
+
+// setEventTags replaces the tags of one event with those in the
+// comma-separated list.
+func setEventTags(tx schemaExecer, d Dialect, id int64, list string) error {
+	if _, err := tx.Exec("DELETE FROM event_tags WHERE event_id = "+d.Placeholder(1), id); err != nil {
+		return fmt.Errorf("clearing tags for event %d: %w", id, err)
+	}
+	for _, name := range splitTags(list) {
+		tagID, err := ensureTag(tx, d, name)
+		if err != nil {
+			return err
+		}
+		if err := linkEvents(tx, d, []int64{id}, tagID); err != nil {
+			return err
+		}
+	}
+	return refreshTagColumn(tx, d, []int64{id})
+}
+
+// ingestEventTags links events whose tag column was written directly, by an
+// insert or a release before event_tags existed, to their tags. Only events
+// with an id above after are read, so an insert passes the lastEventID from
+// before it and the migration passes 0 for every event. Events that already
+// have event_tags rows are left alone.
+func ingestEventTags(tx schemaExecer, d Dialect, after int64) error {
+	idCol := d.IDColumn()
+	rows, err := tx.Query(fmt.Sprintf(
+		"SELECT %[1]s, tag FROM log2timeline WHERE %[1]s > %[2]s AND tag <> '' AND NOT EXISTS (SELECT 1 FROM event_tags WHERE event_id = log2timeline.%[1]s)",
+		idCol, d.Placeholder(1)), after)
+	if err != nil {
+		return fmt.Errorf("reading event tags: %w", err)
+	}
+
+	// Collect everything before writing; PostgreSQL and MySQL cannot run a
+	// statement while a result set is open on the same connection.
+	var names []string
+	byName := make(map[string][]int64)
+	var all []int64
+	for rows.Next() {
+		var id int64
+		var list string
+		if err := rows.Scan(&id, &list); err != nil {
+			rows.Close()
+			return err
+		}
+		all = append(all, id)
+		for _, name := range splitTags(list) {
+			key := strings.ToLower(name)
+			if _, ok := byName[key]; !ok {
+				names = append(names, name)
+			}
+			byName[key] = append(byName[key], id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		tagID, err := ensureTag(tx, d, name)
+		if err != nil {
+			return err
+		}
+		if err := linkEvents(tx, d, byName[strings.ToLower(name)], tagID); err != nil {
+			return err
+		}
+	}
+	// Normalize spacing, order and case to the tags' canonical names
+	return refreshTagColumn(tx, d, all)
+}
+
+// lastEventID returns the highest event id, or 0 for an empty table. Events
+// inserted afterwards get higher ids.
+func lastEventID(tx schemaExecer, d Dialect) (int64, error) {
+	var id int64
+	if err := tx.QueryRow("SELECT COALESCE(MAX(" + d.IDColumn() + "), 0) FROM log2timeline").Scan(&id); err != nil {
+		return 0, fmt.Errorf("reading last event id: %w", err)
+	}
+	return id, nil
+}
+
+// hasTags reports whether any of events has a tag to ingest.
+func hasTags(events []*model.Event) bool {
+	for _, e := range events {
+		if len(splitTags(e.Tag)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// takeTagField removes "tag" from an UpdateEvent field map. The tag column is
+// derived from event_tags, so a new value is applied with setEventTags.
+func takeTagField(fields map[string]interface{}) (map[string]interface{}, string, bool, error) {
+	value, ok := fields["tag"]
+	if !ok {
+		return fields, "", false, nil
+	}
+	list, isString := value.(string)
+	if !isString {
+		return nil, "", false, fmt.Errorf("tag must be a string, got %T", value)
+	}
+	rest := make(map[string]interface{}, len(fields)-1)
+	for k, v := range fields {
+		if k != "tag" {
+			rest[k] = v
+		}
+	}
+	return rest, list, true, nil
+}
+
+// The functions below implement the tag methods of Store for every backend;
+// each store delegates to them with its connection and dialect.
+
+func getTags(conn *sql.DB) ([]Tag, error) {
+	rows, err := conn.Query(
+		"SELECT t.id, t.name, COALESCE(t.color, ''), COALESCE(t.description, ''), COUNT(et.event_id) " +
+			"FROM tags t LEFT JOIN event_tags et ON et.tag_id = t.id " +
+			"GROUP BY t.id, t.name, t.color, t.description ORDER BY t.name")
+	if err != nil {
+		return nil, fmt.Errorf("querying tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var t Tag
+		if err := rows.Scan(&t.ID, &t.Name, &t.Color, &t.Description, &t.EventCount); err != nil {
+			return nil, fmt.Errorf("scanning tag: %w", err)
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+func getDistinctTags(conn *sql.DB) ([]string, error) {
+	rows, err := conn.Query("SELECT name FROM tags WHERE EXISTS (SELECT 1 FROM event_tags WHERE tag_id = tags.id) ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tags = append(tags, name)
+	}
+	return tags, rows.Err()
+}
+
+func saveTag(conn *sql.DB, d Dialect, examiner string, t *Tag) error {
+	name, err := validateTagName(t.Name)
+	if err != nil {
+		return err
+	}
+	var id int64
+	err = runAudited(conn, d, examiner, AuditSaveTag, func(tx *sql.Tx, a *auditChange) error {
+		existing, err := findTag(tx, d, name)
+		if err != nil {
+			return err
+		}
+		if existing != 0 {
+			if err := a.snapshot(tx, "tags", []string{"color", "description"}, []int64{existing}); err != nil {
+				return err
+			}
+		}
+		if id, err = ensureTag(tx, d, name); err != nil {
+			return err
+		}
+		if existing == 0 {
+			a.touch("tags", []int64{id})
+		}
+		a.new = map[string]interface{}{"name": name, "color": t.Color, "description": t.Description}
+		_, err = tx.Exec("UPDATE tags SET color = "+d.Placeholder(1)+", description = "+d.Placeholder(2)+" WHERE id = "+d.Placeholder(3),
+			t.Color, t.Description, id)
+		if err != nil {
+			return fmt.Errorf("updating tag %q: %w", name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	t.ID = id
+	return nil
+}
+
+// affectedEvents returns ids when given, otherwise every event tagged with
+// one of tagIDs: the events a tag operation rewrites.
+func affectedEvents(tx schemaExecer, ids []int64, tagIDs []int64) ([]int64, error) {
+	if len(ids) > 0 {
+		return ids, nil
+	}
+	seen := make(map[int64]bool)
+	var all []int64
+	for _, tagID := range tagIDs {
+		tagged, err := taggedEventIDs(tx, tagID)
+		if err != nil {
+			return nil, fmt.Errorf("reading tagged events: %w", err)
+		}
+		for _, id := range tagged {
+			if !seen[id] {
+				seen[id] = true
+				all = append(all, id)
+			}
+		}
+	}
+	return all, nil
+}
+
+func bulkAddTag(conn *sql.DB, d Dialect, examiner string, ids []int64, name string) error {
+	if len(ids) == 0 || strings.TrimSpace(name) == "" {
+		return nil
+	}
+	name, err := validateTagName(name)
+	if err != nil {
+		return err
+	}
+	return runAudited(conn, d, examiner, AuditAddTag, func(tx *sql.Tx, a *auditChange) error {
+		if err := a.snapshot(tx, "log2timeline", []string{"tag"}, ids); err != nil {
+			return err
+		}
+		a.new = map[string]interface{}{"tag": name}
+		tagID, err := ensureTag(tx, d, name)
+		if err != nil {
+			return err
+		}
+		if err := linkEvents(tx, d, ids, tagID); err != nil {
+			return err
+		}
+		return refreshTagColumn(tx, d, ids)
+	})
+}
+
+func bulkRemoveTag(conn *sql.DB, d Dialect, examiner string, ids []int64, name string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return runAudited(conn, d, examiner, AuditRemoveTag, func(tx *sql.Tx, a *auditChange) error {
+		tagID, err := findTag(tx, d, strings.TrimSpace(name))
+		if err != nil || tagID == 0 {
+			return err
+		}
+		if err := a.snapshot(tx, "log2timeline", []string{"tag"}, ids); err != nil {
+			return err
+		}
+		a.new = map[string]interface{}{"tag": strings.TrimSpace(name)}
+		for _, chunk := range chunkIDs(ids) {
+			_, err := tx.Exec(fmt.Sprintf("DELETE FROM event_tags WHERE tag_id = %d AND event_id IN (%s)",
+				tagID, placeholders(d, len(chunk))), idArgs(chunk)...)
+			if err != nil {
+				return fmt.Errorf("untagging events: %w", err)
+			}
+		}
+		return refreshTagColumn(tx, d, ids)
+	})
+}
+
+// mergeTagIDs moves events from each source tag onto target, creating it if
+// needed. With no ids the whole database is retagged and the source tags are
+// deleted; otherwise only the listed events change.
+func mergeTagIDs(tx *sql.Tx, d Dialect, ids []int64, sources []int64, target string) error {
+	to, err := ensureTag(tx, d, target)
+	if err != nil {
+		return err
+	}
+	for _, from := range sources {
+		if from == to {
+			continue
+		}
+		if err := moveEventTags(tx, d, from, to, ids); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			if _, err := tx.Exec(fmt.Sprintf("DELETE FROM tags WHERE id = %d", from)); err != nil {
+				return fmt.Errorf("deleting merged tag: %w", err)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return refreshTaggedWith(tx, d, to)
+	}
+	return refreshTagColumn(tx, d, ids)
+}
+
+func renameTag(conn *sql.DB, d Dialect, examiner string, ids []int64, oldName, newName string) error {
+	newName, err := validateTagName(newName)
+	if err != nil {
+		return err
+	}
+	return runAudited(conn, d, examiner, AuditRenameTag, func(tx *sql.Tx, a *auditChange) error {
+		from, err := findTag(tx, d, strings.TrimSpace(oldName))
+		if err != nil {
+			return err
+		}
+		if from == 0 {
+			return fmt.Errorf("tag %q not found", oldName)
+		}
+		affected, err := affectedEvents(tx, ids, []int64{from})
+		if err != nil {
+			return err
+		}
+		if err := a.snapshot(tx, "log2timeline", []string{"tag"}, affected); err != nil {
+			return err
+		}
+		a.new = map[string]interface{}{"from": strings.TrimSpace(oldName), "to": newName}
+
+		if len(ids) == 0 {
+			to, err := findTag(tx, d, newName)
+			if err != nil {
+				return err
+			}
+			// Renaming to an unused name, or changing only case, keeps the
+			// tag's color and description; renaming onto another tag merges.
+			if to == 0 || to == from {
+				if _, err := tx.Exec("UPDATE tags SET name = "+d.Placeholder(1)+" WHERE id = "+d.Placeholder(2), newName, from); err != nil {
+					return fmt.Errorf("renaming tag: %w", err)
+				}
+				return refreshTaggedWith(tx, d, from)
+			}
+		}
+		return mergeTagIDs(tx, d, ids, []int64{from}, newName)
+	})
+}
+
+func mergeTags(conn *sql.DB, d Dialect, examiner string, ids []int64, sources []string, target string) error {
+	target, err := validateTagName(target)
+	if err != nil {
+		return err
+	}
+	return runAudited(conn, d, examiner, AuditMergeTags, func(tx *sql.Tx, a *auditChange) error {
+		var from []int64
+		for _, name := range sources {
+			id, err := findTag(tx, d, strings.TrimSpace(name))
+			if err != nil {
+				return err
+			}
+			if id != 0 {
+				from = append(from, id)
+			}
+		}
+		affected, err := affectedEvents(tx, ids, from)
+		if err != nil {
+			return err
+		}
+		if err := a.snapshot(tx, "log2timeline", []string{"tag"}, affected); err != nil {
+			return err
+		}
+		a.new = map[string]interface{}{"sources": sources, "target": target}
+		return mergeTagIDs(tx, d, ids, from, target)
+	})
+}
+
+func deleteTag(conn *sql.DB, d Dialect, examiner, name string) error {
+	return runAudited(conn, d, examiner, AuditDeleteTag, func(tx *sql.Tx, a *auditChange) error {
+		tagID, err := findTag(tx, d, strings.TrimSpace(name))
+		if err != nil || tagID == 0 {
+			return err
+		}
+		ids, err := affectedEvents(tx, nil, []int64{tagID})
+		if err != nil {
+			return err
+		}
+		if err := a.snapshot(tx, "log2timeline", []string{"tag"}, ids); err != nil {
+			return err
+		}
+		a.new = map[string]interface{}{"tag": strings.TrimSpace(name)}
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM event_tags WHERE tag_id = %d", tagID)); err != nil {
+			return fmt.Errorf("untagging events: %w", err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM tags WHERE id = %d", tagID)); err != nil {
+			return fmt.Errorf("deleting tag: %w", err)
+		}
+		return refreshTagColumn(tx, d, ids)
+	})
+}
+
+// populateTagMetadata rebuilds l2t_tags, the tag list older releases read,
+// from the tags in use.
+func populateTagMetadata(tx schemaExecer) error {
+	if _, err := tx.Exec("DELETE FROM l2t_tags"); err != nil {
+		return fmt.Errorf("clearing l2t_tags: %w", err)
+	}
+	_, err := tx.Exec("INSERT INTO l2t_tags (tag) SELECT name FROM tags WHERE EXISTS (SELECT 1 FROM event_tags WHERE tag_id = tags.id)")
+	if err != nil {
+		return fmt.Errorf("populating l2t_tags: %w", err)
+	}
+	return nil
+}
+
+// -- Store methods --
+
+// GetTags returns every tag with the number of events it is applied to.
+func (db *SQLiteStore) GetTags() ([]Tag, error) { return getTags(db.conn) }
+
+// GetDistinctTags returns the names of tags applied to at least one event.
+func (db *SQLiteStore) GetDistinctTags() ([]string, error) { return getDistinctTags(db.conn) }
+
+// SaveTag creates the tag named t.Name, or updates its color and description.
+func (db *SQLiteStore) SaveTag(t *Tag) error { return saveTag(db.conn, db.dialect, db.examiner, t) }
+
+// BulkAddTag tags multiple log2timeline events, creating the tag if needed.
+func (db *SQLiteStore) BulkAddTag(ids []int64, tag string) error {
+	return bulkAddTag(db.conn, db.dialect, db.examiner, ids, tag)
+}
+
+// BulkRemoveTag removes a tag from multiple log2timeline events.
+func (db *SQLiteStore) BulkRemoveTag(ids []int64, tag string) error {
+	return bulkRemoveTag(db.conn, db.dialect, db.examiner, ids, tag)
+}
+
+// RenameTag renames a tag, or retags only the events in ids when given.
+func (db *SQLiteStore) RenameTag(ids []int64, oldName, newName string) error {
+	return renameTag(db.conn, db.dialect, db.examiner, ids, oldName, newName)
+}
+
+// MergeTags folds the source tags into target, or only on the events in ids when given.
+func (db *SQLiteStore) MergeTags(ids []int64, sources []string, target string) error {
+	return mergeTags(db.conn, db.dialect, db.examiner, ids, sources, target)
+}
+
+// DeleteTag removes a tag from every event and deletes it.
+func (db *SQLiteStore) DeleteTag(name string) error {
+	return deleteTag(db.conn, db.dialect, db.examiner, name)
+}
+
+// GetTags returns every tag with the number of events it is applied to.
+func (db *PostgresStore) GetTags() ([]Tag, error) { return getTags(db.conn) }
+
+// GetDistinctTags returns the names of tags applied to at least one event.
+func (db *PostgresStore) GetDistinctTags() ([]string, error) { return getDistinctTags(db.conn) }
+
+// SaveTag creates the tag named t.Name, or updates its color and description.
+func (db *PostgresStore) SaveTag(t *Tag) error { return saveTag(db.conn, db.dialect, db.examiner, t) }
+
+// BulkAddTag tags multiple log2timeline events, creating the tag if needed.
+func (db *PostgresStore) BulkAddTag(ids []int64, tag string) error {
+	return bulkAddTag(db.conn, db.dialect, db.examiner, ids, tag)
+}
+
+// BulkRemoveTag removes a tag from multiple log2timeline events.
+func (db *PostgresStore) BulkRemoveTag(ids []int64, tag string) error {
+	return bulkRemoveTag(db.conn, db.dialect, db.examiner, ids, tag)
+}
+
+// RenameTag renames a tag, or retags only the events in ids when given.
+func (db *PostgresStore) RenameTag(ids []int64, oldName, newName string) error {
+	return renameTag(db.conn, db.dialect, db.examiner, ids, oldName, newName)
+}
+
+// MergeTags folds the source tags into target, or only on the events in ids when given.
+func (db *PostgresStore) MergeTags(ids []int64, sources []string, target string) error {
+	return mergeTags(db.conn, db.dialect, db.examiner, ids, sources, target)
+}
+
+// DeleteTag removes a tag from every event and deletes it.
+func (db *PostgresStore) DeleteTag(name string) error {
+	return deleteTag(db.conn, db.dialect, db.examiner, name)
+}
+
+// GetTags returns every tag with the number of events it is applied to.
+func (db *MySQLStore) GetTags() ([]Tag, error) { return getTags(db.conn) }
+
+// GetDistinctTags returns the names of tags applied to at least one event.
+func (db *MySQLStore) GetDistinctTags() ([]string, error) { return getDistinctTags(db.conn) }
+
+// SaveTag creates the tag named t.Name, or updates its color and description.
+func (db *MySQLStore) SaveTag(t *Tag) error { return saveTag(db.conn, db.dialect, db.examiner, t) }
+
+// BulkAddTag tags multiple log2timeline events, creating the tag if needed.
+func (db *MySQLStore) BulkAddTag(ids []int64, tag string) error {
+	return bulkAddTag(db.conn, db.dialect, db.examiner, ids, tag)
+}
+
+// BulkRemoveTag removes a tag from multiple log2timeline events.
+func (db *MySQLStore) BulkRemoveTag(ids []int64, tag string) error {
+	return bulkRemoveTag(db.conn, db.dialect, db.examiner, ids, tag)
+}
+
+// RenameTag renames a tag, or retags only the events in ids when given.
+func (db *MySQLStore) RenameTag(ids []int64, oldName, newName string) error {
+	return renameTag(db.conn, db.dialect, db.examiner, ids, oldName, newName)
+}
+
+// MergeTags folds the source tags into target, or only on the events in ids when given.
+func (db *MySQLStore) MergeTags(ids []int64, sources []string, target string) error {
+	return mergeTags(db.conn, db.dialect, db.examiner, ids, sources, target)
+}
+
+// DeleteTag removes a tag from every event and deletes it.
+func (db *MySQLStore) DeleteTag(name string) error {
+	return deleteTag(db.conn, db.dialect, db.examiner, name)
+}
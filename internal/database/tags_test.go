@@ -0,0 +1,95 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cdtdelta/4n6time/internal/model"
+)
+
+func TestSplitTags(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{" , ,", nil},
+		{"malware", []string{"malware"}},
+		{" lateral ,persistence,Lateral", []string{"lateral", "persistence"}},
+	}
+	for _, tt := range tests {
+		if got := splitTags(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitTags(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestInsertNormalizesTagColumn(t *testing.T) {
+	db := createTestDB(t)
+	e := sampleEvent()
+	e.Tag = " persistence, Lateral,persistence "
+	if err := db.InsertEvent(e); err != nil {
+		t.Fatalf("InsertEvent failed: %v", err)
+	}
+	e = sampleEvent()
+	e.Tag = "lateral"
+	if _, err := db.InsertEvents([]*model.Event{e}, nil); err != nil {
+		t.Fatalf("InsertEvents failed: %v", err)
+	}
+
+	events, _ := db.QueryEvents("", nil, "rowid", 0, 0)
+	if events[0].Tag != "Lateral,persistence" || events[1].Tag != "Lateral" {
+		t.Errorf("expected tag column rendered from canonical tag names, got %q, %q", events[0].Tag, events[1].Tag)
+	}
+	tags, _ := db.GetTags()
+	if len(tags) != 2 || tags[0].Name != "Lateral" || tags[0].EventCount != 2 {
+		t.Errorf("expected tags shared ignoring case, got %+v", tags)
+	}
+}
+
+// An insert ingests the tags of the events it adds, not those of every
+// tagged event already stored.
+func TestInsertIngestsOnlyItsOwnTags(t *testing.T) {
+	db := createTestDB(t)
+	if err := db.InsertEvent(sampleEvent()); err != nil {
+		t.Fatalf("InsertEvent failed: %v", err)
+	}
+	// A tag column written before event_tags existed
+	if _, err := db.conn.Exec("UPDATE log2timeline SET tag = 'legacy'"); err != nil {
+		t.Fatal(err)
+	}
+
+	e := sampleEvent()
+	e.Tag = "new"
+	if _, err := db.InsertEvents([]*model.Event{e}, nil); err != nil {
+		t.Fatalf("InsertEvents failed: %v", err)
+	}
+	e = sampleEvent()
+	e.Tag = "single"
+	if err := db.InsertEvent(e); err != nil {
+		t.Fatalf("InsertEvent failed: %v", err)
+	}
+
+	tags, err := db.GetTags()
+	if err != nil {
+		t.Fatalf("GetTags failed: %v", err)
+	}
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"new", "single"}) {
+		t.Errorf("tags = %q, want only the inserted events' tags", names)
+	}
+}
+
+func TestChunkIDs(t *testing.T) {
+	ids := make([]int64, 2*tagChunkSize+1)
+	chunks := chunkIDs(ids)
+	if len(chunks) != 3 || len(chunks[0]) != tagChunkSize || len(chunks[2]) != 1 {
+		t.Errorf("unexpected chunk sizes for %d ids: %d chunks", len(ids), len(chunks))
+	}
+	if chunkIDs(nil) != nil {
+		t.Error("expected no chunks for no ids")
+	}
+}
@@ -0,0 +1,37 @@
+-- Schema written by 4n6time 0.7.0: no bookmark column, no examiner_notes.
+CREATE TABLE log2timeline (
+	timezone TEXT, MACB TEXT, source TEXT, sourcetype TEXT,
+	type TEXT, user TEXT, host TEXT, desc TEXT, filename TEXT,
+	inode TEXT, notes TEXT, format TEXT, extra TEXT,
+	datetime DATETIME, reportnotes TEXT, inreport TEXT,
+	tag TEXT, color TEXT, offset INT, store_number INT,
+	store_index INT, vss_store_number INT, URL TEXT,
+	record_number TEXT, event_identifier TEXT, event_type TEXT,
+	source_name TEXT, user_sid TEXT, computer_name TEXT
+);
+CREATE TABLE l2t_sourcetypes (sourcetype TEXT, frequency INT);
+CREATE TABLE l2t_sources (source TEXT, frequency INT);
+CREATE TABLE l2t_users (user TEXT, frequency INT);
+CREATE TABLE l2t_hosts (host TEXT, frequency INT);
+CREATE TABLE l2t_MACBs (MACB TEXT, frequency INT);
+CREATE TABLE l2t_colors (color TEXT, frequency INT);
+CREATE TABLE l2t_types (type TEXT, frequency INT);
+CREATE TABLE l2t_record_numbers (record_number TEXT, frequency INT);
+CREATE TABLE l2t_tags (tag TEXT);
+CREATE TABLE l2t_saved_query (name TEXT, query TEXT);
+CREATE TABLE l2t_disk (
+	disk_type INT, mount_path TEXT, dd_path TEXT,
+	dd_offset TEXT, storage_file TEXT, export_path TEXT
+);
+INSERT INTO l2t_disk (disk_type, mount_path, dd_path, dd_offset, storage_file, export_path)
+	VALUES (0, '', '', '', '', '');
+INSERT INTO l2t_hosts (host, frequency) VALUES ('WKSTN01', 2), ('DC01', 1);
+INSERT INTO l2t_tags (tag) VALUES ('lateral');
+INSERT INTO l2t_saved_query (name, query) VALUES ('PsExec', 'desc LIKE ''%psexec%''');
+INSERT INTO log2timeline (
+	timezone, MACB, source, sourcetype, type, user, host, desc, filename, inode, notes, format, extra, datetime, reportnotes, inreport, tag, color, offset, store_number, store_index, vss_store_number, URL, record_number, event_identifier, event_type, source_name, user_sid, computer_name
+) VALUES
+	('UTC', 'M...', 'FILE', 'NTFS $MFT', 'Content Modification Time', 'admin', 'WKSTN01', 'C:\Windows\PSEXESVC.exe created', 'C:\Windows\PSEXESVC.exe', '', '', 'l2tcsv', '', '2025-01-15 10:30:00', '', '', 'lateral', 'RED', 0, 0, 0, 0, '', '', '', '', '', '', ''),
+	('UTC', '.A..', 'EVT', 'WinEVTX', 'Last Access Time', 'admin', 'WKSTN01', 'Service PSEXESVC installed', 'System.evtx', '', '', 'l2tcsv', '', '2025-01-15 10:31:00', '', '', '', '', 0, 0, 0, 0, '', '4697', '', '', '', '', ''),
+	('UTC', '...B', 'REG', 'Registry Key', 'Creation Time', 'SYSTEM', 'DC01', 'Run key mimikatz.exe', 'NTUSER.DAT', '', '', 'l2tcsv', '', '2025-01-16 08:00:00', '', '', '', '', 0, 0, 0, 0, '', '', '', '', '', '', '');
+CREATE INDEX host_idx ON log2timeline (host);
@@ -0,0 +1,317 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Undo and redo are derived from audit_log, so they survive restarts and
+// every database keeps its own history. Undoing an entry restores the old
+// values it recorded and appends an "undo" entry holding the values it
+// replaced; redoing reapplies those. A new change clears the redo stack.
+
+// undoableOperations lists the operations Undo can revert.
+var undoableOperations = map[string]bool{
+	AuditUpdateEvent:     true,
+	AuditToggleBookmark:  true,
+	AuditSetColor:        true,
+	AuditSetBookmark:     true,
+	AuditAddTag:          true,
+	AuditRemoveTag:       true,
+	AuditAddNote:         true,
+	AuditDeleteNote:      true,
+	AuditApplyColorRules: true,
+}
+
+// undoBarriers lists operations that rewrite tags across the database.
+// Earlier changes cannot be undone past them.
+var undoBarriers = map[string]bool{
+	AuditRenameTag: true,
+	AuditMergeTags: true,
+	AuditDeleteTag: true,
+}
+
+// UndoState describes the undo and redo stacks: how many steps each holds
+// and the operation the next Undo or Redo would revert or reapply.
+type UndoState struct {
+	UndoCount     int    `json:"undoCount"`
+	UndoOperation string `json:"undoOperation"`
+	RedoCount     int    `json:"redoCount"`
+	RedoOperation string `json:"redoOperation"`
+}
+
+// undoStep is one entry of the undo or redo stack. On the redo stack,
+// snapshot is the undo entry holding the values to reapply.
+type undoStep struct {
+	entry     int64
+	operation string
+	snapshot  int64
+}
+
+// undoHistory is how many of the most recent audit entries undoStacks
+// replays, which bounds both the work GetUndoState does after every edit
+// and how far back Undo can reach.
+const undoHistory = 1000
+
+// undoStacks replays the most recent undoHistory entries of audit_log to
+// rebuild the undo and redo stacks, most recent step last. Undo and redo
+// entries that refer to changes older than that are ignored, so those
+// changes fall off the bottom of the stacks.
+func undoStacks(tx schemaExecer) (undo, redo []undoStep, err error) {
+	rows, err := tx.Query(fmt.Sprintf(`SELECT id, operation, new_values FROM audit_log
+		WHERE id > (SELECT COALESCE(MAX(id), 0) FROM audit_log) - %d ORDER BY id`, undoHistory))
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading audit log: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var op string
+		var newJSON sql.NullString
+		if err := rows.Scan(&id, &op, &newJSON); err != nil {
+			return nil, nil, fmt.Errorf("reading audit log: %w", err)
+		}
+		switch {
+		case undoableOperations[op]:
+			undo = append(undo, undoStep{entry: id, operation: op})
+			redo = nil
+		case undoBarriers[op]:
+			undo, redo = nil, nil
+		case op == AuditUndo || op == AuditRedo:
+			var v map[string]interface{}
+			if err := decodeAuditValues(newJSON.String, &v); err != nil {
+				return nil, nil, fmt.Errorf("decoding audit entry %d: %w", id, err)
+			}
+			entry, _ := v["entry"].(int64)
+			if op == AuditUndo && len(undo) > 0 && undo[len(undo)-1].entry == entry {
+				step := undo[len(undo)-1]
+				undo = undo[:len(undo)-1]
+				step.snapshot = id
+				redo = append(redo, step)
+			} else if op == AuditRedo && len(redo) > 0 && redo[len(redo)-1].entry == entry {
+				step := redo[len(redo)-1]
+				redo = redo[:len(redo)-1]
+				step.snapshot = 0
+				undo = append(undo, step)
+			}
+		}
+	}
+	return undo, redo, rows.Err()
+}
+
+// readAuditEntry returns the audit entry with the given ID.
+func readAuditEntry(tx schemaExecer, d Dialect, id int64) (*AuditEntry, error) {
+	rows, err := tx.Query(auditSelectSQL+" WHERE id = "+d.Placeholder(1), id)
+	if err != nil {
+		return nil, fmt.Errorf("reading audit entry %d: %w", id, err)
+	}
+	entries, err := scanAuditEntries(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("audit entry %d not found", id)
+	}
+	return &entries[0], nil
+}
+
+// restoreRows returns the rows of target listed in ids to the values in
+// old, recording the values they replace in a. Examiner notes absent from
+// old are deleted, and notes in old that no longer exist are recreated
+// with their original IDs.
+func restoreRows(tx *sql.Tx, d Dialect, a *auditChange, target string, ids []int64, old map[int64]map[string]interface{}) error {
+	switch target {
+	case AuditTargetEvent:
+		fields := map[string]interface{}{}
+		for _, row := range old {
+			for f := range row {
+				if !isValidField(f) {
+					return fmt.Errorf("invalid field name: %s", f)
+				}
+				fields[f] = nil
+			}
+		}
+		if err := a.snapshot(tx, "log2timeline", fieldNames(fields), ids); err != nil {
+			return err
+		}
+		idCol := d.IDColumn()
+		for _, id := range ids {
+			row, ok := old[id]
+			if !ok {
+				continue
+			}
+			var sets []string
+			var args []interface{}
+			for _, f := range fieldNames(row) {
+				if f == "tag" {
+					continue
+				}
+				args = append(args, row[f])
+				sets = append(sets, d.QuoteColumn(f)+" = "+d.Placeholder(len(args)))
+			}
+			if len(sets) > 0 {
+				args = append(args, id)
+				_, err := tx.Exec(fmt.Sprintf("UPDATE log2timeline SET %s WHERE %s = %s",
+					strings.Join(sets, ", "), idCol, d.Placeholder(len(args))), args...)
+				if err != nil {
+					return fmt.Errorf("restoring event %d: %w", id, err)
+				}
+			}
+			if tag, ok := row["tag"]; ok {
+				list, _ := tag.(string)
+				if err := setEventTags(tx, d, id, list); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+
+	case AuditTargetExaminerNote:
+		if err := a.snapshot(tx, "examiner_notes", examinerNoteFields, ids); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			row, ok := old[id]
+			_, exists := a.old[id]
+			var err error
+			switch {
+			case !ok && exists:
+				_, err = tx.Exec("DELETE FROM examiner_notes WHERE id = "+d.Placeholder(1), id)
+			case ok && !exists:
+				args := []interface{}{id}
+				for _, f := range examinerNoteFields {
+					args = append(args, row[f])
+				}
+				_, err = tx.Exec("INSERT INTO examiner_notes (id, "+strings.Join(examinerNoteFields, ", ")+
+					") VALUES ("+placeholders(d, len(args))+")", args...)
+			case ok:
+				var sets []string
+				var args []interface{}
+				for _, f := range fieldNames(row) {
+					args = append(args, row[f])
+					sets = append(sets, d.QuoteColumn(f)+" = "+d.Placeholder(len(args)))
+				}
+				args = append(args, id)
+				_, err = tx.Exec("UPDATE examiner_notes SET "+strings.Join(sets, ", ")+
+					" WHERE id = "+d.Placeholder(len(args)), args...)
+			}
+			if err != nil {
+				return fmt.Errorf("restoring examiner note %d: %w", id, err)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot restore %s changes", target)
+}
+
+// undoRedo reverts the most recent undoable change, or reapplies the most
+// recently undone one, as a single audited transaction. It returns the
+// entry acted on, or nil when the stack is empty.
+func undoRedo(conn *sql.DB, d Dialect, examiner string, redo bool) (*AuditEntry, error) {
+	operation := AuditUndo
+	if redo {
+		operation = AuditRedo
+	}
+	var acted *AuditEntry
+	err := runAudited(conn, d, examiner, operation, func(tx *sql.Tx, a *auditChange) error {
+		undoStack, redoStack, err := undoStacks(tx)
+		if err != nil {
+			return err
+		}
+		stack := undoStack
+		if redo {
+			stack = redoStack
+		}
+		if len(stack) == 0 {
+			return nil
+		}
+		step := stack[len(stack)-1]
+
+		entry, err := readAuditEntry(tx, d, step.entry)
+		if err != nil {
+			return err
+		}
+		// Undo restores the values the change replaced; redo restores the
+		// values the undo replaced.
+		values := entry
+		if redo {
+			if values, err = readAuditEntry(tx, d, step.snapshot); err != nil {
+				return err
+			}
+		}
+		if err := restoreRows(tx, d, a, entry.Target, values.IDs, values.OldValues); err != nil {
+			return err
+		}
+		a.new = map[string]interface{}{"entry": entry.ID}
+		acted = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return acted, nil
+}
+
+// getUndoState implements Store.GetUndoState for every backend.
+func getUndoState(conn *sql.DB) (*UndoState, error) {
+	undo, redo, err := undoStacks(conn)
+	if err != nil {
+		return nil, err
+	}
+	s := &UndoState{UndoCount: len(undo), RedoCount: len(redo)}
+	if len(undo) > 0 {
+		s.UndoOperation = undo[len(undo)-1].operation
+	}
+	if len(redo) > 0 {
+		s.RedoOperation = redo[len(redo)-1].operation
+	}
+	return s, nil
+}
+
+// -- Store methods --
+
+// Undo reverts the most recent undoable change and returns its audit entry,
+// or nil if there is nothing to undo.
+func (db *SQLiteStore) Undo() (*AuditEntry, error) {
+	return undoRedo(db.conn, db.dialect, db.examiner, false)
+}
+
+// Redo reapplies the most recently undone change and returns its audit
+// entry, or nil if there is nothing to redo.
+func (db *SQLiteStore) Redo() (*AuditEntry, error) {
+	return undoRedo(db.conn, db.dialect, db.examiner, true)
+}
+
+// GetUndoState describes the undo and redo stacks.
+func (db *SQLiteStore) GetUndoState() (*UndoState, error) { return getUndoState(db.conn) }
+
+// Undo reverts the most recent undoable change and returns its audit entry,
+// or nil if there is nothing to undo.
+func (db *PostgresStore) Undo() (*AuditEntry, error) {
+	return undoRedo(db.conn, db.dialect, db.examiner, false)
+}
+
+// Redo reapplies the most recently undone change and returns its audit
+// entry, or nil if there is nothing to redo.
+func (db *PostgresStore) Redo() (*AuditEntry, error) {
+	return undoRedo(db.conn, db.dialect, db.examiner, true)
+}
+
+// GetUndoState describes the undo and redo stacks.
+func (db *PostgresStore) GetUndoState() (*UndoState, error) { return getUndoState(db.conn) }
+
+// Undo reverts the most recent undoable change and returns its audit entry,
+// or nil if there is nothing to undo.
+func (db *MySQLStore) Undo() (*AuditEntry, error) {
+	return undoRedo(db.conn, db.dialect, db.examiner, false)
+}
+
+// Redo reapplies the most recently undone change and returns its audit
+// entry, or nil if there is nothing to redo.
+func (db *MySQLStore) Redo() (*AuditEntry, error) {
+	return undoRedo(db.conn, db.dialect, db.examiner, true)
+}
+
+// GetUndoState describes the undo and redo stacks.
+func (db *MySQLStore) GetUndoState() (*UndoState, error) { return getUndoState(db.conn) }
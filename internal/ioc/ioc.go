@@ -0,0 +1,298 @@
+// Package ioc reads lists of indicators of compromise (IP addresses,
+// domains, hashes, file names, user names) for batch searching: plain text
+// with one indicator per line, CSV, or STIX 2 JSON.
+package ioc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Indicator types, as guessed by Classify or taken from a STIX object.
+const (
+	TypeIP       = "ip"
+	TypeDomain   = "domain"
+	TypeURL      = "url"
+	TypeEmail    = "email"
+	TypeMD5      = "md5"
+	TypeSHA1     = "sha1"
+	TypeSHA256   = "sha256"
+	TypeFilename = "filename"
+	TypeUser     = "user"
+	TypeOther    = "other"
+)
+
+// Indicator is one term to search for. Name labels it in results and is
+// used as the tag for its hits; it defaults to Value.
+type Indicator struct {
+	Value string `json:"value"`
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+}
+
+// Label returns the indicator's name, or its value when it has none.
+func (ind Indicator) Label() string {
+	if n := strings.TrimSpace(ind.Name); n != "" {
+		return n
+	}
+	return ind.Value
+}
+
+// ReadFile reads indicators from a file: STIX when it ends in .json, CSV
+// when it ends in .csv, and one per line otherwise.
+func ReadFile(path string) ([]Indicator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading indicators: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ParseSTIX(data)
+	case ".csv":
+		return ParseCSV(bytes.NewReader(data))
+	}
+	return ParseText(string(data)), nil
+}
+
+// ParseText reads one indicator per line, as pasted by the user. Blank
+// lines and lines starting with # are skipped.
+func ParseText(text string) []Indicator {
+	var list []Indicator
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list = append(list, Indicator{Value: line})
+	}
+	return normalize(list)
+}
+
+// csvValueColumns and csvNameColumns are the header names recognized for
+// the indicator and its label, in order of preference.
+var (
+	csvValueColumns = []string{"indicator", "ioc", "value", "observable", "term"}
+	csvNameColumns  = []string{"name", "label", "description", "tag"}
+)
+
+// ParseCSV reads indicators from CSV. With a header naming an indicator
+// column (indicator, ioc, value, observable or term) that column is read,
+// along with optional name and type columns; otherwise the first column
+// holds the indicator and the second, if any, its name.
+func ParseCSV(r io.Reader) ([]Indicator, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV indicators: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	valueCol, nameCol, typeCol := 0, 1, -1
+	if col := findColumn(records[0], csvValueColumns); col >= 0 {
+		valueCol, nameCol = col, findColumn(records[0], csvNameColumns)
+		typeCol = findColumn(records[0], []string{"type"})
+		records = records[1:]
+	}
+
+	var list []Indicator
+	for _, rec := range records {
+		ind := Indicator{Value: column(rec, valueCol), Name: column(rec, nameCol), Type: column(rec, typeCol)}
+		if ind.Value != "" {
+			list = append(list, ind)
+		}
+	}
+	return normalize(list), nil
+}
+
+// findColumn returns the index of the first of names found in header,
+// ignoring case, or -1.
+func findColumn(header, names []string) int {
+	for _, name := range names {
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func column(rec []string, i int) string {
+	if i < 0 || i >= len(rec) {
+		return ""
+	}
+	return strings.TrimSpace(rec[i])
+}
+
+// stixObject holds the parts of a STIX 2 object read here: indicator
+// patterns and the values of cyber observables.
+type stixObject struct {
+	Type         string            `json:"type"`
+	Name         string            `json:"name"`
+	Pattern      string            `json:"pattern"`
+	Value        string            `json:"value"`
+	Hashes       map[string]string `json:"hashes"`
+	UserID       string            `json:"user_id"`
+	AccountLogin string            `json:"account_login"`
+	Objects      []stixObject      `json:"objects"`
+}
+
+// stixComparison matches one comparison in a STIX pattern, such as
+// [file:hashes.'SHA-256' = '...'], capturing the object type and value.
+var stixComparison = regexp.MustCompile(`([a-z0-9-]+):[A-Za-z0-9_.'\[\]*-]+\s*(?:=|LIKE|MATCHES)\s*'((?:[^'\\]|\\.)*)'`)
+
+// ParseSTIX reads a STIX 2 bundle, or a single object. Each value compared
+// in an indicator's pattern becomes an indicator with its name, and IP
+// address, domain, URL, email, file and user account observables are read
+// directly.
+func ParseSTIX(data []byte) ([]Indicator, error) {
+	var root stixObject
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("reading STIX indicators: %w", err)
+	}
+	objects := root.Objects
+	if root.Type != "bundle" {
+		objects = []stixObject{root}
+	}
+
+	var list []Indicator
+	for _, obj := range objects {
+		switch obj.Type {
+		case "indicator":
+			for _, m := range stixComparison.FindAllStringSubmatch(obj.Pattern, -1) {
+				value := strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(m[2])
+				list = append(list, Indicator{Value: value, Name: obj.Name, Type: stixType(m[1])})
+			}
+		case "file":
+			if obj.Name != "" {
+				list = append(list, Indicator{Value: obj.Name, Type: TypeFilename})
+			}
+			for _, h := range obj.Hashes {
+				list = append(list, Indicator{Value: h})
+			}
+		case "user-account":
+			for _, v := range []string{obj.AccountLogin, obj.UserID} {
+				if v != "" {
+					list = append(list, Indicator{Value: v, Type: TypeUser})
+				}
+			}
+		default:
+			if obj.Value != "" {
+				list = append(list, Indicator{Value: obj.Value, Type: stixType(obj.Type)})
+			}
+		}
+	}
+	return normalize(list), nil
+}
+
+// stixType maps a STIX object type to an indicator type, or "" for file
+// hashes and other types, which are classified by value.
+func stixType(objectType string) string {
+	switch objectType {
+	case "ipv4-addr", "ipv6-addr":
+		return TypeIP
+	case "domain-name":
+		return TypeDomain
+	case "url":
+		return TypeURL
+	case "email-addr":
+		return TypeEmail
+	case "user-account":
+		return TypeUser
+	}
+	return ""
+}
+
+var (
+	hexValue    = regexp.MustCompile(`^[0-9A-Fa-f]+$`)
+	domainValue = regexp.MustCompile(`^(?i)([a-z0-9-]+\.)+[a-z]{2,}$`)
+	emailValue  = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+	// fileExtensions are the extensions that make a value a file name
+	// rather than a domain, such as evil.exe.
+	fileExtensions = map[string]bool{
+		".exe": true, ".dll": true, ".sys": true, ".scr": true, ".msi": true,
+		".ps1": true, ".bat": true, ".cmd": true, ".vbs": true, ".js": true,
+		".hta": true, ".jar": true, ".lnk": true, ".zip": true, ".rar": true,
+		".7z": true, ".doc": true, ".docx": true, ".docm": true, ".xls": true,
+		".xlsx": true, ".xlsm": true, ".pdf": true, ".iso": true, ".tmp": true,
+	}
+)
+
+// Classify guesses the type of an indicator from its value.
+func Classify(value string) string {
+	switch {
+	case net.ParseIP(value) != nil:
+		return TypeIP
+	case hexValue.MatchString(value) && len(value) == 32:
+		return TypeMD5
+	case hexValue.MatchString(value) && len(value) == 40:
+		return TypeSHA1
+	case hexValue.MatchString(value) && len(value) == 64:
+		return TypeSHA256
+	case emailValue.MatchString(value):
+		return TypeEmail
+	case strings.Contains(value, "://"):
+		if u, err := url.Parse(value); err == nil && u.Host != "" {
+			return TypeURL
+		}
+	case strings.ContainsAny(value, `\/`) || fileExtensions[strings.ToLower(filepath.Ext(value))]:
+		return TypeFilename
+	case domainValue.MatchString(value):
+		return TypeDomain
+	}
+	return TypeOther
+}
+
+// Refang undoes the usual defanging of shared indicators, such as
+// hxxp://evil[.]example and 10.0.0[.]1, so they match the evidence.
+func Refang(value string) string {
+	value = refanger.Replace(value)
+	value = defangedHTTP.ReplaceAllString(value, "http$1://")
+	return defangedFTP.ReplaceAllString(value, "ftp://")
+}
+
+var (
+	defangedHTTP = regexp.MustCompile(`^(?i)h(?:xx|\*\*)p(s?)://`)
+	defangedFTP  = regexp.MustCompile(`^(?i)fxp://`)
+)
+
+var refanger = strings.NewReplacer(
+	"[.]", ".", "(.)", ".", "{.}", ".", "[dot]", ".",
+	"[:]", ":", "[://]", "://", "[@]", "@", "[at]", "@",
+)
+
+// normalize refangs and trims each indicator, fills in missing types and
+// drops duplicates, keeping the first of each value regardless of case.
+func normalize(list []Indicator) []Indicator {
+	seen := make(map[string]bool)
+	out := make([]Indicator, 0, len(list))
+	for _, ind := range list {
+		ind.Value = Refang(strings.TrimSpace(ind.Value))
+		ind.Name = strings.TrimSpace(ind.Name)
+		ind.Type = strings.ToLower(strings.TrimSpace(ind.Type))
+		key := strings.ToLower(ind.Value)
+		if ind.Value == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		if ind.Type == "" {
+			ind.Type = Classify(ind.Value)
+		}
+		out = append(out, ind)
+	}
+	return out
+}
@@ -0,0 +1,132 @@
+package ioc
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseText(t *testing.T) {
+	got := ParseText("# campaign X\n10.0.0[.]5\r\n\n  hxxps://evil[.]example/a  \nEVIL.example\nevil.example\nmimikatz.exe\n")
+	want := []Indicator{
+		{Value: "10.0.0.5", Type: TypeIP},
+		{Value: "https://evil.example/a", Type: TypeURL},
+		{Value: "EVIL.example", Type: TypeDomain},
+		{Value: "mimikatz.exe", Type: TypeFilename},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseText = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	got, err := ParseCSV(strings.NewReader("Type,Indicator,Name\nip,10.0.0.5,C2 server\n,d41d8cd98f00b204e9800998ecf8427e,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Indicator{
+		{Value: "10.0.0.5", Name: "C2 server", Type: TypeIP},
+		{Value: "d41d8cd98f00b204e9800998ecf8427e", Type: TypeMD5},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCSV = %+v\nwant %+v", got, want)
+	}
+
+	// Without a recognized header, the first column is the indicator and
+	// the second its name
+	got, err = ParseCSV(strings.NewReader("evil.example,Phishing domain\nadmin2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []Indicator{
+		{Value: "evil.example", Name: "Phishing domain", Type: TypeDomain},
+		{Value: "admin2", Type: TypeOther},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCSV without header = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseSTIX(t *testing.T) {
+	bundle := `{
+		"type": "bundle",
+		"objects": [
+			{"type": "indicator", "name": "Dropper", "pattern_type": "stix",
+			 "pattern": "[file:hashes.'SHA-256' = 'E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855'] OR [file:name = 'drop\\'s.exe']"},
+			{"type": "indicator", "name": "C2", "pattern": "[ipv4-addr:value = '203.0.113.7'] AND [domain-name:value = 'c2.example']"},
+			{"type": "url", "value": "http://c2.example/beacon"},
+			{"type": "user-account", "account_login": "svc_backup"},
+			{"type": "malware", "name": "not an indicator"}
+		]
+	}`
+	got, err := ParseSTIX([]byte(bundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Indicator{
+		{Value: "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855", Name: "Dropper", Type: TypeSHA256},
+		{Value: "drop's.exe", Name: "Dropper", Type: TypeFilename},
+		{Value: "203.0.113.7", Name: "C2", Type: TypeIP},
+		{Value: "c2.example", Name: "C2", Type: TypeDomain},
+		{Value: "http://c2.example/beacon", Type: TypeURL},
+		{Value: "svc_backup", Type: TypeUser},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSTIX = %+v\nwant %+v", got, want)
+	}
+
+	if _, err := ParseSTIX([]byte("not json")); err == nil {
+		t.Error("expected an error for invalid STIX")
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"list.txt":  "evil.example\n",
+		"list.csv":  "ioc,label\nevil.example,Phish\n",
+		"list.json": `{"type": "domain-name", "value": "evil.example"}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%s): %v", name, err)
+		}
+		if len(got) != 1 || got[0].Value != "evil.example" || got[0].Type != TypeDomain {
+			t.Errorf("ReadFile(%s) = %+v", name, got)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := map[string]string{
+		"2001:db8::1": TypeIP,
+		"da39a3ee5e6b4b0d3255bfef95601890afd80709": TypeSHA1,
+		"bob@evil.example":                         TypeEmail,
+		`C:\Users\Public\run.bat`:                  TypeFilename,
+		"invoice.PDF":                              TypeFilename,
+		"login.evil.example":                       TypeDomain,
+		"S-1-5-21-1004":                            TypeOther,
+		"deadbeef":                                 TypeOther,
+	}
+	for value, want := range tests {
+		if got := Classify(value); got != want {
+			t.Errorf("Classify(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestLabel(t *testing.T) {
+	if got := (Indicator{Value: "10.0.0.5"}).Label(); got != "10.0.0.5" {
+		t.Errorf("Label() = %q", got)
+	}
+	if got := (Indicator{Value: "10.0.0.5", Name: "C2"}).Label(); got != "C2" {
+		t.Errorf("Label() = %q", got)
+	}
+}
@@ -1,5 +1,12 @@
 package model
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
 // Fields is the ordered list of column names in the log2timeline table.
 // Used for query building, field validation, and index management.
 var Fields = []string{
@@ -9,6 +16,15 @@ var Fields = []string{
 	"tag", "color", "offset", "store_number", "store_index",
 	"vss_store_number", "URL", "record_number", "event_identifier",
 	"event_type", "source_name", "user_sid", "computer_name", "bookmark",
+	"batch_id",
+}
+
+// SearchFields is the set of columns covered by the search bar. The SQLite
+// full-text index is built over exactly these columns.
+var SearchFields = []string{
+	"desc", "filename", "source", "sourcetype", "type",
+	"user", "host", "extra", "tag", "URL", "source_name",
+	"computer_name", "format", "notes",
 }
 
 // Event represents a single timeline event from a Plaso/log2timeline output.
@@ -45,4 +61,99 @@ type Event struct {
 	UserSID        string `json:"user_sid" db:"user_sid"`
 	ComputerName   string `json:"computer_name" db:"computer_name"`
 	Bookmark       int64  `json:"bookmark" db:"bookmark"`
+	BatchID        int64  `json:"batch_id" db:"batch_id"`
+}
+
+// Fingerprint returns a stable hash of the event's evidence content.
+// Analyst annotations (tag, color, bookmark, report notes), the row ID and
+// the import batch are excluded, so the same source event imported into two
+// databases produces the same fingerprint. Datetimes are normalized so that SQLite text values and
+// PostgreSQL timestamp renderings ("2025-01-15T10:30:00Z") compare equal.
+func (e *Event) Fingerprint() string {
+	sum := e.FingerprintSum()
+	return hex.EncodeToString(sum[:])
+}
+
+// FingerprintSum returns the SHA-256 hash that Fingerprint renders as hex.
+func (e *Event) FingerprintSum() [sha256.Size]byte {
+	dt := strings.TrimSuffix(strings.Replace(e.Datetime, "T", " ", 1), "Z")
+	parts := []string{
+		dt, e.Timezone, e.MACB, e.Source, e.SourceType, e.Type,
+		e.User, e.Host, e.Desc, e.Filename, e.Inode, e.Notes, e.Format, e.Extra,
+		strconv.FormatInt(e.Offset, 10), strconv.FormatInt(e.StoreNumber, 10),
+		strconv.FormatInt(e.StoreIndex, 10), strconv.FormatInt(e.VSSStoreNumber, 10),
+		e.URL, e.RecordNumber, e.EventID, e.EventType, e.SourceName,
+		e.UserSID, e.ComputerName,
+	}
+	return sha256.Sum256([]byte(strings.Join(parts, "\x1f")))
+}
+
+// Value returns the value of the named log2timeline column (one of Fields),
+// or nil if the name is not a column.
+func (e *Event) Value(field string) interface{} {
+	switch field {
+	case "datetime":
+		return e.Datetime
+	case "timezone":
+		return e.Timezone
+	case "MACB":
+		return e.MACB
+	case "source":
+		return e.Source
+	case "sourcetype":
+		return e.SourceType
+	case "type":
+		return e.Type
+	case "user":
+		return e.User
+	case "host":
+		return e.Host
+	case "desc":
+		return e.Desc
+	case "filename":
+		return e.Filename
+	case "inode":
+		return e.Inode
+	case "notes":
+		return e.Notes
+	case "format":
+		return e.Format
+	case "extra":
+		return e.Extra
+	case "reportnotes":
+		return e.ReportNotes
+	case "inreport":
+		return e.InReport
+	case "tag":
+		return e.Tag
+	case "color":
+		return e.Color
+	case "offset":
+		return e.Offset
+	case "store_number":
+		return e.StoreNumber
+	case "store_index":
+		return e.StoreIndex
+	case "vss_store_number":
+		return e.VSSStoreNumber
+	case "URL":
+		return e.URL
+	case "record_number":
+		return e.RecordNumber
+	case "event_identifier":
+		return e.EventID
+	case "event_type":
+		return e.EventType
+	case "source_name":
+		return e.SourceName
+	case "user_sid":
+		return e.UserSID
+	case "computer_name":
+		return e.ComputerName
+	case "bookmark":
+		return e.Bookmark
+	case "batch_id":
+		return e.BatchID
+	}
+	return nil
 }
@@ -13,3 +13,33 @@ func TestEventDefaults(t *testing.T) {
 		t.Errorf("expected empty Timezone, got %s", e.Timezone)
 	}
 }
+
+func TestFingerprintIgnoresAnnotations(t *testing.T) {
+	a := Event{ID: 1, Datetime: "2025-01-15 10:30:00", Source: "FILE", Desc: "x"}
+	b := Event{ID: 99, Datetime: "2025-01-15T10:30:00Z", Source: "FILE", Desc: "x",
+		Tag: "malware", Color: "RED", Bookmark: 1}
+
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Error("expected annotations and datetime format to be ignored")
+	}
+
+	b.Desc = "y"
+	if a.Fingerprint() == b.Fingerprint() {
+		t.Error("expected different content to produce a different fingerprint")
+	}
+}
+
+func TestValueCoversEveryField(t *testing.T) {
+	e := Event{Desc: "x", Offset: 42}
+	for _, f := range Fields {
+		if e.Value(f) == nil {
+			t.Errorf("Value(%q) returned nil", f)
+		}
+	}
+	if e.Value("desc") != "x" || e.Value("offset") != int64(42) {
+		t.Errorf("unexpected values: %v, %v", e.Value("desc"), e.Value("offset"))
+	}
+	if e.Value("bogus") != nil {
+		t.Error("expected nil for unknown field")
+	}
+}
@@ -23,6 +23,64 @@ type QueryDialect interface {
 	QuoteColumn(name string) string
 }
 
+// FullTextDialect is implemented by dialects backed by a full-text index.
+// Search predicates are routed through it; dialects without one fall back to
+// LIKE matching across model.SearchFields.
+type FullTextDialect interface {
+	// FullTextPredicate returns a WHERE fragment restricting log2timeline to
+	// rows matching the search text, with its arguments numbered from
+	// paramIdx. It returns false when the text contains nothing the index can
+	// match, in which case the caller falls back to LIKE matching.
+	FullTextPredicate(text string, paramIdx int) (string, []interface{}, bool)
+}
+
+// TimestampDialect is implemented by dialects that store datetime in a
+// native timestamp column. Values that could not be parsed are stored as NULL
+// and scan back as "", so keyset cursors on datetime treat "" as NULL.
+// Dialects that do not implement it store datetime as text and never NULL.
+type TimestampDialect interface {
+	// NullsLast reports whether NULLs sort after all other values in
+	// ascending order (PostgreSQL) rather than before them (MySQL).
+	NullsLast() bool
+}
+
+// MatchDialect is implemented by dialects whose regular expression and
+// case-sensitive substring syntax differs from SQLite's, which uses the
+// REGEXP operator (backed by a registered function) and instr.
+type MatchDialect interface {
+	// RegexpSQL returns a condition that column matches, or with negate
+	// does not match, the regular expression bound to placeholder,
+	// case-sensitively.
+	RegexpSQL(column, placeholder string, negate bool) string
+
+	// ContainsCaseSQL returns a condition that column contains the text
+	// bound to placeholder, matching case.
+	ContainsCaseSQL(column, placeholder string) string
+
+	// LikeSQL returns a condition that column matches the LIKE pattern bound
+	// to placeholder, ignoring case, with backslash as the escape character.
+	LikeSQL(column, placeholder string) string
+}
+
+// sqliteMatchDialect is the MatchDialect used for dialects that do not
+// implement it.
+type sqliteMatchDialect struct{}
+
+func (sqliteMatchDialect) RegexpSQL(column, placeholder string, negate bool) string {
+	if negate {
+		return column + " NOT REGEXP " + placeholder
+	}
+	return column + " REGEXP " + placeholder
+}
+
+func (sqliteMatchDialect) ContainsCaseSQL(column, placeholder string) string {
+	return "instr(" + column + ", " + placeholder + ") > 0"
+}
+
+func (sqliteMatchDialect) LikeSQL(column, placeholder string) string {
+	return column + " LIKE " + placeholder + ` ESCAPE '\'`
+}
+
 // sqliteQueryDialect is the default dialect, producing SQLite-compatible SQL.
 type sqliteQueryDialect struct{}
 
@@ -37,3 +95,20 @@ func (d sqliteQueryDialect) DateBetweenSQL(paramIdx1, paramIdx2 int) string {
 // DefaultDialect is the query dialect used when none is explicitly set.
 // It produces SQLite-compatible SQL.
 var DefaultDialect QueryDialect = sqliteQueryDialect{}
+
+// IntegerDialect is implemented by dialects whose way of reading a TEXT
+// column as a number differs from SQLite's.
+type IntegerDialect interface {
+	// IntegerSQL returns an expression giving the integer value of the
+	// TEXT column, or NULL when it does not hold a whole number.
+	IntegerSQL(column string) string
+}
+
+// integerSQL returns the IntegerSQL expression of d for column, using
+// SQLite's when d does not implement IntegerDialect.
+func integerSQL(d QueryDialect, column string) string {
+	if id, ok := d.(IntegerDialect); ok {
+		return id.IntegerSQL(column)
+	}
+	return "(CASE WHEN " + column + " <> '' AND " + column + " NOT GLOB '*[^0-9]*' THEN CAST(" + column + " AS INTEGER) END)"
+}
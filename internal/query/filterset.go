@@ -0,0 +1,98 @@
+package query
+
+import "strings"
+
+// Filter is one field condition as entered in the filter panel. Operator is
+// spelled as ParseOperator accepts it.
+type Filter struct {
+	Field    string
+	Operator string
+	Value    string
+}
+
+// FilterGroup is a parenthesized group of filters. Its Filters and nested
+// Groups are combined with Logic ("OR", or AND otherwise).
+type FilterGroup struct {
+	Logic   string
+	Filters []Filter
+	Groups  []FilterGroup
+}
+
+// FilterSet is everything the event grid filters on: filters and filter
+// groups, full-text search text and the bookmark flag. The grid, its count,
+// the CSV export and the timeline histogram all compile it with Predicate,
+// so they select the same events.
+type FilterSet struct {
+	FilterGroup
+	SearchText   string
+	BookmarkOnly bool
+}
+
+// DateExpander expands a partial datetime value such as "2025-01" to the
+// first (end false) or last (end true) full timestamp of the period it
+// names, returning full timestamps unchanged.
+type DateExpander func(value string, end bool) string
+
+// Predicate compiles a filter, expanding the partial dates in a datetime
+// value with expand if it is not nil. Returns nil for an unrecognized
+// operator or an invalid field.
+func (f Filter) Predicate(expand DateExpander) *Predicate {
+	op, ok := ParseOperator(f.Operator)
+	if !ok {
+		return nil
+	}
+	val := f.Value
+	if f.Field == "datetime" && expand != nil {
+		val = expandDateValue(expand, op, val)
+	}
+	return Simple(f.Field, op, val)
+}
+
+// expandDateValue expands the dates in a datetime filter value to the start
+// of their period, or to its end for <= and for the upper bound of BETWEEN.
+func expandDateValue(expand DateExpander, op Operator, value string) string {
+	if op == InRange {
+		if low, high, ok := strings.Cut(value, ","); ok {
+			return expand(low, false) + "," + expand(high, true)
+		}
+	}
+	return expand(value, op == LessOrEqual)
+}
+
+// Predicate compiles the group's filters and nested groups into one
+// predicate. Filters that do not compile and empty groups are skipped;
+// returns nil if nothing is left.
+func (g FilterGroup) Predicate(expand DateExpander) *Predicate {
+	preds := make([]*Predicate, 0, len(g.Filters)+len(g.Groups))
+	for _, f := range g.Filters {
+		preds = append(preds, f.Predicate(expand))
+	}
+	for _, sub := range g.Groups {
+		preds = append(preds, sub.Predicate(expand))
+	}
+	if strings.EqualFold(g.Logic, "OR") {
+		return Combine(preds, OR)
+	}
+	return Combine(preds, AND)
+}
+
+// Predicate compiles the whole set. The filters apply their own logic; the
+// search text and bookmark flag are always ANDed with them. Returns nil if
+// the set matches every event.
+func (s FilterSet) Predicate(expand DateExpander) *Predicate {
+	var bookmark *Predicate
+	if s.BookmarkOnly {
+		bookmark = Simple("bookmark", Equal, "1")
+	}
+	return Combine([]*Predicate{s.FilterGroup.Predicate(expand), Search(s.SearchText), bookmark}, AND)
+}
+
+// Chartable matches events with a plausible datetime, leaving out the
+// zeroed, pre-epoch and far-future timestamps that would stretch a timeline
+// over centuries.
+func Chartable() *Predicate {
+	return Combine([]*Predicate{
+		Compare("datetime", GreaterThan, "1970-01-01"),
+		Compare("datetime", LessThan, "2100-01-01"),
+	}, AND)
+}
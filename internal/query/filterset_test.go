@@ -0,0 +1,71 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterSetPredicate(t *testing.T) {
+	expand := func(value string, end bool) string {
+		if end {
+			return value + " 23:59:59"
+		}
+		return value + " 00:00:00"
+	}
+	search, _ := Search("mimikatz").WhereClause()
+
+	tests := []struct {
+		name string
+		set  FilterSet
+		sql  string
+		args []interface{}
+	}{
+		{"empty", FilterSet{}, "", nil},
+		{"filters", FilterSet{FilterGroup: FilterGroup{Filters: []Filter{
+			{Field: "host", Operator: "=", Value: "WS01"},
+			{Field: "datetime", Operator: "<=", Value: "2025-01-15"},
+		}}}, "((host = ?) AND (datetime <= ?))", []interface{}{"WS01", "2025-01-15 23:59:59"}},
+		{"between dates", FilterSet{FilterGroup: FilterGroup{Filters: []Filter{
+			{Field: "datetime", Operator: "between", Value: "2025-01-15,2025-01-16"},
+		}}}, "(datetime BETWEEN ? AND ?)", []interface{}{"2025-01-15 00:00:00", "2025-01-16 23:59:59"}},
+		{"invalid filters skipped", FilterSet{FilterGroup: FilterGroup{Filters: []Filter{
+			{Field: "host); DROP TABLE log2timeline; --", Operator: "=", Value: "x"},
+			{Field: "host", Operator: "~=", Value: "x"},
+			{Field: "user", Operator: "like", Value: "adm"},
+		}}}, "(user LIKE ?)", []interface{}{"%adm%"}},
+		{"groups, search and bookmark", FilterSet{
+			FilterGroup: FilterGroup{Logic: "or",
+				Filters: []Filter{{Field: "source", Operator: "=", Value: "REG"}},
+				Groups: []FilterGroup{{Filters: []Filter{
+					{Field: "source", Operator: "=", Value: "EVT"},
+					{Field: "event_identifier", Operator: "=", Value: "4624"},
+				}}, {}},
+			},
+			SearchText:   "mimikatz",
+			BookmarkOnly: true,
+		}, "((((source = ?) OR ((source = ?) AND (event_identifier = ?))) AND " + search + ") AND (bookmark = ?))", nil},
+	}
+	for _, tt := range tests {
+		sql, args := tt.set.Predicate(expand).WhereClause()
+		if sql != tt.sql {
+			t.Errorf("%s: SQL = %s, want %s", tt.name, sql, tt.sql)
+		}
+		if tt.args != nil && !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: args = %v, want %v", tt.name, args, tt.args)
+		}
+	}
+}
+
+func TestFilterPredicateWithoutExpander(t *testing.T) {
+	p := Filter{Field: "datetime", Operator: ">=", Value: "2025-01"}.Predicate(nil)
+	if sql, args := p.WhereClause(); sql != "(datetime >= ?)" || !reflect.DeepEqual(args, []interface{}{"2025-01"}) {
+		t.Errorf("got %s %v", sql, args)
+	}
+}
+
+func TestChartable(t *testing.T) {
+	sql, args := Chartable().WhereClause()
+	if sql != "((datetime > ?) AND (datetime < ?))" || !reflect.DeepEqual(args, []interface{}{"1970-01-01", "2100-01-01"}) {
+		t.Errorf("Chartable() = %s %v", sql, args)
+	}
+}
@@ -0,0 +1,269 @@
+package query
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cdtdelta/4n6time/internal/model"
+)
+
+// PageMode selects how a Query paginates its results.
+type PageMode int
+
+const (
+	// PageOffset uses LIMIT/OFFSET with the page set by SetPage (default).
+	PageOffset PageMode = iota
+	// PageFirst returns the first page in sort order.
+	PageFirst
+	// PageLast returns the last page in sort order.
+	PageLast
+	// PageAfter returns the page following the cursor.
+	PageAfter
+	// PageBefore returns the page preceding the cursor.
+	PageBefore
+)
+
+// Cursor marks a position in a result set sorted by the sort keys and id.
+// OrderBy names the sort the cursor was taken under, and Values holds the
+// row's value for each sort key before the id. Cursors are handed to clients
+// as opaque strings via Encode.
+type Cursor struct {
+	OrderBy string        `json:"o,omitempty"`
+	Values  []interface{} `json:"v"`
+	ID      int64         `json:"i"`
+}
+
+// Encode returns the cursor as an opaque URL-safe string.
+func (c *Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses a string produced by Cursor.Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var c Cursor
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	for i, v := range c.Values {
+		if n, ok := v.(json.Number); ok {
+			iv, err := strconv.ParseInt(string(n), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cursor value: %s", n)
+			}
+			c.Values[i] = iv
+		}
+	}
+	return &c, nil
+}
+
+// SeekCursor returns a cursor that, used with PageAfter, starts the page at
+// the first row whose orderBy column is greater than or equal to value. It
+// is used to jump straight to a datetime without counting the rows before it.
+func SeekCursor(orderBy, value string) *Cursor {
+	return &Cursor{OrderBy: orderBy, Values: []interface{}{value}, ID: math.MinInt64}
+}
+
+// SetKeyset switches the query to keyset pagination, which seeks directly to
+// a page through the (sort keys, id) index instead of scanning and discarding
+// OFFSET rows. Any sort set by OrderBy or SetSort can be followed, ascending
+// or descending. PageAfter and PageBefore need a cursor from CursorFor or
+// SeekCursor taken under the same sort; set the sort first.
+func (q *Query) SetKeyset(mode PageMode, c *Cursor) error {
+	if mode == PageAfter || mode == PageBefore {
+		if c == nil {
+			return fmt.Errorf("keyset page requires a cursor")
+		}
+		if c.OrderBy != q.sortOrder() {
+			return fmt.Errorf("cursor is for sort %q, not %q", c.OrderBy, q.sortOrder())
+		}
+		if keys, _ := q.keysetKeys(); len(c.Values) != len(keys) {
+			return fmt.Errorf("cursor has %d sort values, want %d", len(c.Values), len(keys))
+		}
+	}
+	q.pageMode = mode
+	q.cursor = c
+	return nil
+}
+
+// CursorFor returns the cursor positioned at e under the current sort order.
+func (q *Query) CursorFor(e *model.Event) *Cursor {
+	c := &Cursor{OrderBy: q.sortOrder(), Values: []interface{}{}, ID: e.ID}
+	keys, _ := q.keysetKeys()
+	for _, k := range keys {
+		c.Values = append(c.Values, q.cursorValue(e, k.Field))
+	}
+	return c
+}
+
+// cursorValue returns e's value for the sort column col as the database
+// compares it.
+func (q *Query) cursorValue(e *model.Event, col string) interface{} {
+	if col != "datetime" {
+		return e.Value(col)
+	}
+	// Drivers render datetimes as RFC 3339 ("2025-01-15T10:30:00Z"), but
+	// SQLite compares the stored "2025-01-15 10:30:00" text.
+	dt := strings.TrimSuffix(strings.Replace(e.Datetime, "T", " ", 1), "Z")
+	if _, ok := q.dialect.(TimestampDialect); ok && dt == "" {
+		return nil
+	}
+	return dt
+}
+
+// sortOrder names the sort order, as "datetime" or "host DESC,datetime", so a
+// cursor can be checked against the sort it was taken under.
+func (q *Query) sortOrder() string {
+	parts := make([]string, len(q.sort))
+	for i, k := range q.sort {
+		parts[i] = k.Field
+		if k.Desc {
+			parts[i] += " DESC"
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// keysetKeys returns the sort keys preceding the id tiebreaker, and whether
+// the id sorts descending. Keys after an explicit id sort are ignored, as
+// they are by orderClause.
+func (q *Query) keysetKeys() ([]SortKey, bool) {
+	idCol := q.dialect.IDColumn()
+	for i, k := range q.sort {
+		if k.Field == idCol {
+			return q.sort[:i], k.Desc
+		}
+	}
+	return q.sort, false
+}
+
+// buildKeyset turns base, a SELECT with its WHERE clause, into a keyset page:
+//
+//	SELECT * FROM (SELECT * FROM (
+//	    SELECT id AS id, ... FROM log2timeline WHERE ... AND <after cursor>
+//	    ORDER BY keys, id LIMIT n) AS page) AS combined
+//	WHERE <after cursor> ORDER BY keys, id LIMIT n
+//
+// The inner page reads at most n rows through the index. The outer query
+// repeats the cursor condition and limit so rows added next to the page (the
+// database package unions examiner notes in before ") AS combined") are
+// paginated in the same order. Backward pages are read in reverse and then
+// re-sorted, so results are always in the sort order.
+func (q *Query) buildKeyset(base string, args []interface{}, next int) (string, []interface{}) {
+	d := q.dialect
+	idCol := d.IDColumn()
+	keys, idDesc := q.keysetKeys()
+	backward := q.pageMode == PageLast || q.pageMode == PageBefore
+
+	inner := "SELECT " + idCol + " AS id" + strings.TrimPrefix(base, "SELECT "+idCol)
+	var outerWhere string
+	if q.pageMode == PageAfter || q.pageMode == PageBefore {
+		innerCond, innerArgs, n := keysetPredicate(d, keys, idDesc, idCol, q.cursor, backward, next)
+		outerCond, outerArgs, _ := keysetPredicate(d, keys, idDesc, "id", q.cursor, backward, n)
+		if i := strings.Index(inner, " WHERE "); i >= 0 {
+			// Parenthesize the existing clause: a raw WHERE may contain a top-level OR
+			inner = inner[:i] + " WHERE (" + inner[i+len(" WHERE "):] + ") AND " + innerCond
+		} else {
+			inner += " WHERE " + innerCond
+		}
+		outerWhere = " WHERE " + outerCond
+		args = append(append(args, innerArgs...), outerArgs...)
+	}
+
+	limit := ""
+	if q.pageSize > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", q.pageSize)
+	}
+	inner += " ORDER BY " + keysetOrder(d, keys, idDesc, idCol, backward) + limit
+	sql := "SELECT * FROM (SELECT * FROM (" + inner + ") AS page) AS combined" +
+		outerWhere + " ORDER BY " + keysetOrder(d, keys, idDesc, "id", backward) + limit
+	if backward {
+		sql = "SELECT * FROM (" + sql + ") AS reversed ORDER BY " + keysetOrder(d, keys, idDesc, "id", false)
+	}
+	return sql, args
+}
+
+// keysetOrder returns the ORDER BY list for (keys, id), reversed when
+// reverse is set.
+func keysetOrder(d QueryDialect, keys []SortKey, idDesc bool, idCol string, reverse bool) string {
+	parts := make([]string, 0, len(keys)+1)
+	for _, k := range keys {
+		parts = append(parts, d.QuoteColumn(k.Field)+descSQL(k.Desc != reverse))
+	}
+	return strings.Join(append(parts, idCol+descSQL(idDesc != reverse)), ", ")
+}
+
+func descSQL(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return ""
+}
+
+// keysetPredicate returns the condition selecting rows after the cursor in
+// (keys, id) order, or before it when before is true.
+func keysetPredicate(d QueryDialect, keys []SortKey, idDesc bool, idCol string, c *Cursor, before bool, paramIdx int) (string, []interface{}, int) {
+	sql, args, n := keysetCondition(d, keys, c.Values, idDesc, idCol, c.ID, before, paramIdx)
+	if len(keys) == 0 {
+		sql = "(" + sql + ")"
+	}
+	return sql, args, n
+}
+
+// keysetCondition compares the row with the cursor values one key at a time:
+// a row is past the cursor if it is past it on the first key, or ties there
+// and is past it on the remaining keys. NULL sort values are placed according
+// to the dialect's NULL ordering so that paging backward visits exactly the
+// rows paging forward would.
+func keysetCondition(d QueryDialect, keys []SortKey, values []interface{}, idDesc bool, idCol string, id int64, before bool, paramIdx int) (string, []interface{}, int) {
+	if len(keys) == 0 {
+		return idCol + " " + keysetCmp(idDesc, before) + " " + d.Placeholder(paramIdx), []interface{}{id}, paramIdx + 1
+	}
+
+	nullsLast := false
+	if td, ok := d.(TimestampDialect); ok {
+		nullsLast = td.NullsLast()
+	}
+	k, v := keys[0], values[0]
+	// nullsBeyond reports whether NULL rows lie past a non-NULL cursor in the
+	// paging direction; otherwise non-NULL rows lie past a NULL cursor. A
+	// descending sort moves the NULLs to the other end.
+	nullsBeyond := nullsLast != k.Desc != before
+	qc := d.QuoteColumn(k.Field)
+
+	if v == nil {
+		rest, args, n := keysetCondition(d, keys[1:], values[1:], idDesc, idCol, id, before, paramIdx)
+		sql := "(" + qc + " IS NULL AND " + rest + ")"
+		if !nullsBeyond {
+			sql = "(" + sql + " OR " + qc + " IS NOT NULL)"
+		}
+		return sql, args, n
+	}
+
+	rest, args, n := keysetCondition(d, keys[1:], values[1:], idDesc, idCol, id, before, paramIdx+2)
+	sql := fmt.Sprintf("(%s %s %s OR (%s = %s AND %s)", qc, keysetCmp(k.Desc, before), d.Placeholder(paramIdx),
+		qc, d.Placeholder(paramIdx+1), rest)
+	if nullsBeyond {
+		sql += " OR " + qc + " IS NULL"
+	}
+	return sql + ")", append([]interface{}{v, v}, args...), n
+}
+
+// keysetCmp returns the operator selecting values past the cursor on a key.
+func keysetCmp(desc, before bool) string {
+	if desc != before {
+		return "<"
+	}
+	return ">"
+}
@@ -0,0 +1,232 @@
+package query
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/cdtdelta/4n6time/internal/model"
+)
+
+// timestampDialect is a test dialect with native timestamps and NULLs last.
+type timestampDialect struct{ sqliteQueryDialect }
+
+func (d timestampDialect) NullsLast() bool { return true }
+
+func TestKeysetFirstPage(t *testing.T) {
+	q := New(50)
+	q.OrderBy("datetime")
+	q.SetKeyset(PageFirst, nil)
+	sql, args := q.Build()
+
+	want := "SELECT * FROM (SELECT * FROM (SELECT rowid AS id, datetime,"
+	if !strings.HasPrefix(sql, want) {
+		t.Errorf("expected keyset subquery, got: %s", sql)
+	}
+	if !strings.Contains(sql, "FROM log2timeline ORDER BY datetime, rowid LIMIT 50) AS page) AS combined ORDER BY datetime, id LIMIT 50") {
+		t.Errorf("expected inner and outer ordering with id tiebreaker, got: %s", sql)
+	}
+	if strings.Contains(sql, "OFFSET") || len(args) != 0 {
+		t.Errorf("expected no OFFSET or args, got: %s %v", sql, args)
+	}
+}
+
+func TestKeysetAfterCursor(t *testing.T) {
+	q := New(50)
+	q.OrderBy("host")
+	q.AddPredicate(Simple("source", Equal, "REG"))
+	if err := q.SetKeyset(PageAfter, &Cursor{OrderBy: "host", Values: []interface{}{"PC1"}, ID: 7}); err != nil {
+		t.Fatalf("SetKeyset failed: %v", err)
+	}
+	sql, args := q.Build()
+
+	if !strings.Contains(sql, "WHERE ((source = ?)) AND (host > ? OR (host = ? AND rowid > ?)) ORDER BY host, rowid LIMIT 50") {
+		t.Errorf("expected cursor condition on inner page, got: %s", sql)
+	}
+	if !strings.Contains(sql, "AS combined WHERE (host > ? OR (host = ? AND id > ?)) ORDER BY host, id LIMIT 50") {
+		t.Errorf("expected cursor condition on combined rows, got: %s", sql)
+	}
+	if len(args) != 7 || args[0] != "REG" || args[3] != int64(7) || args[6] != int64(7) {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestKeysetBackwardIsResorted(t *testing.T) {
+	q := New(10)
+	q.OrderBy("datetime")
+	q.SetKeyset(PageLast, nil)
+	sql, _ := q.Build()
+
+	if !strings.Contains(sql, "ORDER BY datetime DESC, rowid DESC LIMIT 10") {
+		t.Errorf("expected descending inner page, got: %s", sql)
+	}
+	if !strings.HasSuffix(sql, ") AS reversed ORDER BY datetime, id") {
+		t.Errorf("expected ascending re-sort, got: %s", sql)
+	}
+}
+
+func TestKeysetParenthesizesWhere(t *testing.T) {
+	q := New(10)
+	q.SetLogic(OR)
+	q.AddPredicate(Compare("host", Equal, "A"))
+	q.AddPredicate(Compare("host", Equal, "B"))
+	q.OrderBy("datetime")
+	q.SetKeyset(PageAfter, SeekCursor("datetime", "2025-01-15 00:00:00"))
+	sql, args := q.Build()
+
+	if !strings.Contains(sql, "WHERE (((host = ?) OR (host = ?))) AND (datetime > ?") {
+		t.Errorf("expected the filter in parentheses, got: %s", sql)
+	}
+	if len(args) != 8 || args[4] != int64(math.MinInt64) {
+		t.Errorf("expected filter and seek args, got %v", args)
+	}
+}
+
+func TestKeysetCursorMismatch(t *testing.T) {
+	q := New(10)
+	q.OrderBy("host")
+	if err := q.SetKeyset(PageAfter, &Cursor{OrderBy: "datetime", Values: []interface{}{"x"}}); err == nil {
+		t.Error("expected error for cursor from another sort column")
+	}
+	if err := q.SetKeyset(PageAfter, &Cursor{OrderBy: "host"}); err == nil {
+		t.Error("expected error for cursor without sort values")
+	}
+	if err := q.SetKeyset(PageBefore, nil); err == nil {
+		t.Error("expected error for missing cursor")
+	}
+}
+
+func TestKeysetIDOnly(t *testing.T) {
+	q := New(10)
+	c := q.CursorFor(&model.Event{ID: 9, Datetime: "2025-01-15 00:00:00"})
+	q.SetKeyset(PageAfter, c)
+	sql, args := q.Build()
+
+	if !strings.Contains(sql, "WHERE (rowid > ?) ORDER BY rowid LIMIT 10") || len(args) != 2 {
+		t.Errorf("expected id-only keyset, got: %s %v", sql, args)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	q := New(10)
+	q.OrderBy("offset")
+	c, err := DecodeCursor(q.CursorFor(&model.Event{ID: -3, Offset: 4096}).Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor failed: %v", err)
+	}
+	if c.OrderBy != "offset" || c.Values[0] != int64(4096) || c.ID != -3 {
+		t.Errorf("cursor did not round-trip: %+v", c)
+	}
+
+	q.OrderBy("datetime")
+	c, _ = DecodeCursor(q.CursorFor(&model.Event{ID: 1, Datetime: "2025-01-15T10:30:00Z"}).Encode())
+	if c.Values[0] != "2025-01-15 10:30:00" {
+		t.Errorf("expected normalized datetime, got %v", c.Values[0])
+	}
+
+	if _, err := DecodeCursor("not a cursor!"); err == nil {
+		t.Error("expected error for malformed cursor")
+	}
+}
+
+func TestKeysetNullDatetime(t *testing.T) {
+	q := New(10)
+	q.SetDialect(timestampDialect{})
+	q.OrderBy("datetime")
+	c := q.CursorFor(&model.Event{ID: 5})
+	if c.Values[0] != nil {
+		t.Fatalf("expected empty datetime to be NULL, got %v", c.Values[0])
+	}
+
+	// NULLs last: only NULLs with a higher id follow a NULL cursor
+	q.SetKeyset(PageAfter, c)
+	sql, _ := q.Build()
+	if !strings.Contains(sql, "(datetime IS NULL AND rowid > ?) ORDER BY") {
+		t.Errorf("unexpected forward NULL condition: %s", sql)
+	}
+	// ...and every non-NULL row precedes it
+	q.SetKeyset(PageBefore, c)
+	sql, _ = q.Build()
+	if !strings.Contains(sql, "((datetime IS NULL AND rowid < ?) OR datetime IS NOT NULL)") {
+		t.Errorf("unexpected backward NULL condition: %s", sql)
+	}
+	// NULLs follow every non-NULL cursor
+	q.SetKeyset(PageAfter, &Cursor{OrderBy: "datetime", Values: []interface{}{"2025-01-15 00:00:00"}, ID: 1})
+	sql, _ = q.Build()
+	if !strings.Contains(sql, "rowid > ?) OR datetime IS NULL)") {
+		t.Errorf("expected NULLs after non-NULL cursor: %s", sql)
+	}
+}
+
+func TestKeysetDescending(t *testing.T) {
+	q := New(10)
+	q.SetSort(SortKey{Field: "datetime", Desc: true})
+	c := q.CursorFor(&model.Event{ID: 4, Datetime: "2025-01-15 10:30:00"})
+	if c.OrderBy != "datetime DESC" {
+		t.Errorf("expected the cursor to name the descending sort, got %q", c.OrderBy)
+	}
+	if err := q.SetKeyset(PageAfter, c); err != nil {
+		t.Fatalf("SetKeyset failed: %v", err)
+	}
+	sql, _ := q.Build()
+	// SQLite sorts NULLs first, so last when descending
+	if !strings.Contains(sql, "WHERE (datetime < ? OR (datetime = ? AND rowid > ?) OR datetime IS NULL) ORDER BY datetime DESC, rowid LIMIT 10") {
+		t.Errorf("expected descending cursor condition, got: %s", sql)
+	}
+
+	// Backward pages run ascending and are re-sorted descending
+	q.SetKeyset(PageBefore, c)
+	sql, _ = q.Build()
+	if !strings.Contains(sql, "WHERE (datetime > ? OR (datetime = ? AND rowid < ?)) ORDER BY datetime, rowid DESC LIMIT 10") {
+		t.Errorf("expected ascending backward page, got: %s", sql)
+	}
+	if !strings.HasSuffix(sql, ") AS reversed ORDER BY datetime DESC, id") {
+		t.Errorf("expected descending re-sort, got: %s", sql)
+	}
+}
+
+func TestKeysetMultiColumn(t *testing.T) {
+	q := New(10)
+	q.SetSort(SortKey{Field: "host"}, SortKey{Field: "datetime", Desc: true})
+	c, err := DecodeCursor(q.CursorFor(&model.Event{ID: 4, Host: "PC1", Datetime: "2025-01-15 10:30:00"}).Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor failed: %v", err)
+	}
+	if err := q.SetKeyset(PageAfter, c); err != nil {
+		t.Fatalf("SetKeyset failed: %v", err)
+	}
+	sql, args := q.Build()
+	want := "WHERE (host > ? OR (host = ? AND (datetime < ? OR (datetime = ? AND rowid > ?) OR datetime IS NULL))) ORDER BY host, datetime DESC, rowid LIMIT 10"
+	if !strings.Contains(sql, want) {
+		t.Errorf("expected compound cursor condition, got: %s", sql)
+	}
+	if len(args) != 10 || args[0] != "PC1" || args[2] != "2025-01-15 10:30:00" || args[4] != int64(4) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	// A cursor from the single-column sort does not fit
+	q.OrderBy("host")
+	if err := q.SetKeyset(PageAfter, c); err == nil {
+		t.Error("expected error for cursor from another sort")
+	}
+}
+
+func TestKeysetDescendingNulls(t *testing.T) {
+	q := New(10)
+	q.SetDialect(timestampDialect{})
+	q.SetSort(SortKey{Field: "datetime", Desc: true})
+
+	// NULLs sort last ascending, so first descending: none follow a NULL...
+	c := q.CursorFor(&model.Event{ID: 5})
+	q.SetKeyset(PageAfter, c)
+	sql, _ := q.Build()
+	if !strings.Contains(sql, "((datetime IS NULL AND rowid > ?) OR datetime IS NOT NULL)") {
+		t.Errorf("expected non-NULL rows after a NULL cursor: %s", sql)
+	}
+	// ...and none follow a non-NULL cursor
+	q.SetKeyset(PageAfter, &Cursor{OrderBy: "datetime DESC", Values: []interface{}{"2025-01-15 00:00:00"}, ID: 1})
+	sql, _ = q.Build()
+	if strings.Contains(sql, "datetime IS NULL") {
+		t.Errorf("expected no NULLs after a non-NULL cursor: %s", sql)
+	}
+}
@@ -0,0 +1,41 @@
+package query
+
+// Named parameters let an advanced search such as
+//
+//	user = :username AND datetime >= :start
+//
+// be stored once and run with different values. A parameter is a colon
+// followed by a letter or underscore and then letters, digits or
+// underscores. Text inside quoted strings ('10:00:00') is never a parameter.
+
+// ParamNames returns the names of the parameters in advanced search input,
+// in order of first use. It reads the same tokens ParseWithParams does, so
+// the names are exactly the ones the parse will ask params for. Errors are
+// the tokenizer's *SyntaxError values.
+func ParamNames(input string) ([]string, error) {
+	var names []string
+	seen := make(map[string]bool)
+	p := &parser{input: input}
+	for {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		switch p.tok.kind {
+		case tokEOF:
+			return names, nil
+		case tokParam:
+			if !seen[p.tok.text] {
+				seen[p.tok.text] = true
+				names = append(names, p.tok.text)
+			}
+		}
+	}
+}
+
+func isParamStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isParamChar(c byte) bool {
+	return isParamStart(c) || (c >= '0' && c <= '9')
+}
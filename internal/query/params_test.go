@@ -0,0 +1,47 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// numberedDialect is a test dialect with PostgreSQL-style placeholders.
+type numberedDialect struct{ sqliteQueryDialect }
+
+func (d numberedDialect) Placeholder(index int) string { return fmt.Sprintf("$%d", index) }
+
+func TestParamNames(t *testing.T) {
+	where := "user = :username AND (host = :host OR host = :host) AND datetime >= :start_1"
+	want := []string{"username", "host", "start_1"}
+	got, err := ParamNames(where)
+	if err != nil {
+		t.Fatalf("ParamNames: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParamNames = %v, want %v", got, want)
+	}
+}
+
+func TestParamNamesIgnoresStrings(t *testing.T) {
+	where := "datetime >= '2025-01-15 10:00:00' AND desc LIKE '%:notparam%' AND inode = :inode"
+	got, err := ParamNames(where)
+	if err != nil {
+		t.Fatalf("ParamNames: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"inode"}) {
+		t.Errorf("ParamNames = %v, want [inode]", got)
+	}
+}
+
+func TestParamNamesRejectsWhatParseRejects(t *testing.T) {
+	for _, where := range []string{"inode::text = 1", "host = 'open", "host = :"} {
+		_, err := ParamNames(where)
+		if _, ok := err.(*SyntaxError); !ok {
+			t.Errorf("ParamNames(%q) error = %v, want a *SyntaxError", where, err)
+		}
+		if _, err := ParseWithParams(where, map[string]string{"text": "x"}); err == nil {
+			t.Errorf("ParseWithParams(%q) succeeded", where)
+		}
+	}
+}
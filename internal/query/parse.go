@@ -0,0 +1,453 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cdtdelta/4n6time/internal/model"
+)
+
+// Parse compiles the advanced search syntax into a predicate tree. The
+// syntax is the WHERE clause subset analysts write by hand:
+//
+//	expr       = term { OR term }
+//	term       = factor { AND factor }
+//	factor     = NOT factor | "(" expr ")" | comparison
+//	comparison = field ( cmp value
+//	                   | [NOT] LIKE value
+//	                   | [NOT] IN "(" value { "," value } ")"
+//	                   | [NOT] BETWEEN value AND value
+//	                   | IS [NOT] EMPTY )
+//	cmp        = "=" | "==" | "!=" | "<>" | "<" | "<=" | ">" | ">="
+//	value      = 'quoted string' | number | :parameter
+//
+// Keywords are case-insensitive. Fields are matched case-insensitively
+// against model.Fields and may be quoted as "user" or `user`. Strings escape
+// a quote by doubling it. IS NULL is accepted as a synonym for IS EMPTY.
+// Values become placeholders, so nothing typed reaches the SQL text except
+// known column names. Blank input returns a nil predicate.
+//
+// Errors are *SyntaxError values carrying the column of the problem.
+func Parse(input string) (*Predicate, error) {
+	return ParseWithParams(input, nil)
+}
+
+// ParseWithParams is Parse with :name parameters taking their values from
+// params. It is an error for the input to use a parameter params lacks.
+func ParseWithParams(input string, params map[string]string) (*Predicate, error) {
+	p := &parser{input: input, params: params}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokEOF {
+		return nil, nil
+	}
+	pred, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok.describe())
+	}
+	return pred, nil
+}
+
+// SyntaxError reports a problem in advanced search input. Column counts
+// characters from 1.
+type SyntaxError struct {
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokQuotedIdent
+	tokString
+	tokNumber
+	tokParam
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string // identifier, operator, parameter name or unquoted string value
+	pos  int    // byte offset in the input
+}
+
+// describe names the token for error messages.
+func (t token) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of input"
+	case tokString:
+		return fmt.Sprintf("string '%s'", t.text)
+	case tokParam:
+		return "parameter :" + t.text
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// keyword reports whether the token is the given keyword.
+func (t token) keyword(kw string) bool {
+	return t.kind == tokIdent && strings.EqualFold(t.text, kw)
+}
+
+type parser struct {
+	input  string
+	pos    int
+	tok    token
+	params map[string]string
+}
+
+func (p *parser) errorAt(pos int, format string, args ...interface{}) error {
+	return &SyntaxError{
+		Column: utf8.RuneCountInString(p.input[:pos]) + 1,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+// errorf reports a problem at the current token.
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return p.errorAt(p.tok.pos, format, args...)
+}
+
+// advance reads the next token into p.tok.
+func (p *parser) advance() error {
+	s := p.input
+	for p.pos < len(s) && strings.IndexByte(" \t\r\n", s[p.pos]) >= 0 {
+		p.pos++
+	}
+	start := p.pos
+	if start >= len(s) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return nil
+	}
+
+	c := s[start]
+	switch {
+	case c == '(':
+		p.pos++
+		p.tok = token{kind: tokLParen, text: "(", pos: start}
+	case c == ')':
+		p.pos++
+		p.tok = token{kind: tokRParen, text: ")", pos: start}
+	case c == ',':
+		p.pos++
+		p.tok = token{kind: tokComma, text: ",", pos: start}
+	case strings.IndexByte("=!<>", c) >= 0:
+		op := s[start : start+1]
+		if start+1 < len(s) {
+			switch two := s[start : start+2]; two {
+			case "==", "!=", "<>", "<=", ">=":
+				op = two
+			}
+		}
+		if op == "!" {
+			return p.errorAt(start, "unexpected character '!'")
+		}
+		p.pos += len(op)
+		p.tok = token{kind: tokOp, text: op, pos: start}
+	case c == '\'':
+		var b strings.Builder
+		i := start + 1
+		for {
+			if i >= len(s) {
+				return p.errorAt(start, "unterminated string")
+			}
+			if s[i] == '\'' {
+				if i+1 < len(s) && s[i+1] == '\'' {
+					b.WriteByte('\'')
+					i += 2
+					continue
+				}
+				break
+			}
+			b.WriteByte(s[i])
+			i++
+		}
+		p.pos = i + 1
+		p.tok = token{kind: tokString, text: b.String(), pos: start}
+	case c == '"' || c == '`':
+		end := strings.IndexByte(s[start+1:], c)
+		if end < 0 {
+			return p.errorAt(start, "unterminated quoted field name")
+		}
+		p.pos = start + 1 + end + 1
+		p.tok = token{kind: tokQuotedIdent, text: s[start+1 : start+1+end], pos: start}
+	case c == ':':
+		i := start + 1
+		if i >= len(s) || !isParamStart(s[i]) {
+			return p.errorAt(start, "expected a parameter name after ':'")
+		}
+		for i < len(s) && isParamChar(s[i]) {
+			i++
+		}
+		p.pos = i
+		p.tok = token{kind: tokParam, text: s[start+1 : i], pos: start}
+	case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		i := start
+		if c == '-' {
+			i++
+		}
+		digits := 0
+		for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+			if s[i] != '.' {
+				digits++
+			}
+			i++
+		}
+		if digits == 0 || (i < len(s) && isParamChar(s[i])) {
+			return p.errorAt(start, "invalid number")
+		}
+		p.pos = i
+		p.tok = token{kind: tokNumber, text: s[start:i], pos: start}
+	case isParamStart(c):
+		i := start
+		for i < len(s) && isParamChar(s[i]) {
+			i++
+		}
+		p.pos = i
+		p.tok = token{kind: tokIdent, text: s[start:i], pos: start}
+	default:
+		r, _ := utf8.DecodeRuneInString(s[start:])
+		return p.errorAt(start, "unexpected character %q", r)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (*Predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.keyword("OR") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Combine([]*Predicate{left, right}, OR)
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (*Predicate, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.keyword("AND") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = Combine([]*Predicate{left, right}, AND)
+	}
+	return left, nil
+}
+
+func (p *parser) parseFactor() (*Predicate, error) {
+	switch {
+	case p.tok.keyword("NOT"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return Not(inner), nil
+	case p.tok.kind == tokLParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("expected ')' but found %s", p.tok.describe())
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (*Predicate, error) {
+	field, err := p.parseField()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokOp {
+		op := Operator(p.tok.text)
+		switch op {
+		case "==":
+			op = Equal
+		case "<>":
+			op = NotEqual
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return Compare(field, op, value), nil
+	}
+
+	if p.tok.keyword("IS") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		negate := p.tok.keyword("NOT")
+		if negate {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+		if !p.tok.keyword("EMPTY") && !p.tok.keyword("NULL") {
+			return nil, p.errorf("expected EMPTY after IS but found %s", p.tok.describe())
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return negateIf(IsEmpty(field), negate), nil
+	}
+
+	negate := p.tok.keyword("NOT")
+	if negate {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case p.tok.keyword("LIKE"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if negate {
+			return Compare(field, NotLike, value), nil
+		}
+		return Compare(field, Like, value), nil
+
+	case p.tok.keyword("IN"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokLParen {
+			return nil, p.errorf("expected '(' after IN but found %s", p.tok.describe())
+		}
+		var values []string
+		for {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			if p.tok.kind != tokComma {
+				break
+			}
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("expected ',' or ')' but found %s", p.tok.describe())
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return negateIf(In(field, values...), negate), nil
+
+	case p.tok.keyword("BETWEEN"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		low, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if !p.tok.keyword("AND") {
+			return nil, p.errorf("expected AND in BETWEEN but found %s", p.tok.describe())
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		high, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return negateIf(Between(field, low, high), negate), nil
+	}
+
+	if negate {
+		return nil, p.errorf("expected LIKE, IN or BETWEEN after NOT but found %s", p.tok.describe())
+	}
+	return nil, p.errorf("expected an operator after %s but found %s", field, p.tok.describe())
+}
+
+// parseField reads a field name and returns its canonical spelling.
+func (p *parser) parseField() (string, error) {
+	if p.tok.kind != tokIdent && p.tok.kind != tokQuotedIdent {
+		return "", p.errorf("expected a field name but found %s", p.tok.describe())
+	}
+	for _, f := range model.Fields {
+		if strings.EqualFold(f, p.tok.text) {
+			return f, p.advance()
+		}
+	}
+	return "", p.errorf("unknown field %q", p.tok.text)
+}
+
+// parseValue reads a string, number or parameter value.
+func (p *parser) parseValue() (string, error) {
+	var value string
+	switch p.tok.kind {
+	case tokString, tokNumber:
+		value = p.tok.text
+	case tokParam:
+		v, ok := p.params[p.tok.text]
+		if !ok {
+			return "", p.errorf("no value for parameter :%s", p.tok.text)
+		}
+		value = v
+	case tokIdent, tokQuotedIdent:
+		return "", p.errorf("expected a value but found %s; quote text values as '%s'", p.tok.describe(), p.tok.text)
+	default:
+		return "", p.errorf("expected a value but found %s", p.tok.describe())
+	}
+	return value, p.advance()
+}
+
+// negateIf wraps pred in Not when negate is set.
+func negateIf(pred *Predicate, negate bool) *Predicate {
+	if negate {
+		return Not(pred)
+	}
+	return pred
+}
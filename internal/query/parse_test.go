@@ -0,0 +1,151 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		sql   string
+		args  []interface{}
+	}{
+		{"source = 'REG'", "(source = ?)", []interface{}{"REG"}},
+		{"SOURCE == 'REG'", "(source = ?)", []interface{}{"REG"}},
+		{`"desc" LIKE '%mimikatz%'`, "(desc LIKE ?)", []interface{}{"%mimikatz%"}},
+		{"host <> 'WS01' and offset > -5", "((host != ?) AND (offset > ?))", []interface{}{"WS01", "-5"}},
+		{"user = 'o''brien'", "(user = ?)", []interface{}{"o'brien"}},
+		{"event_identifier IN (4624, '4625')", "(event_identifier IN (?, ?))", []interface{}{"4624", "4625"}},
+		{"event_identifier NOT IN (4624)", "(NOT (event_identifier IN (?)))", []interface{}{"4624"}},
+		{"datetime BETWEEN '2025-01-01' AND '2025-01-31' AND host = 'A'",
+			"((datetime BETWEEN ? AND ?) AND (host = ?))", []interface{}{"2025-01-01", "2025-01-31", "A"}},
+		{"notes IS EMPTY", "(notes IS NULL OR notes = '')", nil},
+		{"bookmark is not null", "(NOT (bookmark IS NULL))", nil},
+		{"desc NOT LIKE 'x%'", "(desc NOT LIKE ?)", []interface{}{"x%"}},
+		{"NOT (host = 'A' OR host = 'B') AND type = 'x'",
+			"((NOT ((host = ?) OR (host = ?))) AND (type = ?))", []interface{}{"A", "B", "x"}},
+		{"host = 'A' OR host = 'B' AND type = 'x'",
+			"((host = ?) OR ((host = ?) AND (type = ?)))", []interface{}{"A", "B", "x"}},
+	}
+	for _, tt := range tests {
+		p, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.input, err)
+			continue
+		}
+		sql, args := p.WhereClause()
+		if sql != tt.sql || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("Parse(%q) = %s %v, want %s %v", tt.input, sql, args, tt.sql, tt.args)
+		}
+	}
+}
+
+func TestParseBlank(t *testing.T) {
+	if p, err := Parse("   "); p != nil || err != nil {
+		t.Errorf("Parse(blank) = %v, %v; want nil, nil", p, err)
+	}
+}
+
+func TestParseQuotesReservedWordsForDialect(t *testing.T) {
+	p, err := Parse("user = 'admin' AND desc LIKE 'a%'")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	sql, _, next := p.WhereClauseFor(quotingDialect{}, 1)
+	if sql != `(("user" = $1) AND ("desc" LIKE $2))` || next != 3 {
+		t.Errorf("unexpected SQL %s (next %d)", sql, next)
+	}
+}
+
+// quotingDialect quotes reserved words and numbers its placeholders.
+type quotingDialect struct{ numberedDialect }
+
+func (d quotingDialect) QuoteColumn(name string) string {
+	if name == "user" || name == "desc" || name == "offset" {
+		return `"` + name + `"`
+	}
+	return name
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		column int
+		msg    string
+	}{
+		{"source = 'REG'; DROP TABLE log2timeline", 15, "unexpected character ';'"},
+		{"source = (SELECT 1)", 10, "expected a value"},
+		{"nosuch = 'x'", 1, `unknown field "nosuch"`},
+		{"source = REG", 10, "quote text values"},
+		{"source = 'REG", 10, "unterminated string"},
+		{"(host = 'A'", 12, "expected ')'"},
+		{"host 'A'", 6, "expected an operator"},
+		{"host IN ()", 10, "expected a value"},
+		{"host BETWEEN 'a' OR 'b'", 18, "expected AND in BETWEEN"},
+		{"desc = 'é' | 'x'", 12, "unexpected character '|'"},
+		{"host = :who", 8, "no value for parameter :who"},
+		{"host = 'A' host = 'B'", 12, `unexpected "host"`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		var se *SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("Parse(%q) error = %v, want a SyntaxError", tt.input, err)
+			continue
+		}
+		if se.Column != tt.column || !strings.Contains(se.Msg, tt.msg) {
+			t.Errorf("Parse(%q) = %v, want column %d containing %q", tt.input, err, tt.column, tt.msg)
+		}
+	}
+}
+
+func TestParseWithParams(t *testing.T) {
+	p, err := ParseWithParams("user = :who AND datetime >= :start", map[string]string{"who": "admin", "start": "2025-01-01"})
+	if err != nil {
+		t.Fatalf("ParseWithParams failed: %v", err)
+	}
+	sql, args := p.WhereClause()
+	if sql != "((user = ?) AND (datetime >= ?))" || !reflect.DeepEqual(args, []interface{}{"admin", "2025-01-01"}) {
+		t.Errorf("unexpected clause %s %v", sql, args)
+	}
+}
+
+func TestNewPredicateFields(t *testing.T) {
+	p := Combine([]*Predicate{Not(In("host", "A")), Between("offset", "1", "2"), IsEmpty("notes")}, AND)
+	if got := p.Fields(); !reflect.DeepEqual(got, []string{"host", "offset", "notes"}) {
+		t.Errorf("Fields = %v", got)
+	}
+	if In("host") != nil || Compare("host", HasTag, "x") != nil || IsEmpty("nosuch") != nil {
+		t.Error("expected nil for invalid predicates")
+	}
+	if sql, _ := IsEmpty("offset").WhereClause(); sql != "(offset IS NULL)" {
+		t.Errorf("expected a NULL check for a numeric column, got %s", sql)
+	}
+}
+
+func TestExcludesSource(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"source = 'REG'", true},
+		{"source = 'examiner'", false},
+		{"source IN ('REG', 'FILE') AND host = 'A'", true},
+		{"source = 'REG' OR host = 'A'", false},
+		{"source = 'REG' OR source = 'FILE'", true},
+		{"NOT source = 'EXAMINER'", false},
+		{"source LIKE 'R%'", false},
+	}
+	for _, tt := range tests {
+		p, err := Parse(tt.input)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.input, err)
+		}
+		if got := p.ExcludesSource("EXAMINER"); got != tt.want {
+			t.Errorf("ExcludesSource(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
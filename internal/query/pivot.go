@@ -0,0 +1,60 @@
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/cdtdelta/4n6time/internal/model"
+)
+
+// URLDomain names the pivot on the host name in an event's URL, which
+// relates https://evil.example/a to http://evil.example:8080/b.
+const URLDomain = "url_domain"
+
+// PivotFields are the pivots offered for an event, in display order.
+var PivotFields = []string{"filename", "inode", "user_sid", URLDomain, "computer_name", "host", "user"}
+
+// Pivot returns the predicate matching the events that share e's value of
+// field, which is a column or URLDomain, along with that value. The
+// predicate is nil when e has no value to pivot on: an empty field, or
+// plaso's "-" placeholder. Returns an error for an unknown field.
+func Pivot(e *model.Event, field string) (*Predicate, string, error) {
+	if field == URLDomain {
+		host := URLHost(e.URL)
+		if host == "" {
+			return nil, "", nil
+		}
+		return hostPredicate(host), host, nil
+	}
+	if !isValidField(field) {
+		return nil, "", fmt.Errorf("unknown pivot field: %s", field)
+	}
+	value := fmt.Sprint(e.Value(field))
+	if v := strings.TrimSpace(value); v == "" || v == "-" {
+		return nil, "", nil
+	}
+	return Compare(field, Equal, value), value, nil
+}
+
+// URLHost returns the host name in a URL, without user info or port, or ""
+// if value is not a URL with a host.
+func URLHost(value string) string {
+	u, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// hostPredicate matches URLs on host, whatever their scheme, user info,
+// port, path, query or fragment.
+func hostPredicate(host string) *Predicate {
+	var preds []*Predicate
+	for _, prefix := range []string{"%://", "%://%@"} {
+		for _, suffix := range []string{"", "/%", ":%", "?%", "#%"} {
+			preds = append(preds, Compare("URL", Like, prefix+host+suffix))
+		}
+	}
+	return Combine(preds, OR)
+}
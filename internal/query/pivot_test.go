@@ -0,0 +1,64 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cdtdelta/4n6time/internal/model"
+)
+
+func TestPivot(t *testing.T) {
+	e := &model.Event{
+		Filename: `C:\Windows\evil.exe`,
+		Inode:    "-",
+		URL:      "https://user@Evil.example:8443/payload?x=1",
+		UserSID:  "  ",
+		Offset:   512,
+	}
+
+	p, value, err := Pivot(e, "filename")
+	if err != nil || value != `C:\Windows\evil.exe` {
+		t.Fatalf("Pivot(filename) = %q, %v", value, err)
+	}
+	if sql, args := p.WhereClause(); sql != "(filename = ?)" || !reflect.DeepEqual(args, []interface{}{`C:\Windows\evil.exe`}) {
+		t.Errorf("Pivot(filename) = %s %v", sql, args)
+	}
+
+	if _, value, _ := Pivot(e, "offset"); value != "512" {
+		t.Errorf("Pivot(offset) value = %q, want 512", value)
+	}
+
+	for _, field := range []string{"inode", "user_sid", "computer_name"} {
+		if p, _, err := Pivot(e, field); p != nil || err != nil {
+			t.Errorf("Pivot(%s) = %v, %v; want no pivot for a blank value", field, p, err)
+		}
+	}
+
+	if _, _, err := Pivot(e, "nosuch"); err == nil {
+		t.Error("expected an error for an unknown pivot field")
+	}
+}
+
+func TestPivotURLDomain(t *testing.T) {
+	e := &model.Event{URL: "https://user@Evil.example:8443/payload?x=1"}
+	p, value, err := Pivot(e, URLDomain)
+	if err != nil || value != "Evil.example" {
+		t.Fatalf("Pivot(url_domain) = %q, %v", value, err)
+	}
+	sql, args := p.WhereClause()
+	if strings.Count(sql, "(URL LIKE ?)") != 10 || strings.Count(sql, " OR ") != 9 {
+		t.Errorf("Pivot(url_domain) = %s", sql)
+	}
+	want := []interface{}{"%://Evil.example", "%://Evil.example/%", "%://Evil.example:%", "%://Evil.example?%", "%://Evil.example#%",
+		"%://%@Evil.example", "%://%@Evil.example/%", "%://%@Evil.example:%", "%://%@Evil.example?%", "%://%@Evil.example#%"}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("Pivot(url_domain) args = %v", args)
+	}
+
+	for _, u := range []string{"", "not a url", "file:///C:/x", "%zz"} {
+		if p, _, _ := Pivot(&model.Event{URL: u}, URLDomain); p != nil {
+			t.Errorf("Pivot(url_domain) of %q = %v, want nil", u, p)
+		}
+	}
+}
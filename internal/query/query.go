@@ -2,6 +2,7 @@ package query
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/cdtdelta/4n6time/internal/model"
@@ -25,26 +26,78 @@ const (
 	NotLike        Operator = "NOT LIKE"
 	GreaterOrEqual Operator = ">="
 	LessOrEqual    Operator = "<="
+	GreaterThan    Operator = ">"
+	LessThan       Operator = "<"
+
+	// HasTag and NotHasTag match events that do or do not carry the named
+	// tag, ignoring case, through the event_tags table. They apply only to
+	// the "tag" field.
+	HasTag    Operator = "HAS TAG"
+	NotHasTag Operator = "NOT HAS TAG"
+
+	// InList and NotInList match a comma-separated list of values, such as
+	// "4624, 4625, 4634". InRange matches the inclusive range "low, high".
+	InList    Operator = "IN"
+	NotInList Operator = "NOT IN"
+	InRange   Operator = "BETWEEN"
+
+	// Empty and NotEmpty match a field that is or is not NULL or, for text
+	// fields, the empty string. They ignore the value.
+	Empty    Operator = "IS EMPTY"
+	NotEmpty Operator = "IS NOT EMPTY"
+
+	// Regexp and NotRegexp match a regular expression anywhere in a text
+	// field, and LikeCase matches text fields containing the value exactly,
+	// case included. Regular expressions are case-sensitive; their syntax is
+	// the backend's (Go's RE2 on SQLite, POSIX on PostgreSQL, ICU or PCRE on
+	// MySQL and MariaDB), which agree on the common constructs.
+	Regexp    Operator = "REGEXP"
+	NotRegexp Operator = "NOT REGEXP"
+	LikeCase  Operator = "LIKE CASE"
 )
 
 // validOperators is the set of allowed operators for validation.
 var validOperators = map[Operator]bool{
 	Equal: true, NotEqual: true, Like: true, NotLike: true,
 	GreaterOrEqual: true, LessOrEqual: true,
+	HasTag: true, NotHasTag: true,
+	InList: true, NotInList: true, InRange: true, Empty: true, NotEmpty: true,
+	Regexp: true, NotRegexp: true, LikeCase: true,
+}
+
+// compareOperators is the set of operators accepted by Compare.
+var compareOperators = map[Operator]bool{
+	Equal: true, NotEqual: true, Like: true, NotLike: true,
+	GreaterOrEqual: true, LessOrEqual: true, GreaterThan: true, LessThan: true,
+}
+
+// ParseOperator returns the Operator spelled s, ignoring case and
+// surrounding space, and whether it is a recognized operator.
+func ParseOperator(s string) (Operator, bool) {
+	op := Operator(strings.ToUpper(strings.TrimSpace(s)))
+	return op, validOperators[op]
 }
 
 // Predicate represents a single filter condition or a composite of conditions.
 // Predicates use parameterized values to prevent SQL injection.
 type Predicate struct {
-	kind  predicateKind
-	field string
-	op    Operator
-	value string
-	date1 string
-	date2 string
-	left  *Predicate
-	right *Predicate
-	logic Logic
+	kind   predicateKind
+	field  string
+	op     Operator
+	value  string
+	values []string
+	date1  string
+	date2  string
+	left   *Predicate
+	right  *Predicate
+	logic  Logic
+
+	// numeric compares a TEXT column as a number (see numericTextFields)
+	numeric bool
+
+	// escaped marks a LIKE pattern from Pattern, which ignores case and
+	// takes backslash escapes
+	escaped bool
 }
 
 type predicateKind int
@@ -54,14 +107,46 @@ const (
 	predSimple
 	predDate
 	predComposite
+	predSearch
+	predCompare
+	predIn
+	predBetween
+	predEmpty
+	predNot
 )
 
 // Simple creates a predicate that compares a field to a value.
-// Returns nil if the field name is invalid or the operator is unrecognized.
+// Returns nil if the field name is invalid, the operator is unrecognized,
+// a tag operator is used on a field other than "tag", a text match operator
+// on a field that is not text, or a list or range has no values.
 func Simple(field string, op Operator, value string) *Predicate {
 	if !isValidField(field) || !validOperators[op] {
 		return nil
 	}
+	switch op {
+	case HasTag, NotHasTag:
+		if field != "tag" {
+			return nil
+		}
+	case Regexp, NotRegexp, LikeCase:
+		if !isTextField(field) {
+			return nil
+		}
+	case InList:
+		return In(field, splitList(value)...)
+	case NotInList:
+		return Not(In(field, splitList(value)...))
+	case InRange:
+		bounds := splitList(value)
+		if len(bounds) != 2 {
+			return nil
+		}
+		return Between(field, bounds[0], bounds[1])
+	case Empty:
+		return IsEmpty(field)
+	case NotEmpty:
+		return Not(IsEmpty(field))
+	}
 	return &Predicate{
 		kind:  predSimple,
 		field: field,
@@ -70,6 +155,79 @@ func Simple(field string, op Operator, value string) *Predicate {
 	}
 }
 
+// Compare creates a predicate that compares a field to a value as written:
+// unlike Simple, LIKE and NOT LIKE use value as the whole pattern. It accepts
+// =, !=, <, <=, >, >=, LIKE and NOT LIKE, and returns nil for an invalid
+// field or any other operator.
+//
+// <, <=, > and >= compare a column in numericTextFields as a number when
+// value is a whole number, so event_identifier > 4624 does not match "500".
+func Compare(field string, op Operator, value string) *Predicate {
+	if !isValidField(field) || !compareOperators[op] {
+		return nil
+	}
+	ordered := op == LessThan || op == LessOrEqual || op == GreaterThan || op == GreaterOrEqual
+	return &Predicate{kind: predCompare, field: field, op: op, value: value,
+		numeric: ordered && numericTextFields[field] && isWholeNumber(value)}
+}
+
+// Pattern creates a predicate matching a text field against a LIKE pattern,
+// ignoring case on every backend. A backslash in the pattern escapes the
+// %, _ or backslash after it; EscapeLike escapes literal text. Returns nil
+// for an invalid field or one that is not text.
+func Pattern(field, pattern string) *Predicate {
+	if !isValidField(field) || !isTextField(field) {
+		return nil
+	}
+	return &Predicate{kind: predCompare, field: field, op: Like, value: pattern, escaped: true}
+}
+
+// EscapeLike escapes the LIKE wildcards and backslashes in s for use in a
+// Pattern.
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// In creates a predicate matching events whose field equals one of values.
+// Returns nil for an invalid field or an empty list.
+func In(field string, values ...string) *Predicate {
+	if !isValidField(field) || len(values) == 0 {
+		return nil
+	}
+	return &Predicate{kind: predIn, field: field, values: values}
+}
+
+// Between creates a predicate matching events whose field lies between low
+// and high, inclusive. A column in numericTextFields is compared as a number
+// when both ends are whole numbers, so [4624 TO 4625] does not match
+// "46245". Returns nil for an invalid field.
+func Between(field, low, high string) *Predicate {
+	if !isValidField(field) {
+		return nil
+	}
+	return &Predicate{kind: predBetween, field: field, values: []string{low, high},
+		numeric: numericTextFields[field] && isWholeNumber(low) && isWholeNumber(high)}
+}
+
+// IsEmpty creates a predicate matching events whose field is NULL or, for
+// text columns, the empty string. Returns nil for an invalid field.
+func IsEmpty(field string) *Predicate {
+	if !isValidField(field) {
+		return nil
+	}
+	return &Predicate{kind: predEmpty, field: field}
+}
+
+// Not negates a predicate. Returns nil if p is nil.
+func Not(p *Predicate) *Predicate {
+	if p == nil {
+		return nil
+	}
+	return &Predicate{kind: predNot, left: p}
+}
+
 // DateRange creates a predicate filtering events between two datetimes (inclusive).
 func DateRange(date1, date2 string) *Predicate {
 	return &Predicate{
@@ -79,6 +237,21 @@ func DateRange(date1, date2 string) *Predicate {
 	}
 }
 
+// Search creates a predicate matching events whose searchable columns
+// (model.SearchFields) contain the given text. Dialects implementing
+// FullTextDialect route it through their full-text index, which understands
+// phrase, prefix and boolean syntax; other dialects OR together LIKE matches.
+// Returns nil for blank text.
+func Search(text string) *Predicate {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	return &Predicate{
+		kind:  predSearch,
+		value: text,
+	}
+}
+
 // Combine joins multiple predicates with the given logic (AND or OR).
 // Returns nil for an empty slice. Returns the single predicate if only one is given.
 // Nil predicates in the slice are skipped.
@@ -126,6 +299,14 @@ func (p *Predicate) WhereClause() (string, []interface{}) {
 	return sql, args
 }
 
+// WhereClauseFor generates the WHERE fragment using the specified dialect,
+// for callers that assemble their own SQL around a predicate.
+// startIdx is the 1-based index of the first placeholder; the returned int is
+// the next available index.
+func (p *Predicate) WhereClauseFor(d QueryDialect, startIdx int) (string, []interface{}, int) {
+	return p.whereClauseWithDialect(d, startIdx)
+}
+
 // whereClauseWithDialect generates the WHERE fragment using the specified dialect.
 // startIdx is the 1-based parameter index for numbered placeholder styles.
 // Returns the SQL fragment, parameter values, and the next available parameter index.
@@ -141,10 +322,30 @@ func (p *Predicate) whereClauseWithDialect(d QueryDialect, startIdx int) (string
 	case predSimple:
 		placeholder := d.Placeholder(startIdx)
 		quotedField := d.QuoteColumn(p.field)
+		if p.op == HasTag || p.op == NotHasTag {
+			not := ""
+			if p.op == NotHasTag {
+				not = "NOT "
+			}
+			return fmt.Sprintf("(%s %sIN (SELECT et.event_id FROM event_tags et JOIN tags t ON t.id = et.tag_id WHERE LOWER(t.name) = LOWER(%s)))",
+					d.IDColumn(), not, placeholder),
+				[]interface{}{strings.TrimSpace(p.value)}, startIdx + 1
+		}
 		if p.op == Like || p.op == NotLike {
 			return fmt.Sprintf("(%s %s %s)", quotedField, p.op, placeholder),
 				[]interface{}{"%" + p.value + "%"}, startIdx + 1
 		}
+		if p.op == Regexp || p.op == NotRegexp || p.op == LikeCase {
+			md, ok := d.(MatchDialect)
+			if !ok {
+				md = sqliteMatchDialect{}
+			}
+			sql := md.ContainsCaseSQL(quotedField, placeholder)
+			if p.op != LikeCase {
+				sql = md.RegexpSQL(quotedField, placeholder, p.op == NotRegexp)
+			}
+			return "(" + sql + ")", []interface{}{p.value}, startIdx + 1
+		}
 		return fmt.Sprintf("(%s %s %s)", quotedField, p.op, placeholder),
 			[]interface{}{p.value}, startIdx + 1
 
@@ -152,6 +353,69 @@ func (p *Predicate) whereClauseWithDialect(d QueryDialect, startIdx int) (string
 		return d.DateBetweenSQL(startIdx, startIdx+1),
 			[]interface{}{p.date1, p.date2}, startIdx + 2
 
+	case predCompare:
+		if p.escaped {
+			md, ok := d.(MatchDialect)
+			if !ok {
+				md = sqliteMatchDialect{}
+			}
+			return "(" + md.LikeSQL(d.QuoteColumn(p.field), d.Placeholder(startIdx)) + ")",
+				[]interface{}{p.value}, startIdx + 1
+		}
+		if p.numeric {
+			n, _ := strconv.ParseInt(p.value, 10, 64)
+			return fmt.Sprintf("(%s %s %s)", integerSQL(d, d.QuoteColumn(p.field)), p.op, d.Placeholder(startIdx)),
+				[]interface{}{n}, startIdx + 1
+		}
+		return fmt.Sprintf("(%s %s %s)", d.QuoteColumn(p.field), p.op, d.Placeholder(startIdx)),
+			[]interface{}{p.value}, startIdx + 1
+
+	case predIn:
+		ph := make([]string, len(p.values))
+		args := make([]interface{}, len(p.values))
+		for i, v := range p.values {
+			ph[i] = d.Placeholder(startIdx + i)
+			args[i] = v
+		}
+		return fmt.Sprintf("(%s IN (%s))", d.QuoteColumn(p.field), strings.Join(ph, ", ")),
+			args, startIdx + len(p.values)
+
+	case predBetween:
+		if p.numeric {
+			low, _ := strconv.ParseInt(p.values[0], 10, 64)
+			high, _ := strconv.ParseInt(p.values[1], 10, 64)
+			return fmt.Sprintf("(%s BETWEEN %s AND %s)", integerSQL(d, d.QuoteColumn(p.field)), d.Placeholder(startIdx), d.Placeholder(startIdx+1)),
+				[]interface{}{low, high}, startIdx + 2
+		}
+		return fmt.Sprintf("(%s BETWEEN %s AND %s)", d.QuoteColumn(p.field), d.Placeholder(startIdx), d.Placeholder(startIdx+1)),
+			[]interface{}{p.values[0], p.values[1]}, startIdx + 2
+
+	case predEmpty:
+		col := d.QuoteColumn(p.field)
+		if !isTextField(p.field) {
+			return fmt.Sprintf("(%s IS NULL)", col), nil, startIdx
+		}
+		return fmt.Sprintf("(%s IS NULL OR %s = '')", col, col), nil, startIdx
+
+	case predNot:
+		sql, args, next := p.left.whereClauseWithDialect(d, startIdx)
+		if sql == "" {
+			return "", nil, next
+		}
+		return "(NOT " + sql + ")", args, next
+
+	case predSearch:
+		if ft, ok := d.(FullTextDialect); ok {
+			if sql, args, ok := ft.FullTextPredicate(p.value, startIdx); ok {
+				return sql, args, startIdx + len(args)
+			}
+		}
+		likes := make([]*Predicate, 0, len(model.SearchFields))
+		for _, f := range model.SearchFields {
+			likes = append(likes, Simple(f, Like, p.value))
+		}
+		return Combine(likes, OR).whereClauseWithDialect(d, startIdx)
+
 	case predComposite:
 		leftSQL, leftArgs, nextIdx := p.left.whereClauseWithDialect(d, startIdx)
 		rightSQL, rightArgs, nextIdx2 := p.right.whereClauseWithDialect(d, nextIdx)
@@ -189,10 +453,14 @@ func (p *Predicate) Fields() []string {
 	switch p.kind {
 	case predNone:
 		return nil
-	case predSimple:
+	case predSimple, predCompare, predIn, predBetween, predEmpty:
 		return []string{p.field}
+	case predNot:
+		return p.left.Fields()
 	case predDate:
 		return []string{"datetime"}
+	case predSearch:
+		return append([]string(nil), model.SearchFields...)
 	case predComposite:
 		seen := make(map[string]bool)
 		var result []string
@@ -214,14 +482,52 @@ func (p *Predicate) Fields() []string {
 	}
 }
 
+// ExcludesSource reports whether the predicate can only match rows whose
+// source differs from the given value, ignoring case: it requires source to
+// equal, or be one of, other values. Callers use it to leave examiner notes
+// (source "EXAMINER") out of results. It errs on the side of false.
+func (p *Predicate) ExcludesSource(source string) bool {
+	if p == nil {
+		return false
+	}
+	switch p.kind {
+	case predSimple, predCompare:
+		return p.field == "source" && p.op == Equal && !strings.EqualFold(p.value, source)
+	case predIn:
+		if p.field != "source" {
+			return false
+		}
+		for _, v := range p.values {
+			if strings.EqualFold(v, source) {
+				return false
+			}
+		}
+		return true
+	case predComposite:
+		if p.logic == OR {
+			return p.left.ExcludesSource(source) && p.right.ExcludesSource(source)
+		}
+		return p.left.ExcludesSource(source) || p.right.ExcludesSource(source)
+	}
+	return false
+}
+
+// SortKey is one column of a sort order.
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
 // Query builds a full SELECT statement from predicates, ordering, and pagination.
 type Query struct {
 	predicates []*Predicate
 	logic      Logic
-	orderBy    string
+	sort       []SortKey
 	pageSize   int
 	page       int
 	dialect    QueryDialect
+	pageMode   PageMode
+	cursor     *Cursor
 }
 
 // New creates a new Query with the given page size.
@@ -267,21 +573,57 @@ func (q *Query) ClearPredicates() {
 	q.predicates = nil
 }
 
-// OrderBy sets the column to sort results by.
+// OrderBy sets the column to sort results by, ascending.
 // Pass an empty string to clear ordering.
 // Returns an error if the field name is not valid.
 func (q *Query) OrderBy(field string) error {
 	if field == "" {
-		q.orderBy = ""
-		return nil
+		return q.SetSort()
 	}
-	if !isValidField(field) && field != q.dialect.IDColumn() {
-		return fmt.Errorf("invalid order by field: %s", field)
+	return q.SetSort(SortKey{Field: field})
+}
+
+// SetSort sets the columns to sort results by, most significant first. The
+// id column is appended as a final tiebreaker, so rows that tie on every key
+// keep the same order from page to page. Pass no keys to clear ordering.
+// Returns an error if a field name is not valid.
+func (q *Query) SetSort(keys ...SortKey) error {
+	for _, k := range keys {
+		if !isValidField(k.Field) && k.Field != q.dialect.IDColumn() {
+			return fmt.Errorf("invalid order by field: %s", k.Field)
+		}
 	}
-	q.orderBy = field
+	q.sort = append([]SortKey(nil), keys...)
 	return nil
 }
 
+// orderClause returns the ORDER BY list for the sort keys and the id
+// tiebreaker, or "" if the query is unsorted. The id column keeps its name
+// through the database package's examiner notes union, so the list applies
+// to the wrapped query too.
+func (q *Query) orderClause() string {
+	if len(q.sort) == 0 {
+		return ""
+	}
+	idCol := q.dialect.IDColumn()
+	parts := make([]string, 0, len(q.sort)+1)
+	for _, k := range q.sort {
+		col := idCol
+		if k.Field != idCol {
+			col = q.dialect.QuoteColumn(k.Field)
+		}
+		if k.Desc {
+			col += " DESC"
+		}
+		parts = append(parts, col)
+		if k.Field == idCol {
+			// Nothing sorts after the id
+			return strings.Join(parts, ", ")
+		}
+	}
+	return strings.Join(append(parts, idCol), ", ")
+}
+
 // SetPage sets the current page number (1-based).
 func (q *Query) SetPage(page int) {
 	if page >= 1 {
@@ -319,9 +661,13 @@ func (q *Query) Build() (string, []interface{}) {
 		}
 	}
 
-	// ORDER BY
-	if q.orderBy != "" {
-		sql += " ORDER BY " + q.dialect.QuoteColumn(q.orderBy)
+	if q.pageMode != PageOffset {
+		return q.buildKeyset(sql, allArgs, len(allArgs)+1)
+	}
+
+	// ORDER BY, with the id tiebreaker
+	if order := q.orderClause(); order != "" {
+		sql += " ORDER BY " + order
 	}
 
 	// LIMIT / OFFSET for pagination
@@ -369,63 +715,43 @@ func (q *Query) PredicateFields() []string {
 	return result
 }
 
-// RawQuery wraps a user-provided SQL WHERE clause for direct execution.
-// This is the equivalent of the original SQLQuery class.
-type RawQuery struct {
-	Query
-	rawWhere string
-}
-
-// NewRaw creates a query from a raw WHERE clause string.
-// The raw clause is used as-is, so the caller is responsible for safety.
-// Pagination and ordering still work normally on top of it.
-func NewRaw(pageSize int, whereClause string) *RawQuery {
-	return &RawQuery{
-		Query:    *New(pageSize),
-		rawWhere: whereClause,
+// splitList splits a comma-separated list, trimming each value and
+// dropping empty ones.
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
 	}
+	return values
 }
 
-// SetRawWhere updates the raw WHERE clause.
-func (rq *RawQuery) SetRawWhere(where string) {
-	rq.rawWhere = where
+// numericTextFields are TEXT columns that usually hold whole numbers, which
+// ordered comparisons and ranges compare numerically rather than as text.
+var numericTextFields = map[string]bool{
+	"event_identifier": true, "record_number": true, "inode": true,
 }
 
-// Build generates the SQL using the raw WHERE clause plus ordering and pagination.
-func (rq *RawQuery) Build() (string, []interface{}) {
-	idCol := rq.dialect.IDColumn()
-	quotedFields := make([]string, len(model.Fields))
-	for i, f := range model.Fields {
-		quotedFields[i] = rq.dialect.QuoteColumn(f)
-	}
-	selectFields := idCol + ", " + strings.Join(quotedFields, ", ")
-	sql := "SELECT " + selectFields + " FROM log2timeline"
-
-	if rq.rawWhere != "" {
-		sql += " WHERE " + rq.rawWhere
+// isWholeNumber reports whether s is an unsigned whole number that fits in
+// the 18 digits every dialect's IntegerSQL accepts.
+func isWholeNumber(s string) bool {
+	if s == "" || len(s) > 18 {
+		return false
 	}
-
-	if rq.orderBy != "" {
-		sql += " ORDER BY " + rq.dialect.QuoteColumn(rq.orderBy)
-	}
-
-	if rq.pageSize > 0 {
-		offset := rq.pageSize * (rq.page - 1)
-		sql += fmt.Sprintf(" LIMIT %d OFFSET %d", rq.pageSize, offset)
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
 	}
-
-	// Raw queries don't use parameterized args for the WHERE clause
-	return sql, nil
+	return true
 }
 
-// BuildCount generates a COUNT query using the raw WHERE clause.
-func (rq *RawQuery) BuildCount() (string, []interface{}) {
-	idCol := rq.dialect.IDColumn()
-	sql := "SELECT COUNT(" + idCol + ") FROM log2timeline"
-	if rq.rawWhere != "" {
-		sql += " WHERE " + rq.rawWhere
-	}
-	return sql, nil
+// isTextField reports whether a column holds text in every dialect.
+// datetime is a native timestamp on PostgreSQL and MySQL.
+func isTextField(name string) bool {
+	_, ok := (&model.Event{}).Value(name).(string)
+	return ok && name != "datetime"
 }
 
 // isValidField checks a field name against the known columns.
@@ -1,6 +1,7 @@
 package query
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -70,6 +71,161 @@ func TestNotEqualPredicate(t *testing.T) {
 	}
 }
 
+func TestHasTagPredicate(t *testing.T) {
+	p := Simple("tag", HasTag, " Lateral ")
+	sql, args := p.WhereClause()
+
+	want := "(rowid IN (SELECT et.event_id FROM event_tags et JOIN tags t ON t.id = et.tag_id WHERE LOWER(t.name) = LOWER(?)))"
+	if sql != want {
+		t.Errorf("expected %q, got %q", want, sql)
+	}
+	if len(args) != 1 || args[0] != "Lateral" {
+		t.Errorf("expected args ['Lateral'], got %v", args)
+	}
+
+	sql, _ = Simple("tag", NotHasTag, "lateral").WhereClause()
+	if !strings.HasPrefix(sql, "(rowid NOT IN (SELECT") {
+		t.Errorf("expected a NOT IN subquery, got %q", sql)
+	}
+}
+
+func TestHasTagRequiresTagField(t *testing.T) {
+	if p := Simple("desc", HasTag, "lateral"); p != nil {
+		t.Error("expected nil for a tag operator on a field other than tag")
+	}
+}
+
+func TestNumericTextComparisons(t *testing.T) {
+	const eventID = "(CASE WHEN event_identifier <> '' AND event_identifier NOT GLOB '*[^0-9]*' THEN CAST(event_identifier AS INTEGER) END)"
+	tests := []struct {
+		pred *Predicate
+		sql  string
+		args []interface{}
+	}{
+		{Between("event_identifier", "4624", "4625"), "(" + eventID + " BETWEEN ? AND ?)", []interface{}{int64(4624), int64(4625)}},
+		{Compare("event_identifier", GreaterOrEqual, "4624"), "(" + eventID + " >= ?)", []interface{}{int64(4624)}},
+		// Equality and non-numeric bounds still compare text
+		{Compare("event_identifier", Equal, "4624"), "(event_identifier = ?)", []interface{}{"4624"}},
+		{Between("inode", "12-1", "12-9"), "(inode BETWEEN ? AND ?)", []interface{}{"12-1", "12-9"}},
+		{Between("host", "1", "9"), "(host BETWEEN ? AND ?)", []interface{}{"1", "9"}},
+	}
+	for _, tt := range tests {
+		sql, args := tt.pred.WhereClause()
+		if sql != tt.sql || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("WhereClause = %s %#v, want %s %#v", sql, args, tt.sql, tt.args)
+		}
+	}
+}
+
+func TestParseOperator(t *testing.T) {
+	if op, ok := ParseOperator(" has tag "); !ok || op != HasTag {
+		t.Errorf("ParseOperator(\" has tag \") = %q, %v", op, ok)
+	}
+	if op, ok := ParseOperator("like"); !ok || op != Like {
+		t.Errorf("ParseOperator(\"like\") = %q, %v", op, ok)
+	}
+	if _, ok := ParseOperator("DROP"); ok {
+		t.Error("expected an unknown operator to be rejected")
+	}
+}
+
+func TestListRangeAndEmptyOperators(t *testing.T) {
+	tests := []struct {
+		op    Operator
+		value string
+		sql   string
+		args  int
+	}{
+		{InList, "4624, 4625,,4634", "(event_identifier IN (?, ?, ?))", 3},
+		{NotInList, "4624", "(NOT (event_identifier IN (?)))", 1},
+		{InRange, "4600,4700", "((CASE WHEN event_identifier <> '' AND event_identifier NOT GLOB '*[^0-9]*' THEN CAST(event_identifier AS INTEGER) END) BETWEEN ? AND ?)", 2},
+		{Empty, "ignored", "(event_identifier IS NULL OR event_identifier = '')", 0},
+		{NotEmpty, "", "(NOT (event_identifier IS NULL OR event_identifier = ''))", 0},
+	}
+	for _, tt := range tests {
+		p := Simple("event_identifier", tt.op, tt.value)
+		sql, args := p.WhereClause()
+		if sql != tt.sql || len(args) != tt.args {
+			t.Errorf("%s %q = %s %v, want %s with %d args", tt.op, tt.value, sql, args, tt.sql, tt.args)
+		}
+		if f := p.Fields(); len(f) != 1 || f[0] != "event_identifier" {
+			t.Errorf("%s Fields = %v", tt.op, f)
+		}
+	}
+	if Simple("host", InList, " , ") != nil || Simple("host", InRange, "a") != nil {
+		t.Error("expected nil for an empty list or a one-sided range")
+	}
+}
+
+func TestRegexpAndCaseOperators(t *testing.T) {
+	tests := []struct {
+		d    QueryDialect
+		op   Operator
+		want string
+	}{
+		{DefaultDialect, Regexp, "(desc REGEXP ?)"},
+		{DefaultDialect, NotRegexp, "(desc NOT REGEXP ?)"},
+		{DefaultDialect, LikeCase, "(instr(desc, ?) > 0)"},
+		{matchingDialect{}, Regexp, "(desc ~ $1)"},
+		{matchingDialect{}, NotRegexp, "(desc !~ $1)"},
+		{matchingDialect{}, LikeCase, "(strpos(desc, $1) > 0)"},
+	}
+	for _, tt := range tests {
+		sql, args, _ := Simple("desc", tt.op, `mimi[k]atz\.exe`).WhereClauseFor(tt.d, 1)
+		if sql != tt.want || len(args) != 1 || args[0] != `mimi[k]atz\.exe` {
+			t.Errorf("%s = %s %v, want %s", tt.op, sql, args, tt.want)
+		}
+	}
+	if Simple("offset", Regexp, "1") != nil || Simple("datetime", LikeCase, "2025") != nil {
+		t.Error("expected nil for a text operator on a non-text field")
+	}
+	if op, ok := ParseOperator("not regexp"); !ok || op != NotRegexp {
+		t.Errorf("ParseOperator(\"not regexp\") = %q, %v", op, ok)
+	}
+}
+
+func TestPattern(t *testing.T) {
+	pattern := "%" + EscapeLike(`C:\Temp\50%_off`) + "%"
+	if want := `%C:\\Temp\\50\%\_off%`; pattern != want {
+		t.Fatalf("pattern = %s, want %s", pattern, want)
+	}
+	tests := []struct {
+		d    QueryDialect
+		want string
+	}{
+		{DefaultDialect, `(filename LIKE ? ESCAPE '\')`},
+		{matchingDialect{}, `(filename ILIKE $1 ESCAPE '\')`},
+	}
+	for _, tt := range tests {
+		sql, args, _ := Pattern("filename", pattern).WhereClauseFor(tt.d, 1)
+		if sql != tt.want || len(args) != 1 || args[0] != pattern {
+			t.Errorf("Pattern = %s %v, want %s", sql, args, tt.want)
+		}
+	}
+	if Pattern("offset", "1%") != nil || Pattern("nosuch", "x") != nil {
+		t.Error("expected nil for a field that is not text")
+	}
+}
+
+// matchingDialect is a test dialect implementing MatchDialect the way
+// PostgreSQL does.
+type matchingDialect struct{ numberedDialect }
+
+func (matchingDialect) RegexpSQL(column, placeholder string, negate bool) string {
+	if negate {
+		return column + " !~ " + placeholder
+	}
+	return column + " ~ " + placeholder
+}
+
+func (matchingDialect) ContainsCaseSQL(column, placeholder string) string {
+	return "strpos(" + column + ", " + placeholder + ") > 0"
+}
+
+func (matchingDialect) LikeSQL(column, placeholder string) string {
+	return column + " ILIKE " + placeholder + ` ESCAPE '\'`
+}
+
 func TestDateRangePredicate(t *testing.T) {
 	p := DateRange("2025-01-01 00:00:00", "2025-06-30 23:59:59")
 	sql, args := p.WhereClause()
@@ -212,6 +368,55 @@ func TestPredicateFields(t *testing.T) {
 	}
 }
 
+// fullTextDialect is a test dialect with a full-text index that rejects
+// text containing no letters.
+type fullTextDialect struct{ sqliteQueryDialect }
+
+func (d fullTextDialect) FullTextPredicate(text string, paramIdx int) (string, []interface{}, bool) {
+	if strings.Trim(text, "0123456789 ") == "" {
+		return "", nil, false
+	}
+	return "(fts MATCH ?)", []interface{}{"<" + text + ">"}, true
+}
+
+func TestSearchPredicateFallsBackToLike(t *testing.T) {
+	sql, args := Search("mimikatz").WhereClause()
+
+	if strings.Count(sql, " LIKE ?") != 14 || !strings.Contains(sql, " OR ") {
+		t.Errorf("expected 14 OR'd LIKE clauses, got: %s", sql)
+	}
+	if len(args) != 14 || args[0] != "%mimikatz%" {
+		t.Errorf("expected 14 wildcard args, got %v", args)
+	}
+}
+
+func TestSearchPredicateUsesFullTextDialect(t *testing.T) {
+	p := Combine([]*Predicate{Simple("host", Equal, "PC1"), Search("mimikatz")}, AND)
+	sql, args, next := p.WhereClauseFor(fullTextDialect{}, 1)
+
+	if sql != "((host = ?) AND (fts MATCH ?))" {
+		t.Errorf("unexpected sql: %s", sql)
+	}
+	if len(args) != 2 || args[1] != "<mimikatz>" {
+		t.Errorf("expected converted search arg, got %v", args)
+	}
+	if next != 3 {
+		t.Errorf("expected next index 3, got %d", next)
+	}
+
+	// Text the index cannot match falls back to LIKE
+	sql, _, _ = Search("42").WhereClauseFor(fullTextDialect{}, 1)
+	if !strings.Contains(sql, "LIKE") {
+		t.Errorf("expected LIKE fallback, got: %s", sql)
+	}
+}
+
+func TestSearchPredicateBlank(t *testing.T) {
+	if Search("   ") != nil {
+		t.Error("expected nil predicate for blank search text")
+	}
+}
+
 // --- Query builder tests ---
 
 func TestQueryBuildNoPredicates(t *testing.T) {
@@ -273,6 +478,33 @@ func TestQueryOrderByRowid(t *testing.T) {
 	}
 }
 
+func TestQuerySetSort(t *testing.T) {
+	tests := []struct {
+		keys []SortKey
+		want string
+	}{
+		{[]SortKey{{Field: "datetime"}}, " ORDER BY datetime, rowid"},
+		{[]SortKey{{Field: "host"}, {Field: "datetime", Desc: true}}, " ORDER BY host, datetime DESC, rowid"},
+		{[]SortKey{{Field: "user"}, {Field: "rowid", Desc: true}, {Field: "host"}}, " ORDER BY user, rowid DESC"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		q := New(0)
+		if err := q.SetSort(tt.keys...); err != nil {
+			t.Fatalf("SetSort(%v) failed: %v", tt.keys, err)
+		}
+		sql, _ := q.Build()
+		if !strings.HasSuffix(sql, "FROM log2timeline"+tt.want) {
+			t.Errorf("SetSort(%v) = %s, want suffix %q", tt.keys, sql, tt.want)
+		}
+	}
+
+	q := New(0)
+	if err := q.SetSort(SortKey{Field: "datetime"}, SortKey{Field: "DROP TABLE"}); err == nil {
+		t.Error("expected error for invalid sort field")
+	}
+}
+
 func TestQueryBuildWithPagination(t *testing.T) {
 	q := New(1000)
 	q.SetPage(1)
@@ -404,45 +636,3 @@ func TestQueryORLogic(t *testing.T) {
 		t.Errorf("expected OR logic, got: %s", sql)
 	}
 }
-
-// --- RawQuery tests ---
-
-func TestRawQueryBuild(t *testing.T) {
-	rq := NewRaw(1000, "source = 'FILE' AND host = 'WS1'")
-	rq.OrderBy("datetime")
-	rq.SetPage(1)
-
-	sql, args := rq.Build()
-
-	if !strings.Contains(sql, "WHERE source = 'FILE' AND host = 'WS1'") {
-		t.Errorf("expected raw WHERE clause, got: %s", sql)
-	}
-	if !strings.Contains(sql, "ORDER BY datetime") {
-		t.Errorf("expected ORDER BY, got: %s", sql)
-	}
-	if !strings.Contains(sql, "LIMIT 1000 OFFSET 0") {
-		t.Errorf("expected LIMIT/OFFSET, got: %s", sql)
-	}
-	if args != nil {
-		t.Errorf("expected nil args for raw query, got: %v", args)
-	}
-}
-
-func TestRawQueryEmpty(t *testing.T) {
-	rq := NewRaw(0, "")
-	sql, _ := rq.Build()
-
-	if strings.Contains(sql, "WHERE") {
-		t.Errorf("expected no WHERE for empty raw query, got: %s", sql)
-	}
-}
-
-func TestRawQuerySetRawWhere(t *testing.T) {
-	rq := NewRaw(0, "source = 'FILE'")
-	rq.SetRawWhere("host = 'WS2'")
-
-	sql, _ := rq.Build()
-	if !strings.Contains(sql, "host = 'WS2'") {
-		t.Errorf("expected updated WHERE, got: %s", sql)
-	}
-}
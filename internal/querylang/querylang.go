@@ -0,0 +1,597 @@
+// Package querylang parses the field:value search syntax of Lucene, Kibana
+// (KQL) and Timesketch and compiles it to query.Predicate trees, so
+// analysts can write
+//
+//	host:WS01 AND user:admin* AND NOT source:REG
+//
+// The syntax is:
+//
+//	field:value          exact match; * and ? in value match any run of
+//	                     characters and any one character
+//	field:"two words"    exact match of a quoted value (no wildcards)
+//	field:*              field is not empty
+//	field:>value         also >=, < and <=
+//	field:[a TO b]       inclusive range; {a TO b} is exclusive and * leaves
+//	                     an end open
+//	field:(a OR b)       a group whose bare values apply to field
+//	word                 full-text search of model.SearchFields
+//	"two words"          substring match of the phrase in any search field
+//	a AND b, a b         both (AND is the default between clauses)
+//	a OR b               either
+//	NOT a, -a            negation
+//	( ... )              grouping
+//
+// Keywords are case-insensitive, as are field names, which may also be one
+// of the Timesketch names in fieldAliases. A backslash makes the next
+// character literal. A field:value on the tag field matches a whole tag, as
+// the HAS TAG filter does. Values on datetime accept partial dates through
+// the DateExpander, so datetime:2025-01 matches all of January.
+package querylang
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cdtdelta/4n6time/internal/model"
+	"github.com/cdtdelta/4n6time/internal/query"
+)
+
+// DateExpander expands partial dates in datetime values; see
+// query.DateExpander.
+type DateExpander = query.DateExpander
+
+// fieldAliases maps the Timesketch and plaso names analysts often type to
+// 4n6time columns.
+var fieldAliases = map[string]string{
+	"message":      "desc",
+	"timestamp":    "datetime",
+	"hostname":     "host",
+	"username":     "user",
+	"data_type":    "sourcetype",
+	"tags":         "tag",
+	"display_name": "filename",
+}
+
+// Parse compiles input into a predicate tree. expand, if not nil, expands
+// partial dates in datetime values. Blank input returns a nil predicate.
+// Errors are *query.SyntaxError values carrying the column of the problem.
+func Parse(input string, expand DateExpander) (*query.Predicate, error) {
+	if expand == nil {
+		expand = func(value string, end bool) string { return value }
+	}
+	p := &parser{input: input, expand: expand}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokEOF {
+		return nil, nil
+	}
+	pred, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok.describe())
+	}
+	return pred, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokPhrase
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokLBrace
+	tokRBrace
+)
+
+type token struct {
+	kind tokenKind
+	text string // a word as typed, escapes included, or an unescaped phrase
+	pos  int    // byte offset in the input
+}
+
+// describe names the token for error messages.
+func (t token) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of input"
+	case tokPhrase:
+		return fmt.Sprintf("phrase %q", t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// keyword reports whether the token is one of the given keywords. && and
+// || are synonyms for AND and OR.
+func (t token) keyword(kws ...string) bool {
+	if t.kind != tokWord {
+		return false
+	}
+	for _, kw := range kws {
+		if strings.EqualFold(t.text, kw) ||
+			(kw == "AND" && t.text == "&&") || (kw == "OR" && t.text == "||") {
+			return true
+		}
+	}
+	return false
+}
+
+type parser struct {
+	input  string
+	pos    int
+	tok    token
+	expand DateExpander
+	field  string // the field bare values apply to inside field:( ... )
+}
+
+func (p *parser) errorAt(pos int, format string, args ...interface{}) error {
+	return &query.SyntaxError{
+		Column: utf8.RuneCountInString(p.input[:pos]) + 1,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+// errorf reports a problem at the current token.
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return p.errorAt(p.tok.pos, format, args...)
+}
+
+// isDelimiter reports whether r ends a word.
+func isDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(`()[]{}"`, r)
+}
+
+// advance reads the next token into p.tok.
+func (p *parser) advance() error {
+	s := p.input
+	for p.pos < len(s) {
+		r, n := utf8.DecodeRuneInString(s[p.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		p.pos += n
+	}
+	start := p.pos
+	if start >= len(s) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return nil
+	}
+
+	if i := strings.IndexByte("()[]{}", s[start]); i >= 0 {
+		kinds := []tokenKind{tokLParen, tokRParen, tokLBracket, tokRBracket, tokLBrace, tokRBrace}
+		p.pos++
+		p.tok = token{kind: kinds[i], text: s[start : start+1], pos: start}
+		return nil
+	}
+
+	if s[start] == '"' {
+		var b strings.Builder
+		i := start + 1
+		for {
+			if i >= len(s) {
+				return p.errorAt(start, "unterminated phrase")
+			}
+			if s[i] == '"' {
+				break
+			}
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+			}
+			b.WriteByte(s[i])
+			i++
+		}
+		p.pos = i + 1
+		p.tok = token{kind: tokPhrase, text: b.String(), pos: start}
+		return nil
+	}
+
+	i := start
+	for i < len(s) {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		if isDelimiter(r) {
+			break
+		}
+		if r == '\\' && i+1 < len(s) {
+			_, m := utf8.DecodeRuneInString(s[i+1:])
+			n += m
+		}
+		i += n
+	}
+	p.pos = i
+	p.tok = token{kind: tokWord, text: s[start:i], pos: start}
+	return nil
+}
+
+// startsClause reports whether the current token can begin a clause, which
+// makes the AND between adjacent clauses implicit.
+func (p *parser) startsClause() bool {
+	switch p.tok.kind {
+	case tokWord:
+		return !p.tok.keyword("AND", "OR")
+	case tokPhrase, tokLParen, tokLBracket, tokLBrace:
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (*query.Predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.keyword("OR") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = query.Combine([]*query.Predicate{left, right}, query.OR)
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (*query.Predicate, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if p.tok.keyword("AND") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		} else if !p.startsClause() {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = query.Combine([]*query.Predicate{left, right}, query.AND)
+	}
+}
+
+func (p *parser) parseUnary() (*query.Predicate, error) {
+	switch {
+	case p.tok.keyword("NOT"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	case p.tok.kind == tokWord && strings.HasPrefix(p.tok.text, "-"):
+		// -clause: drop the dash and parse what follows it
+		if p.tok.text == "-" {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		} else {
+			p.tok.text = p.tok.text[1:]
+			p.tok.pos++
+		}
+	default:
+		return p.parsePrimary()
+	}
+	inner, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return query.Not(inner), nil
+}
+
+func (p *parser) parsePrimary() (*query.Predicate, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokLParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("expected ')' but found %s", p.tok.describe())
+		}
+		return inner, p.advance()
+
+	case tokLBracket, tokLBrace:
+		if p.field == "" {
+			return nil, p.errorf("a range needs a field, as in datetime:[a TO b]")
+		}
+		return p.parseRange()
+
+	case tokPhrase:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.field != "" {
+			return p.exact(p.field, tok.text), nil
+		}
+		return containsAny(query.EscapeLike(tok.text)), nil
+
+	case tokWord:
+		if p.tok.keyword("AND", "OR") {
+			return nil, p.errorf("unexpected %s; quote it to search for the word", strings.ToUpper(tok.text))
+		}
+		if p.field != "" {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			return p.fieldValue(p.field, tok)
+		}
+		if i := fieldSeparator(tok.text); i > 0 {
+			return p.parseFieldClause(tok, i)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return freeText(tok.text), nil
+	}
+	return nil, p.errorf("expected a search term but found %s", tok.describe())
+}
+
+// parseFieldClause parses field:value, where the current token is the word
+// tok with its field separator at index sep.
+func (p *parser) parseFieldClause(tok token, sep int) (*query.Predicate, error) {
+	field, ok := resolveField(unescape(tok.text[:sep]))
+	if !ok {
+		return nil, p.errorf("unknown field %q", unescape(tok.text[:sep]))
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if rest := tok.text[sep+1:]; rest != "" {
+		return p.fieldValue(field, token{kind: tokWord, text: rest, pos: tok.pos + sep + 1})
+	}
+
+	// field: followed by a phrase, group or range
+	switch p.tok.kind {
+	case tokPhrase, tokWord, tokLParen, tokLBracket, tokLBrace:
+	default:
+		return nil, p.errorf("expected a value after %s: but found %s", field, p.tok.describe())
+	}
+	outer := p.field
+	p.field = field
+	pred, err := p.parsePrimary()
+	p.field = outer
+	return pred, err
+}
+
+// parseRange parses [low TO high] with either end exclusive when written
+// with a brace.
+func (p *parser) parseRange() (*query.Predicate, error) {
+	field := p.field
+	lowInclusive := p.tok.kind == tokLBracket
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	low, err := p.rangeEnd()
+	if err != nil {
+		return nil, err
+	}
+	if !p.tok.keyword("TO") {
+		return nil, p.errorf("expected TO in range but found %s", p.tok.describe())
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	high, err := p.rangeEnd()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokRBracket && p.tok.kind != tokRBrace {
+		return nil, p.errorf("expected ']' or '}' but found %s", p.tok.describe())
+	}
+	highInclusive := p.tok.kind == tokRBracket
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if low != "*" && high != "*" && lowInclusive && highInclusive {
+		if field == "datetime" {
+			low, high = p.expand(low, false), p.expand(high, true)
+		}
+		return query.Between(field, low, high), nil
+	}
+	var preds []*query.Predicate
+	if low != "*" {
+		op := query.GreaterThan
+		if lowInclusive {
+			op = query.GreaterOrEqual
+		}
+		preds = append(preds, p.compare(field, op, low))
+	}
+	if high != "*" {
+		op := query.LessThan
+		if highInclusive {
+			op = query.LessOrEqual
+		}
+		preds = append(preds, p.compare(field, op, high))
+	}
+	if len(preds) == 0 {
+		return query.Not(query.IsEmpty(field)), nil
+	}
+	return query.Combine(preds, query.AND), nil
+}
+
+// rangeEnd reads one end of a range, returning "*" for an open end.
+func (p *parser) rangeEnd() (string, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokPhrase:
+		return tok.text, p.advance()
+	case tokWord:
+		if !tok.keyword("TO") {
+			value := unescape(tok.text)
+			if tok.text == "*" {
+				value = "*"
+			} else if value == "*" {
+				return "", p.errorf("quote a literal * in a range")
+			}
+			return value, p.advance()
+		}
+	}
+	return "", p.errorf("expected a range value but found %s", tok.describe())
+}
+
+// fieldValue compiles the value written as tok for field: a comparison,
+// field:*, a wildcard pattern or an exact match.
+func (p *parser) fieldValue(field string, tok token) (*query.Predicate, error) {
+	raw := tok.text
+	for _, op := range []query.Operator{query.GreaterOrEqual, query.LessOrEqual, query.GreaterThan, query.LessThan} {
+		if strings.HasPrefix(raw, string(op)) {
+			value := unescape(raw[len(op):])
+			if value == "" {
+				return nil, p.errorAt(tok.pos, "expected a value after %s", op)
+			}
+			return p.compare(field, op, value), nil
+		}
+	}
+	if raw == "*" {
+		return query.Not(query.IsEmpty(field)), nil
+	}
+	pattern, wild := likePattern(raw)
+	if !wild {
+		return p.exact(field, unescape(raw)), nil
+	}
+	if !isTextField(field) {
+		return nil, p.errorAt(tok.pos, "wildcards only apply to text fields; use a range on %s", field)
+	}
+	return query.Pattern(field, pattern), nil
+}
+
+// exact matches field against value. Tags match a whole tag; a partial date
+// matches the period it names.
+func (p *parser) exact(field, value string) *query.Predicate {
+	switch field {
+	case "tag":
+		return query.Simple("tag", query.HasTag, value)
+	case "datetime":
+		low, high := p.expand(value, false), p.expand(value, true)
+		if low != high {
+			return query.Between(field, low, high)
+		}
+		value = low
+	}
+	return query.Compare(field, query.Equal, value)
+}
+
+// compare applies a comparison operator, expanding a partial date to the
+// end of its period where that keeps the comparison intuitive: datetime:>2025
+// means after 2025, not after its first second.
+func (p *parser) compare(field string, op query.Operator, value string) *query.Predicate {
+	if field == "datetime" {
+		value = p.expand(value, op == query.GreaterThan || op == query.LessOrEqual)
+	}
+	return query.Compare(field, op, value)
+}
+
+// freeText searches model.SearchFields for a word. Words with wildcards
+// become LIKE patterns, since full-text indexes only support trailing ones,
+// as do words holding a literal % or _, which query.Search would read as
+// wildcards.
+func freeText(raw string) *query.Predicate {
+	pattern, wild := likePattern(raw)
+	word := unescape(raw)
+	if !wild && !strings.ContainsAny(word, "%_") {
+		return query.Search(word)
+	}
+	return containsAny(pattern)
+}
+
+// containsAny matches events with pattern, a query.Pattern, anywhere in one
+// of model.SearchFields. Unlike query.Search it behaves the same with and
+// without a full-text index, which keeps phrases exact.
+func containsAny(pattern string) *query.Predicate {
+	if strings.TrimSpace(pattern) == "" {
+		return nil
+	}
+	likes := make([]*query.Predicate, 0, len(model.SearchFields))
+	for _, f := range model.SearchFields {
+		likes = append(likes, query.Pattern(f, "%"+pattern+"%"))
+	}
+	return query.Combine(likes, query.OR)
+}
+
+// fieldSeparator returns the index of the first unescaped ':' in a word, or
+// -1.
+func fieldSeparator(word string) int {
+	for i := 0; i < len(word); i++ {
+		switch word[i] {
+		case '\\':
+			i++
+		case ':':
+			return i
+		}
+	}
+	return -1
+}
+
+// unescape removes backslash escapes from a word.
+func unescape(word string) string {
+	if !strings.Contains(word, `\`) {
+		return word
+	}
+	var b strings.Builder
+	for i := 0; i < len(word); i++ {
+		if word[i] == '\\' && i+1 < len(word) {
+			i++
+		}
+		b.WriteByte(word[i])
+	}
+	return b.String()
+}
+
+// likePattern converts a word to a query.Pattern, turning its unescaped *
+// and ? wildcards into % and _ and escaping everything else with
+// query.EscapeLike, and reports whether there were any wildcards.
+func likePattern(word string) (string, bool) {
+	var b strings.Builder
+	wild := false
+	for i := 0; i < len(word); i++ {
+		c := word[i]
+		switch {
+		case c == '\\' && i+1 < len(word):
+			i++
+			b.WriteString(query.EscapeLike(word[i : i+1]))
+		case c == '*':
+			b.WriteByte('%')
+			wild = true
+		case c == '?':
+			b.WriteByte('_')
+			wild = true
+		default:
+			b.WriteString(query.EscapeLike(word[i : i+1]))
+		}
+	}
+	return b.String(), wild
+}
+
+// resolveField returns the column a field name refers to, ignoring case.
+func resolveField(name string) (string, bool) {
+	if f, ok := fieldAliases[strings.ToLower(name)]; ok {
+		return f, true
+	}
+	for _, f := range model.Fields {
+		if strings.EqualFold(f, name) {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// isTextField reports whether a column holds free text that LIKE can match
+// on every backend.
+func isTextField(name string) bool {
+	_, ok := (&model.Event{}).Value(name).(string)
+	return ok && name != "datetime"
+}
@@ -0,0 +1,153 @@
+package querylang
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cdtdelta/4n6time/internal/query"
+)
+
+// expandDate is a DateExpander for whole days.
+func expandDate(value string, end bool) string {
+	if len(value) != len("2025-01-15") {
+		return value
+	}
+	if end {
+		return value + " 23:59:59"
+	}
+	return value + " 00:00:00"
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		sql   string
+		args  []interface{}
+	}{
+		{"host:WS01", "(host = ?)", []interface{}{"WS01"}},
+		{"HOST:WS01 AND user:admin* AND NOT source:REG",
+			"(((host = ?) AND (user LIKE ? ESCAPE '\\')) AND (NOT (source = ?)))", []interface{}{"WS01", "admin%", "REG"}},
+		{"host:WS01 user:adm?n", "((host = ?) AND (user LIKE ? ESCAPE '\\'))", []interface{}{"WS01", "adm_n"}},
+		{`filename:C\:\\temp\\a_b*`, "(filename LIKE ? ESCAPE '\\')", []interface{}{`C:\\temp\\a\_b%`}},
+		{"host:WS01 OR host:WS02 -source:REG",
+			"((host = ?) OR ((host = ?) AND (NOT (source = ?))))", []interface{}{"WS01", "WS02", "REG"}},
+		{`desc:"Run key added"`, "(desc = ?)", []interface{}{"Run key added"}},
+		{`message:"a*b"`, "(desc = ?)", []interface{}{"a*b"}},
+		{`filename:C\:\\Windows\*`, "(filename = ?)", []interface{}{`C:\Windows*`}},
+		{"host:(WS01 OR WS02)", "((host = ?) OR (host = ?))", []interface{}{"WS01", "WS02"}},
+		{"(host:WS01 || host:WS02) && user:*", "(((host = ?) OR (host = ?)) AND (NOT (user IS NULL OR user = '')))",
+			[]interface{}{"WS01", "WS02"}},
+		{"datetime:[2024-01-01 TO 2024-01-02]", "(datetime BETWEEN ? AND ?)",
+			[]interface{}{"2024-01-01 00:00:00", "2024-01-02 23:59:59"}},
+		{"datetime:{2024-01-01 TO *]", "(datetime > ?)", []interface{}{"2024-01-01 23:59:59"}},
+		{`timestamp:["2024-01-01 10:00:00" TO "2024-01-01 11:00:00"}`, "((datetime >= ?) AND (datetime < ?))",
+			[]interface{}{"2024-01-01 10:00:00", "2024-01-01 11:00:00"}},
+		{"datetime:2024-01-01", "(datetime BETWEEN ? AND ?)", []interface{}{"2024-01-01 00:00:00", "2024-01-01 23:59:59"}},
+		{"offset:>=-5 offset:<10", "((offset >= ?) AND (offset < ?))", []interface{}{"-5", "10"}},
+		{"event_identifier:[4624 TO 4625]",
+			"((CASE WHEN event_identifier <> '' AND event_identifier NOT GLOB '*[^0-9]*' THEN CAST(event_identifier AS INTEGER) END) BETWEEN ? AND ?)",
+			[]interface{}{int64(4624), int64(4625)}},
+		{"record_number:{100 TO *]",
+			"((CASE WHEN record_number <> '' AND record_number NOT GLOB '*[^0-9]*' THEN CAST(record_number AS INTEGER) END) > ?)",
+			[]interface{}{int64(100)}},
+		{"tag:persistence", "(rowid IN (SELECT et.event_id FROM event_tags et JOIN tags t ON t.id = et.tag_id WHERE LOWER(t.name) = LOWER(?)))",
+			[]interface{}{"persistence"}},
+		{"not host:*", "(NOT (NOT (host IS NULL OR host = '')))", nil},
+	}
+	for _, tt := range tests {
+		p, err := Parse(tt.input, expandDate)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.input, err)
+			continue
+		}
+		sql, args := p.WhereClause()
+		if sql != tt.sql || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("Parse(%q) = %s %v, want %s %v", tt.input, sql, args, tt.sql, tt.args)
+		}
+	}
+}
+
+func TestParseFreeText(t *testing.T) {
+	p, err := Parse("mimikatz host:WS01", nil)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := query.Combine([]*query.Predicate{query.Search("mimikatz"), query.Compare("host", query.Equal, "WS01")}, query.AND)
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("expected a full-text search ANDed with host, got %v", p)
+	}
+
+	// Phrases and wildcards match substrings of every search field
+	for _, input := range []string{`"run key"`, "mimi*"} {
+		p, err := Parse(input, nil)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", input, err)
+		}
+		sql, args := p.WhereClause()
+		if strings.Count(sql, " LIKE ?") != 14 || !strings.HasPrefix(sql, "((((") {
+			t.Errorf("Parse(%q) = %s", input, sql)
+		}
+		if arg := args[0].(string); arg != "%run key%" && arg != "%mimi%%" {
+			t.Errorf("Parse(%q) pattern = %q", input, arg)
+		}
+	}
+}
+
+// Quoted and escaped text matches itself: % and _ are not wildcards.
+func TestParseLiteralWildcards(t *testing.T) {
+	for input, want := range map[string]string{
+		`"a_b"`: `%a\_b%`,
+		`100\%`: `%100\%%`,
+	} {
+		p, err := Parse(input, nil)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", input, err)
+		}
+		sql, args := p.WhereClause()
+		if strings.Count(sql, " LIKE ? ESCAPE '\\'") != 14 {
+			t.Errorf("Parse(%q) = %s", input, sql)
+		}
+		if arg := args[0].(string); arg != want {
+			t.Errorf("Parse(%q) pattern = %q, want %q", input, arg, want)
+		}
+	}
+}
+
+func TestParseBlank(t *testing.T) {
+	if p, err := Parse("  ", nil); p != nil || err != nil {
+		t.Errorf("Parse(blank) = %v, %v; want nil, nil", p, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		column int
+		msg    string
+	}{
+		{"nosuch:x", 1, `unknown field "nosuch"`},
+		{"host:", 6, "expected a value after host:"},
+		{"(host:A", 8, "expected ')'"},
+		{"host:A)", 7, `unexpected ")"`},
+		{"datetime:[2024 2025]", 16, "expected TO in range"},
+		{"datetime:[2024 TO 2025", 23, "expected ']' or '}'"},
+		{"[a TO b]", 1, "a range needs a field"},
+		{`desc:"abc`, 6, "unterminated phrase"},
+		{"offset:1*", 8, "wildcards only apply to text fields"},
+		{"host:A AND OR host:B", 12, "unexpected OR"},
+		{"user:>=", 6, "expected a value after >="},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input, nil)
+		var se *query.SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("Parse(%q) error = %v, want a SyntaxError", tt.input, err)
+			continue
+		}
+		if se.Column != tt.column || !strings.Contains(se.Msg, tt.msg) {
+			t.Errorf("Parse(%q) = %v, want column %d containing %q", tt.input, err, tt.column, tt.msg)
+		}
+	}
+}
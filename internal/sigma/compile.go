@@ -0,0 +1,498 @@
+package sigma
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/cdtdelta/4n6time/internal/model"
+	"github.com/cdtdelta/4n6time/internal/query"
+)
+
+// FieldMap maps Sigma field names, in lower case, onto log2timeline
+// columns.
+type FieldMap map[string]string
+
+// DefaultFields maps the Windows event log fields that plaso stores in
+// their own columns. Other fields, such as Image and CommandLine, are kept
+// by plaso in the event's extra attributes and message, and are matched
+// there (see Compile).
+var DefaultFields = FieldMap{
+	"eventid":       "event_identifier",
+	"eventrecordid": "record_number",
+	"provider_name": "source_name",
+	"computer":      "computer_name",
+	"computername":  "computer_name",
+	"hostname":      "host",
+	"user":          "user",
+	"username":      "user",
+	"usersid":       "user_sid",
+	"url":           "URL",
+	"c-uri":         "URL",
+}
+
+// unmappedColumns are searched for the fields a FieldMap does not name.
+var unmappedColumns = []string{"extra", "desc"}
+
+// serviceSources restricts a rule to the event log provider of its
+// logsource service, as a query.Pattern on source_name.
+var serviceSources = map[string]string{
+	"sysmon":        "%Sysmon%",
+	"security":      "Microsoft-Windows-Security-Auditing",
+	"powershell":    "%PowerShell%",
+	"taskscheduler": "%TaskScheduler%",
+	"windefend":     "%Windows Defender%",
+}
+
+// Compile turns a rule's detection into a predicate over log2timeline,
+// mapping field names through fields. The rule's logsource service, when
+// it names a known event log provider, further restricts the matches;
+// other services, products and categories add no condition.
+//
+// Fields with a column match it as Sigma does, ignoring case: exactly, or
+// by the contains, startswith and endswith modifiers, with * and ? as
+// wildcards. Fields without one cannot be located exactly, so any value
+// matches when it appears anywhere in the extra attributes or the message.
+// Other characters, % and _ included, match only themselves. Aggregations,
+// timeframes and the base64, cidr and comparison modifiers are not
+// supported and return an error.
+func Compile(r *Rule, fields FieldMap) (*query.Predicate, error) {
+	c := &compiler{fields: fields, selections: make(map[string]*query.Predicate)}
+	var names []string
+	for name, def := range r.Detection {
+		switch name {
+		case "condition":
+			continue
+		case "timeframe":
+			return nil, fmt.Errorf("timeframes are not supported")
+		}
+		p, err := c.selection(def)
+		if err != nil {
+			return nil, fmt.Errorf("selection %s: %w", name, err)
+		}
+		c.selections[name] = p
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	c.names = names
+
+	var conditions []string
+	switch cond := r.Detection["condition"].(type) {
+	case string:
+		conditions = []string{cond}
+	case []interface{}:
+		for _, v := range cond {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid condition: %v", v)
+			}
+			conditions = append(conditions, s)
+		}
+	default:
+		return nil, fmt.Errorf("rule has no condition")
+	}
+
+	var preds []*query.Predicate
+	for _, cond := range conditions {
+		p, err := c.condition(cond)
+		if err != nil {
+			return nil, fmt.Errorf("condition %q: %w", cond, err)
+		}
+		preds = append(preds, p)
+	}
+	pred := query.Combine(preds, query.OR)
+
+	if pattern, ok := serviceSources[strings.ToLower(r.LogSource.Service)]; ok {
+		pred = query.Combine([]*query.Predicate{query.Pattern("source_name", pattern), pred}, query.AND)
+	}
+	return pred, nil
+}
+
+type compiler struct {
+	fields     FieldMap
+	selections map[string]*query.Predicate
+	names      []string
+
+	// condition parser state
+	tokens []string
+	pos    int
+}
+
+// selection compiles one search identifier: a map of fields, all of which
+// must match; a list of such maps, any of which must; or a list of
+// keywords searched for like the search bar does.
+func (c *compiler) selection(def interface{}) (*query.Predicate, error) {
+	switch def := def.(type) {
+	case map[string]interface{}:
+		return c.fieldMap(def)
+	case []interface{}:
+		if len(def) == 0 {
+			return nil, fmt.Errorf("empty selection")
+		}
+		var preds []*query.Predicate
+		for _, item := range def {
+			var p *query.Predicate
+			var err error
+			if m, ok := item.(map[string]interface{}); ok {
+				p, err = c.fieldMap(m)
+			} else {
+				p, err = keyword(item)
+			}
+			if err != nil {
+				return nil, err
+			}
+			preds = append(preds, p)
+		}
+		return query.Combine(preds, query.OR), nil
+	}
+	return nil, fmt.Errorf("unsupported selection: %v", def)
+}
+
+// keyword matches a value anywhere in the searchable fields.
+func keyword(v interface{}) (*query.Predicate, error) {
+	s := strings.Trim(scalar(v), "*")
+	if s == "" || hasWildcard(s) {
+		return nil, fmt.Errorf("unsupported keyword: %v", v)
+	}
+	return query.Search(unescape(s)), nil
+}
+
+func (c *compiler) fieldMap(m map[string]interface{}) (*query.Predicate, error) {
+	if len(m) == 0 {
+		return nil, fmt.Errorf("empty selection")
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	preds := make([]*query.Predicate, 0, len(keys))
+	for _, k := range keys {
+		p, err := c.field(k, m[k])
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, p)
+	}
+	return query.Combine(preds, query.AND), nil
+}
+
+// field compiles "Field|modifier|..." against one value or a list of them,
+// any of which may match, or all of them with the all modifier.
+func (c *compiler) field(key string, value interface{}) (*query.Predicate, error) {
+	parts := strings.Split(key, "|")
+	name := strings.TrimSpace(parts[0])
+	mods := make(map[string]bool)
+	for _, m := range parts[1:] {
+		m = strings.ToLower(strings.TrimSpace(m))
+		switch m {
+		case "contains", "startswith", "endswith", "all", "re", "cased", "windash", "exists":
+			mods[m] = true
+		default:
+			return nil, fmt.Errorf("unsupported modifier %q on %s", m, name)
+		}
+	}
+	column := c.fields[strings.ToLower(name)]
+	if column != "" && !slices.Contains(model.Fields, column) {
+		return nil, fmt.Errorf("%s is mapped to unknown column %s", name, column)
+	}
+
+	var values []interface{}
+	if list, ok := value.([]interface{}); ok {
+		values = list
+		if len(values) == 0 {
+			return nil, fmt.Errorf("no values for %s", name)
+		}
+	} else {
+		values = []interface{}{value}
+	}
+
+	preds := make([]*query.Predicate, 0, len(values))
+	for _, v := range values {
+		variants := []interface{}{v}
+		if mods["windash"] {
+			variants = windash(v)
+		}
+		var alts []*query.Predicate
+		for _, alt := range variants {
+			p, err := match(name, column, mods, alt)
+			if err != nil {
+				return nil, err
+			}
+			alts = append(alts, p)
+		}
+		preds = append(preds, query.Combine(alts, query.OR))
+	}
+	logic := query.OR
+	if mods["all"] {
+		logic = query.AND
+	}
+	return query.Combine(preds, logic), nil
+}
+
+// match compiles one value of a field, on column or, when column is "",
+// anywhere in unmappedColumns.
+func match(name, column string, mods map[string]bool, v interface{}) (*query.Predicate, error) {
+	if mods["exists"] || v == nil {
+		if column == "" {
+			return nil, fmt.Errorf("cannot test whether %s is present", name)
+		}
+		if b, ok := v.(bool); mods["exists"] && (!ok || b) {
+			return query.Not(query.IsEmpty(column)), nil
+		}
+		return query.IsEmpty(column), nil
+	}
+
+	s := scalar(v)
+	if column == "" {
+		var preds []*query.Predicate
+		for _, col := range unmappedColumns {
+			p, err := columnMatch(col, mods, s, true)
+			if err != nil {
+				return nil, err
+			}
+			preds = append(preds, p)
+		}
+		return query.Combine(preds, query.OR), nil
+	}
+	return columnMatch(column, mods, s, false)
+}
+
+// columnMatch matches s on column, anywhere in it if anywhere is set.
+func columnMatch(column string, mods map[string]bool, s string, anywhere bool) (*query.Predicate, error) {
+	var p *query.Predicate
+	switch {
+	case mods["re"]:
+		p = query.Simple(column, query.Regexp, s)
+	case mods["cased"]:
+		if hasWildcard(s) {
+			return nil, fmt.Errorf("wildcards are not supported with cased: %s", s)
+		}
+		s = unescape(s)
+		switch {
+		case anywhere || mods["contains"]:
+			p = query.Simple(column, query.LikeCase, s)
+		case mods["startswith"]:
+			p = query.Combine([]*query.Predicate{query.Pattern(column, query.EscapeLike(s)+"%"), query.Simple(column, query.LikeCase, s)}, query.AND)
+		case mods["endswith"]:
+			p = query.Combine([]*query.Predicate{query.Pattern(column, "%"+query.EscapeLike(s)), query.Simple(column, query.LikeCase, s)}, query.AND)
+		default:
+			p = query.Compare(column, query.Equal, s)
+		}
+	default:
+		pattern := likePattern(s)
+		switch {
+		case anywhere || mods["contains"]:
+			pattern = "%" + pattern + "%"
+		case mods["startswith"]:
+			pattern += "%"
+		case mods["endswith"]:
+			pattern = "%" + pattern
+		}
+		p = query.Pattern(column, pattern)
+	}
+	if p == nil {
+		return nil, fmt.Errorf("cannot match %s on %s", s, column)
+	}
+	return p, nil
+}
+
+// scalar formats a YAML scalar as the text stored in the database.
+func scalar(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// likePattern turns a Sigma value into a Pattern: * and ? become % and _,
+// their backslash escapes become the literal characters, and the rest of
+// the text is escaped with query.EscapeLike.
+func likePattern(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == '\\' && i+1 < len(s) && strings.IndexByte(`*?\`, s[i+1]) >= 0:
+			i++
+			b.WriteString(query.EscapeLike(s[i : i+1]))
+		case ch == '*':
+			b.WriteByte('%')
+		case ch == '?':
+			b.WriteByte('_')
+		default:
+			b.WriteString(query.EscapeLike(s[i : i+1]))
+		}
+	}
+	return b.String()
+}
+
+// hasWildcard reports whether s has an unescaped * or ?.
+func hasWildcard(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '*', '?':
+			return true
+		}
+	}
+	return false
+}
+
+// unescape removes the backslash escapes of a value without wildcards.
+func unescape(s string) string {
+	return strings.NewReplacer(`\*`, `*`, `\?`, `?`, `\\`, `\`).Replace(s)
+}
+
+// windashFlag matches a command line flag introduced by a dash.
+var windashFlag = regexp.MustCompile(`(^|\s)-`)
+
+// windash returns v and, if it has dash flags, the same value with slash
+// flags, since Windows programs accept either.
+func windash(v interface{}) []interface{} {
+	if s, ok := v.(string); ok && windashFlag.MatchString(s) {
+		return []interface{}{v, windashFlag.ReplaceAllString(s, "${1}/")}
+	}
+	return []interface{}{v}
+}
+
+// condition parses a detection condition such as
+// "selection and not (filter1 or 1 of filter_*)".
+func (c *compiler) condition(cond string) (*query.Predicate, error) {
+	c.tokens = tokenize(cond)
+	c.pos = 0
+	if len(c.tokens) == 0 {
+		return nil, fmt.Errorf("empty condition")
+	}
+	p, err := c.or()
+	if err != nil {
+		return nil, err
+	}
+	if c.pos < len(c.tokens) {
+		return nil, fmt.Errorf("unexpected %q", c.tokens[c.pos])
+	}
+	return p, nil
+}
+
+func tokenize(cond string) []string {
+	cond = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(cond)
+	return strings.Fields(cond)
+}
+
+func (c *compiler) peek() string {
+	if c.pos < len(c.tokens) {
+		return strings.ToLower(c.tokens[c.pos])
+	}
+	return ""
+}
+
+func (c *compiler) next() string {
+	t := c.tokens[c.pos]
+	c.pos++
+	return t
+}
+
+func (c *compiler) or() (*query.Predicate, error) {
+	return c.binary("or", query.OR, c.and)
+}
+
+func (c *compiler) and() (*query.Predicate, error) {
+	return c.binary("and", query.AND, c.not)
+}
+
+// binary parses operands joined by op, such as "a or b or c".
+func (c *compiler) binary(op string, logic query.Logic, operand func() (*query.Predicate, error)) (*query.Predicate, error) {
+	p, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	preds := []*query.Predicate{p}
+	for c.peek() == op {
+		c.next()
+		p, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, p)
+	}
+	return query.Combine(preds, logic), nil
+}
+
+func (c *compiler) not() (*query.Predicate, error) {
+	if c.peek() != "not" {
+		return c.primary()
+	}
+	c.next()
+	p, err := c.not()
+	if err != nil {
+		return nil, err
+	}
+	return query.Not(p), nil
+}
+
+func (c *compiler) primary() (*query.Predicate, error) {
+	switch tok := c.peek(); tok {
+	case "":
+		return nil, fmt.Errorf("unexpected end of condition")
+	case "(":
+		c.next()
+		p, err := c.or()
+		if err != nil {
+			return nil, err
+		}
+		if c.peek() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		c.next()
+		return p, nil
+	case "|":
+		return nil, fmt.Errorf("aggregations are not supported")
+	case "1", "any", "all":
+		c.next()
+		if c.peek() != "of" {
+			return nil, fmt.Errorf("expected of after %s", tok)
+		}
+		c.next()
+		if c.peek() == "" {
+			return nil, fmt.Errorf("expected a selection after of")
+		}
+		return c.quantified(tok == "all", c.next())
+	}
+
+	name := c.next()
+	p, ok := c.selections[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown selection %q", name)
+	}
+	return p, nil
+}
+
+// quantified matches one or all of the selections whose names match
+// pattern, or of every selection for "them" except those whose names start
+// with an underscore.
+func (c *compiler) quantified(all bool, pattern string) (*query.Predicate, error) {
+	var preds []*query.Predicate
+	for _, name := range c.names {
+		var ok bool
+		if strings.EqualFold(pattern, "them") {
+			ok = !strings.HasPrefix(name, "_")
+		} else {
+			ok, _ = path.Match(pattern, name)
+		}
+		if ok {
+			preds = append(preds, c.selections[name])
+		}
+	}
+	if len(preds) == 0 {
+		return nil, fmt.Errorf("no selection matches %q", pattern)
+	}
+	logic := query.OR
+	if all {
+		logic = query.AND
+	}
+	return query.Combine(preds, logic), nil
+}
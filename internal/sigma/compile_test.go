@@ -0,0 +1,187 @@
+package sigma
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cdtdelta/4n6time/internal/database"
+)
+
+// compileRule compiles a rule with the default field map and returns its
+// WHERE clause.
+func compileRule(t *testing.T, doc string) (string, []interface{}) {
+	t.Helper()
+	r, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := Compile(r, DefaultFields)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	return p.WhereClause()
+}
+
+func TestCompileFieldsAndModifiers(t *testing.T) {
+	sql, args := compileRule(t, `title: t
+logsource: {product: windows, service: sysmon}
+detection:
+  sel:
+    EventID: 1
+    Image|endswith: '\mimikatz.exe'
+  filter_system:
+    User: 'NT AUTHORITY\SYSTEM'
+  filter_enc:
+    CommandLine|windash|contains|all: ['-enc', ' x ']
+  condition: sel and not 1 of filter_*
+`)
+	want := "((source_name LIKE ? ESCAPE '\\') AND (((event_identifier LIKE ? ESCAPE '\\') AND ((extra LIKE ? ESCAPE '\\') OR (desc LIKE ? ESCAPE '\\'))) AND " +
+		"(NOT (((((extra LIKE ? ESCAPE '\\') OR (desc LIKE ? ESCAPE '\\')) OR ((extra LIKE ? ESCAPE '\\') OR (desc LIKE ? ESCAPE '\\'))) AND ((extra LIKE ? ESCAPE '\\') OR (desc LIKE ? ESCAPE '\\'))) OR (user LIKE ? ESCAPE '\\')))))"
+	if sql != want {
+		t.Errorf("sql =\n%s\nwant\n%s", sql, want)
+	}
+	wantArgs := []interface{}{"%Sysmon%", "1", `%\\mimikatz.exe%`, `%\\mimikatz.exe%`,
+		"%-enc%", "%-enc%", "%/enc%", "%/enc%", "% x %", "% x %", `NT AUTHORITY\\SYSTEM`}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %q", args)
+	}
+}
+
+func TestCompileListsAndKeywords(t *testing.T) {
+	sql, args := compileRule(t, `title: t
+detection:
+  keywords: ['*evil*']
+  sel:
+    - EventID: [4624, 4625]
+      Computer|startswith: 'WS\*'
+    - Computer|re: '^WS\d+$'
+      UserSid: null
+    - Computer|cased: WS01
+      User|exists: true
+  _helper:
+    Hostname: 'x?z'
+  condition: all of them
+`)
+	for i, arg := range args[:14] {
+		if arg != "%evil%" {
+			t.Errorf("keyword arg %d = %q, want it searched across the search fields", i, arg)
+		}
+	}
+	for _, arg := range args {
+		if arg == "x_z" {
+			t.Errorf("them included the _helper selection: %s", sql)
+		}
+	}
+	tail := "((((computer_name LIKE ? ESCAPE '\\') AND ((event_identifier LIKE ? ESCAPE '\\') OR (event_identifier LIKE ? ESCAPE '\\'))) OR " +
+		"((computer_name REGEXP ?) AND (user_sid IS NULL OR user_sid = ''))) OR " +
+		"((computer_name = ?) AND (NOT (user IS NULL OR user = ''))))"
+	if !strings.HasSuffix(sql, " AND "+tail+")") {
+		t.Errorf("sql =\n%s\nwant suffix\n%s", sql, tail)
+	}
+	wantArgs := []interface{}{"WS*%", "4624", "4625", `^WS\d+$`, "WS01"}
+	if !reflect.DeepEqual(args[len(args)-5:], wantArgs) {
+		t.Errorf("args = %q", args)
+	}
+}
+
+// On PostgreSQL, whose LIKE matches case, values are matched with ILIKE,
+// and the explicit ESCAPE keeps the backslashes of Windows paths literal.
+func TestCompilePostgres(t *testing.T) {
+	r, err := Parse([]byte(`title: t
+detection:
+  sel:
+    Image|endswith: '\mimikatz.exe'
+    User: 'NT AUTHORITY\SYSTEM'
+    Computer|startswith: 'ws_'
+  condition: sel
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := Compile(r, DefaultFields)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	sql, args, _ := p.WhereClauseFor(&database.PostgresDialect{}, 1)
+	want := `(((computer_name ILIKE $1 ESCAPE '\') AND ((extra ILIKE $2 ESCAPE '\') OR ("desc" ILIKE $3 ESCAPE '\'))) AND ("user" ILIKE $4 ESCAPE '\'))`
+	if sql != want {
+		t.Errorf("sql =\n%s\nwant\n%s", sql, want)
+	}
+	wantArgs := []interface{}{`ws\_%`, `%\\mimikatz.exe%`, `%\\mimikatz.exe%`, `NT AUTHORITY\\SYSTEM`}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %q", args)
+	}
+}
+
+func TestCompileConditions(t *testing.T) {
+	rule := `title: t
+detection:
+  a: {EventID: 1}
+  b: {EventID: 2}
+  c: {EventID: 3}
+  condition: %s
+`
+	tests := map[string]string{
+		"a or b and c":          "((event_identifier LIKE ? ESCAPE '\\') OR ((event_identifier LIKE ? ESCAPE '\\') AND (event_identifier LIKE ? ESCAPE '\\')))",
+		"(a or b) and not c":    "(((event_identifier LIKE ? ESCAPE '\\') OR (event_identifier LIKE ? ESCAPE '\\')) AND (NOT (event_identifier LIKE ? ESCAPE '\\')))",
+		"1 of them":             "(((event_identifier LIKE ? ESCAPE '\\') OR (event_identifier LIKE ? ESCAPE '\\')) OR (event_identifier LIKE ? ESCAPE '\\'))",
+		"a AND NOT NOT b":       "((event_identifier LIKE ? ESCAPE '\\') AND (NOT (NOT (event_identifier LIKE ? ESCAPE '\\'))))",
+		"[a, b]":                "((event_identifier LIKE ? ESCAPE '\\') OR (event_identifier LIKE ? ESCAPE '\\'))",
+		"all of [ab] and not c": "(((event_identifier LIKE ? ESCAPE '\\') AND (event_identifier LIKE ? ESCAPE '\\')) AND (NOT (event_identifier LIKE ? ESCAPE '\\')))",
+	}
+	for cond, want := range tests {
+		sql, _ := compileRule(t, strings.Replace(rule, "%s", cond, 1))
+		if sql != want {
+			t.Errorf("condition %q =\n%s\nwant\n%s", cond, sql, want)
+		}
+	}
+}
+
+func TestCompileUnsupported(t *testing.T) {
+	tests := map[string]string{
+		"aggregation":      "sel: {EventID: 1}\n  condition: sel | count() > 5",
+		"timeframe":        "sel: {EventID: 1}\n  timeframe: 5m\n  condition: sel",
+		"modifier":         "sel: {CommandLine|base64offset|contains: x}\n  condition: sel",
+		"unknown name":     "sel: {EventID: 1}\n  condition: other",
+		"no match":         "sel: {EventID: 1}\n  condition: 1 of filter_*",
+		"unbalanced":       "sel: {EventID: 1}\n  condition: (sel",
+		"trailing":         "sel: {EventID: 1}\n  condition: sel sel",
+		"no condition":     "sel: {EventID: 1}",
+		"unmapped exists":  "sel: {Image|exists: true}\n  condition: sel",
+		"empty list":       "sel: {EventID: []}\n  condition: sel",
+		"cased wildcard":   "sel: {Image|cased: '*x'}\n  condition: sel",
+		"wildcard keyword": "sel: ['a*b']\n  condition: sel",
+	}
+	for name, detection := range tests {
+		r, err := Parse([]byte("title: t\ndetection:\n  " + detection + "\n"))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if p, err := Compile(r, DefaultFields); err == nil {
+			sql, _ := p.WhereClause()
+			t.Errorf("%s: expected an error, got %s", name, sql)
+		}
+	}
+
+	r, _ := Parse([]byte("title: t\ndetection:\n  sel: {EventID: 1}\n  condition: sel\n"))
+	if _, err := Compile(r, FieldMap{"eventid": "nosuch"}); err == nil {
+		t.Error("expected an error for a field mapped to an unknown column")
+	}
+}
+
+func TestLikePattern(t *testing.T) {
+	tests := map[string]string{
+		`C:\Windows\\*.exe`: `C:\\Windows\\%.exe`,
+		`C:\Windows\*.exe`:  `C:\\Windows*.exe`,
+		`a?c`:               `a_c`,
+		`lit\*star\?`:       `lit*star?`,
+		`back\\slash*`:      `back\\slash%`,
+		`50%_off`:           `50\%\_off`,
+	}
+	for in, want := range tests {
+		if got := likePattern(in); got != want {
+			t.Errorf("likePattern(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
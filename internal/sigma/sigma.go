@@ -0,0 +1,119 @@
+// Package sigma runs Sigma detection rules over imported timelines. Rules
+// are read from YAML, their field names mapped onto log2timeline columns,
+// and their detections compiled into query predicates.
+package sigma
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule is a Sigma rule as read from YAML. Path is the file it was read
+// from, if any.
+type Rule struct {
+	Title       string                 `yaml:"title" json:"title"`
+	ID          string                 `yaml:"id" json:"id"`
+	Status      string                 `yaml:"status" json:"status"`
+	Description string                 `yaml:"description" json:"description"`
+	Level       string                 `yaml:"level" json:"level"`
+	Tags        []string               `yaml:"tags" json:"tags"`
+	LogSource   LogSource              `yaml:"logsource" json:"logsource"`
+	Detection   map[string]interface{} `yaml:"detection" json:"-"`
+	Path        string                 `yaml:"-" json:"path"`
+}
+
+// LogSource names the logs a rule applies to.
+type LogSource struct {
+	Product  string `yaml:"product" json:"product"`
+	Category string `yaml:"category" json:"category"`
+	Service  string `yaml:"service" json:"service"`
+}
+
+// LoadError records a rule file that could not be read.
+type LoadError struct {
+	Path string `json:"path"`
+	Err  string `json:"error"`
+}
+
+// Parse reads a rule from YAML. Only the first document is read; rule
+// collections, which carry an action key, are not supported.
+func Parse(data []byte) (*Rule, error) {
+	var doc map[string]interface{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("empty rule")
+		}
+		return nil, fmt.Errorf("reading rule: %w", err)
+	}
+	if _, ok := doc["action"]; ok {
+		return nil, fmt.Errorf("rule collections are not supported")
+	}
+
+	var r Rule
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("reading rule: %w", err)
+	}
+	if strings.TrimSpace(r.Title) == "" {
+		return nil, fmt.Errorf("rule has no title")
+	}
+	if len(r.Detection) == 0 {
+		return nil, fmt.Errorf("rule %q has no detection", r.Title)
+	}
+	return &r, nil
+}
+
+// LoadFile reads a rule from a YAML file.
+func LoadFile(path string) (*Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	r.Path = path
+	return r, nil
+}
+
+// LoadDir reads every .yml and .yaml file under dir, in path order. Files
+// that cannot be read as rules are returned as errors rather than stopping
+// the load, since rule repositories often hold a few unsupported ones.
+func LoadDir(dir string) ([]*Rule, []LoadError, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if !d.IsDir() && (ext == ".yml" || ext == ".yaml") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading rule directory: %w", err)
+	}
+	sort.Strings(paths)
+
+	var rules []*Rule
+	var failed []LoadError
+	for _, path := range paths {
+		r, err := LoadFile(path)
+		if err != nil {
+			failed = append(failed, LoadError{Path: path, Err: err.Error()})
+			continue
+		}
+		rules = append(rules, r)
+	}
+	return rules, failed, nil
+}
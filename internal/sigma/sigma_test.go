@@ -0,0 +1,83 @@
+package sigma
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const mimikatzRule = `title: Mimikatz Command Line
+id: a642964e-bead-4bed-8910-1bb4d63e3b4d
+status: test
+level: high
+tags:
+  - attack.credential_access
+logsource:
+  product: windows
+  category: process_creation
+detection:
+  selection:
+    CommandLine|contains:
+      - sekurlsa::
+      - lsadump::
+  condition: selection
+`
+
+func TestParse(t *testing.T) {
+	r, err := Parse([]byte(mimikatzRule))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Title != "Mimikatz Command Line" || r.Level != "high" || r.LogSource.Category != "process_creation" {
+		t.Errorf("Parse = %+v", r)
+	}
+	if len(r.Tags) != 1 || r.Detection["condition"] != "selection" {
+		t.Errorf("Parse tags %v, detection %v", r.Tags, r.Detection)
+	}
+
+	for name, doc := range map[string]string{
+		"empty":        "",
+		"no title":     "detection:\n  condition: x\n",
+		"no detection": "title: x\n",
+		"collection":   "action: global\ntitle: x\ndetection:\n  condition: x\n",
+		"not yaml":     "title: [unclosed\n",
+	} {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("Parse(%s): expected an error", name)
+		}
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"b/mimikatz.yml": mimikatzRule,
+		"a/broken.yaml":  "title: [unclosed\n",
+		"a/readme.md":    "not a rule",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rules, failed, err := LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 1 || rules[0].Title != "Mimikatz Command Line" || !strings.HasSuffix(rules[0].Path, "mimikatz.yml") {
+		t.Errorf("LoadDir rules = %+v", rules)
+	}
+	if len(failed) != 1 || !strings.HasSuffix(failed[0].Path, "broken.yaml") {
+		t.Errorf("LoadDir failed = %+v", failed)
+	}
+
+	if _, _, err := LoadDir(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
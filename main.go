@@ -40,6 +40,13 @@ func main() {
 	})
 
 	editMenu := appMenu.AddSubmenu("Edit")
+	editMenu.AddText("Undo", keys.CmdOrCtrl("z"), func(cd *menu.CallbackData) {
+		runtime.EventsEmit(app.ctx, "menu:undo")
+	})
+	editMenu.AddText("Redo", keys.Combo("z", keys.CmdOrCtrlKey, keys.ShiftKey), func(cd *menu.CallbackData) {
+		runtime.EventsEmit(app.ctx, "menu:redo")
+	})
+	editMenu.AddSeparator()
 	editMenu.AddText("Cut", keys.CmdOrCtrl("x"), func(cd *menu.CallbackData) {
 		runtime.EventsEmit(app.ctx, "menu:cut")
 	})